@@ -0,0 +1,46 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+)
+
+// CompileUnhealthyCEL compiles a spec.unhealthyCEL expression. The Node object is
+// declared as the variable "node", a dynamically typed map like its JSON form.
+// It is used by the validating webhook for rejecting broken expressions early, and
+// by the controller for building the cached evaluation programs.
+func CompileUnhealthyCEL(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("node", decls.NewMapType(decls.String, decls.Dyn)),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the CEL environment: %v", err)
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid unhealthyCEL expression: %v", issues.Err())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build a program from the unhealthyCEL expression: %v", err)
+	}
+	return program, nil
+}
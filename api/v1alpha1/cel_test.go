@@ -0,0 +1,43 @@
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestCompileUnhealthyCEL(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		expectError bool
+	}{
+		{
+			name:       "condition check",
+			expression: "node.status.conditions.exists(c, c.type == 'Ready' && c.status == 'Unknown')",
+		},
+		{
+			name:       "label and allocatable check",
+			expression: "'gpu' in node.metadata.labels && node.status.allocatable['nvidia.com/gpu'] == '0'",
+		},
+		{
+			name:        "syntax error",
+			expression:  "node.status.conditions.exists(",
+			expectError: true,
+		},
+		{
+			name:        "unknown variable",
+			expression:  "pod.metadata.name == 'foo'",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := CompileUnhealthyCEL(test.expression)
+			if test.expectError && err == nil {
+				t.Errorf("expected expression %q to fail compilation", test.expression)
+			}
+			if !test.expectError && err != nil {
+				t.Errorf("expected expression %q to compile, got: %v", test.expression, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strconv"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// UnhealthyCountIndexField indexes NHCs by status.summary.unhealthyCount, the
+	// index values are the plain decimal counts.
+	UnhealthyCountIndexField = "status.summary.unhealthyCount"
+
+	// HasUnhealthyIndexField indexes NHCs by whether any of their nodes is currently
+	// unhealthy, the index values are "true" and "false".
+	HasUnhealthyIndexField = "status.summary.hasUnhealthy"
+)
+
+// RegisterSummaryIndexes registers the status summary field indexes with the given
+// indexer, typically mgr.GetFieldIndexer(). Afterwards NHCs can be listed with field
+// selectors on their summary, e.g. client.MatchingFields{HasUnhealthyIndexField: "true"}.
+// Note that field selectors on custom fields are served from the indexed cache and only
+// support equality matches, they don't work against the API server directly.
+func RegisterSummaryIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &NodeHealthCheck{}, UnhealthyCountIndexField, func(obj client.Object) []string {
+		nhc := obj.(*NodeHealthCheck)
+		if nhc.Status.Summary == nil {
+			return nil
+		}
+		return []string{strconv.Itoa(nhc.Status.Summary.UnhealthyCount)}
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &NodeHealthCheck{}, HasUnhealthyIndexField, func(obj client.Object) []string {
+		nhc := obj.(*NodeHealthCheck)
+		if nhc.Status.Summary == nil {
+			return nil
+		}
+		return []string{strconv.FormatBool(nhc.Status.Summary.UnhealthyCount > 0)}
+	})
+}
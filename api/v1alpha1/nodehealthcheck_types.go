@@ -35,8 +35,65 @@ const (
 	ConditionReasonDisabledTemplateNotFound = "RemediationTemplateNotFound"
 	// ConditionReasonEnabled is the condition reason for type Disabled and status False
 	ConditionReasonEnabled = "NodeHealthCheckEnabled"
+	// ConditionTypeGloballyPaused is the condition type set on every NHC while the
+	// cluster wide global remediation pause is active
+	ConditionTypeGloballyPaused = "GloballyPaused"
+	// ConditionReasonGlobalPauseConfigMap is the reason for type GloballyPaused when
+	// the global pause ConfigMap exists in the operator's namespace
+	ConditionReasonGlobalPauseConfigMap = "GlobalPauseConfigMapExists"
+	// ConditionReasonGlobalPauseNotActive is the reason for type GloballyPaused and status False
+	ConditionReasonGlobalPauseNotActive = "GlobalPauseNotActive"
+	// ConditionReasonGlobalPauseSafeMode is the reason for type GloballyPaused while
+	// the operator runs in post-crash-loop safe mode and doesn't fence
+	ConditionReasonGlobalPauseSafeMode = "OperatorSafeMode"
+	// ConditionTypeDegraded is the condition type used when NHC can't remediate properly
+	ConditionTypeDegraded = "Degraded"
+	// ConditionReasonDegradedNamespaceMissing is the reason for type Degraded when the
+	// namespace holding the remediation CRs doesn't exist, e.g. after it was deleted
+	ConditionReasonDegradedNamespaceMissing = "RemediationNamespaceMissing"
+	// ConditionReasonNotDegraded is the reason for type Degraded and status False
+	ConditionReasonNotDegraded = "NodeHealthCheckOperational"
+	// ConditionTypeClockSkewed is the condition type set while the clock of the node
+	// the operator runs on is skewed too far from the API server's clock. It is a
+	// separate type from Degraded, so both can be reported at the same time.
+	ConditionTypeClockSkewed = "ClockSkewed"
+	// ConditionReasonClockSkewDetected is the reason for type ClockSkewed when the
+	// measured skew exceeds the threshold
+	ConditionReasonClockSkewDetected = "ClockSkewDetected"
+	// ConditionReasonClockSkewTolerable is the reason for type ClockSkewed and status False
+	ConditionReasonClockSkewTolerable = "ClockSkewTolerable"
+	// ConditionTypeSchemaMismatch is the condition type set when rendered remediation
+	// CRs don't match the schema of the installed remediation CRD, e.g. after a
+	// remediator API upgrade the template wasn't adjusted to
+	ConditionTypeSchemaMismatch = "RemediationSchemaMismatch"
+	// ConditionReasonSchemaMismatch is the reason for type RemediationSchemaMismatch
+	// when the pre-flight validation found offending fields
+	ConditionReasonSchemaMismatch = "SchemaValidationFailed"
+	// ConditionReasonSchemaMatches is the reason for type RemediationSchemaMismatch and status False
+	ConditionReasonSchemaMatches = "SchemaValidationPassed"
+	// ConditionTypeRecentActivity summarizes the last day's remediation activity in
+	// its message, so it shows up at a glance when describing the NHC
+	ConditionTypeRecentActivity = "RecentActivity"
+	// ConditionReasonRecentActivity is the reason for type RecentActivity when
+	// remediation activity was observed within the window
+	ConditionReasonRecentActivity = "RemediationActivityObserved"
+	// ConditionReasonNoRecentActivity is the reason for type RecentActivity and status False
+	ConditionReasonNoRecentActivity = "NoRemediationActivity"
+	// ConditionTypeApprovalPending is set while remediations wait for an admin's
+	// approval, see spec.approvalRequired
+	ConditionTypeApprovalPending = "ApprovalPending"
+	// ConditionReasonApprovalPending is the reason for type ApprovalPending while at
+	// least one node's remediation waits for approval
+	ConditionReasonApprovalPending = "AwaitingApproval"
+	// ConditionReasonNoApprovalPending is the reason for type ApprovalPending and status False
+	ConditionReasonNoApprovalPending = "NoPendingApprovals"
 )
 
+// ApprovedNodesAnnotation holds a comma-separated list of node names whose
+// remediation an admin approved, see spec.approvalRequired. The controller never
+// modifies the annotation, remove stale entries to avoid standing approvals.
+const ApprovedNodesAnnotation = "remediation.medik8s.io/approved-nodes"
+
 // NHCPhase is the string used for NHC.Status.Phase
 type NHCPhase string
 
@@ -54,6 +111,52 @@ const (
 	PhaseEnabled NHCPhase = "Enabled"
 )
 
+// NodeDeletedPolicy is the string used for NHC.Spec.NodeDeletedPolicy
+type NodeDeletedPolicy string
+
+const (
+	// NodeDeletedPolicyCompleteRemediation leaves the remediation CR in place when the
+	// node is deleted mid remediation, the remediator finishes its work
+	NodeDeletedPolicyCompleteRemediation NodeDeletedPolicy = "CompleteRemediation"
+
+	// NodeDeletedPolicyAbortAndCleanup deletes the remediation CR and cleans up the
+	// in-flight tracking when the node is deleted mid remediation
+	NodeDeletedPolicyAbortAndCleanup NodeDeletedPolicy = "AbortAndCleanup"
+
+	// NodeDeletedPolicyWaitForReplacement leaves the remediation CR in place, and waits
+	// for a node with the same name to come back healthy
+	NodeDeletedPolicyWaitForReplacement NodeDeletedPolicy = "WaitForReplacement"
+)
+
+// DeletionPolicy is the string used for NHC.Spec.DeletionPolicy
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyOrphan strips this NHC's owner reference from its in-flight
+	// remediation CRs on deletion, the remediators finish their work unmanaged
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+
+	// DeletionPolicyAbort deletes the in-flight remediation CRs and invalidates
+	// their node leases on deletion
+	DeletionPolicyAbort DeletionPolicy = "Abort"
+
+	// DeletionPolicyWaitForCompletion blocks the NHC's deletion until no
+	// remediation is in flight anymore
+	DeletionPolicyWaitForCompletion DeletionPolicy = "WaitForCompletion"
+)
+
+// MachineHealthCheckPolicy is the string used for NHC.Spec.MachineHealthCheckPolicy
+type MachineHealthCheckPolicy string
+
+const (
+	// MachineHealthCheckPolicyDisable disables NHC while custom MHCs exist in the cluster
+	MachineHealthCheckPolicyDisable MachineHealthCheckPolicy = "Disable"
+
+	// MachineHealthCheckPolicyIgnoreCoveredNodes keeps NHC running alongside custom MHCs,
+	// standing down only for nodes which are covered or already remediated by an MHC
+	MachineHealthCheckPolicyIgnoreCoveredNodes MachineHealthCheckPolicy = "IgnoreCoveredNodes"
+)
+
 // NodeHealthCheckSpec defines the desired state of NodeHealthCheck
 type NodeHealthCheckSpec struct {
 	// Label selector to match nodes whose health will be exercised.
@@ -62,6 +165,22 @@ type NodeHealthCheckSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec
 	Selector metav1.LabelSelector `json:"selector"`
 
+	// MachineSetRefs selects nodes by the MachineSets backing their machines,
+	// resolved via the machine ownership chain, instead of replicating the pool's
+	// label scheme in Selector. When set, only nodes belonging to one of the
+	// referenced MachineSets are health-checked, a non-empty Selector narrows
+	// them down further.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	MachineSetRefs []PoolRef `json:"machineSetRefs,omitempty"`
+
+	// NodePoolRefs selects nodes by the Hypershift NodePools they belong to, read
+	// from the nodes' nodePool annotation. Combined with MachineSetRefs in a
+	// logical OR, and like them a non-empty Selector narrows the nodes down further.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	NodePoolRefs []PoolRef `json:"nodePoolRefs,omitempty"`
+
 	// UnhealthyConditions contains a list of the conditions that determine
 	// whether a node is considered unhealthy.  The conditions are combined in a
 	// logical OR, i.e. if any of the conditions is met, the node is unhealthy.
@@ -81,6 +200,18 @@ type NodeHealthCheckSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	MinHealthy *intstr.IntOrString `json:"minHealthy,omitempty"`
 
+	// MinHealthyPerZone makes the MinHealthy evaluation failure-domain aware:
+	// the selected nodes are grouped by their topology.kubernetes.io/zone label, and
+	// remediation is paused only in the zones with less healthy nodes than this threshold,
+	// instead of globally. Nodes without the zone label form their own group.
+	// Expects either a positive integer value or a percentage value.
+	// Percentage values must be positive whole numbers and are capped at 100%.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:Pattern="^((100|[0-9]{1,2})%|[0-9]+)$"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MinHealthyPerZone *intstr.IntOrString `json:"minHealthyPerZone,omitempty"`
+
 	// RemediationTemplate is a reference to a remediation template
 	// provided by an infrastructure provider.
 	//
@@ -89,6 +220,241 @@ type NodeHealthCheckSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec
 	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate"`
 
+	// RemediationTemplates allows using a different remediation template per pool of nodes,
+	// e.g. a fencing based remediator for bare metal workers and a virtual machine based
+	// remediator for virtual workers, without the need of one NodeHealthCheck per pool.
+	// A node is remediated with the template of the first entry whose nodeSelector it matches.
+	// Nodes not matching any entry fall back to RemediationTemplate.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	RemediationTemplates []NodePoolRemediationTemplate `json:"remediationTemplates,omitempty"`
+
+	// OSRemediationTemplates overrides RemediationTemplate per operating system, keyed
+	// by the node's kubernetes.io/os label value, e.g. "windows". Windows nodes can't
+	// run the Self Node Remediation agent, with this field they get e.g. a fencing
+	// based remediator while Linux nodes keep the default template.
+	// Entries in RemediationTemplates take precedence over this field.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	OSRemediationTemplates map[string]*corev1.ObjectReference `json:"osRemediationTemplates,omitempty"`
+
+	// FallbackRemediationTemplate takes over for a node whose remediation CR couldn't
+	// be created repeatedly, e.g. because the remediator's webhook rejects it or a
+	// quota blocks it, instead of retrying the broken template forever. The switch
+	// happens after 3 consecutive failed creation attempts and lasts until the node
+	// is healthy again, the counters live in status.remediationCreateFailures.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	FallbackRemediationTemplate *corev1.ObjectReference `json:"fallbackRemediationTemplate,omitempty"`
+
+	// ObservationWindow is the minimum time a node must continuously match the unhealthy
+	// conditions before remediation starts, on top of the per condition durations.
+	// It absorbs brief condition flaps which would otherwise cause needless remediation.
+	// The controller tracks when a node was first observed unhealthy in
+	// status.unhealthyNodeObservations.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	ObservationWindow *metav1.Duration `json:"observationWindow,omitempty"`
+
+	// LeasePolicy tunes the node leases taken while remediating nodes of this
+	// NodeHealthCheck, overriding the operator defaults. Use it for remediation
+	// chains which legitimately hold leases longer, e.g. bare-metal fencing.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	LeasePolicy *LeasePolicy `json:"leasePolicy,omitempty"`
+
+	// FlappingDetection marks nodes whose Ready condition flips repeatedly as
+	// unhealthy, even while they are currently Ready: flapping nodes degrade
+	// workloads without ever staying NotReady long enough to match the duration
+	// based criteria. The controller tracks the observed flips in
+	// status.flapObservations.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	FlappingDetection *FlappingPolicy `json:"flappingDetection,omitempty"`
+
+	// UseOutOfServiceTaint makes the controller taint unhealthy nodes with the
+	// node.kubernetes.io/out-of-service taint while they are being remediated, and
+	// remove the taint on recovery. With the taint set, the cluster's non-graceful
+	// node shutdown handling detaches volumes and deletes pods of the node immediately,
+	// so stateful workloads fail over faster even with remediators that don't
+	// manage the taint themselves.
+	// +kubebuilder:default:=false
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	UseOutOfServiceTaint bool `json:"useOutOfServiceTaint,omitempty"`
+
+	// WaitForStorageDetach makes the controller keep a remediation in place until no
+	// volume is mid-detach from the unhealthy node anymore, before remediation is
+	// declared complete and the node lease is released. This prevents data corruption
+	// when workloads with RWO volumes fail over to another node. Combine it with
+	// UseOutOfServiceTaint so stuck volumes are force-detached instead of waiting
+	// forever.
+	// +kubebuilder:default:=false
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	WaitForStorageDetach bool `json:"waitForStorageDetach,omitempty"`
+
+	// PreFenceDrain enables connection draining before fencing: once a node needs
+	// remediation, its pod endpoints are marked not ready, and the creation of the
+	// remediation CR is delayed for the given duration, so service traffic moves to
+	// other nodes instead of being blackholed while the node is fenced.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	PreFenceDrain *metav1.Duration `json:"preFenceDrain,omitempty"`
+
+	// Virtualization holds the options for nodes hosting KubeVirt virtual machine
+	// instances, see the VirtualizationOptions type. Has no effect on clusters
+	// without KubeVirt.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	Virtualization *VirtualizationOptions `json:"virtualization,omitempty"`
+
+	// ApprovalRequired gates every remediation on explicit approval: instead of
+	// creating the remediation CR right away, the controller records the node in
+	// status.pendingApprovals and waits until an admin lists its name in the
+	// "remediation.medik8s.io/approved-nodes" annotation of this NodeHealthCheck.
+	// Requests not approved within approvalTimeout are denied, and the node stays
+	// unremediated until it recovers. Meant for change-controlled environments
+	// where fencing a node needs a sign-off.
+	// +kubebuilder:default:=false
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
+
+	// ApprovalTimeout bounds how long the controller waits for the approval of a
+	// node's remediation, see approvalRequired. Defaults to 1 hour.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	ApprovalTimeout *metav1.Duration `json:"approvalTimeout,omitempty"`
+
+	// CaptureDiagnostics makes the controller snapshot diagnostic data of an unhealthy node
+	// (conditions, resources, recent events, kubelet lease, machine status) into a ConfigMap
+	// named "nhc-diagnostics-<node name>" in the remediation CR's namespace, right before
+	// remediation starts. The node's logs are typically lost with the reboot, the bundle
+	// keeps enough context for root cause analysis.
+	// +kubebuilder:default:=false
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	CaptureDiagnostics bool `json:"captureDiagnostics,omitempty"`
+
+	// ExportNodeCondition makes the controller write a "medik8s.io/NodeHealthy"
+	// condition onto the selected Node objects, reflecting this NHC's verdict and
+	// remediation state. Workloads, descheduler policies and humans can then react
+	// to the assessment directly from the node, without reading the NHC status.
+	// +kubebuilder:default:=false
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	ExportNodeCondition bool `json:"exportNodeCondition,omitempty"`
+
+	// RemediationCRTemplateMetadata holds labels and annotations which are stamped onto
+	// every remediation CR created by this NodeHealthCheck, so cost or ownership tracking
+	// and policy engines can act on them. On top of that, labels of the NodeHealthCheck
+	// itself with the "remediation.medik8s.io/" prefix are propagated automatically.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	RemediationCRTemplateMetadata *RemediationCRMetadata `json:"remediationCRTemplateMetadata,omitempty"`
+
+	// UnhealthyResources contains a list of extended resources whose disappearance marks
+	// a node as unhealthy, e.g. a GPU node advertising "nvidia.com/gpu: 0" after previously
+	// advertising more. A node is only considered unhealthy when it advertised the resource
+	// before, and kept advertising zero of it for at least the given duration.
+	// The controller tracks the observed history in status.resourceObservations.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	UnhealthyResources []UnhealthyResource `json:"unhealthyResources,omitempty"`
+
+	// UnhealthyEvents contains a list of event based criteria: a node is considered
+	// unhealthy when at least minCount events with the given reason were observed for
+	// it within the given window, e.g. repeated "SystemOOM" events. The criteria are
+	// combined with the unhealthy conditions in a logical OR.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	UnhealthyEvents []UnhealthyEvent `json:"unhealthyEvents,omitempty"`
+
+	// UnhealthyCEL is a CEL expression evaluated against each selected Node object,
+	// which is available as "node". A node is considered unhealthy when the expression
+	// evaluates to true, in addition to the other criteria. Use it for advanced criteria
+	// which can't be expressed with the structured fields, e.g. combinations of
+	// conditions, labels, taints or allocatable resources:
+	// "node.status.allocatable['cpu'] == '0' && 'pressure' in node.metadata.labels".
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	UnhealthyCEL *string `json:"unhealthyCEL,omitempty"`
+
+	// MachineHealthCheckPolicy defines how to behave when custom MachineHealthChecks exist
+	// in the cluster. With "Disable", the default, NodeHealthCheck disables itself completely,
+	// to avoid remediating nodes twice. With "IgnoreCoveredNodes" it keeps running, but stands
+	// down for nodes which are covered by an MHC selector, or whose machine already has an
+	// in-flight MHC remediation. Use the latter for migrating from MHC to NHC without a
+	// remediation gap.
+	// +kubebuilder:default:=Disable
+	// +kubebuilder:validation:Enum=Disable;IgnoreCoveredNodes
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	MachineHealthCheckPolicy MachineHealthCheckPolicy `json:"machineHealthCheckPolicy,omitempty"`
+
+	// NodeDeletedPolicy defines what to do when a Node object is deleted while its
+	// remediation is still in flight.
+	// With "CompleteRemediation", the default, the remediation CR is left in place and
+	// the remediator finishes its work, fitting bare metal hosts which come back with
+	// the same name.
+	// With "AbortAndCleanup" the remediation CR is deleted and the in-flight tracking
+	// cleaned up, fitting cloud environments where the machine is re-provisioned and
+	// the old node never returns.
+	// With "WaitForReplacement" the remediation CR is left in place, and the tracking
+	// is only cleaned up once a node with the same name is back and healthy.
+	// +kubebuilder:default:=CompleteRemediation
+	// +kubebuilder:validation:Enum=CompleteRemediation;AbortAndCleanup;WaitForReplacement
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeDeletedPolicy NodeDeletedPolicy `json:"nodeDeletedPolicy,omitempty"`
+
+	// DeletionPolicy defines what happens to in-flight remediations when this
+	// NodeHealthCheck is deleted, enforced with a finalizer.
+	// With "Abort", the default, the remediation CRs are deleted and their node
+	// leases invalidated, so no half-fenced node is left behind unmanaged.
+	// With "Orphan" the remediation CRs are left in place and the remediators
+	// finish their work, the CRs must be cleaned up manually afterwards.
+	// With "WaitForCompletion" the deletion is blocked until all in-flight
+	// remediations completed, while no new remediation is started.
+	// +kubebuilder:default:=Abort
+	// +kubebuilder:validation:Enum=Orphan;Abort;WaitForCompletion
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Canary configures canary remediation. When enabled, a new NodeHealthCheck, or one
+	// whose unhealthy criteria changed, remediates a single node first and waits for it
+	// to recover and stay healthy for the success window, before further nodes are
+	// remediated. This protects against misconfigured criteria fencing large parts of
+	// the cluster at once.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	Canary *CanarySpec `json:"canary,omitempty"`
+
+	// ChangeRolloutPolicy phases in changed unhealthy criteria. When set, nodes matching
+	// changed criteria are held back for the grace period first, and afterwards
+	// remediated with limited concurrency until no matching node is left. This prevents
+	// a tightened criteria change from instantly fencing large parts of the cluster.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec
+	ChangeRolloutPolicy *ChangeRolloutPolicy `json:"changeRolloutPolicy,omitempty"`
+
 	// PauseRequests will prevent any new remdiation to start, while in-flight remediations
 	// keep running. Each entry is free form, and ideally represents the requested party reason
 	// for this pausing - i.e:
@@ -98,6 +464,208 @@ type NodeHealthCheckSpec struct {
 	PauseRequests []string `json:"pauseRequests,omitempty"`
 }
 
+// PoolRef references a node pool object, e.g. a MachineSet or a NodePool,
+// by namespace and name, see spec.machineSetRefs and spec.nodePoolRefs.
+type PoolRef struct {
+	// Namespace of the referenced pool object.
+	Namespace string `json:"namespace"`
+
+	// Name of the referenced pool object.
+	Name string `json:"name"`
+}
+
+// NodePoolRemediationTemplate maps a pool of nodes, selected by a label selector,
+// to the remediation template to use for them.
+type NodePoolRemediationTemplate struct {
+	// NodeSelector matches the nodes this remediation template is used for.
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+
+	// RemediationTemplate is a reference to a remediation template
+	// provided by an infrastructure provider.
+	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate"`
+}
+
+// CanarySpec configures canary remediation for new or changed unhealthy criteria.
+type CanarySpec struct {
+	// Enabled turns canary remediation on.
+	// +kubebuilder:default:=false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// SuccessWindow is how long the canary node must stay healthy after its
+	// remediation before the remaining unhealthy nodes are allowed to be remediated.
+	// +kubebuilder:default:="10m"
+	// +optional
+	SuccessWindow *metav1.Duration `json:"successWindow,omitempty"`
+}
+
+// ChangeRolloutPolicy configures how changed unhealthy criteria are phased in,
+// see spec.changeRolloutPolicy.
+type ChangeRolloutPolicy struct {
+	// GracePeriod is how long remediation is held back after the unhealthy criteria
+	// changed. During the grace period matching nodes are observed and reported, but
+	// not remediated yet.
+	// +kubebuilder:default:="5m"
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+
+	// MaxConcurrency is how many nodes may be remediated at the same time after the
+	// grace period, while the rollout is still in progress. The rollout completes once
+	// no node matches the changed criteria anymore.
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrency *int `json:"maxConcurrency,omitempty"`
+}
+
+// FlappingPolicy defines when a node's Ready condition changes often enough to
+// consider the node flapping, and with that unhealthy.
+type FlappingPolicy struct {
+	// Window is the sliding window within which Ready condition flips are counted.
+	// +kubebuilder:default:="20m"
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// MaxFlips is how many Ready condition flips within the window mark the node
+	// as flapping.
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum=2
+	// +optional
+	MaxFlips *int `json:"maxFlips,omitempty"`
+}
+
+// LeasePolicy tunes the node leases taken while remediating, unset fields keep
+// the operator defaults.
+type LeasePolicy struct {
+	// Duration is the minimum lease duration per renewal. A remediator declaring
+	// a longer typical remediation duration still gets the longer lease.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// Buffer is added to the lease duration, to give the controller time to renew
+	// the lease before it expires. Defaults to 1 minute.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	// +optional
+	Buffer *metav1.Duration `json:"buffer,omitempty"`
+
+	// MaxExtensions is how often a node's lease may be extended before it is
+	// considered overdue, which invalidates the lease and stops the remediation
+	// attempt. Defaults to 2.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxExtensions *int `json:"maxExtensions,omitempty"`
+}
+
+// VirtualizationOptions configures how nodes hosting KubeVirt virtual machine
+// instances are remediated.
+type VirtualizationOptions struct {
+	// WaitForLiveMigration delays fencing a node which hosts virtual machine
+	// instances: a live-migration is requested for each instance, and the
+	// remediation CR is only created once no instance runs on the node anymore,
+	// or liveMigrationTimeout passed. Nodes without instances are fenced right
+	// away.
+	// +kubebuilder:default:=false
+	// +optional
+	WaitForLiveMigration bool `json:"waitForLiveMigration,omitempty"`
+
+	// LiveMigrationTimeout bounds how long fencing waits for the live-migrations,
+	// instances which didn't migrate away in time are interrupted by the fencing.
+	// Defaults to 10 minutes.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	// +optional
+	LiveMigrationTimeout *metav1.Duration `json:"liveMigrationTimeout,omitempty"`
+}
+
+// ApprovalRecord tracks one pending remediation approval, see spec.approvalRequired.
+type ApprovalRecord struct {
+	// RequestedAt is when the approval was first requested.
+	RequestedAt metav1.Time `json:"requestedAt"`
+
+	// Denied is set once the approval timed out, the node is not remediated.
+	// +optional
+	Denied bool `json:"denied,omitempty"`
+}
+
+// RemediationImpact estimates the blast radius of fencing a node, see
+// status.remediationImpacts.
+type RemediationImpact struct {
+	// Pods is the number of pods running on the node.
+	Pods int `json:"pods"`
+
+	// BoundVolumes is the number of distinct persistent volume claims those pods mount.
+	BoundVolumes int `json:"boundVolumes"`
+
+	// SinglePointServices is the number of services whose endpoints all sit on the
+	// node, they lose all their backends while the node is fenced.
+	SinglePointServices int `json:"singlePointServices"`
+
+	// EstimatedAt is when the estimate was taken.
+	EstimatedAt metav1.Time `json:"estimatedAt"`
+}
+
+// RemediationCRMetadata holds the metadata stamped onto created remediation CRs.
+type RemediationCRMetadata struct {
+	// Labels to add to the remediation CRs.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to add to the remediation CRs.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// UnhealthyResource represents an extended resource and the duration it must stay at
+// zero allocatable, after having been advertised, before the node is considered unhealthy.
+type UnhealthyResource struct {
+	// Name of the extended resource, e.g. "nvidia.com/gpu".
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:MinLength=1
+	Name corev1.ResourceName `json:"name"`
+
+	// Duration the resource must stay at zero allocatable before the node is
+	// considered unhealthy.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	Duration metav1.Duration `json:"duration"`
+}
+
+// UnhealthyEvent represents repeated node events which mark a node as unhealthy
+// when they occur often enough within a sliding window.
+type UnhealthyEvent struct {
+	// Reason of the node events, e.g. "SystemOOM" or "OOMKilling".
+	// +kubebuilder:validation:MinLength=1
+	Reason string `json:"reason"`
+
+	// MinCount of events with the given reason within the window for the node
+	// to be considered unhealthy.
+	// +kubebuilder:validation:Minimum=1
+	MinCount int `json:"minCount"`
+
+	// Window is the sliding window the events are counted in.
+	// Expects a string of decimal numbers each with optional
+	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	// +kubebuilder:validation:Type=string
+	Window metav1.Duration `json:"window"`
+}
+
 // UnhealthyCondition represents a Node condition type and value with a
 // specified duration. When the named condition has been in the given
 // status for at least the duration value a node is considered unhealthy.
@@ -114,6 +682,9 @@ type UnhealthyCondition struct {
 	// Expects a string of decimal numbers each with optional
 	// fraction and a unit suffix, eg "300ms", "1.5h" or "2h45m".
 	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// A zero duration remediates as soon as the condition matches; it must be
+	// acknowledged with the "remediation.medik8s.io/allow-immediate-remediation"
+	// annotation, and a short internal debounce still applies.
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
 	// +kubebuilder:validation:Type=string
 	Duration metav1.Duration `json:"duration"`
@@ -121,6 +692,12 @@ type UnhealthyCondition struct {
 
 // NodeHealthCheckStatus defines the observed state of NodeHealthCheck
 type NodeHealthCheckStatus struct {
+	// ObservedGeneration is the generation of the spec this status was calculated for.
+	// GitOps tools, e.g. the Argo CD health check in config/argocd, compare it with
+	// metadata.generation to tell whether the controller processed the latest spec yet.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="observedNodes",xDescriptors="urn:alm:descriptor:com.tectonic.ui:observedNodes"
 	//ObservedNodes specified the number of nodes observed by using the NHC spec.selecor
 	ObservedNodes int `json:"observedNodes,omitempty"`
@@ -129,13 +706,121 @@ type NodeHealthCheckStatus struct {
 	//HealthyNodes specified the number of healthy nodes observed
 	HealthyNodes int `json:"healthyNodes,omitempty"`
 
+	// Summary rolls the per node details up into counters. The manager indexes them,
+	// so other controllers can list NHCs with unhealthy nodes via field selectors
+	// instead of scanning everything, see RegisterSummaryIndexes.
+	// +optional
+	Summary *RemediationSummary `json:"summary,omitempty"`
+
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="inFlightRemediations",xDescriptors="urn:alm:descriptor:com.tectonic.ui:inFlightRemediations"
-	//InFlightRemediations records the timestamp when remediation triggered per node
+	//InFlightRemediations records the timestamp when remediation triggered per node.
+	// Deprecated: use InFlightRemediationDetails, which carries the full picture.
+	// The map is kept in sync for older consumers.
 	InFlightRemediations map[string]metav1.Time `json:"inFlightRemediations,omitempty"`
 
+	// InFlightRemediationDetails describes the ongoing remediations in full detail:
+	// the remediation CR including its kind, the escalation step, the start time and
+	// the current state, sorted by node name. It is rebuilt from the live remediation
+	// CRs on every reconcile, which also fills it in for statuses written by older
+	// operator versions.
+	// +optional
+	InFlightRemediationDetails []InFlightRemediation `json:"inFlightRemediationDetails,omitempty"`
+
+	//UnhealthyNodeObservations records the timestamp when a node was first observed unhealthy,
+	// used for the spec.observationWindow. Entries are removed once the node is healthy again.
+	// +optional
+	UnhealthyNodeObservations map[string]metav1.Time `json:"unhealthyNodeObservations,omitempty"`
+
+	// BootObservations records each unhealthy node's boot identification when its
+	// unhealthiness was first observed. A boot ID which changed since means the node
+	// already rebooted: its observation window restarts instead of remediating again,
+	// the reboot may have fixed what fencing would reboot for. Entries are removed
+	// once the node is healthy again.
+	// +optional
+	BootObservations map[string]BootObservation `json:"bootObservations,omitempty"`
+
+	// PendingFenceDrains records when the pre-fence connection draining of a node
+	// started, see spec.preFenceDrain. Entries are removed once the drain window
+	// passed or the node turned healthy again.
+	// +optional
+	PendingFenceDrains map[string]metav1.Time `json:"pendingFenceDrains,omitempty"`
+
+	// RemediationCreateFailures counts the consecutive failed creation attempts of a
+	// node's remediation CR, see spec.fallbackRemediationTemplate. Entries are removed
+	// once the node is healthy again.
+	// +optional
+	RemediationCreateFailures map[string]int `json:"remediationCreateFailures,omitempty"`
+
+	// PendingLiveMigrations records when the live-migration of a node's virtual
+	// machine instances was requested, see spec.virtualization. Entries are removed
+	// once the node has no instances left, the timeout passed, or the node turned
+	// healthy again.
+	// +optional
+	PendingLiveMigrations map[string]metav1.Time `json:"pendingLiveMigrations,omitempty"`
+
+	// PendingApprovals records the nodes whose remediation waits for an admin's
+	// approval, see spec.approvalRequired. Approved entries are removed when the
+	// remediation starts, denied entries stay until the node turns healthy again,
+	// so a denial is not re-requested every reconcile.
+	// +optional
+	PendingApprovals map[string]ApprovalRecord `json:"pendingApprovals,omitempty"`
+
+	// RemediationImpacts records the estimated blast radius of each upcoming
+	// remediation, taken once before anything happens to the node, so approvers and
+	// post-mortems can judge the impact. Entries are removed once the node is
+	// healthy again.
+	// +optional
+	RemediationImpacts map[string]RemediationImpact `json:"remediationImpacts,omitempty"`
+
+	// ResourceObservations records the observed allocatable history of the extended resources
+	// configured in spec.unhealthyResources, keyed by "<node name>/<resource name>".
+	// An entry exists once a node advertised the resource.
+	// +optional
+	ResourceObservations map[string]ResourceObservation `json:"resourceObservations,omitempty"`
+
+	// FlapObservations records the observed Ready condition transitions per node,
+	// used for spec.flappingDetection. Entries of nodes which aren't selected
+	// anymore are removed.
+	// +optional
+	FlapObservations map[string]FlapObservation `json:"flapObservations,omitempty"`
+
+	// LastRemediationOutcomes records the most recent remediation attempt per node.
+	// Entries survive remediation CR deletion, so recurring offenders can be identified.
+	// +optional
+	LastRemediationOutcomes map[string]RemediationOutcome `json:"lastRemediationOutcomes,omitempty"`
+
+	// RemediationStats aggregates how all past remediation attempts of this config
+	// ended. The tuning report derives right-sizing suggestions from it, see the
+	// "nhc-tuning-<name>" ConfigMap in the operator namespace.
+	// +optional
+	RemediationStats *RemediationStats `json:"remediationStats,omitempty"`
+
+	// Canary tracks the canary remediation state machine, see spec.canary.
+	// +optional
+	Canary *CanaryStatus `json:"canary,omitempty"`
+
+	// ChangeRollout tracks the phase-in of changed unhealthy criteria,
+	// see spec.changeRolloutPolicy.
+	// +optional
+	ChangeRollout *ChangeRolloutStatus `json:"changeRollout,omitempty"`
+
+	// RemediationTemplateStatuses reports the validity of every remediation template
+	// the spec references, sorted by kind, namespace and name. It is written by the
+	// template validation controller independently of the remediation loop, so
+	// configuration problems surface before the first node fails.
+	// +optional
+	RemediationTemplateStatuses []RemediationTemplateStatus `json:"remediationTemplateStatuses,omitempty"`
+
+	// SpecRevisions records the last observed spec changes as short hashes with
+	// their observation time, newest last, capped at 10 entries. Together with the
+	// SpecChanged events it ties fencing behavior changes to config changes during
+	// post-incident review.
+	// +optional
+	SpecRevisions []SpecRevision `json:"specRevisions,omitempty"`
+
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="conditions",xDescriptors="urn:alm:descriptor:com.tectonic.ui:conditions"
 	// Represents the observations of a NodeHealthCheck's current state.
-	// Known .status.conditions.type are: "Disabled"
+	// Known .status.conditions.type are: "Disabled", "GloballyPaused", "Degraded"
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	// +listType=map
@@ -158,9 +843,279 @@ type NodeHealthCheckStatus struct {
 	Reason string `json:"reason,omitempty"`
 }
 
+// InFlightRemediationState is where an ongoing remediation currently stands
+type InFlightRemediationState string
+
+const (
+	// InFlightStateRemediating means the remediation CR exists and the remediator
+	// is expected to work on it
+	InFlightStateRemediating InFlightRemediationState = "Remediating"
+
+	// InFlightStateNodeDeleted means the node was deleted while its remediation CR
+	// still exists, what happens next depends on spec.nodeDeletedPolicy
+	InFlightStateNodeDeleted InFlightRemediationState = "NodeDeleted"
+)
+
+// InFlightRemediation describes one ongoing remediation: one remediation CR
+// created for an unhealthy node. A node remediated by multiple templates gets
+// one entry per remediation CR.
+type InFlightRemediation struct {
+	// NodeName is the name of the node being remediated.
+	NodeName string `json:"nodeName"`
+
+	// Resource points to the remediation CR, including its API version and kind.
+	Resource corev1.ObjectReference `json:"resource"`
+
+	// Step is the escalation step of this remediation: 1 for the first remediator
+	// of an unhealthiness, growing with every escalation to another remediator.
+	// Unset when the step isn't known, e.g. for a CR created by an older operator
+	// version.
+	// +optional
+	Step int `json:"step,omitempty"`
+
+	// Started is when the remediation CR was created.
+	Started metav1.Time `json:"started"`
+
+	// State is where the remediation currently stands, one of Remediating or
+	// NodeDeleted.
+	// +optional
+	State InFlightRemediationState `json:"state,omitempty"`
+}
+
+// RemediationOutcomeResult is how a node's remediation attempt ended
+type RemediationOutcomeResult string
+
+const (
+	// RemediationOutcomeSucceeded means the node turned healthy again
+	RemediationOutcomeSucceeded RemediationOutcomeResult = "Succeeded"
+	// RemediationOutcomeTimedOut means the remediation ran for too long without the
+	// node turning healthy
+	RemediationOutcomeTimedOut RemediationOutcomeResult = "TimedOut"
+	// RemediationOutcomeEscalated means the remediation was replaced with one of
+	// another remediator kind
+	RemediationOutcomeEscalated RemediationOutcomeResult = "Escalated"
+)
+
+// RemediationOutcome records one node's most recent remediation attempt. Ended and
+// Result are unset while the remediation is still in flight.
+type RemediationOutcome struct {
+	// Remediator is the kind of the remediation CR which was created.
+	// +optional
+	Remediator string `json:"remediator,omitempty"`
+
+	// Started is when the remediation CR was created.
+	// +optional
+	Started metav1.Time `json:"started,omitempty"`
+
+	// Ended is when the remediation ended.
+	// +optional
+	Ended *metav1.Time `json:"ended,omitempty"`
+
+	// Result is how the remediation ended, one of Succeeded, TimedOut or Escalated.
+	// +optional
+	Result RemediationOutcomeResult `json:"result,omitempty"`
+
+	// Step is the escalation step of this attempt: 1 for the first remediator of
+	// an unhealthiness, growing with every escalation to another remediator.
+	// +optional
+	Step int `json:"step,omitempty"`
+
+	// AttemptID uniquely identifies this attempt. It is stamped on the remediation
+	// CR as an idempotency token, so remediators can detect a CR re-created for an
+	// attempt they already acted on, see the reason package for the contract.
+	// +optional
+	AttemptID string `json:"attemptID,omitempty"`
+
+	// BootID is the node's boot identification when the attempt started. A node
+	// which turns healthy with the same boot recovered on its own before fencing
+	// completed, which counts into Status.RemediationStats.
+	// +optional
+	BootID string `json:"bootID,omitempty"`
+}
+
+// RemediationStats aggregates how past remediation attempts ended. Every attempt
+// is counted once, with its first result; only a TimedOut result is re-counted
+// when the node turns healthy after all, matching the outcome tracking.
+type RemediationStats struct {
+	// Attempts is the number of remediation attempts which ended with a result.
+	// +optional
+	Attempts int `json:"attempts,omitempty"`
+
+	// Succeeded is the number of attempts after which the node turned healthy.
+	// +optional
+	Succeeded int `json:"succeeded,omitempty"`
+
+	// TimedOut is the number of attempts which ran for too long without the node
+	// turning healthy.
+	// +optional
+	TimedOut int `json:"timedOut,omitempty"`
+
+	// Escalated is the number of attempts which were replaced with a remediator
+	// of another kind.
+	// +optional
+	Escalated int `json:"escalated,omitempty"`
+
+	// SelfRecovered is the number of succeeded attempts where the node turned
+	// healthy with the boot it was unhealthy with, so it recovered on its own
+	// before fencing completed.
+	// +optional
+	SelfRecovered int `json:"selfRecovered,omitempty"`
+
+	// LongestSelfRecovery is the longest time a self recovered attempt took from
+	// remediation CR creation to the node turning healthy. Unhealthy condition
+	// durations longer than this would have avoided all self recovered fencings.
+	// +optional
+	LongestSelfRecovery *metav1.Duration `json:"longestSelfRecovery,omitempty"`
+}
+
+// RemediationTemplateValidationReason classifies the validation result of one
+// remediation template reference
+type RemediationTemplateValidationReason string
+
+const (
+	// TemplateReasonValid means the template passed all checks
+	TemplateReasonValid RemediationTemplateValidationReason = "Valid"
+	// TemplateReasonMisplaced means the template lives in a namespace its
+	// remediator doesn't watch
+	TemplateReasonMisplaced RemediationTemplateValidationReason = "TemplateMisplaced"
+	// TemplateReasonCRDNotInstalled means no CRD serves the template's kind,
+	// usually because the remediator isn't deployed
+	TemplateReasonCRDNotInstalled RemediationTemplateValidationReason = "CRDNotInstalled"
+	// TemplateReasonNotFound means the referenced template CR doesn't exist
+	TemplateReasonNotFound RemediationTemplateValidationReason = "TemplateNotFound"
+	// TemplateReasonSchemaMismatch means the template's embedded spec doesn't fit
+	// the installed remediation CRD schema
+	TemplateReasonSchemaMismatch RemediationTemplateValidationReason = "SchemaMismatch"
+	// TemplateReasonPermissionsMissing means the operator lacks permissions on the
+	// template or its remediation CRs
+	TemplateReasonPermissionsMissing RemediationTemplateValidationReason = "PermissionsMissing"
+)
+
+// RemediationTemplateStatus reports the validity of one referenced remediation
+// template, see Status.RemediationTemplateStatuses.
+type RemediationTemplateStatus struct {
+	// TemplateRef points to the validated remediation template.
+	TemplateRef corev1.ObjectReference `json:"templateRef"`
+
+	// Valid is whether the template passed all checks: it exists, its namespace is
+	// allowed, its embedded spec fits the installed remediation CRD and the
+	// operator holds the permissions to use it.
+	Valid bool `json:"valid"`
+
+	// Reason classifies the result, one of Valid, TemplateMisplaced,
+	// CRDNotInstalled, TemplateNotFound, SchemaMismatch or PermissionsMissing.
+	Reason RemediationTemplateValidationReason `json:"reason"`
+
+	// Message explains the result in human readable form.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is when the result last changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// SpecRevision records one observed spec change of a NodeHealthCheck.
+type SpecRevision struct {
+	// Hash is a short hash identifying the spec content.
+	Hash string `json:"hash"`
+
+	// ChangedAt is when the spec change was first observed.
+	ChangedAt metav1.Time `json:"changedAt"`
+}
+
+// RemediationSummary holds the indexable counters of one NodeHealthCheck's status.
+type RemediationSummary struct {
+	// UnhealthyCount is the number of nodes currently observed unhealthy.
+	UnhealthyCount int `json:"unhealthyCount"`
+
+	// InFlightCount is the number of remediations currently in flight.
+	InFlightCount int `json:"inFlightCount"`
+}
+
+// CanaryStatus tracks the canary remediation state machine of one NodeHealthCheck.
+type CanaryStatus struct {
+	// ObservedConditionsHash identifies the unhealthy criteria this canary verdict
+	// was made for. The canary restarts when the criteria change.
+	// +optional
+	ObservedConditionsHash string `json:"observedConditionsHash,omitempty"`
+
+	// Node is the node selected as the canary.
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// HealthySince is when the canary node was first observed healthy again after
+	// its remediation started.
+	// +optional
+	HealthySince *metav1.Time `json:"healthySince,omitempty"`
+
+	// Passed is true once the canary node stayed healthy for the success window,
+	// allowing full remediation concurrency.
+	// +optional
+	Passed bool `json:"passed,omitempty"`
+}
+
+// ChangeRolloutStatus tracks the phase-in of changed unhealthy criteria of one
+// NodeHealthCheck.
+type ChangeRolloutStatus struct {
+	// ObservedConditionsHash identifies the unhealthy criteria this rollout belongs
+	// to. A new rollout starts when the criteria change again.
+	// +optional
+	ObservedConditionsHash string `json:"observedConditionsHash,omitempty"`
+
+	// ChangedAt is when the changed criteria were first observed, the start of the
+	// grace period.
+	// +optional
+	ChangedAt *metav1.Time `json:"changedAt,omitempty"`
+
+	// Completed is true once no node matched the changed criteria anymore after the
+	// grace period, allowing full remediation concurrency again.
+	// +optional
+	Completed bool `json:"completed,omitempty"`
+}
+
+// ResourceObservation records the observed allocatable history of one extended
+// resource on one node.
+type ResourceObservation struct {
+	// ZeroSince is the time the resource was first observed at zero allocatable after
+	// having been advertised. It is unset while the node advertises the resource.
+	// +optional
+	ZeroSince *metav1.Time `json:"zeroSince,omitempty"`
+}
+
+// FlapObservation records the observed Ready condition transitions of one node.
+type FlapObservation struct {
+	// LastTransition is the node's last observed Ready condition transition time,
+	// used to detect new flips between reconciles.
+	LastTransition metav1.Time `json:"lastTransition,omitempty"`
+
+	// Flips are the Ready condition transitions observed within the flap window,
+	// older entries are pruned.
+	// +optional
+	Flips []metav1.Time `json:"flips,omitempty"`
+}
+
+// BootObservation identifies the boot a node was running when it was observed, taken
+// from the node's status
+type BootObservation struct {
+	// BootID is the node's boot ID, it changes with every reboot.
+	// +optional
+	BootID string `json:"bootID,omitempty"`
+
+	// SystemUUID is the node's system UUID, it survives reboots and changes when
+	// the underlying machine was replaced.
+	// +optional
+	SystemUUID string `json:"systemUUID,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=nodehealthchecks,scope=Cluster,shortName=nhc
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Observed",type="integer",JSONPath=".status.observedNodes",description="Number of nodes observed by this NHC"
+// +kubebuilder:printcolumn:name="Healthy",type="integer",JSONPath=".status.healthyNodes",description="Number of healthy nodes observed by this NHC"
+// +kubebuilder:printcolumn:name="Remediating",type="string",JSONPath=".status.inFlightRemediationDetails",priority=1,description="Ongoing remediations with their remediator kind, escalation step and start time"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.reason",priority=1
 
 // NodeHealthCheck is the Schema for the nodehealthchecks API
 // +operator-sdk:csv:customresourcedefinitions:resources={{"NodeHealthCheck","v1alpha1","nodehealthchecks"}}
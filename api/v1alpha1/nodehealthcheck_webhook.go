@@ -0,0 +1,537 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var nodehealthchecklog = logf.Log.WithName("nodehealthcheck-resource")
+
+// webhookClient is used for looking up remediation templates while defaulting,
+// it is set in SetupWebhookWithManager
+var webhookClient client.Client
+
+// snrTemplateGVK is the kind of the Self Node Remediation templates, the only
+// remediator templates the webhook knows how to default to
+var snrTemplateGVK = schema.GroupVersionKind{
+	Group:   "self-node-remediation.medik8s.io",
+	Version: "v1alpha1",
+	Kind:    "SelfNodeRemediationTemplateList",
+}
+
+// validateWebhookPath is where the builder would register the validating webhook,
+// the path is fixed by the webhook marker below
+const validateWebhookPath = "/validate-remediation-medik8s-io-v1alpha1-nodehealthcheck"
+
+// watchNamespaces holds the namespaces the operator is restricted to, empty means
+// the whole cluster, see SetWatchNamespaces
+var watchNamespaces []string
+
+// SetWatchNamespaces tells the webhook which namespaces the operator is restricted
+// to, so template references outside of them are rejected instead of silently never
+// being served. Set from the watch-namespaces flag before the webhook starts.
+func SetWatchNamespaces(namespaces []string) {
+	watchNamespaces = namespaces
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *NodeHealthCheck) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetClient()
+	// register the validating handler before the builder, which then skips its own:
+	// handling the requests ourselves lets us return non-fatal advisories as
+	// admission warnings, which kubectl prints also with --dry-run=server
+	mgr.GetWebhookServer().Register(validateWebhookPath, &webhook.Admission{Handler: &nodeHealthCheckValidator{}})
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-remediation-medik8s-io-v1alpha1-nodehealthcheck,mutating=true,failurePolicy=ignore,sideEffects=None,groups=remediation.medik8s.io,resources=nodehealthchecks,verbs=create;update,versions=v1alpha1,name=mnodehealthcheck.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &NodeHealthCheck{}
+
+// Default implements webhook.Defaulter. It fills sensible defaults so a minimal
+// NHC manifest with just a selector becomes valid and functional.
+func (r *NodeHealthCheck) Default() {
+	nodehealthchecklog.Info("default", "name", r.Name)
+
+	if r.Spec.MinHealthy == nil {
+		minHealthy := defaultMinHealthy()
+		r.Spec.MinHealthy = &minHealthy
+	}
+
+	if len(r.Spec.UnhealthyConditions) == 0 {
+		r.Spec.UnhealthyConditions = []UnhealthyCondition{
+			{
+				Type:     corev1.NodeReady,
+				Status:   corev1.ConditionFalse,
+				Duration: metav1.Duration{Duration: 300 * time.Second},
+			},
+			{
+				Type:     corev1.NodeReady,
+				Status:   corev1.ConditionUnknown,
+				Duration: metav1.Duration{Duration: 300 * time.Second},
+			},
+		}
+	}
+
+	if r.Spec.RemediationTemplate == nil {
+		r.Spec.RemediationTemplate = findSingleRemediationTemplate()
+	}
+
+	r.applyStatusSimulation()
+}
+
+// SimulateStatusAnnotation asks the defaulting webhook to annotate the NHC with a
+// point in time simulation of what the controller would observe with this
+// configuration. Combined with `kubectl apply --dry-run=server` the returned
+// object shows the effect of the configuration without applying it, see the
+// simulated-* annotations.
+const SimulateStatusAnnotation = "remediation.medik8s.io/simulate-status"
+
+// AllowImmediateRemediationAnnotation acknowledges unhealthy conditions with a zero
+// duration, which remediate as soon as the condition matches. Zero durations are
+// rejected without this annotation set to "true", an accidental "0s" would fence
+// nodes on the first brief condition flip. The controller still applies a short
+// internal debounce, so the verdict doesn't depend on reconcile timing.
+const AllowImmediateRemediationAnnotation = "remediation.medik8s.io/allow-immediate-remediation"
+
+// the annotations holding the simulation results, see SimulateStatusAnnotation
+const (
+	simulatedTopologyAnnotation       = "remediation.medik8s.io/simulated-topology"
+	simulatedObservedNodesAnnotation  = "remediation.medik8s.io/simulated-observed-nodes"
+	simulatedUnhealthyNodesAnnotation = "remediation.medik8s.io/simulated-unhealthy-nodes"
+	simulatedMinHealthyAnnotation     = "remediation.medik8s.io/simulated-min-healthy"
+)
+
+// applyStatusSimulation implements the SimulateStatusAnnotation contract: the
+// selected nodes and the currently unhealthy ones among them are counted, and the
+// results are written back as annotations. Failures are left out silently, the
+// simulation must not get in the way of a real apply.
+func (r *NodeHealthCheck) applyStatusSimulation() {
+	if _, requested := r.GetAnnotations()[SimulateStatusAnnotation]; !requested || webhookClient == nil {
+		return
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&r.Spec.Selector)
+	if err != nil {
+		// left for validation to report
+		return
+	}
+	nodes := &corev1.NodeList{}
+	if err := webhookClient.List(context.Background(), nodes, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		nodehealthchecklog.Error(err, "failed to list the nodes for the status simulation")
+		return
+	}
+	unhealthy := 0
+	for i := range nodes.Items {
+		if simulateUnhealthy(r.Spec.UnhealthyConditions, &nodes.Items[i]) {
+			unhealthy++
+		}
+	}
+	minHealthy := 0
+	if r.Spec.MinHealthy != nil {
+		if scaled, err := intstr.GetScaledValueFromIntOrPercent(r.Spec.MinHealthy, len(nodes.Items), true); err == nil {
+			minHealthy = scaled
+		}
+	}
+	annotations := r.GetAnnotations()
+	annotations[simulatedTopologyAnnotation] = string(currentTopology())
+	annotations[simulatedObservedNodesAnnotation] = fmt.Sprintf("%d", len(nodes.Items))
+	annotations[simulatedUnhealthyNodesAnnotation] = fmt.Sprintf("%d", unhealthy)
+	annotations[simulatedMinHealthyAnnotation] = fmt.Sprintf("%d", minHealthy)
+	r.SetAnnotations(annotations)
+}
+
+// simulateUnhealthy is a point in time view of the unhealthy conditions, the
+// durations are ignored since the simulation has no observation history
+func simulateUnhealthy(conditions []UnhealthyCondition, node *corev1.Node) bool {
+	for _, unhealthy := range conditions {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == unhealthy.Type && condition.Status == unhealthy.Status {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defaultMinHealthy picks the minHealthy default by cluster topology: on a single
+// node cluster 51% scales to 1 and would block the only possible remediation, and
+// on a compact cluster 51% of 3 would allow fencing a second control plane node
+// while one is already down, losing the etcd quorum
+func defaultMinHealthy() intstr.IntOrString {
+	switch currentTopology() {
+	case TopologySNO:
+		return intstr.FromInt(0)
+	case TopologyCompact:
+		return intstr.FromInt(2)
+	default:
+		return intstr.FromString("51%")
+	}
+}
+
+// currentTopology detects the cluster topology with the webhook's client. It falls
+// back to the standard topology when the nodes can't be listed, defaulting and
+// validation must not block on a detection problem
+func currentTopology() Topology {
+	if webhookClient == nil {
+		return TopologyStandard
+	}
+	topology, err := DetectTopology(context.Background(), webhookClient)
+	if err != nil {
+		nodehealthchecklog.Error(err, "failed to detect the cluster topology, assuming a standard one")
+		return TopologyStandard
+	}
+	return topology
+}
+
+// +kubebuilder:webhook:path=/validate-remediation-medik8s-io-v1alpha1-nodehealthcheck,mutating=false,failurePolicy=fail,sideEffects=None,groups=remediation.medik8s.io,resources=nodehealthchecks,verbs=create;update,versions=v1alpha1,name=vnodehealthcheck.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &NodeHealthCheck{}
+
+// ValidateCreate implements webhook.Validator
+func (r *NodeHealthCheck) ValidateCreate() error {
+	nodehealthchecklog.Info("validate create", "name", r.Name)
+	if err := r.validateRemediationTemplates(); err != nil {
+		return err
+	}
+	if err := r.validateRemediationTemplateConfiguration(); err != nil {
+		return err
+	}
+	if err := r.validateTemplateNamespaces(); err != nil {
+		return err
+	}
+	if err := r.ValidateSelectorLabels(); err != nil {
+		return err
+	}
+	if err := r.validateLeasePolicy(); err != nil {
+		return err
+	}
+	if err := r.validateFlappingDetection(); err != nil {
+		return err
+	}
+	if err := r.validateTopologySafety(); err != nil {
+		return err
+	}
+	if err := r.validateImmediateRemediation(); err != nil {
+		return err
+	}
+	return r.validateUnhealthyCEL()
+}
+
+// ValidateUpdate implements webhook.Validator
+func (r *NodeHealthCheck) ValidateUpdate(old runtime.Object) error {
+	nodehealthchecklog.Info("validate update", "name", r.Name)
+	if err := r.validateRemediationTemplates(); err != nil {
+		return err
+	}
+	if err := r.validateRemediationTemplateConfiguration(); err != nil {
+		return err
+	}
+	if err := r.validateTemplateNamespaces(); err != nil {
+		return err
+	}
+	if err := r.ValidateSelectorLabels(); err != nil {
+		return err
+	}
+	if err := r.validateLeasePolicy(); err != nil {
+		return err
+	}
+	if err := r.validateFlappingDetection(); err != nil {
+		return err
+	}
+	if err := r.validateTopologySafety(); err != nil {
+		return err
+	}
+	if err := r.validateImmediateRemediation(); err != nil {
+		return err
+	}
+	return r.validateUnhealthyCEL()
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *NodeHealthCheck) ValidateDelete() error {
+	return nil
+}
+
+// nodeHealthCheckValidator handles the validating admission requests itself instead
+// of relying on the webhook.Validator plumbing, so non-fatal advisories can be
+// returned as warnings alongside the verdict
+type nodeHealthCheckValidator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &nodeHealthCheckValidator{}
+
+// Handle implements admission.Handler
+func (v *nodeHealthCheckValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	nhc := &NodeHealthCheck{}
+	switch req.Operation {
+	case admissionv1.Delete:
+		// mirrors ValidateDelete, nothing to check
+		return admission.Allowed("")
+	case admissionv1.Update:
+		if err := v.decoder.Decode(req, nhc); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		old := &NodeHealthCheck{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := nhc.ValidateUpdate(old); err != nil {
+			return admission.Denied(err.Error())
+		}
+	default:
+		if err := v.decoder.Decode(req, nhc); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := nhc.ValidateCreate(); err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+	return admission.Allowed("").WithWarnings(nhc.collectWarnings()...)
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *nodeHealthCheckValidator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// collectWarnings returns non-fatal advisories about configurations which are valid
+// but likely not what the user wanted, surfaced as admission warnings
+func (r *NodeHealthCheck) collectWarnings() []string {
+	var warnings []string
+	if r.Spec.MinHealthy != nil && r.Spec.MinHealthy.Type == intstr.Int && r.Spec.MinHealthy.IntValue() == 0 {
+		warnings = append(warnings, "minHealthy 0 allows remediating all selected nodes at once")
+	}
+	if len(r.Spec.Selector.MatchLabels) == 0 && len(r.Spec.Selector.MatchExpressions) == 0 {
+		warnings = append(warnings, "the empty selector selects all nodes, including the control plane")
+	}
+	for _, condition := range r.Spec.UnhealthyConditions {
+		// zero durations were acknowledged explicitly, see validateImmediateRemediation
+		if condition.Duration.Duration > 0 && condition.Duration.Duration < 30*time.Second {
+			warnings = append(warnings, fmt.Sprintf("the %s duration of the unhealthy condition %s/%s may remediate nodes which are only briefly unhealthy",
+				condition.Duration.Duration, condition.Type, condition.Status))
+		}
+	}
+	return warnings
+}
+
+// validateImmediateRemediation rejects unhealthy conditions with a zero duration
+// unless they were acknowledged with the AllowImmediateRemediationAnnotation, see
+// the annotation's documentation
+func (r *NodeHealthCheck) validateImmediateRemediation() error {
+	if r.GetAnnotations()[AllowImmediateRemediationAnnotation] == "true" {
+		return nil
+	}
+	for _, condition := range r.Spec.UnhealthyConditions {
+		if condition.Duration.Duration == 0 {
+			return fmt.Errorf("the unhealthy condition %s/%s has a zero duration and remediates as soon as the condition matches, "+
+				"acknowledge this by setting the %q annotation to \"true\"",
+				condition.Type, condition.Status, AllowImmediateRemediationAnnotation)
+		}
+	}
+	return nil
+}
+
+// validateRemediationTemplates rejects remediation templates whose kind doesn't follow
+// the "<remediation kind>Template" naming convention, the remediation CR kind couldn't
+// be derived from them
+func (r *NodeHealthCheck) validateRemediationTemplates() error {
+	for _, ref := range r.remediationTemplateRefs() {
+		if _, err := ParseRemediationKind(ref.Kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRemediationTemplateConfiguration rejects remediation template
+// configurations which can't resolve a template for every node. At least one
+// of the template fields must be set, each remediationTemplates entry must
+// carry a template, and since the entries resolve in order with the first
+// matching nodeSelector winning, repeating a nodeSelector leaves the later
+// entry unreachable
+func (r *NodeHealthCheck) validateRemediationTemplateConfiguration() error {
+	if r.Spec.RemediationTemplate == nil && len(r.Spec.RemediationTemplates) == 0 && len(r.Spec.OSRemediationTemplates) == 0 {
+		return fmt.Errorf("no remediation template configured, set remediationTemplate, remediationTemplates or osRemediationTemplates")
+	}
+	for i := range r.Spec.RemediationTemplates {
+		if r.Spec.RemediationTemplates[i].RemediationTemplate == nil {
+			return fmt.Errorf("remediationTemplates[%d].remediationTemplate must be set", i)
+		}
+		for j := 0; j < i; j++ {
+			if equality.Semantic.DeepEqual(r.Spec.RemediationTemplates[i].NodeSelector, r.Spec.RemediationTemplates[j].NodeSelector) {
+				return fmt.Errorf("remediationTemplates[%d] repeats the nodeSelector of remediationTemplates[%d], the earlier entry always wins and the later one can never be selected", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTemplateNamespaces rejects template references outside the operator's
+// watch namespaces: their templates would never be read and their remediation CRs
+// never be watched, leaving nodes unremediated without any feedback
+func (r *NodeHealthCheck) validateTemplateNamespaces() error {
+	if len(watchNamespaces) == 0 {
+		return nil
+	}
+	watched := map[string]bool{}
+	for _, namespace := range watchNamespaces {
+		watched[namespace] = true
+	}
+	for _, ref := range r.remediationTemplateRefs() {
+		if !watched[ref.Namespace] {
+			return fmt.Errorf("%s %q is referenced in namespace %q, which the operator doesn't watch, the watched namespaces are: %s",
+				ref.Kind, ref.Name, ref.Namespace, strings.Join(watchNamespaces, ", "))
+		}
+	}
+	return nil
+}
+
+// validateLeasePolicy rejects lease policies whose durations can't work: a
+// non-positive lease duration would expire leases immediately, and a negative
+// buffer would expire them before remediation even started
+func (r *NodeHealthCheck) validateLeasePolicy() error {
+	policy := r.Spec.LeasePolicy
+	if policy == nil {
+		return nil
+	}
+	if policy.Duration != nil && policy.Duration.Duration <= 0 {
+		return fmt.Errorf("leasePolicy.duration must be positive, got %s", policy.Duration.Duration)
+	}
+	if policy.Buffer != nil && policy.Buffer.Duration < 0 {
+		return fmt.Errorf("leasePolicy.buffer must not be negative, got %s", policy.Buffer.Duration)
+	}
+	return nil
+}
+
+// validateFlappingDetection rejects a flap window which can't work: with a
+// non-positive window every flip would expire immediately and no node could
+// ever be considered flapping
+func (r *NodeHealthCheck) validateFlappingDetection() error {
+	policy := r.Spec.FlappingDetection
+	if policy == nil {
+		return nil
+	}
+	if policy.Window != nil && policy.Window.Duration <= 0 {
+		return fmt.Errorf("flappingDetection.window must be positive, got %s", policy.Window.Duration)
+	}
+	return nil
+}
+
+// validateTopologySafety refuses configurations which are unsafe for the cluster's
+// topology: on a single node cluster only Self Node Remediation can fence the one
+// node without destroying the cluster this operator runs on, and on a compact
+// cluster fewer than 2 healthy control plane nodes lose the etcd quorum
+func (r *NodeHealthCheck) validateTopologySafety() error {
+	switch currentTopology() {
+	case TopologySNO:
+		for _, ref := range r.remediationTemplateRefs() {
+			if !strings.HasPrefix(ref.Kind, SelfNodeRemediationKindPrefix) {
+				return fmt.Errorf("remediation template kind %s is not allowed on a single node cluster, only Self Node Remediation can fence the one node without destroying the cluster", ref.Kind)
+			}
+		}
+	case TopologyCompact:
+		if r.Spec.MinHealthy == nil {
+			return nil
+		}
+		minHealthy, err := intstr.GetScaledValueFromIntOrPercent(r.Spec.MinHealthy, compactClusterNodes, true)
+		if err != nil {
+			return err
+		}
+		if minHealthy < 2 {
+			return fmt.Errorf("minHealthy %s resolves to %d on a compact cluster, at least 2 control plane nodes must stay healthy to keep the etcd quorum", r.Spec.MinHealthy.String(), minHealthy)
+		}
+	}
+	return nil
+}
+
+// remediationTemplateRefs collects the configured remediation template references
+// from all spec fields which hold one, skipping the ones defaulting fills later
+func (r *NodeHealthCheck) remediationTemplateRefs() []*corev1.ObjectReference {
+	refs := []*corev1.ObjectReference{r.Spec.RemediationTemplate, r.Spec.FallbackRemediationTemplate}
+	for i := range r.Spec.RemediationTemplates {
+		refs = append(refs, r.Spec.RemediationTemplates[i].RemediationTemplate)
+	}
+	for _, ref := range r.Spec.OSRemediationTemplates {
+		refs = append(refs, ref)
+	}
+	var set []*corev1.ObjectReference
+	for _, ref := range refs {
+		if ref != nil {
+			set = append(set, ref)
+		}
+	}
+	return set
+}
+
+// validateUnhealthyCEL rejects unhealthyCEL expressions which don't compile, broken
+// expressions are better caught at admission than at evaluation time
+func (r *NodeHealthCheck) validateUnhealthyCEL() error {
+	if r.Spec.UnhealthyCEL == nil || *r.Spec.UnhealthyCEL == "" {
+		return nil
+	}
+	_, err := CompileUnhealthyCEL(*r.Spec.UnhealthyCEL)
+	return err
+}
+
+// findSingleRemediationTemplate returns a reference to the only known remediation
+// template in the cluster, or nil when there is none or more than one to choose from
+func findSingleRemediationTemplate() *corev1.ObjectReference {
+	if webhookClient == nil {
+		return nil
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(snrTemplateGVK)
+	if err := webhookClient.List(context.Background(), list); err != nil {
+		nodehealthchecklog.Error(err, "failed to list remediation templates for defaulting")
+		return nil
+	}
+	if len(list.Items) != 1 {
+		nodehealthchecklog.Info("not defaulting the remediation template", "numOfTemplates", len(list.Items))
+		return nil
+	}
+	template := list.Items[0]
+	return &corev1.ObjectReference{
+		APIVersion: template.GetAPIVersion(),
+		Kind:       template.GetKind(),
+		Namespace:  template.GetNamespace(),
+		Name:       template.GetName(),
+	}
+}
@@ -0,0 +1,278 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newTestTemplateRef(name string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: "test.medik8s.io/v1alpha1",
+		Kind:       "InfrastructureRemediationTemplate",
+		Namespace:  "default",
+		Name:       name,
+	}
+}
+
+func TestValidateRemediationTemplateConfiguration(t *testing.T) {
+	poolSelector := metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}}
+	otherSelector := metav1.LabelSelector{MatchLabels: map[string]string{"pool": "storage"}}
+
+	cases := []struct {
+		name  string
+		spec  NodeHealthCheckSpec
+		valid bool
+	}{
+		{
+			name: "default template only",
+			spec: NodeHealthCheckSpec{
+				RemediationTemplate: newTestTemplateRef("template-1"),
+			},
+			valid: true,
+		},
+		{
+			name: "pool entries only",
+			spec: NodeHealthCheckSpec{
+				RemediationTemplates: []NodePoolRemediationTemplate{
+					{NodeSelector: poolSelector, RemediationTemplate: newTestTemplateRef("template-1")},
+					{NodeSelector: otherSelector, RemediationTemplate: newTestTemplateRef("template-2")},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "os templates only",
+			spec: NodeHealthCheckSpec{
+				OSRemediationTemplates: map[string]*corev1.ObjectReference{
+					"linux": newTestTemplateRef("template-1"),
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "same template under two selectors",
+			spec: NodeHealthCheckSpec{
+				RemediationTemplates: []NodePoolRemediationTemplate{
+					{NodeSelector: poolSelector, RemediationTemplate: newTestTemplateRef("template-1")},
+					{NodeSelector: otherSelector, RemediationTemplate: newTestTemplateRef("template-1")},
+				},
+			},
+			valid: true,
+		},
+		{
+			name:  "no template at all",
+			spec:  NodeHealthCheckSpec{},
+			valid: false,
+		},
+		{
+			name: "pool entry without a template",
+			spec: NodeHealthCheckSpec{
+				RemediationTemplates: []NodePoolRemediationTemplate{
+					{NodeSelector: poolSelector},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "repeated nodeSelector",
+			spec: NodeHealthCheckSpec{
+				RemediationTemplates: []NodePoolRemediationTemplate{
+					{NodeSelector: poolSelector, RemediationTemplate: newTestTemplateRef("template-1")},
+					{NodeSelector: poolSelector, RemediationTemplate: newTestTemplateRef("template-2")},
+				},
+			},
+			valid: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			nhc := &NodeHealthCheck{Spec: c.spec}
+			err := nhc.validateRemediationTemplateConfiguration()
+			if c.valid && err != nil {
+				t.Errorf("expected the configuration to be valid, got %v", err)
+			}
+			if !c.valid && err == nil {
+				t.Error("expected the configuration to be refused")
+			}
+		})
+	}
+}
+
+func TestValidateTemplateNamespaces(t *testing.T) {
+	nhc := &NodeHealthCheck{Spec: NodeHealthCheckSpec{
+		RemediationTemplate: newTestTemplateRef("template-1"),
+	}}
+
+	t.Run("without watch namespaces any namespace is accepted", func(t *testing.T) {
+		SetWatchNamespaces(nil)
+		if err := nhc.validateTemplateNamespaces(); err != nil {
+			t.Errorf("expected the reference to be valid, got %v", err)
+		}
+	})
+
+	t.Run("references in watched namespaces are accepted", func(t *testing.T) {
+		SetWatchNamespaces([]string{"default", "remediation"})
+		defer SetWatchNamespaces(nil)
+		if err := nhc.validateTemplateNamespaces(); err != nil {
+			t.Errorf("expected the reference to be valid, got %v", err)
+		}
+	})
+
+	t.Run("references outside the watched namespaces are refused", func(t *testing.T) {
+		SetWatchNamespaces([]string{"remediation"})
+		defer SetWatchNamespaces(nil)
+		if err := nhc.validateTemplateNamespaces(); err == nil {
+			t.Error("expected the reference to be refused")
+		}
+	})
+}
+
+func TestCollectWarnings(t *testing.T) {
+	workerSelector := metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}}
+	minHealthyZero := intstr.FromInt(0)
+	minHealthyHalf := intstr.FromString("51%")
+
+	cases := []struct {
+		name     string
+		spec     NodeHealthCheckSpec
+		expected []string
+	}{
+		{
+			name: "clean configuration",
+			spec: NodeHealthCheckSpec{
+				Selector:   workerSelector,
+				MinHealthy: &minHealthyHalf,
+				UnhealthyConditions: []UnhealthyCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Duration: metav1.Duration{Duration: 300 * time.Second}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "minHealthy zero",
+			spec: NodeHealthCheckSpec{
+				Selector:   workerSelector,
+				MinHealthy: &minHealthyZero,
+			},
+			expected: []string{"minHealthy 0"},
+		},
+		{
+			name:     "empty selector",
+			spec:     NodeHealthCheckSpec{MinHealthy: &minHealthyHalf},
+			expected: []string{"empty selector"},
+		},
+		{
+			name: "very short unhealthy duration",
+			spec: NodeHealthCheckSpec{
+				Selector:   workerSelector,
+				MinHealthy: &minHealthyHalf,
+				UnhealthyConditions: []UnhealthyCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Duration: metav1.Duration{Duration: 10 * time.Second}},
+				},
+			},
+			expected: []string{"briefly unhealthy"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			nhc := &NodeHealthCheck{Spec: c.spec}
+			warnings := nhc.collectWarnings()
+			if len(warnings) != len(c.expected) {
+				t.Fatalf("expected %d warnings, got %v", len(c.expected), warnings)
+			}
+			for i, substring := range c.expected {
+				if !strings.Contains(warnings[i], substring) {
+					t.Errorf("expected warning %d to contain %q, got %q", i, substring, warnings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateImmediateRemediation(t *testing.T) {
+	newNHC := func(duration time.Duration, annotations map[string]string) *NodeHealthCheck {
+		return &NodeHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec: NodeHealthCheckSpec{
+				UnhealthyConditions: []UnhealthyCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Duration: metav1.Duration{Duration: duration}},
+				},
+			},
+		}
+	}
+
+	t.Run("a positive duration needs no acknowledgment", func(t *testing.T) {
+		if err := newNHC(time.Minute, nil).validateImmediateRemediation(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("a zero duration without acknowledgment is rejected", func(t *testing.T) {
+		err := newNHC(0, nil).validateImmediateRemediation()
+		if err == nil || !strings.Contains(err.Error(), AllowImmediateRemediationAnnotation) {
+			t.Errorf("expected an error pointing at the acknowledgment annotation, got %v", err)
+		}
+	})
+
+	t.Run("the acknowledgment annotation allows a zero duration", func(t *testing.T) {
+		annotations := map[string]string{AllowImmediateRemediationAnnotation: "true"}
+		if err := newNHC(0, annotations).validateImmediateRemediation(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestApplyStatusSimulation(t *testing.T) {
+	worker := map[string]string{"role": "worker"}
+	newWorkerNode := func(name string, ready corev1.ConditionStatus) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: worker},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready},
+			}},
+		}
+	}
+	setTestTopologyClient(t,
+		newWorkerNode("worker-1", corev1.ConditionTrue),
+		newWorkerNode("worker-2", corev1.ConditionFalse),
+	)
+
+	minHealthy := intstr.FromString("51%")
+	nhc := &NodeHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "simulated",
+			Annotations: map[string]string{SimulateStatusAnnotation: "true"},
+		},
+		Spec: NodeHealthCheckSpec{
+			Selector:   metav1.LabelSelector{MatchLabels: worker},
+			MinHealthy: &minHealthy,
+			UnhealthyConditions: []UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Duration: metav1.Duration{Duration: 300 * time.Second}},
+			},
+		},
+	}
+	nhc.applyStatusSimulation()
+
+	expected := map[string]string{
+		simulatedTopologyAnnotation:       string(TopologyStandard),
+		simulatedObservedNodesAnnotation:  "2",
+		simulatedUnhealthyNodesAnnotation: "1",
+		simulatedMinHealthyAnnotation:     "2",
+	}
+	for key, value := range expected {
+		if actual := nhc.GetAnnotations()[key]; actual != value {
+			t.Errorf("expected annotation %s to be %q, got %q", key, value, actual)
+		}
+	}
+
+	nhc.SetAnnotations(map[string]string{})
+	nhc.applyStatusSimulation()
+	if _, exists := nhc.GetAnnotations()[simulatedObservedNodesAnnotation]; exists {
+		t.Error("expected no simulation without the simulate-status annotation")
+	}
+}
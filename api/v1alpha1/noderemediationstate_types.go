@@ -0,0 +1,127 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeStatePhase is where a node currently stands in the health check state machine
+type NodeStatePhase string
+
+const (
+	// NodeStateObserving means the node matches the unhealthy criteria, but is still
+	// inside the observation window and might just be flapping. It is also kept for
+	// nodes which recently flapped, while their condition history is retained.
+	NodeStateObserving NodeStatePhase = "Observing"
+
+	// NodeStateUnhealthy means the node is due for remediation, but it wasn't started
+	// yet, e.g. because it is held back by minHealthy, a canary or a rollout
+	NodeStateUnhealthy NodeStatePhase = "Unhealthy"
+
+	// NodeStateRemediating means a remediation of the node is in flight
+	NodeStateRemediating NodeStatePhase = "Remediating"
+
+	// NodeStateCooldown means the node turned healthy again recently, its state is
+	// kept around for a while for debugging before it is cleaned up
+	NodeStateCooldown NodeStatePhase = "Cooldown"
+)
+
+// NodeRemediationStateSpec identifies the node this state belongs to
+type NodeRemediationStateSpec struct {
+	// NodeName is the name of the node this state machine tracks
+	NodeName string `json:"nodeName"`
+}
+
+// NodeRemediationStateStatus holds the node's current health check state
+type NodeRemediationStateStatus struct {
+	// Phase is where the node stands in the health check state machine, one of
+	// Observing, Unhealthy, Remediating and Cooldown.
+	// +optional
+	Phase NodeStatePhase `json:"phase,omitempty"`
+
+	// Step counts the remediation attempts of the current unhealthiness, starting at
+	// 1 with the first remediation CR and growing with every escalation to another
+	// remediator. It is only set while remediating.
+	// +optional
+	Step int `json:"step,omitempty"`
+
+	// Remediator is the kind of the remediation CR currently in flight, only set
+	// while remediating.
+	// +optional
+	Remediator string `json:"remediator,omitempty"`
+
+	// LastTransition is when the node last changed its phase.
+	// +optional
+	LastTransition *metav1.Time `json:"lastTransition,omitempty"`
+
+	// ConditionHistory is a ring buffer of the node's observed condition transitions,
+	// oldest first, capped per node. It remembers what the node conditions did even
+	// when their lastTransitionTime is reset by flaps or by the API server, the
+	// unhealthy duration evaluation uses it instead of the raw transition time.
+	// +optional
+	ConditionHistory []ConditionTransition `json:"conditionHistory,omitempty"`
+}
+
+// ConditionTransition is one observed change of a node condition, see
+// status.conditionHistory
+type ConditionTransition struct {
+	// Type is the node condition type which changed.
+	Type corev1.NodeConditionType `json:"type"`
+
+	// Status is the status the condition changed to.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// At is the condition's lastTransitionTime when the change was observed.
+	At metav1.Time `json:"at"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=noderemediationstates,shortName=nrs
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.nodeName"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Step",type="integer",JSONPath=".status.step",priority=1
+// +kubebuilder:printcolumn:name="Remediator",type="string",JSONPath=".status.remediator",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// NodeRemediationState is the per node health check state machine of one
+// NodeHealthCheck. The NHC controller maintains one instance per node which is
+// observed unhealthy, remediated, or cooling down, in the operator's namespace and
+// owned by the NHC. It exists for fine-grained watches and debugging, all decisions
+// are still made by the NHC controller.
+type NodeRemediationState struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeRemediationStateSpec   `json:"spec,omitempty"`
+	Status NodeRemediationStateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeRemediationStateList contains a list of NodeRemediationState
+type NodeRemediationStateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeRemediationState `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeRemediationState{}, &NodeRemediationStateList{})
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TemplateSuffix is the mandatory suffix of remediation template kinds. By the medik8s
+// convention the kind of the remediation CRs instantiated from a template is the
+// template's kind with this suffix stripped.
+const TemplateSuffix = "Template"
+
+// RemediationKind is the kind of the remediation CRs instantiated from a template
+type RemediationKind string
+
+func (k RemediationKind) String() string {
+	return string(k)
+}
+
+// ParseRemediationKind derives the remediation CR kind from a template kind. It returns
+// an error when the template kind doesn't follow the naming convention.
+func ParseRemediationKind(templateKind string) (RemediationKind, error) {
+	if !strings.HasSuffix(templateKind, TemplateSuffix) || templateKind == TemplateSuffix {
+		return "", fmt.Errorf("invalid remediation template kind %q: it must be the remediation kind followed by the %q suffix, like \"SelfNodeRemediationTemplate\"",
+			templateKind, TemplateSuffix)
+	}
+	return RemediationKind(strings.TrimSuffix(templateKind, TemplateSuffix)), nil
+}
+
+// RemediationGVK returns the GroupVersionKind of the remediation CRs instantiated from
+// the given template reference
+func RemediationGVK(ref *corev1.ObjectReference) (schema.GroupVersionKind, error) {
+	kind, err := ParseRemediationKind(ref.Kind)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	gvk := ref.GroupVersionKind()
+	gvk.Kind = kind.String()
+	return gvk, nil
+}
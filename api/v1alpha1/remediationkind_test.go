@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseRemediationKind(t *testing.T) {
+	tests := []struct {
+		templateKind string
+		expectedKind RemediationKind
+		expectError  bool
+	}{
+		{templateKind: "SelfNodeRemediationTemplate", expectedKind: "SelfNodeRemediation"},
+		{templateKind: "MachineDeletionRemediationTemplate", expectedKind: "MachineDeletionRemediation"},
+		{templateKind: "SelfNodeRemediation", expectError: true},
+		{templateKind: "Template", expectError: true},
+		{templateKind: "", expectError: true},
+	}
+	for _, test := range tests {
+		kind, err := ParseRemediationKind(test.templateKind)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("expected an error for template kind %q, got kind %q", test.templateKind, kind)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for template kind %q: %v", test.templateKind, err)
+			continue
+		}
+		if kind != test.expectedKind {
+			t.Errorf("expected kind %q for template kind %q, got %q", test.expectedKind, test.templateKind, kind)
+		}
+	}
+}
+
+func TestRemediationGVK(t *testing.T) {
+	ref := &corev1.ObjectReference{
+		APIVersion: "self-node-remediation.medik8s.io/v1alpha1",
+		Kind:       "SelfNodeRemediationTemplate",
+	}
+	gvk, err := RemediationGVK(ref)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk.Group != "self-node-remediation.medik8s.io" || gvk.Version != "v1alpha1" || gvk.Kind != "SelfNodeRemediation" {
+		t.Errorf("unexpected GVK %v", gvk)
+	}
+
+	ref.Kind = "NotFollowingTheConvention"
+	if _, err := RemediationGVK(ref); err == nil {
+		t.Error("expected an error for a template kind without the Template suffix")
+	}
+}
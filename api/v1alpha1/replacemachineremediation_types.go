@@ -0,0 +1,126 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeMachineDeleted is set on a ReplaceMachineRemediation once the
+	// backing machine's deletion was requested, or when no machine could be found
+	ConditionTypeMachineDeleted = "MachineDeleted"
+
+	// ConditionReasonMachineDeletionRequested means the machine's deletion was requested,
+	// the machine set provisions the replacement
+	ConditionReasonMachineDeletionRequested = "MachineDeletionRequested"
+
+	// ConditionReasonMachineNotFound means no machine backing the node could be found,
+	// e.g. because the node isn't machine managed
+	ConditionReasonMachineNotFound = "MachineNotFound"
+)
+
+// ReplaceMachineRemediationSpec defines the desired state of ReplaceMachineRemediation.
+// The node to remediate is identified by the CR's name, following the remediation
+// CR convention, so there is nothing to configure.
+type ReplaceMachineRemediationSpec struct{}
+
+// ReplaceMachineRemediationStatus defines the observed state of ReplaceMachineRemediation
+type ReplaceMachineRemediationStatus struct {
+	// Machine is the namespaced name of the machine whose deletion was requested.
+	// +optional
+	Machine string `json:"machine,omitempty"`
+
+	// MachineDeleted is when the machine's deletion was requested.
+	// +optional
+	MachineDeleted *metav1.Time `json:"machineDeleted,omitempty"`
+
+	// Represents the observations of the remediation's current state.
+	// Known .status.conditions.type is: "MachineDeleted"
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=replacemachineremediations,shortName=rmr
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".status.machine"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ReplaceMachineRemediation is the built-in remediation for machine managed cloud
+// clusters: it deletes the unhealthy node's backing machine, letting the machine set
+// provision a replacement. NHC creates the CRs from ReplaceMachineRemediationTemplates
+// like for any external remediator; the in-process controller acting on them is only
+// registered with the enable-replace-machine-remediation flag, for clusters which
+// don't want to install a separate remediation operator.
+type ReplaceMachineRemediation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReplaceMachineRemediationSpec   `json:"spec,omitempty"`
+	Status ReplaceMachineRemediationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReplaceMachineRemediationList contains a list of ReplaceMachineRemediation
+type ReplaceMachineRemediationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplaceMachineRemediation `json:"items"`
+}
+
+// ReplaceMachineRemediationTemplateResource describes the ReplaceMachineRemediation
+// CRs created from this template
+type ReplaceMachineRemediationTemplateResource struct {
+	Spec ReplaceMachineRemediationSpec `json:"spec"`
+}
+
+// ReplaceMachineRemediationTemplateSpec defines the desired state of
+// ReplaceMachineRemediationTemplate
+type ReplaceMachineRemediationTemplateSpec struct {
+	// Template is stamped onto the ReplaceMachineRemediation CRs NHC creates.
+	Template ReplaceMachineRemediationTemplateResource `json:"template"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=replacemachineremediationtemplates,shortName=rmrt
+
+// ReplaceMachineRemediationTemplate is the template NHCs reference to remediate with
+// the built-in machine replacement, see ReplaceMachineRemediation
+type ReplaceMachineRemediationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReplaceMachineRemediationTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReplaceMachineRemediationTemplateList contains a list of ReplaceMachineRemediationTemplate
+type ReplaceMachineRemediationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplaceMachineRemediationTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ReplaceMachineRemediation{}, &ReplaceMachineRemediationList{},
+		&ReplaceMachineRemediationTemplate{}, &ReplaceMachineRemediationTemplateList{},
+	)
+}
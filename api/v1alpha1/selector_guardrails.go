@@ -0,0 +1,80 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SelectorLabelAllowlistEnvVar lists the only node label keys NHC selectors may
+	// use, comma separated. Empty or unset allows every key not on the denylist.
+	SelectorLabelAllowlistEnvVar = "SELECTOR_LABEL_ALLOWLIST"
+
+	// SelectorLabelDenylistEnvVar lists node label keys NHC selectors must not use,
+	// comma separated. The denylist wins over the allowlist.
+	SelectorLabelDenylistEnvVar = "SELECTOR_LABEL_DENYLIST"
+)
+
+// ValidateSelectorLabels enforces the operator level guardrails on the label keys an
+// NHC selector may use, configured with the SELECTOR_LABEL_ALLOWLIST and
+// SELECTOR_LABEL_DENYLIST environment variables of the operator deployment. Managed
+// service environments use them to keep customer NHCs away from labels like
+// "node-role.kubernetes.io/infra". The validating webhook enforces the guardrails on
+// admission, and the reconciler re-checks them because the lists can change after an
+// NHC was admitted.
+func (r *NodeHealthCheck) ValidateSelectorLabels() error {
+	denylist := splitLabelKeyList(os.Getenv(SelectorLabelDenylistEnvVar))
+	allowlist := splitLabelKeyList(os.Getenv(SelectorLabelAllowlistEnvVar))
+	if len(denylist) == 0 && len(allowlist) == 0 {
+		return nil
+	}
+	for _, key := range selectorLabelKeys(&r.Spec.Selector) {
+		if denylist[key] {
+			return fmt.Errorf("selector label key %q is denied by the operator configuration", key)
+		}
+		if len(allowlist) > 0 && !allowlist[key] {
+			return fmt.Errorf("selector label key %q is not on the operator's allowlist", key)
+		}
+	}
+	return nil
+}
+
+func splitLabelKeyList(value string) map[string]bool {
+	keys := map[string]bool{}
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+func selectorLabelKeys(selector *metav1.LabelSelector) []string {
+	var keys []string
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	for _, expression := range selector.MatchExpressions {
+		keys = append(keys, expression.Key)
+	}
+	return keys
+}
@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func nhcWithSelector(selector metav1.LabelSelector) *NodeHealthCheck {
+	return &NodeHealthCheck{Spec: NodeHealthCheckSpec{Selector: selector}}
+}
+
+func TestValidateSelectorLabels(t *testing.T) {
+	workerSelector := metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "node-role.kubernetes.io/worker", Operator: metav1.LabelSelectorOpExists},
+		},
+	}
+	infraSelector := metav1.LabelSelector{
+		MatchLabels: map[string]string{"node-role.kubernetes.io/infra": ""},
+	}
+
+	t.Run("no guardrails configured", func(t *testing.T) {
+		if err := nhcWithSelector(infraSelector).ValidateSelectorLabels(); err != nil {
+			t.Errorf("expected any selector to pass without guardrails, got %v", err)
+		}
+	})
+
+	t.Run("denylist", func(t *testing.T) {
+		t.Setenv(SelectorLabelDenylistEnvVar, "node-role.kubernetes.io/infra, node-role.kubernetes.io/master")
+		if err := nhcWithSelector(infraSelector).ValidateSelectorLabels(); err == nil {
+			t.Error("expected a denied selector label key to fail validation")
+		}
+		if err := nhcWithSelector(workerSelector).ValidateSelectorLabels(); err != nil {
+			t.Errorf("expected a selector without denied label keys to pass, got %v", err)
+		}
+	})
+
+	t.Run("allowlist", func(t *testing.T) {
+		t.Setenv(SelectorLabelAllowlistEnvVar, "node-role.kubernetes.io/worker")
+		if err := nhcWithSelector(workerSelector).ValidateSelectorLabels(); err != nil {
+			t.Errorf("expected an allowlisted selector label key to pass, got %v", err)
+		}
+		if err := nhcWithSelector(infraSelector).ValidateSelectorLabels(); err == nil {
+			t.Error("expected a selector label key missing from the allowlist to fail validation")
+		}
+	})
+
+	t.Run("denylist wins over allowlist", func(t *testing.T) {
+		t.Setenv(SelectorLabelAllowlistEnvVar, "node-role.kubernetes.io/infra")
+		t.Setenv(SelectorLabelDenylistEnvVar, "node-role.kubernetes.io/infra")
+		if err := nhcWithSelector(infraSelector).ValidateSelectorLabels(); err == nil {
+			t.Error("expected a denied selector label key to fail validation even when allowlisted")
+		}
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Topology classifies the cluster's control plane layout. Single node and compact
+// clusters need different remediation safety than standard clusters: fencing the
+// wrong node can take the control plane, and with it this operator, down.
+type Topology string
+
+const (
+	// TopologySNO is a single node cluster, the one node is control plane and
+	// worker at once
+	TopologySNO Topology = "SingleNode"
+	// TopologyCompact is a cluster whose control plane nodes are its only nodes,
+	// typically three schedulable control plane nodes
+	TopologyCompact Topology = "Compact"
+	// TopologyStandard is a cluster with dedicated worker nodes
+	TopologyStandard Topology = "Standard"
+)
+
+// SelfNodeRemediationKindPrefix prefixes the kinds of the Self Node Remediation
+// remediator, the only remediator which can safely fence the node it runs on
+const SelfNodeRemediationKindPrefix = "SelfNodeRemediation"
+
+// compactClusterNodes is the node count of a compact cluster, three schedulable
+// control plane nodes without dedicated workers
+const compactClusterNodes = 3
+
+// the control plane role labels, the master label is the deprecated predecessor
+// of the control-plane one and still set on older clusters
+const (
+	controlPlaneRoleLabel = "node-role.kubernetes.io/control-plane"
+	masterRoleLabel       = "node-role.kubernetes.io/master"
+)
+
+// DetectTopology lists the cluster's nodes and classifies their layout, see TopologyOf
+func DetectTopology(ctx context.Context, reader client.Reader) (Topology, error) {
+	nodes := &corev1.NodeList{}
+	if err := reader.List(ctx, nodes); err != nil {
+		return "", err
+	}
+	return TopologyOf(nodes.Items), nil
+}
+
+// TopologyOf classifies the given nodes: one node is a single node cluster, a cluster
+// made up of control plane nodes only is compact, everything else is standard
+func TopologyOf(nodes []corev1.Node) Topology {
+	if len(nodes) == 1 {
+		return TopologySNO
+	}
+	controlPlaneNodes := 0
+	for i := range nodes {
+		if isControlPlaneNode(&nodes[i]) {
+			controlPlaneNodes++
+		}
+	}
+	if controlPlaneNodes > 0 && controlPlaneNodes == len(nodes) {
+		return TopologyCompact
+	}
+	return TopologyStandard
+}
+
+func isControlPlaneNode(node *corev1.Node) bool {
+	labels := node.GetLabels()
+	_, controlPlane := labels[controlPlaneRoleLabel]
+	_, master := labels[masterRoleLabel]
+	return controlPlane || master
+}
@@ -0,0 +1,156 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTopologyNode(name string, labels map[string]string) corev1.Node {
+	return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestTopologyOf(t *testing.T) {
+	controlPlane := map[string]string{controlPlaneRoleLabel: ""}
+	master := map[string]string{masterRoleLabel: ""}
+
+	cases := []struct {
+		name     string
+		nodes    []corev1.Node
+		expected Topology
+	}{
+		{
+			name:     "single node",
+			nodes:    []corev1.Node{newTopologyNode("node-1", controlPlane)},
+			expected: TopologySNO,
+		},
+		{
+			name: "compact",
+			nodes: []corev1.Node{
+				newTopologyNode("node-1", controlPlane),
+				newTopologyNode("node-2", controlPlane),
+				newTopologyNode("node-3", master),
+			},
+			expected: TopologyCompact,
+		},
+		{
+			name: "standard",
+			nodes: []corev1.Node{
+				newTopologyNode("node-1", controlPlane),
+				newTopologyNode("node-2", controlPlane),
+				newTopologyNode("node-3", controlPlane),
+				newTopologyNode("worker-1", nil),
+			},
+			expected: TopologyStandard,
+		},
+		{
+			name:     "no nodes",
+			nodes:    nil,
+			expected: TopologyStandard,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if topology := TopologyOf(c.nodes); topology != c.expected {
+				t.Errorf("expected topology %s, got %s", c.expected, topology)
+			}
+		})
+	}
+}
+
+// setTestTopologyClient points the webhook's client at a fake cluster with the
+// given nodes, and restores the previous client when the test ends
+func setTestTopologyClient(t *testing.T, nodes ...corev1.Node) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range nodes {
+		builder = builder.WithObjects(&nodes[i])
+	}
+	previous := webhookClient
+	webhookClient = builder.Build()
+	t.Cleanup(func() { webhookClient = previous })
+}
+
+func TestValidateTopologySafety(t *testing.T) {
+	controlPlane := map[string]string{controlPlaneRoleLabel: ""}
+
+	t.Run("single node refuses external remediators", func(t *testing.T) {
+		setTestTopologyClient(t, newTopologyNode("node-1", controlPlane))
+		nhc := &NodeHealthCheck{Spec: NodeHealthCheckSpec{
+			RemediationTemplate: &corev1.ObjectReference{Kind: "MachineDeletionRemediationTemplate"},
+		}}
+		if err := nhc.validateTopologySafety(); err == nil {
+			t.Error("expected an external remediator to be refused on a single node cluster")
+		}
+		nhc.Spec.RemediationTemplate.Kind = "SelfNodeRemediationTemplate"
+		if err := nhc.validateTopologySafety(); err != nil {
+			t.Errorf("expected Self Node Remediation to pass on a single node cluster, got %v", err)
+		}
+	})
+
+	t.Run("compact refuses quorum breaking minHealthy", func(t *testing.T) {
+		setTestTopologyClient(t,
+			newTopologyNode("node-1", controlPlane),
+			newTopologyNode("node-2", controlPlane),
+			newTopologyNode("node-3", controlPlane),
+		)
+		minHealthy := intstr.FromInt(1)
+		nhc := &NodeHealthCheck{Spec: NodeHealthCheckSpec{MinHealthy: &minHealthy}}
+		if err := nhc.validateTopologySafety(); err == nil {
+			t.Error("expected minHealthy 1 to be refused on a compact cluster")
+		}
+		minHealthy = intstr.FromInt(2)
+		if err := nhc.validateTopologySafety(); err != nil {
+			t.Errorf("expected minHealthy 2 to pass on a compact cluster, got %v", err)
+		}
+	})
+
+	t.Run("standard accepts any configuration", func(t *testing.T) {
+		setTestTopologyClient(t,
+			newTopologyNode("node-1", controlPlane),
+			newTopologyNode("worker-1", nil),
+		)
+		minHealthy := intstr.FromInt(0)
+		nhc := &NodeHealthCheck{Spec: NodeHealthCheckSpec{
+			MinHealthy:          &minHealthy,
+			RemediationTemplate: &corev1.ObjectReference{Kind: "MachineDeletionRemediationTemplate"},
+		}}
+		if err := nhc.validateTopologySafety(); err != nil {
+			t.Errorf("expected a standard cluster to accept the configuration, got %v", err)
+		}
+	})
+}
+
+func TestDefaultMinHealthyByTopology(t *testing.T) {
+	controlPlane := map[string]string{controlPlaneRoleLabel: ""}
+
+	setTestTopologyClient(t, newTopologyNode("node-1", controlPlane))
+	if minHealthy := defaultMinHealthy(); minHealthy != intstr.FromInt(0) {
+		t.Errorf("expected minHealthy 0 on a single node cluster, got %s", minHealthy.String())
+	}
+
+	setTestTopologyClient(t,
+		newTopologyNode("node-1", controlPlane),
+		newTopologyNode("node-2", controlPlane),
+		newTopologyNode("node-3", controlPlane),
+	)
+	if minHealthy := defaultMinHealthy(); minHealthy != intstr.FromInt(2) {
+		t.Errorf("expected minHealthy 2 on a compact cluster, got %s", minHealthy.String())
+	}
+
+	setTestTopologyClient(t,
+		newTopologyNode("node-1", controlPlane),
+		newTopologyNode("worker-1", nil),
+	)
+	if minHealthy := defaultMinHealthy(); minHealthy != intstr.FromString("51%") {
+		t.Errorf("expected minHealthy 51%% on a standard cluster, got %s", minHealthy.String())
+	}
+}
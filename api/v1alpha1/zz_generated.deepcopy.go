@@ -24,10 +24,235 @@ package v1alpha1
 import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalRecord) DeepCopyInto(out *ApprovalRecord) {
+	*out = *in
+	in.RequestedAt.DeepCopyInto(&out.RequestedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalRecord.
+func (in *ApprovalRecord) DeepCopy() *ApprovalRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootObservation) DeepCopyInto(out *BootObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootObservation.
+func (in *BootObservation) DeepCopy() *BootObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(BootObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+	*out = *in
+	if in.SuccessWindow != nil {
+		in, out := &in.SuccessWindow, &out.SuccessWindow
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySpec.
+func (in *CanarySpec) DeepCopy() *CanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	if in.HealthySince != nil {
+		in, out := &in.HealthySince, &out.HealthySince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeRolloutPolicy) DeepCopyInto(out *ChangeRolloutPolicy) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxConcurrency != nil {
+		in, out := &in.MaxConcurrency, &out.MaxConcurrency
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeRolloutPolicy.
+func (in *ChangeRolloutPolicy) DeepCopy() *ChangeRolloutPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeRolloutPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeRolloutStatus) DeepCopyInto(out *ChangeRolloutStatus) {
+	*out = *in
+	if in.ChangedAt != nil {
+		in, out := &in.ChangedAt, &out.ChangedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeRolloutStatus.
+func (in *ChangeRolloutStatus) DeepCopy() *ChangeRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionTransition) DeepCopyInto(out *ConditionTransition) {
+	*out = *in
+	in.At.DeepCopyInto(&out.At)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionTransition.
+func (in *ConditionTransition) DeepCopy() *ConditionTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlapObservation) DeepCopyInto(out *FlapObservation) {
+	*out = *in
+	in.LastTransition.DeepCopyInto(&out.LastTransition)
+	if in.Flips != nil {
+		in, out := &in.Flips, &out.Flips
+		*out = make([]metav1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlapObservation.
+func (in *FlapObservation) DeepCopy() *FlapObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(FlapObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlappingPolicy) DeepCopyInto(out *FlappingPolicy) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxFlips != nil {
+		in, out := &in.MaxFlips, &out.MaxFlips
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlappingPolicy.
+func (in *FlappingPolicy) DeepCopy() *FlappingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FlappingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InFlightRemediation) DeepCopyInto(out *InFlightRemediation) {
+	*out = *in
+	out.Resource = in.Resource
+	in.Started.DeepCopyInto(&out.Started)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InFlightRemediation.
+func (in *InFlightRemediation) DeepCopy() *InFlightRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(InFlightRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeasePolicy) DeepCopyInto(out *LeasePolicy) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxExtensions != nil {
+		in, out := &in.MaxExtensions, &out.MaxExtensions
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeasePolicy.
+func (in *LeasePolicy) DeepCopy() *LeasePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LeasePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeHealthCheck) DeepCopyInto(out *NodeHealthCheck) {
 	*out = *in
@@ -91,6 +316,16 @@ func (in *NodeHealthCheckList) DeepCopyObject() runtime.Object {
 func (in *NodeHealthCheckSpec) DeepCopyInto(out *NodeHealthCheckSpec) {
 	*out = *in
 	in.Selector.DeepCopyInto(&out.Selector)
+	if in.MachineSetRefs != nil {
+		in, out := &in.MachineSetRefs, &out.MachineSetRefs
+		*out = make([]PoolRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePoolRefs != nil {
+		in, out := &in.NodePoolRefs, &out.NodePoolRefs
+		*out = make([]PoolRef, len(*in))
+		copy(*out, *in)
+	}
 	if in.UnhealthyConditions != nil {
 		in, out := &in.UnhealthyConditions, &out.UnhealthyConditions
 		*out = make([]UnhealthyCondition, len(*in))
@@ -101,11 +336,103 @@ func (in *NodeHealthCheckSpec) DeepCopyInto(out *NodeHealthCheckSpec) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.MinHealthyPerZone != nil {
+		in, out := &in.MinHealthyPerZone, &out.MinHealthyPerZone
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 	if in.RemediationTemplate != nil {
 		in, out := &in.RemediationTemplate, &out.RemediationTemplate
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.RemediationTemplates != nil {
+		in, out := &in.RemediationTemplates, &out.RemediationTemplates
+		*out = make([]NodePoolRemediationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OSRemediationTemplates != nil {
+		in, out := &in.OSRemediationTemplates, &out.OSRemediationTemplates
+		*out = make(map[string]*v1.ObjectReference, len(*in))
+		for key, val := range *in {
+			var outVal *v1.ObjectReference
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(v1.ObjectReference)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.FallbackRemediationTemplate != nil {
+		in, out := &in.FallbackRemediationTemplate, &out.FallbackRemediationTemplate
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.ObservationWindow != nil {
+		in, out := &in.ObservationWindow, &out.ObservationWindow
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LeasePolicy != nil {
+		in, out := &in.LeasePolicy, &out.LeasePolicy
+		*out = new(LeasePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FlappingDetection != nil {
+		in, out := &in.FlappingDetection, &out.FlappingDetection
+		*out = new(FlappingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreFenceDrain != nil {
+		in, out := &in.PreFenceDrain, &out.PreFenceDrain
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Virtualization != nil {
+		in, out := &in.Virtualization, &out.Virtualization
+		*out = new(VirtualizationOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ApprovalTimeout != nil {
+		in, out := &in.ApprovalTimeout, &out.ApprovalTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RemediationCRTemplateMetadata != nil {
+		in, out := &in.RemediationCRTemplateMetadata, &out.RemediationCRTemplateMetadata
+		*out = new(RemediationCRMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnhealthyResources != nil {
+		in, out := &in.UnhealthyResources, &out.UnhealthyResources
+		*out = make([]UnhealthyResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnhealthyEvents != nil {
+		in, out := &in.UnhealthyEvents, &out.UnhealthyEvents
+		*out = make([]UnhealthyEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnhealthyCEL != nil {
+		in, out := &in.UnhealthyCEL, &out.UnhealthyCEL
+		*out = new(string)
+		**out = **in
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChangeRolloutPolicy != nil {
+		in, out := &in.ChangeRolloutPolicy, &out.ChangeRolloutPolicy
+		*out = new(ChangeRolloutPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PauseRequests != nil {
 		in, out := &in.PauseRequests, &out.PauseRequests
 		*out = make([]string, len(*in))
@@ -126,6 +453,11 @@ func (in *NodeHealthCheckSpec) DeepCopy() *NodeHealthCheckSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeHealthCheckStatus) DeepCopyInto(out *NodeHealthCheckStatus) {
 	*out = *in
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(RemediationSummary)
+		**out = **in
+	}
 	if in.InFlightRemediations != nil {
 		in, out := &in.InFlightRemediations, &out.InFlightRemediations
 		*out = make(map[string]metav1.Time, len(*in))
@@ -133,6 +465,112 @@ func (in *NodeHealthCheckStatus) DeepCopyInto(out *NodeHealthCheckStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.InFlightRemediationDetails != nil {
+		in, out := &in.InFlightRemediationDetails, &out.InFlightRemediationDetails
+		*out = make([]InFlightRemediation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnhealthyNodeObservations != nil {
+		in, out := &in.UnhealthyNodeObservations, &out.UnhealthyNodeObservations
+		*out = make(map[string]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.BootObservations != nil {
+		in, out := &in.BootObservations, &out.BootObservations
+		*out = make(map[string]BootObservation, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PendingFenceDrains != nil {
+		in, out := &in.PendingFenceDrains, &out.PendingFenceDrains
+		*out = make(map[string]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.RemediationCreateFailures != nil {
+		in, out := &in.RemediationCreateFailures, &out.RemediationCreateFailures
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PendingLiveMigrations != nil {
+		in, out := &in.PendingLiveMigrations, &out.PendingLiveMigrations
+		*out = make(map[string]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.PendingApprovals != nil {
+		in, out := &in.PendingApprovals, &out.PendingApprovals
+		*out = make(map[string]ApprovalRecord, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.RemediationImpacts != nil {
+		in, out := &in.RemediationImpacts, &out.RemediationImpacts
+		*out = make(map[string]RemediationImpact, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ResourceObservations != nil {
+		in, out := &in.ResourceObservations, &out.ResourceObservations
+		*out = make(map[string]ResourceObservation, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.FlapObservations != nil {
+		in, out := &in.FlapObservations, &out.FlapObservations
+		*out = make(map[string]FlapObservation, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.LastRemediationOutcomes != nil {
+		in, out := &in.LastRemediationOutcomes, &out.LastRemediationOutcomes
+		*out = make(map[string]RemediationOutcome, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.RemediationStats != nil {
+		in, out := &in.RemediationStats, &out.RemediationStats
+		*out = new(RemediationStats)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChangeRollout != nil {
+		in, out := &in.ChangeRollout, &out.ChangeRollout
+		*out = new(ChangeRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemediationTemplateStatuses != nil {
+		in, out := &in.RemediationTemplateStatuses, &out.RemediationTemplateStatuses
+		*out = make([]RemediationTemplateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SpecRevisions != nil {
+		in, out := &in.SpecRevisions, &out.SpecRevisions
+		*out = make([]SpecRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -153,17 +591,547 @@ func (in *NodeHealthCheckStatus) DeepCopy() *NodeHealthCheckStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UnhealthyCondition) DeepCopyInto(out *UnhealthyCondition) {
+func (in *NodePoolRemediationTemplate) DeepCopyInto(out *NodePoolRemediationTemplate) {
 	*out = *in
-	out.Duration = in.Duration
+	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.RemediationTemplate != nil {
+		in, out := &in.RemediationTemplate, &out.RemediationTemplate
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyCondition.
-func (in *UnhealthyCondition) DeepCopy() *UnhealthyCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolRemediationTemplate.
+func (in *NodePoolRemediationTemplate) DeepCopy() *NodePoolRemediationTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(UnhealthyCondition)
+	out := new(NodePoolRemediationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediationState) DeepCopyInto(out *NodeRemediationState) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRemediationState.
+func (in *NodeRemediationState) DeepCopy() *NodeRemediationState {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRemediationState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeRemediationState) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediationStateList) DeepCopyInto(out *NodeRemediationStateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeRemediationState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRemediationStateList.
+func (in *NodeRemediationStateList) DeepCopy() *NodeRemediationStateList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRemediationStateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeRemediationStateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediationStateSpec) DeepCopyInto(out *NodeRemediationStateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRemediationStateSpec.
+func (in *NodeRemediationStateSpec) DeepCopy() *NodeRemediationStateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRemediationStateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediationStateStatus) DeepCopyInto(out *NodeRemediationStateStatus) {
+	*out = *in
+	if in.LastTransition != nil {
+		in, out := &in.LastTransition, &out.LastTransition
+		*out = (*in).DeepCopy()
+	}
+	if in.ConditionHistory != nil {
+		in, out := &in.ConditionHistory, &out.ConditionHistory
+		*out = make([]ConditionTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRemediationStateStatus.
+func (in *NodeRemediationStateStatus) DeepCopy() *NodeRemediationStateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRemediationStateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolRef) DeepCopyInto(out *PoolRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolRef.
+func (in *PoolRef) DeepCopy() *PoolRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationCRMetadata) DeepCopyInto(out *RemediationCRMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationCRMetadata.
+func (in *RemediationCRMetadata) DeepCopy() *RemediationCRMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationCRMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationImpact) DeepCopyInto(out *RemediationImpact) {
+	*out = *in
+	in.EstimatedAt.DeepCopyInto(&out.EstimatedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationImpact.
+func (in *RemediationImpact) DeepCopy() *RemediationImpact {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationImpact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationOutcome) DeepCopyInto(out *RemediationOutcome) {
+	*out = *in
+	in.Started.DeepCopyInto(&out.Started)
+	if in.Ended != nil {
+		in, out := &in.Ended, &out.Ended
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationOutcome.
+func (in *RemediationOutcome) DeepCopy() *RemediationOutcome {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationOutcome)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationStats) DeepCopyInto(out *RemediationStats) {
+	*out = *in
+	if in.LongestSelfRecovery != nil {
+		in, out := &in.LongestSelfRecovery, &out.LongestSelfRecovery
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationStats.
+func (in *RemediationStats) DeepCopy() *RemediationStats {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationSummary) DeepCopyInto(out *RemediationSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationSummary.
+func (in *RemediationSummary) DeepCopy() *RemediationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationTemplateStatus) DeepCopyInto(out *RemediationTemplateStatus) {
+	*out = *in
+	out.TemplateRef = in.TemplateRef
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationTemplateStatus.
+func (in *RemediationTemplateStatus) DeepCopy() *RemediationTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediation) DeepCopyInto(out *ReplaceMachineRemediation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediation.
+func (in *ReplaceMachineRemediation) DeepCopy() *ReplaceMachineRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReplaceMachineRemediation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediationList) DeepCopyInto(out *ReplaceMachineRemediationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ReplaceMachineRemediation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediationList.
+func (in *ReplaceMachineRemediationList) DeepCopy() *ReplaceMachineRemediationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReplaceMachineRemediationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediationSpec) DeepCopyInto(out *ReplaceMachineRemediationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediationSpec.
+func (in *ReplaceMachineRemediationSpec) DeepCopy() *ReplaceMachineRemediationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediationStatus) DeepCopyInto(out *ReplaceMachineRemediationStatus) {
+	*out = *in
+	if in.MachineDeleted != nil {
+		in, out := &in.MachineDeleted, &out.MachineDeleted
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediationStatus.
+func (in *ReplaceMachineRemediationStatus) DeepCopy() *ReplaceMachineRemediationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediationTemplate) DeepCopyInto(out *ReplaceMachineRemediationTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediationTemplate.
+func (in *ReplaceMachineRemediationTemplate) DeepCopy() *ReplaceMachineRemediationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReplaceMachineRemediationTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediationTemplateList) DeepCopyInto(out *ReplaceMachineRemediationTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ReplaceMachineRemediationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediationTemplateList.
+func (in *ReplaceMachineRemediationTemplateList) DeepCopy() *ReplaceMachineRemediationTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediationTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReplaceMachineRemediationTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediationTemplateResource) DeepCopyInto(out *ReplaceMachineRemediationTemplateResource) {
+	*out = *in
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediationTemplateResource.
+func (in *ReplaceMachineRemediationTemplateResource) DeepCopy() *ReplaceMachineRemediationTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediationTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplaceMachineRemediationTemplateSpec) DeepCopyInto(out *ReplaceMachineRemediationTemplateSpec) {
+	*out = *in
+	out.Template = in.Template
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplaceMachineRemediationTemplateSpec.
+func (in *ReplaceMachineRemediationTemplateSpec) DeepCopy() *ReplaceMachineRemediationTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplaceMachineRemediationTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceObservation) DeepCopyInto(out *ResourceObservation) {
+	*out = *in
+	if in.ZeroSince != nil {
+		in, out := &in.ZeroSince, &out.ZeroSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceObservation.
+func (in *ResourceObservation) DeepCopy() *ResourceObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecRevision) DeepCopyInto(out *SpecRevision) {
+	*out = *in
+	in.ChangedAt.DeepCopyInto(&out.ChangedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecRevision.
+func (in *SpecRevision) DeepCopy() *SpecRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnhealthyCondition) DeepCopyInto(out *UnhealthyCondition) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyCondition.
+func (in *UnhealthyCondition) DeepCopy() *UnhealthyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnhealthyEvent) DeepCopyInto(out *UnhealthyEvent) {
+	*out = *in
+	out.Window = in.Window
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyEvent.
+func (in *UnhealthyEvent) DeepCopy() *UnhealthyEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnhealthyResource) DeepCopyInto(out *UnhealthyResource) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyResource.
+func (in *UnhealthyResource) DeepCopy() *UnhealthyResource {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualizationOptions) DeepCopyInto(out *VirtualizationOptions) {
+	*out = *in
+	if in.LiveMigrationTimeout != nil {
+		in, out := &in.LiveMigrationTimeout, &out.LiveMigrationTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualizationOptions.
+func (in *VirtualizationOptions) DeepCopy() *VirtualizationOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualizationOptions)
 	in.DeepCopyInto(out)
 	return out
 }
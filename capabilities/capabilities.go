@@ -0,0 +1,166 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capabilities defines the convention through which remediators declare what
+// their remediation does, and a reader for those declarations. Remediators annotate
+// their remediation template CRD, e.g.:
+//
+//	metadata:
+//	  annotations:
+//	    capabilities.remediation.medik8s.io/reboots-node: "true"
+//	    capabilities.remediation.medik8s.io/typical-duration: "5m"
+//
+// NHC reads the declarations to sanity check remediation chains and to size node
+// leases, other consumers are welcome to import this package.
+package capabilities
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
+const (
+	annotationPrefix = "capabilities.remediation.medik8s.io/"
+
+	// RebootsNodeAnnotation declares whether the remediation reboots the node,
+	// "true" or "false"
+	RebootsNodeAnnotation = annotationPrefix + "reboots-node"
+
+	// DeletesMachineAnnotation declares whether the remediation deletes the backing
+	// machine, "true" or "false"
+	DeletesMachineAnnotation = annotationPrefix + "deletes-machine"
+
+	// NeedsBMCAnnotation declares whether the remediation requires BMC access,
+	// "true" or "false"
+	NeedsBMCAnnotation = annotationPrefix + "needs-bmc"
+
+	// TypicalDurationAnnotation declares how long a remediation typically takes,
+	// a Go duration like "5m"
+	TypicalDurationAnnotation = annotationPrefix + "typical-duration"
+
+	// cacheTTL is how long read declarations are served from cache. Capabilities
+	// only change with remediator upgrades, so staleness of this order is fine.
+	cacheTTL = 10 * time.Minute
+)
+
+// Capabilities is what a remediator declared about its remediation. A nil field means
+// the remediator didn't declare that capability, which is distinct from declaring it
+// false: remediators not taking part in the convention must not be treated as unable.
+type Capabilities struct {
+	RebootsNode     *bool
+	DeletesMachine  *bool
+	NeedsBMC        *bool
+	TypicalDuration *time.Duration
+}
+
+// FromAnnotations reads the declared capabilities from the given annotations, usually
+// the ones of a remediation template CRD
+func FromAnnotations(annotations map[string]string) (Capabilities, error) {
+	caps := Capabilities{}
+	var err error
+	if caps.RebootsNode, err = parseBoolAnnotation(annotations, RebootsNodeAnnotation); err != nil {
+		return caps, err
+	}
+	if caps.DeletesMachine, err = parseBoolAnnotation(annotations, DeletesMachineAnnotation); err != nil {
+		return caps, err
+	}
+	if caps.NeedsBMC, err = parseBoolAnnotation(annotations, NeedsBMCAnnotation); err != nil {
+		return caps, err
+	}
+	if value, declared := annotations[TypicalDurationAnnotation]; declared {
+		duration, parseErr := time.ParseDuration(value)
+		if parseErr != nil {
+			return caps, errors.Wrapf(parseErr, "invalid value %q of annotation %s", value, TypicalDurationAnnotation)
+		}
+		caps.TypicalDuration = &duration
+	}
+	return caps, nil
+}
+
+func parseBoolAnnotation(annotations map[string]string, annotation string) (*bool, error) {
+	value, declared := annotations[annotation]
+	if !declared {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid value %q of annotation %s", value, annotation)
+	}
+	return &parsed, nil
+}
+
+// Reader fetches the capabilities declared on remediation template CRDs
+type Reader struct {
+	client client.Client
+	mutex  sync.Mutex
+	cache  map[schema.GroupKind]cacheEntry
+}
+
+type cacheEntry struct {
+	capabilities Capabilities
+	fetched      time.Time
+}
+
+// NewReader returns a Reader backed by the given client. Pass the manager's client
+// where available, so CRD lookups are served from an informer.
+func NewReader(c client.Client) *Reader {
+	return &Reader{
+		client: c,
+		cache:  map[schema.GroupKind]cacheEntry{},
+	}
+}
+
+// ForGroupKind returns the capabilities declared on the CRD serving the given template
+// group and kind. A missing CRD or one without declarations yields empty capabilities,
+// not an error.
+func (r *Reader) ForGroupKind(ctx context.Context, gk schema.GroupKind) (Capabilities, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if entry, cached := r.cache[gk]; cached && time.Since(entry.fetched) < cacheTTL {
+		return entry.capabilities, nil
+	}
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.client.List(ctx, crds); err != nil {
+		return Capabilities{}, errors.Wrap(err, "failed to list CRDs for capability discovery")
+	}
+
+	caps := Capabilities{}
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if crd.Spec.Group != gk.Group || crd.Spec.Names.Kind != gk.Kind {
+			continue
+		}
+		var err error
+		if caps, err = FromAnnotations(crd.GetAnnotations()); err != nil {
+			return Capabilities{}, errors.Wrapf(err, "CRD %s declares invalid capabilities", crd.GetName())
+		}
+		break
+	}
+	r.cache[gk] = cacheEntry{capabilities: caps, fetched: time.Now()}
+	return caps, nil
+}
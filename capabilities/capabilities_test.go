@@ -0,0 +1,48 @@
+package capabilities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromAnnotations(t *testing.T) {
+	caps, err := FromAnnotations(map[string]string{
+		RebootsNodeAnnotation:     "true",
+		DeletesMachineAnnotation:  "false",
+		TypicalDurationAnnotation: "5m",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps.RebootsNode == nil || !*caps.RebootsNode {
+		t.Error("expected reboots-node to be declared true")
+	}
+	if caps.DeletesMachine == nil || *caps.DeletesMachine {
+		t.Error("expected deletes-machine to be declared false")
+	}
+	if caps.NeedsBMC != nil {
+		t.Error("expected needs-bmc to be undeclared")
+	}
+	if caps.TypicalDuration == nil || *caps.TypicalDuration != 5*time.Minute {
+		t.Errorf("expected a typical duration of 5m, got %v", caps.TypicalDuration)
+	}
+}
+
+func TestFromAnnotationsUndeclared(t *testing.T) {
+	caps, err := FromAnnotations(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps.RebootsNode != nil || caps.DeletesMachine != nil || caps.NeedsBMC != nil || caps.TypicalDuration != nil {
+		t.Errorf("expected all capabilities to be undeclared, got %+v", caps)
+	}
+}
+
+func TestFromAnnotationsInvalidValues(t *testing.T) {
+	if _, err := FromAnnotations(map[string]string{RebootsNodeAnnotation: "maybe"}); err == nil {
+		t.Error("expected an error for an invalid bool value")
+	}
+	if _, err := FromAnnotations(map[string]string{TypicalDurationAnnotation: "fast"}); err == nil {
+		t.Error("expected an error for an invalid duration value")
+	}
+}
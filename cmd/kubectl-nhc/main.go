@@ -0,0 +1,280 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-nhc is a kubectl plugin for inspecting and controlling NodeHealthCheck
+// driven fencing. Install the binary as kubectl-nhc on the PATH (or via krew) and
+// run it as `kubectl nhc <subcommand>`.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	// so the plugin works with the same kubeconfigs as kubectl.
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		fail(err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "status":
+		err = runStatus(ctx, c)
+	case "unhealthy-nodes":
+		err = runUnhealthyNodes(ctx, c)
+	case "pause":
+		if len(args) != 3 {
+			fail(fmt.Errorf("usage: kubectl nhc pause <name> <reason>"))
+		}
+		err = runPause(ctx, c, args[1], args[2])
+	case "unpause":
+		if len(args) != 2 && len(args) != 3 {
+			fail(fmt.Errorf("usage: kubectl nhc unpause <name> [reason]"))
+		}
+		reason := ""
+		if len(args) == 3 {
+			reason = args[2]
+		}
+		err = runUnpause(ctx, c, args[1], reason)
+	case "history":
+		err = runHistory(ctx, c)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `View and control NodeHealthCheck driven fencing.
+
+Usage:
+  kubectl nhc status                    Show all NodeHealthChecks and their phase
+  kubectl nhc unhealthy-nodes           List nodes currently considered unhealthy
+  kubectl nhc pause <name> <reason>     Add a pause request to a NodeHealthCheck
+  kubectl nhc unpause <name> [reason]   Remove a pause request, or all of them
+  kubectl nhc history                   Show recent NodeHealthCheck events
+`)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
+
+func newClient() (client.Client, error) {
+	scheme := pkgruntime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := remediationv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+func runStatus(ctx context.Context, c client.Client) error {
+	nhcList := &remediationv1alpha1.NodeHealthCheckList{}
+	if err := c.List(ctx, nhcList); err != nil {
+		return err
+	}
+	w := newTabWriter()
+	defer w.Flush()
+	fmt.Fprintln(w, "NAME\tPHASE\tOBSERVED\tHEALTHY\tREMEDIATING\tPAUSED\tREASON")
+	for _, nhc := range nhcList.Items {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			nhc.Name,
+			nhc.Status.Phase,
+			nhc.Status.ObservedNodes,
+			nhc.Status.HealthyNodes,
+			len(nhc.Status.InFlightRemediations),
+			len(nhc.Spec.PauseRequests),
+			nhc.Status.Reason,
+		)
+	}
+	return nil
+}
+
+func runUnhealthyNodes(ctx context.Context, c client.Client) error {
+	nhcList := &remediationv1alpha1.NodeHealthCheckList{}
+	if err := c.List(ctx, nhcList); err != nil {
+		return err
+	}
+	w := newTabWriter()
+	defer w.Flush()
+	fmt.Fprintln(w, "NODEHEALTHCHECK\tNODE\tREMEDIATING SINCE\tREMEDIATOR\tSTEP")
+	for _, nhc := range nhcList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&nhc.Spec.Selector)
+		if err != nil {
+			return err
+		}
+		nodes := &v1.NodeList{}
+		if err := c.List(ctx, nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+			return err
+		}
+		for _, node := range nodes.Items {
+			healthy, _ := utils.IsNodeHealthy(nhc.Spec.UnhealthyConditions, node.Status.Conditions, time.Now())
+			remediatingSince := ""
+			if started, inFlight := nhc.Status.InFlightRemediations[node.GetName()]; inFlight {
+				remediatingSince = duration.HumanDuration(time.Since(started.Time))
+			}
+			remediator, step := "", ""
+			for _, detail := range nhc.Status.InFlightRemediationDetails {
+				if detail.NodeName == node.GetName() {
+					remediator = detail.Resource.Kind
+					if detail.Step > 0 {
+						step = fmt.Sprintf("%d", detail.Step)
+					}
+				}
+			}
+			if healthy && remediatingSince == "" {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", nhc.Name, node.GetName(), remediatingSince, remediator, step)
+		}
+	}
+	return nil
+}
+
+func runPause(ctx context.Context, c client.Client, name string, reason string) error {
+	nhc, err := getNHC(ctx, c, name)
+	if err != nil {
+		return err
+	}
+	for _, existing := range nhc.Spec.PauseRequests {
+		if existing == reason {
+			fmt.Printf("NodeHealthCheck %s is already paused with reason %q\n", name, reason)
+			return nil
+		}
+	}
+	patch := client.MergeFrom(nhc.DeepCopy())
+	nhc.Spec.PauseRequests = append(nhc.Spec.PauseRequests, reason)
+	if err := c.Patch(ctx, nhc, patch); err != nil {
+		return err
+	}
+	fmt.Printf("NodeHealthCheck %s paused with reason %q\n", name, reason)
+	return nil
+}
+
+func runUnpause(ctx context.Context, c client.Client, name string, reason string) error {
+	nhc, err := getNHC(ctx, c, name)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(nhc.DeepCopy())
+	if reason == "" {
+		if len(nhc.Spec.PauseRequests) == 0 {
+			fmt.Printf("NodeHealthCheck %s is not paused\n", name)
+			return nil
+		}
+		nhc.Spec.PauseRequests = nil
+	} else {
+		remaining := make([]string, 0, len(nhc.Spec.PauseRequests))
+		for _, existing := range nhc.Spec.PauseRequests {
+			if existing != reason {
+				remaining = append(remaining, existing)
+			}
+		}
+		if len(remaining) == len(nhc.Spec.PauseRequests) {
+			return fmt.Errorf("NodeHealthCheck %s has no pause request with reason %q", name, reason)
+		}
+		nhc.Spec.PauseRequests = remaining
+	}
+	if err := c.Patch(ctx, nhc, patch); err != nil {
+		return err
+	}
+	fmt.Printf("NodeHealthCheck %s unpaused\n", name)
+	return nil
+}
+
+func runHistory(ctx context.Context, c client.Client) error {
+	events := &v1.EventList{}
+	if err := c.List(ctx, events, client.MatchingFields{"involvedObject.kind": "NodeHealthCheck"}); err != nil {
+		return err
+	}
+	sort.Slice(events.Items, func(i, j int) bool {
+		return eventTime(&events.Items[i]).Before(eventTime(&events.Items[j]))
+	})
+	w := newTabWriter()
+	defer w.Flush()
+	fmt.Fprintln(w, "AGE\tTYPE\tREASON\tNODEHEALTHCHECK\tMESSAGE")
+	for i := range events.Items {
+		event := &events.Items[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			duration.HumanDuration(time.Since(eventTime(event))),
+			event.Type,
+			event.Reason,
+			event.InvolvedObject.Name,
+			event.Message,
+		)
+	}
+	return nil
+}
+
+func getNHC(ctx context.Context, c client.Client, name string) (*remediationv1alpha1.NodeHealthCheck, error) {
+	nhc := &remediationv1alpha1.NodeHealthCheck{}
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, nhc); err != nil {
+		return nil, err
+	}
+	return nhc, nil
+}
+
+func eventTime(event *v1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.EventTime.Time
+}
+
+func newTabWriter() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+}
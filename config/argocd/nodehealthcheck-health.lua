@@ -0,0 +1,51 @@
+-- Argo CD resource health check for NodeHealthCheck CRs.
+--
+-- Register it in the argocd-cm ConfigMap under
+-- resource.customizations.health.remediation.medik8s.io_NodeHealthCheck.
+--
+-- The condition types and the observedGeneration contract mirror
+-- api/v1alpha1/nodehealthcheck_types.go, keep them in sync:
+-- - Disabled=True and Degraded=True map to Degraded
+-- - GloballyPaused=True maps to Suspended
+-- - a status or condition behind metadata.generation maps to Progressing
+
+hs = {}
+if obj.status ~= nil then
+  if obj.status.observedGeneration ~= nil and obj.metadata.generation ~= nil and obj.status.observedGeneration < obj.metadata.generation then
+    hs.status = "Progressing"
+    hs.message = "Waiting for the controller to observe the latest spec"
+    return hs
+  end
+  if obj.status.conditions ~= nil then
+    for i, condition in ipairs(obj.status.conditions) do
+      if condition.observedGeneration ~= nil and obj.metadata.generation ~= nil and condition.observedGeneration < obj.metadata.generation then
+        hs.status = "Progressing"
+        hs.message = "Condition " .. condition.type .. " was not evaluated for the latest spec yet"
+        return hs
+      end
+      if condition.type == "Disabled" and condition.status == "True" then
+        hs.status = "Degraded"
+        hs.message = condition.message
+        return hs
+      end
+      if condition.type == "Degraded" and condition.status == "True" then
+        hs.status = "Degraded"
+        hs.message = condition.message
+        return hs
+      end
+      if condition.type == "GloballyPaused" and condition.status == "True" then
+        hs.status = "Suspended"
+        hs.message = condition.message
+        return hs
+      end
+    end
+  end
+  if obj.status.phase == "Remediating" then
+    hs.status = "Progressing"
+    hs.message = obj.status.reason
+    return hs
+  end
+end
+hs.status = "Healthy"
+hs.message = "NodeHealthCheck is operational"
+return hs
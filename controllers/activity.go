@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+// activityWindow is the look-back of the RecentActivity condition, capped by the
+// events aggregator's retention
+const activityWindow = 24 * time.Hour
+
+// updateRecentActivityCondition rolls the NHC's own recent events up into the
+// RecentActivity condition, so the last day's remediation activity shows up when
+// describing the NHC without querying the metrics
+func (r *NodeHealthCheckReconciler) updateRecentActivityCondition(nhc *remediationv1alpha1.NodeHealthCheck) {
+	if r.EventsAggregator == nil {
+		return
+	}
+	remediations := r.EventsAggregator.CountRecentFor("NodeHealthCheck", nhc.GetName(), eventReasonRemediationCreated, activityWindow)
+	escalations := r.EventsAggregator.CountRecentFor("NodeHealthCheck", nhc.GetName(), eventReasonRemediationEscalated, activityWindow)
+	failures := r.EventsAggregator.CountRecentFor("NodeHealthCheck", nhc.GetName(), eventReasonRemediationTimedOut, activityWindow)
+
+	condition := metav1.Condition{
+		Type:    remediationv1alpha1.ConditionTypeRecentActivity,
+		Status:  metav1.ConditionFalse,
+		Reason:  remediationv1alpha1.ConditionReasonNoRecentActivity,
+		Message: "No remediation activity in the last 24h",
+	}
+	if remediations+escalations+failures > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = remediationv1alpha1.ConditionReasonRecentActivity
+		condition.Message = fmt.Sprintf("%d remediations, %d escalations and %d failures in the last 24h",
+			remediations, escalations, failures)
+	}
+	setStatusCondition(nhc, condition)
+}
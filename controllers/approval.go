@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	eventReasonApprovalRequested = "RemediationApprovalRequested"
+	eventReasonApprovalGranted   = "RemediationApproved"
+	eventReasonApprovalDenied    = "RemediationApprovalDenied"
+
+	// defaultApprovalTimeout bounds how long remediation waits for approval when
+	// spec.approvalTimeout is unset
+	defaultApprovalTimeout = time.Hour
+)
+
+// applyApproval implements spec.approvalRequired: the remediation CR of a node is
+// only created once an admin listed the node in the approved-nodes annotation.
+// Requests not approved in time are denied until the node recovers. It returns
+// whether fencing may proceed, and a requeue delay while it may not. Every state
+// change is evented for the audit trail.
+func (r *NodeHealthCheckReconciler) applyApproval(node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) (*time.Duration, bool) {
+	if !nhc.Spec.ApprovalRequired {
+		return nil, true
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+	name := node.GetName()
+	record, exists := nhc.Status.PendingApprovals[name]
+
+	if approvedNodes(nhc)[name] {
+		if exists {
+			delete(nhc.Status.PendingApprovals, name)
+			log.Info("remediation was approved", "Node name", name)
+			r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonApprovalGranted,
+				"Remediation of node %s was approved via the %s annotation", name, remediationv1alpha1.ApprovedNodesAnnotation)
+		}
+		return nil, true
+	}
+
+	timeout := approvalTimeout(nhc)
+	if !exists {
+		if nhc.Status.PendingApprovals == nil {
+			nhc.Status.PendingApprovals = map[string]remediationv1alpha1.ApprovalRecord{}
+		}
+		nhc.Status.PendingApprovals[name] = remediationv1alpha1.ApprovalRecord{RequestedAt: metav1.Time{Time: r.now()}}
+		log.Info("waiting for remediation approval", "Node name", name, "timeout", timeout)
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonApprovalRequested,
+			"Node %s needs remediation, approve it within %s by adding the node to the %s annotation",
+			name, timeout, remediationv1alpha1.ApprovedNodesAnnotation)
+		return &timeout, false
+	}
+
+	if record.Denied {
+		// denied stays denied until the node recovers, see markHealthy
+		return nil, false
+	}
+
+	if remaining := timeout - r.now().Sub(record.RequestedAt.Time); remaining > 0 {
+		return &remaining, false
+	}
+
+	record.Denied = true
+	nhc.Status.PendingApprovals[name] = record
+	log.Info("remediation approval timed out, denying remediation", "Node name", name)
+	r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonApprovalDenied,
+		"Remediation of node %s was not approved within %s and is denied, the node stays unremediated until it recovers",
+		name, timeout)
+	return nil, false
+}
+
+// approvalTimeout returns the configured approval timeout, or the default
+func approvalTimeout(nhc *remediationv1alpha1.NodeHealthCheck) time.Duration {
+	if timeout := nhc.Spec.ApprovalTimeout; timeout != nil && timeout.Duration > 0 {
+		return timeout.Duration
+	}
+	return defaultApprovalTimeout
+}
+
+// approvedNodes parses the approved-nodes annotation into a set of node names
+func approvedNodes(nhc *remediationv1alpha1.NodeHealthCheck) map[string]bool {
+	approved := map[string]bool{}
+	for _, name := range strings.Split(nhc.GetAnnotations()[remediationv1alpha1.ApprovedNodesAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			approved[name] = true
+		}
+	}
+	return approved
+}
+
+// updateApprovalPendingCondition reflects the nodes still waiting for approval in
+// the ApprovalPending condition, so they show up when describing the NHC
+func (r *NodeHealthCheckReconciler) updateApprovalPendingCondition(nhc *remediationv1alpha1.NodeHealthCheck) {
+	var waiting []string
+	for name, record := range nhc.Status.PendingApprovals {
+		if !record.Denied {
+			waiting = append(waiting, name)
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    remediationv1alpha1.ConditionTypeApprovalPending,
+		Status:  metav1.ConditionFalse,
+		Reason:  remediationv1alpha1.ConditionReasonNoApprovalPending,
+		Message: "No remediation waits for approval",
+	}
+	if len(waiting) > 0 {
+		sort.Strings(waiting)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = remediationv1alpha1.ConditionReasonApprovalPending
+		condition.Message = fmt.Sprintf("Remediation of node(s) %s waits for approval", strings.Join(waiting, ", "))
+	}
+	setStatusCondition(nhc, condition)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const eventReasonNodeRebooted = "NodeRebooted"
+
+// trackNodeBoots records each unhealthy node's boot identification when its
+// unhealthiness is first observed, and returns the names of the nodes which rebooted
+// since. A rebooted node may already have recovered on its own, so its observation
+// window restarts instead of fencing it for a problem the reboot may have fixed; it
+// is remediated as usual when it stays unhealthy past the restarted window. Nodes
+// whose kubelet doesn't report a boot ID are tracked without the reboot detection.
+func (r *NodeHealthCheckReconciler) trackNodeBoots(nhc *remediationv1alpha1.NodeHealthCheck, unhealthyNodes []v1.Node) map[string]struct{} {
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	rebooted := map[string]struct{}{}
+	var observations map[string]remediationv1alpha1.BootObservation
+	for i := range unhealthyNodes {
+		node := &unhealthyNodes[i]
+		current := remediationv1alpha1.BootObservation{
+			BootID:     node.Status.NodeInfo.BootID,
+			SystemUUID: node.Status.NodeInfo.SystemUUID,
+		}
+		if observations == nil {
+			observations = make(map[string]remediationv1alpha1.BootObservation, len(unhealthyNodes))
+		}
+		previous, observed := nhc.Status.BootObservations[node.GetName()]
+		if !observed {
+			observations[node.GetName()] = current
+			continue
+		}
+		if previous.BootID == "" || current.BootID == "" || previous.BootID == current.BootID {
+			observations[node.GetName()] = previous
+			continue
+		}
+		if previous.SystemUUID != "" && current.SystemUUID != "" && previous.SystemUUID != current.SystemUUID {
+			log.Info("node was replaced since it was observed unhealthy, restarting its observation",
+				"Node name", node.GetName())
+		} else {
+			log.Info("node rebooted since it was observed unhealthy, restarting its observation",
+				"Node name", node.GetName())
+		}
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonNodeRebooted,
+			"Node %s rebooted since it was observed unhealthy, restarting its observation instead of remediating", node.GetName())
+		// restarting the observation makes filterObservedNodes treat the node as
+		// newly observed
+		delete(nhc.Status.UnhealthyNodeObservations, node.GetName())
+		observations[node.GetName()] = current
+		rebooted[node.GetName()] = struct{}{}
+	}
+	// nodes which turned healthy again are dropped here
+	nhc.Status.BootObservations = observations
+	return rebooted
+}
+
+// filterRebootedNodes drops the remediation candidates which rebooted since their
+// unhealthiness was first observed, see trackNodeBoots
+func filterRebootedNodes(candidates []v1.Node, rebooted map[string]struct{}) []v1.Node {
+	if len(rebooted) == 0 {
+		return candidates
+	}
+	var kept []v1.Node
+	for _, node := range candidates {
+		if _, recentlyRebooted := rebooted[node.GetName()]; !recentlyRebooted {
+			kept = append(kept, node)
+		}
+	}
+	return kept
+}
@@ -6,7 +6,10 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/medik8s/node-healthcheck-operator/controllers/consistency"
 	"github.com/medik8s/node-healthcheck-operator/controllers/defaults"
+	"github.com/medik8s/node-healthcheck-operator/controllers/lease"
+	"github.com/medik8s/node-healthcheck-operator/controllers/monitoring"
 	"github.com/medik8s/node-healthcheck-operator/controllers/rbac"
 	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
 )
@@ -14,7 +17,9 @@ import (
 // Initialize runs some bootstrapping code:
 // - setup role aggregation
 // - create default NHC
-func Initialize(mgr ctrl.Manager, log logr.Logger) error {
+// - setup metrics monitoring
+// - clean up stale state left over from a restored etcd backup
+func Initialize(mgr ctrl.Manager, leaseManager lease.Manager, log logr.Logger) error {
 
 	ns, err := utils.GetDeploymentNamespace()
 	if err != nil {
@@ -29,5 +34,13 @@ func Initialize(mgr ctrl.Manager, log logr.Logger) error {
 		return errors.Wrap(err, "failed to create or update a default NHC resource")
 	}
 
+	if err = monitoring.CreateOrUpdateMonitoring(mgr, ns, ctrl.Log.WithName("monitoring")); err != nil {
+		return errors.Wrap(err, "failed to set up metrics monitoring")
+	}
+
+	if err = consistency.CheckAndCleanup(mgr, leaseManager, ctrl.Log.WithName("consistency")); err != nil {
+		return errors.Wrap(err, "failed to clean up stale in-flight remediations")
+	}
+
 	return nil
 }
@@ -11,12 +11,14 @@ import (
 
 	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
 	"github.com/medik8s/node-healthcheck-operator/controllers/defaults"
+	"github.com/medik8s/node-healthcheck-operator/controllers/lease"
 )
 
 var _ = Describe("Init", func() {
 
 	BeforeEach(func() {
-		Expect(Initialize(k8sManager, ctrl.Log.WithName("test init"))).To(Succeed())
+		leaseManager := lease.NewManager(k8sClient, "default", lease.NewLocalClock(), ctrl.Log.WithName("test lease manager"))
+		Expect(Initialize(k8sManager, leaseManager, ctrl.Log.WithName("test init"))).To(Succeed())
 	})
 
 	When("initialization is called", func() {
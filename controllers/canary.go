@@ -0,0 +1,127 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	// defaultCanarySuccessWindow matches the kubebuilder default of spec.canary.successWindow
+	defaultCanarySuccessWindow = 10 * time.Minute
+
+	eventReasonCanaryStarted = "CanaryRemediationStarted"
+	eventReasonCanaryPassed  = "CanaryRemediationPassed"
+)
+
+// applyCanary implements the canary state machine of spec.canary: with new or changed
+// unhealthy criteria a single node is remediated first, and the remaining candidates are
+// held back until that node recovered and stayed healthy for the success window. It
+// returns the remediation candidates allowed in this reconcile.
+func (r *NodeHealthCheckReconciler) applyCanary(nhc *remediationv1alpha1.NodeHealthCheck, unhealthyNodes []v1.Node, candidates []v1.Node, result *ctrl.Result) []v1.Node {
+	if nhc.Spec.Canary == nil || !nhc.Spec.Canary.Enabled {
+		nhc.Status.Canary = nil
+		return candidates
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	hash := unhealthyCriteriaHash(nhc)
+	canary := nhc.Status.Canary
+	if canary == nil || canary.ObservedConditionsHash != hash {
+		log.Info("unhealthy criteria are new or changed, remediation starts with a canary node")
+		canary = &remediationv1alpha1.CanaryStatus{ObservedConditionsHash: hash}
+		nhc.Status.Canary = canary
+	}
+	if canary.Passed {
+		return candidates
+	}
+
+	if canary.Node == "" {
+		if len(candidates) == 0 {
+			return candidates
+		}
+		canary.Node = candidates[0].GetName()
+		log.Info("selected canary node", "Node name", canary.Node)
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonCanaryStarted,
+			"Remediating canary node %s first, holding back %d other unhealthy node(s)", canary.Node, len(candidates)-1)
+		return candidates[:1]
+	}
+
+	// a canary was chosen and didn't pass yet, no other node is remediated
+	for i := range unhealthyNodes {
+		if unhealthyNodes[i].GetName() != canary.Node {
+			continue
+		}
+		// the canary node is still unhealthy, keep remediating it alone
+		canary.HealthySince = nil
+		for j := range candidates {
+			if candidates[j].GetName() == canary.Node {
+				return candidates[j : j+1]
+			}
+		}
+		return nil
+	}
+
+	// the canary node is healthy again, it passes once it stayed healthy for the
+	// success window
+	successWindow := defaultCanarySuccessWindow
+	if nhc.Spec.Canary.SuccessWindow != nil {
+		successWindow = nhc.Spec.Canary.SuccessWindow.Duration
+	}
+	if canary.HealthySince == nil {
+		now := metav1.Time{Time: r.now()}
+		canary.HealthySince = &now
+	}
+	if healthyFor := r.now().Sub(canary.HealthySince.Time); healthyFor < successWindow {
+		updateResultNextReconcile(result, successWindow-healthyFor)
+		return nil
+	}
+
+	canary.Passed = true
+	log.Info("canary node recovered and stayed healthy, enabling full remediation", "Node name", canary.Node)
+	r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonCanaryPassed,
+		"Canary node %s recovered, remediation of further nodes is allowed", canary.Node)
+	return candidates
+}
+
+// unhealthyCriteriaHash identifies the NHC's unhealthy criteria, so changes to them can
+// be detected across reconciles
+func unhealthyCriteriaHash(nhc *remediationv1alpha1.NodeHealthCheck) string {
+	criteria := struct {
+		Conditions []remediationv1alpha1.UnhealthyCondition `json:"conditions"`
+		Resources  []remediationv1alpha1.UnhealthyResource  `json:"resources"`
+		Events     []remediationv1alpha1.UnhealthyEvent     `json:"events"`
+		CEL        *string                                  `json:"cel"`
+	}{nhc.Spec.UnhealthyConditions, nhc.Spec.UnhealthyResources, nhc.Spec.UnhealthyEvents, nhc.Spec.UnhealthyCEL}
+	marshalled, err := json.Marshal(criteria)
+	if err != nil {
+		// not reachable with these types, but don't block remediation on it
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(marshalled))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/lease"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const eventReasonCapabilityMismatch = "RemediatorCapabilityMismatch"
+
+// checkRemediationCapabilities validates the capabilities declared for this NHC's
+// remediation chain: when every remediator in the chain declared its capabilities and
+// none of them reboots nodes or deletes machines, remediation can never free the
+// workloads of a dead node, which is worth a warning. Remediators which don't take
+// part in the capability convention are given the benefit of the doubt.
+func (r *NodeHealthCheckReconciler) checkRemediationCapabilities(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, resolver *templateResolver) {
+	if r.Capabilities == nil {
+		return
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	allDeclared := true
+	fences := false
+	for _, template := range resolver.Templates() {
+		caps, err := r.Capabilities.ForGroupKind(ctx, template.GroupVersionKind().GroupKind())
+		if err != nil {
+			log.Error(err, "failed to read remediator capabilities", "kind", template.GetKind())
+			return
+		}
+		if caps.RebootsNode == nil && caps.DeletesMachine == nil {
+			allDeclared = false
+			continue
+		}
+		if (caps.RebootsNode != nil && *caps.RebootsNode) || (caps.DeletesMachine != nil && *caps.DeletesMachine) {
+			fences = true
+		}
+	}
+	if allDeclared && !fences {
+		msg := "No remediator of this NodeHealthCheck declares to reboot nodes or to delete machines, remediation may never free the workloads of a failed node"
+		log.Info(msg)
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonCapabilityMismatch, msg)
+	}
+}
+
+// remediationLeaseDuration picks the node lease duration for the given remediation
+// template: when its remediator declared how long a remediation typically takes, the
+// lease covers that instead of relying on renewals only. The default duration is the
+// lower bound, and capability read errors fall back to it as well.
+func (r *NodeHealthCheckReconciler) remediationLeaseDuration(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, template *unstructured.Unstructured) time.Duration {
+	minDuration := nodeLeaseDuration
+	if nhc.Spec.LeasePolicy != nil && nhc.Spec.LeasePolicy.Duration != nil {
+		minDuration = nhc.Spec.LeasePolicy.Duration.Duration
+	}
+	if r.Capabilities == nil {
+		return minDuration
+	}
+	caps, err := r.Capabilities.ForGroupKind(ctx, template.GroupVersionKind().GroupKind())
+	if err != nil {
+		log := utils.GetLogWithNHC(r.Log, nhc)
+		log.Error(err, "failed to read remediator capabilities, using the default lease duration", "kind", template.GetKind())
+		return minDuration
+	}
+	if caps.TypicalDuration != nil && *caps.TypicalDuration > minDuration {
+		return *caps.TypicalDuration
+	}
+	return minDuration
+}
+
+// leasePolicy translates spec.leasePolicy into the lease manager's Policy
+func leasePolicy(nhc *remediationv1alpha1.NodeHealthCheck) lease.Policy {
+	policy := lease.Policy{}
+	if nhc.Spec.LeasePolicy == nil {
+		return policy
+	}
+	if nhc.Spec.LeasePolicy.Buffer != nil {
+		policy.Buffer = &nhc.Spec.LeasePolicy.Buffer.Duration
+	}
+	policy.MaxExtensions = nhc.Spec.LeasePolicy.MaxExtensions
+	return policy
+}
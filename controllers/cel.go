@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	celgo "github.com/google/cel-go/cel"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// maxCachedCELPrograms bounds the program cache, repeatedly edited expressions
+// must not grow it forever
+const maxCachedCELPrograms = 32
+
+// celEvaluator evaluates spec.unhealthyCEL expressions against nodes, caching the
+// compiled programs per expression. Safe for concurrent use.
+type celEvaluator struct {
+	mutex    sync.Mutex
+	programs map[string]celgo.Program
+}
+
+// evaluate runs the expression against the node, which is bound as "node" in its
+// unstructured JSON form
+func (e *celEvaluator) evaluate(expression string, node *v1.Node) (bool, error) {
+	program, err := e.program(expression)
+	if err != nil {
+		return false, err
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(node)
+	if err != nil {
+		return false, err
+	}
+	value, _, err := program.Eval(map[string]interface{}{"node": obj})
+	if err != nil {
+		return false, err
+	}
+	unhealthy, isBool := value.Value().(bool)
+	if !isBool {
+		return false, fmt.Errorf("the unhealthyCEL expression must evaluate to a boolean, got %T", value.Value())
+	}
+	return unhealthy, nil
+}
+
+func (e *celEvaluator) program(expression string) (celgo.Program, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if program, compiled := e.programs[expression]; compiled {
+		return program, nil
+	}
+	program, err := remediationv1alpha1.CompileUnhealthyCEL(expression)
+	if err != nil {
+		return nil, err
+	}
+	if e.programs == nil || len(e.programs) >= maxCachedCELPrograms {
+		e.programs = map[string]celgo.Program{}
+	}
+	e.programs[expression] = program
+	return program, nil
+}
+
+// hasUnhealthyCEL applies spec.unhealthyCEL: it evaluates the expression against the
+// node. Evaluation errors count as healthy, a broken expression which slipped past
+// the webhook must not fence the whole cluster.
+func (r *NodeHealthCheckReconciler) hasUnhealthyCEL(nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node) bool {
+	if nhc.Spec.UnhealthyCEL == nil || *nhc.Spec.UnhealthyCEL == "" {
+		return false
+	}
+	log := utils.GetLogWithNHC(r.Log, nhc)
+	unhealthy, err := r.celEvaluator.evaluate(*nhc.Spec.UnhealthyCEL, node)
+	if err != nil {
+		log.Error(err, "failed to evaluate the unhealthyCEL expression, ignoring it", "Node name", node.GetName())
+		return false
+	}
+	if unhealthy {
+		log.Info("node is unhealthy, the unhealthyCEL expression matched", "Node name", node.GetName())
+	}
+	return unhealthy
+}
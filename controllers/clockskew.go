@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	// clockSkewThreshold is how far the local clock may drift from the API server's
+	// clock before the ClockSkewed condition is set. The lease clock compensates the
+	// offset, but timeout math based on local timestamps silently breaks with it.
+	clockSkewThreshold = 30 * time.Second
+
+	eventReasonClockSkew         = "ClockSkewDetected"
+	eventReasonClockSkewResolved = "ClockSkewResolved"
+)
+
+// checkClockSkew surfaces a too large offset between the local clock and the API
+// server's clock as a ClockSkewed condition. It is a dedicated condition type, not a
+// Degraded reason, so it doesn't fight with other degradation causes over the one
+// Degraded condition. The offset is measured against API server issued timestamps by
+// the lease clock, which refreshes it periodically; the first reconcile after startup
+// covers the startup check. Remediation continues, the lease math compensates the
+// offset, but operators should fix the drift.
+func (r *NodeHealthCheckReconciler) checkClockSkew(nhc *remediationv1alpha1.NodeHealthCheck) {
+	if r.ClockSkewChecker == nil {
+		return
+	}
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	skew := r.ClockSkewChecker.Skew()
+	absolute := skew
+	if absolute < 0 {
+		absolute = -absolute
+	}
+	if absolute <= clockSkewThreshold {
+		if utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeClockSkewed, remediationv1alpha1.ConditionReasonClockSkewDetected) {
+			log.Info("the clock skew to the API server is back below the threshold")
+			setStatusCondition(nhc, metav1.Condition{
+				Type:    remediationv1alpha1.ConditionTypeClockSkewed,
+				Status:  metav1.ConditionFalse,
+				Reason:  remediationv1alpha1.ConditionReasonClockSkewTolerable,
+				Message: "The clock skew to the API server is back below the threshold",
+			})
+			r.Recorder.Event(nhc, eventTypeNormal, eventReasonClockSkewResolved,
+				"The clock skew to the API server is back below the threshold")
+		}
+		return
+	}
+
+	msg := fmt.Sprintf("The local clock is %s off from the API server's clock, timeout and lease math may be unreliable, check the node's time synchronization", skew)
+	if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeClockSkewed, remediationv1alpha1.ConditionReasonClockSkewDetected) {
+		log.Info("detected a too large clock skew to the API server", "skew", skew)
+		setStatusCondition(nhc, metav1.Condition{
+			Type:    remediationv1alpha1.ConditionTypeClockSkewed,
+			Status:  metav1.ConditionTrue,
+			Reason:  remediationv1alpha1.ConditionReasonClockSkewDetected,
+			Message: msg,
+		})
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonClockSkew, msg)
+	}
+}
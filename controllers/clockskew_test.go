@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// fakeSkew is a SkewChecker returning a fixed offset
+type fakeSkew time.Duration
+
+func (f fakeSkew) Skew() time.Duration {
+	return time.Duration(f)
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	newReconciler := func(skew time.Duration) *NodeHealthCheckReconciler {
+		return &NodeHealthCheckReconciler{
+			Log:              ctrl.Log.WithName("test"),
+			Recorder:         record.NewFakeRecorder(10),
+			ClockSkewChecker: fakeSkew(skew),
+		}
+	}
+	isSkewed := func(nhc *v1alpha1.NodeHealthCheck) bool {
+		return utils.IsConditionTrue(nhc.Status.Conditions, v1alpha1.ConditionTypeClockSkewed, v1alpha1.ConditionReasonClockSkewDetected)
+	}
+
+	t.Run("a small skew stays quiet", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		newReconciler(time.Second).checkClockSkew(nhc)
+		if isSkewed(nhc) || len(nhc.Status.Conditions) != 0 {
+			t.Errorf("expected no condition for a small skew, got %v", nhc.Status.Conditions)
+		}
+	})
+
+	t.Run("a large skew sets the ClockSkewed condition", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		newReconciler(clockSkewThreshold + time.Second).checkClockSkew(nhc)
+		if !isSkewed(nhc) {
+			t.Errorf("expected the ClockSkewed condition, got %v", nhc.Status.Conditions)
+		}
+	})
+
+	t.Run("a large negative skew sets the ClockSkewed condition too", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		newReconciler(-clockSkewThreshold - time.Second).checkClockSkew(nhc)
+		if !isSkewed(nhc) {
+			t.Errorf("expected the ClockSkewed condition, got %v", nhc.Status.Conditions)
+		}
+	})
+
+	t.Run("a recovered skew lifts the condition", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		newReconciler(clockSkewThreshold + time.Second).checkClockSkew(nhc)
+		newReconciler(time.Second).checkClockSkew(nhc)
+		if isSkewed(nhc) {
+			t.Errorf("expected the condition to be lifted, got %v", nhc.Status.Conditions)
+		}
+	})
+
+	t.Run("an active Degraded condition is left alone", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		setStatusCondition(nhc, metav1.Condition{
+			Type:    v1alpha1.ConditionTypeDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  v1alpha1.ConditionReasonDegradedNamespaceMissing,
+			Message: "namespace missing",
+		})
+		newReconciler(clockSkewThreshold + time.Second).checkClockSkew(nhc)
+		if !isSkewed(nhc) {
+			t.Errorf("expected the ClockSkewed condition, got %v", nhc.Status.Conditions)
+		}
+		if !utils.IsConditionTrue(nhc.Status.Conditions, v1alpha1.ConditionTypeDegraded, v1alpha1.ConditionReasonDegradedNamespaceMissing) {
+			t.Errorf("expected the Degraded condition to stay untouched, got %v", nhc.Status.Conditions)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+
+	clusterversion "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	gerrors "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+var noClusterIDErr = errors.New(
+	"the cluster doesn't expose a cluster ID." +
+		" Currently only OpenShift/OKD is supported, configure the ID explicitly instead")
+
+// GetClusterID returns a stable identifier of this cluster, read from the OpenShift
+// ClusterVersion. Non OpenShift clusters don't carry one and get an error.
+func GetClusterID(ctx context.Context, config *rest.Config) (string, error) {
+	openshift, err := utils.IsOnOpenshift(config)
+	if err != nil {
+		return "", err
+	}
+	if !openshift {
+		return "", noClusterIDErr
+	}
+	configV1Client, err := clusterversion.NewForConfig(config)
+	if err != nil {
+		return "", gerrors.Wrap(err, "failed to create a client to Openshift ClusterVersion objects")
+	}
+	version, err := configV1Client.ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return "", gerrors.Wrap(err, "failed to read the ClusterVersion")
+	}
+	return string(version.Spec.ClusterID), nil
+}
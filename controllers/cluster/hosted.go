@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecluster "sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+// hostedKubeconfigSecretEntry is the secret entry holding the hosted cluster kubeconfig,
+// it matches the entry name used by Hypershift's generated kubeconfig secrets
+const hostedKubeconfigSecretEntry = "kubeconfig"
+
+// NewHostedCluster returns a runtime cluster for the hosted cluster of a Hypershift
+// hosted control plane. The hosted cluster's kubeconfig is read from the given secret
+// in the given namespace of the management cluster. On Hypershift Nodes live in the
+// hosted cluster while Machines and this operator live in the management cluster, so
+// node reads and writes need their own client and cache.
+func NewHostedCluster(managementConfig *rest.Config, namespace, secretName string, scheme *pkgruntime.Scheme) (runtimecluster.Cluster, error) {
+	// the manager's cached client isn't usable before the manager started, read the
+	// secret with a dedicated client
+	reader, err := client.New(managementConfig, client.Options{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a client for reading the hosted cluster kubeconfig secret")
+	}
+	secret := &v1.Secret{}
+	if err := reader.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: secretName}, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the hosted cluster kubeconfig secret %q/%q", namespace, secretName)
+	}
+	kubeconfig, exists := secret.Data[hostedKubeconfigSecretEntry]
+	if !exists {
+		return nil, errors.Errorf("the hosted cluster kubeconfig secret %q/%q has no %q entry", namespace, secretName, hostedKubeconfigSecretEntry)
+	}
+	hostedConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the hosted cluster kubeconfig")
+	}
+	return runtimecluster.New(hostedConfig, func(options *runtimecluster.Options) {
+		options.Scheme = scheme
+	})
+}
@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	v1 "github.com/openshift/api/config/v1"
+
+	configv1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	gerrors "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// PlatformHealthChecker checks whether the platform itself is unhealthy. When core
+// cluster operators like the network operator are degraded, nodes may look unhealthy
+// without anything being wrong with them, and fencing would make the outage worse.
+type PlatformHealthChecker interface {
+	// DegradedOperators returns the names of the currently degraded cluster
+	// operators. An error should be thrown if the platform health can't be
+	// determined reliably.
+	DegradedOperators() ([]string, error)
+}
+
+type openshiftPlatformHealthChecker struct {
+	clusterOperatorsClient configv1.ClusterOperatorInterface
+	logger                 logr.Logger
+}
+
+// force implementation of interface
+var _ PlatformHealthChecker = openshiftPlatformHealthChecker{}
+
+func (o openshiftPlatformHealthChecker) DegradedOperators() ([]string, error) {
+	operators, err := o.clusterOperatorsClient.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, gerrors.Wrap(err, "failed to check for degraded Openshift cluster operators")
+	}
+	var degraded []string
+	for _, operator := range operators.Items {
+		for _, condition := range operator.Status.Conditions {
+			if condition.Type == v1.OperatorDegraded && condition.Status == v1.ConditionTrue {
+				o.logger.V(5).Info("cluster operator is degraded", "name", operator.Name, "condition", condition)
+				degraded = append(degraded, operator.Name)
+				break
+			}
+		}
+	}
+	return degraded, nil
+}
+
+type noopPlatformHealthChecker struct {
+}
+
+// force implementation of interface
+var _ PlatformHealthChecker = noopPlatformHealthChecker{}
+
+func (n noopPlatformHealthChecker) DegradedOperators() ([]string, error) {
+	return nil, nil
+}
+
+// NewPlatformHealthChecker will return some implementation of a platform health
+// checker, on clusters without cluster operators the platform counts as healthy.
+func NewPlatformHealthChecker(mgr manager.Manager) (PlatformHealthChecker, error) {
+	openshift, err := utils.IsOnOpenshift(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	if !openshift {
+		return noopPlatformHealthChecker{}, nil
+	}
+	configV1Client, err := configv1.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, gerrors.Wrap(err, "failed to create a client to Openshift ClusterOperator objects")
+	}
+	return openshiftPlatformHealthChecker{
+		clusterOperatorsClient: configV1Client.ClusterOperators(),
+		logger:                 mgr.GetLogger().WithName("OpenshiftPlatformHealthChecker"),
+	}, nil
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	// conditionHistoryLimit caps the ring buffer of recorded transitions per node,
+	// the oldest entries are dropped first
+	conditionHistoryLimit = 20
+
+	// flapTolerance is the longest healthy interlude which doesn't restart the
+	// unhealthy duration of a flapping condition
+	flapTolerance = time.Minute
+
+	// conditionHistoryRetention keeps a node's state CR around this long after its
+	// last recorded transition, so the history survives the healthy interludes of
+	// flapping nodes and controller restarts
+	conditionHistoryRetention = 30 * time.Minute
+)
+
+// conditionHistories holds the per node condition transition ring buffers of one
+// reconcile, indexed by node name
+type conditionHistories map[string][]remediationv1alpha1.ConditionTransition
+
+// updateConditionHistories maintains the per node condition transition ring buffers,
+// persisted in the NodeRemediationState CRs. Recording starts once a condition matches
+// an unhealthy status test, from then on every transition of a tested condition type is
+// kept, including the healthy ones. The duration evaluation derives the effective
+// transition time from the buffer, so flaps and API server side lastTransitionTime
+// resets don't restart the unhealthy clock over and over.
+func (r *NodeHealthCheckReconciler) updateConditionHistories(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node) (conditionHistories, error) {
+	namespace, err := utils.GetDeploymentNamespace()
+	if err != nil {
+		// without a known namespace nothing is persisted, evaluation falls back to
+		// the conditions' own transition times
+		return nil, nil
+	}
+
+	existing := &remediationv1alpha1.NodeRemediationStateList{}
+	if err := r.Client.List(ctx, existing, client.InNamespace(namespace),
+		client.MatchingLabels{nodeStateNHCLabel: nhc.GetName()}); err != nil {
+		return nil, errors.Wrap(err, "failed to list NodeRemediationStates")
+	}
+	existingByNode := map[string]*remediationv1alpha1.NodeRemediationState{}
+	for i := range existing.Items {
+		existingByNode[existing.Items[i].Spec.NodeName] = &existing.Items[i]
+	}
+
+	histories := conditionHistories{}
+	for i := range nodes {
+		node := &nodes[i]
+		state := existingByNode[node.GetName()]
+		var history []remediationv1alpha1.ConditionTransition
+		if state != nil {
+			history = state.Status.ConditionHistory
+		}
+		merged := mergeConditionHistory(history, nhc.Spec.UnhealthyConditions, node.Status.Conditions)
+		if len(merged) == 0 {
+			continue
+		}
+		histories[node.GetName()] = merged
+		if len(merged) == len(history) {
+			// nothing new to persist
+			continue
+		}
+		if state == nil {
+			// the node started matching an unhealthy status, its state CR is created
+			// right away so the history survives flaps back to healthy
+			state = &remediationv1alpha1.NodeRemediationState{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      node.GetName(),
+					Namespace: namespace,
+					Labels:    map[string]string{nodeStateNHCLabel: nhc.GetName()},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(nhc, remediationv1alpha1.GroupVersion.WithKind("NodeHealthCheck")),
+					},
+				},
+				Spec: remediationv1alpha1.NodeRemediationStateSpec{NodeName: node.GetName()},
+			}
+			if err := r.Client.Create(ctx, state); err != nil {
+				return nil, errors.Wrapf(err, "failed to create the NodeRemediationState of node %s", node.GetName())
+			}
+		}
+		state.Status.ConditionHistory = merged
+		if err := r.Client.Status().Update(ctx, state); err != nil {
+			return nil, errors.Wrapf(err, "failed to record the condition history of node %s", node.GetName())
+		}
+	}
+	return histories, nil
+}
+
+// mergeConditionHistory appends the transitions of the tested condition types which
+// happened since the last recorded entry, and trims the buffer to its cap. For nodes
+// without any history yet, recording only starts with a condition matching an
+// unhealthy status test, healthy nodes don't grow a buffer.
+func mergeConditionHistory(history []remediationv1alpha1.ConditionTransition,
+	conditionTests []remediationv1alpha1.UnhealthyCondition, conditions []v1.NodeCondition) []remediationv1alpha1.ConditionTransition {
+	testedTypes := map[v1.NodeConditionType]bool{}
+	matchingStatus := map[v1.NodeConditionType]v1.ConditionStatus{}
+	for _, c := range conditionTests {
+		testedTypes[c.Type] = true
+		matchingStatus[c.Type] = c.Status
+	}
+	newestByType := map[v1.NodeConditionType]*remediationv1alpha1.ConditionTransition{}
+	for i := range history {
+		newestByType[history[i].Type] = &history[i]
+	}
+
+	merged := history
+	for _, condition := range conditions {
+		if !testedTypes[condition.Type] {
+			continue
+		}
+		newest := newestByType[condition.Type]
+		if newest == nil {
+			if matchingStatus[condition.Type] != condition.Status {
+				continue
+			}
+		} else if newest.Status == condition.Status && newest.At.Equal(&condition.LastTransitionTime) {
+			continue
+		}
+		merged = append(merged, remediationv1alpha1.ConditionTransition{
+			Type:   condition.Type,
+			Status: condition.Status,
+			At:     condition.LastTransitionTime,
+		})
+	}
+	if len(merged) > conditionHistoryLimit {
+		merged = merged[len(merged)-conditionHistoryLimit:]
+	}
+	return merged
+}
+
+// adjustedConditions returns the node's conditions with their transition times
+// replaced by the effective times derived from the recorded history
+func (h conditionHistories) adjustedConditions(node *v1.Node) []v1.NodeCondition {
+	history := h[node.GetName()]
+	if len(history) == 0 {
+		return node.Status.Conditions
+	}
+	conditions := make([]v1.NodeCondition, len(node.Status.Conditions))
+	copy(conditions, node.Status.Conditions)
+	for i := range conditions {
+		conditions[i].LastTransitionTime = metav1.Time{Time: effectiveTransitionTime(history, &conditions[i])}
+	}
+	return conditions
+}
+
+// effectiveTransitionTime returns when the condition effectively entered its current
+// status: recorded entries with an unchanged status collapse into one run, so server
+// side lastTransitionTime resets are ignored, and interludes of other statuses up to
+// the flapTolerance don't restart the clock either
+func effectiveTransitionTime(history []remediationv1alpha1.ConditionTransition, condition *v1.NodeCondition) time.Time {
+	type run struct {
+		status v1.ConditionStatus
+		start  time.Time
+	}
+	var runs []run
+	for _, entry := range history {
+		if entry.Type != condition.Type {
+			continue
+		}
+		if len(runs) > 0 && runs[len(runs)-1].status == entry.Status {
+			// the status didn't change, the later transition time is a reset
+			continue
+		}
+		runs = append(runs, run{status: entry.Status, start: entry.At.Time})
+	}
+	if len(runs) == 0 || runs[len(runs)-1].status != condition.Status {
+		// no usable history, e.g. it lags behind the condition
+		return condition.LastTransitionTime.Time
+	}
+
+	effective := runs[len(runs)-1].start
+	for i := len(runs) - 2; i >= 0; {
+		// find the preceding run with the current status, the runs in between form
+		// the interlude
+		j := i
+		for j >= 0 && runs[j].status != condition.Status {
+			j--
+		}
+		if j < 0 {
+			break
+		}
+		if effective.Sub(runs[j+1].start) > flapTolerance {
+			break
+		}
+		effective = runs[j].start
+		i = j - 1
+	}
+	if condition.LastTransitionTime.Time.Before(effective) {
+		return condition.LastTransitionTime.Time
+	}
+	return effective
+}
+
+// hasFreshConditionHistory reports whether the state's ring buffer has an entry
+// within the retention, such states survive the node turning healthy
+func hasFreshConditionHistory(state *remediationv1alpha1.NodeRemediationState, now time.Time) bool {
+	for _, entry := range state.Status.ConditionHistory {
+		if now.Sub(entry.At.Time) < conditionHistoryRetention {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+func TestEffectiveTransitionTime(t *testing.T) {
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	at := func(offset time.Duration) metav1.Time {
+		return metav1.Time{Time: base.Add(offset)}
+	}
+	entry := func(status v1.ConditionStatus, offset time.Duration) v1alpha1.ConditionTransition {
+		return v1alpha1.ConditionTransition{Type: v1.NodeReady, Status: status, At: at(offset)}
+	}
+	ready := func(status v1.ConditionStatus, offset time.Duration) *v1.NodeCondition {
+		return &v1.NodeCondition{Type: v1.NodeReady, Status: status, LastTransitionTime: at(offset)}
+	}
+
+	cases := []struct {
+		name      string
+		history   []v1alpha1.ConditionTransition
+		condition *v1.NodeCondition
+		expected  time.Time
+	}{
+		{
+			name:      "no history falls back to the condition",
+			condition: ready(v1.ConditionFalse, 10*time.Minute),
+			expected:  base.Add(10 * time.Minute),
+		},
+		{
+			name: "server side reset keeps the original transition",
+			history: []v1alpha1.ConditionTransition{
+				entry(v1.ConditionFalse, 0),
+				entry(v1.ConditionFalse, 20*time.Minute),
+			},
+			condition: ready(v1.ConditionFalse, 20*time.Minute),
+			expected:  base,
+		},
+		{
+			name: "brief flap back to healthy doesn't restart the clock",
+			history: []v1alpha1.ConditionTransition{
+				entry(v1.ConditionFalse, 0),
+				entry(v1.ConditionTrue, 10*time.Minute),
+				entry(v1.ConditionFalse, 10*time.Minute+30*time.Second),
+			},
+			condition: ready(v1.ConditionFalse, 10*time.Minute+30*time.Second),
+			expected:  base,
+		},
+		{
+			name: "a long recovery does restart the clock",
+			history: []v1alpha1.ConditionTransition{
+				entry(v1.ConditionFalse, 0),
+				entry(v1.ConditionTrue, 10*time.Minute),
+				entry(v1.ConditionFalse, 20*time.Minute),
+			},
+			condition: ready(v1.ConditionFalse, 20*time.Minute),
+			expected:  base.Add(20 * time.Minute),
+		},
+		{
+			name: "history lagging behind the condition is ignored",
+			history: []v1alpha1.ConditionTransition{
+				entry(v1.ConditionTrue, 0),
+			},
+			condition: ready(v1.ConditionFalse, 5*time.Minute),
+			expected:  base.Add(5 * time.Minute),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			effective := effectiveTransitionTime(c.history, c.condition)
+			if !effective.Equal(c.expected) {
+				t.Errorf("expected the effective transition time %v, got %v", c.expected, effective)
+			}
+		})
+	}
+}
+
+func TestMergeConditionHistory(t *testing.T) {
+	tests := []v1alpha1.UnhealthyCondition{
+		{Type: v1.NodeReady, Status: v1.ConditionFalse, Duration: metav1.Duration{Duration: 5 * time.Minute}},
+	}
+	now := metav1.Now()
+	ready := func(status v1.ConditionStatus) []v1.NodeCondition {
+		return []v1.NodeCondition{{Type: v1.NodeReady, Status: status, LastTransitionTime: now}}
+	}
+
+	t.Run("healthy nodes don't grow a buffer", func(t *testing.T) {
+		if merged := mergeConditionHistory(nil, tests, ready(v1.ConditionTrue)); len(merged) != 0 {
+			t.Errorf("expected no history, got %v", merged)
+		}
+	})
+
+	t.Run("recording starts with a matching status", func(t *testing.T) {
+		merged := mergeConditionHistory(nil, tests, ready(v1.ConditionFalse))
+		if len(merged) != 1 || merged[0].Status != v1.ConditionFalse {
+			t.Fatalf("expected the matching condition to be recorded, got %v", merged)
+		}
+		// a repeated merge without any change records nothing new
+		if merged = mergeConditionHistory(merged, tests, ready(v1.ConditionFalse)); len(merged) != 1 {
+			t.Errorf("expected no new entry without a transition, got %v", merged)
+		}
+		// once recording started, healthy transitions are kept too
+		if merged = mergeConditionHistory(merged, tests, ready(v1.ConditionTrue)); len(merged) != 2 {
+			t.Errorf("expected the healthy transition to be recorded, got %v", merged)
+		}
+	})
+
+	t.Run("the buffer is capped", func(t *testing.T) {
+		var history []v1alpha1.ConditionTransition
+		status := v1.ConditionFalse
+		for i := 0; i < 2*conditionHistoryLimit; i++ {
+			conditions := []v1.NodeCondition{{
+				Type:               v1.NodeReady,
+				Status:             status,
+				LastTransitionTime: metav1.Time{Time: now.Add(time.Duration(i) * time.Minute)},
+			}}
+			history = mergeConditionHistory(history, tests, conditions)
+			if status == v1.ConditionFalse {
+				status = v1.ConditionTrue
+			} else {
+				status = v1.ConditionFalse
+			}
+		}
+		if len(history) != conditionHistoryLimit {
+			t.Errorf("expected the history to be capped at %d entries, got %d", conditionHistoryLimit, len(history))
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package consistency
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/lease"
+)
+
+// CheckAndCleanup validates status.inFlightRemediations of all NHCs against the
+// actual nodes and remediation CRs in the cluster, and removes stale entries and
+// their node leases. After a restore from an etcd backup the status can reference
+// remediations which don't exist anymore, which would cause spurious fencing.
+func CheckAndCleanup(mgr ctrl.Manager, leaseManager lease.Manager, log logr.Logger) error {
+
+	ctx := context.Background()
+
+	nhcList := remediationv1alpha1.NodeHealthCheckList{}
+	if err := mgr.GetAPIReader().List(ctx, &nhcList); err != nil {
+		return errors.Wrap(err, "failed to list NHC objects")
+	}
+
+	for i := range nhcList.Items {
+		nhc := &nhcList.Items[i]
+		if len(nhc.Status.InFlightRemediations) == 0 {
+			continue
+		}
+
+		remediatedNodes, err := getRemediatedNodeNames(ctx, mgr.GetAPIReader(), nhc)
+		if err != nil {
+			return err
+		}
+
+		nhcOrig := nhc.DeepCopy()
+		for nodeName := range nhc.Status.InFlightRemediations {
+			stale, err := isStaleRemediation(ctx, mgr.GetAPIReader(), nodeName, remediatedNodes)
+			if err != nil {
+				return err
+			}
+			if !stale {
+				continue
+			}
+			log.Info("cleaning up stale in-flight remediation, it has no backing remediation CR or node",
+				"NHC name", nhc.GetName(), "Node name", nodeName)
+			delete(nhc.Status.InFlightRemediations, nodeName)
+			delete(nhc.Status.UnhealthyNodeObservations, nodeName)
+			if err := leaseManager.InvalidateNodeLease(ctx, nodeName); err != nil {
+				return errors.Wrapf(err, "failed to invalidate the lease of node %s", nodeName)
+			}
+		}
+
+		if len(nhc.Status.InFlightRemediations) != len(nhcOrig.Status.InFlightRemediations) {
+			if err := mgr.GetClient().Status().Patch(ctx, nhc, client.MergeFrom(nhcOrig)); err != nil {
+				return errors.Wrapf(err, "failed to patch status of NHC %s", nhc.GetName())
+			}
+		}
+	}
+
+	return nil
+}
+
+// getRemediatedNodeNames returns the names of the nodes which have a remediation CR
+// owned by the given NHC, for any of its configured remediation templates
+func getRemediatedNodeNames(ctx context.Context, reader client.Reader, nhc *remediationv1alpha1.NodeHealthCheck) (map[string]struct{}, error) {
+	templateRefs := make([]*v1.ObjectReference, 0, 1+len(nhc.Spec.RemediationTemplates))
+	if nhc.Spec.RemediationTemplate != nil {
+		templateRefs = append(templateRefs, nhc.Spec.RemediationTemplate)
+	}
+	for i := range nhc.Spec.RemediationTemplates {
+		templateRefs = append(templateRefs, nhc.Spec.RemediationTemplates[i].RemediationTemplate)
+	}
+	if nhc.Spec.FallbackRemediationTemplate != nil {
+		templateRefs = append(templateRefs, nhc.Spec.FallbackRemediationTemplate)
+	}
+
+	remediatedNodes := map[string]struct{}{}
+	for _, ref := range templateRefs {
+		gvk, err := remediationv1alpha1.RemediationGVK(ref)
+		if err != nil {
+			// the webhook rejects such templates, restored objects might still have them
+			continue
+		}
+		crList := &unstructured.UnstructuredList{}
+		crList.SetGroupVersionKind(gvk)
+		if err := reader.List(ctx, crList); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				// the remediator might not be deployed anymore after a restore
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to list remediation CRs of kind %s", crList.GetKind())
+		}
+		for _, cr := range crList.Items {
+			if isOwnedBy(&cr, nhc) {
+				remediatedNodes[cr.GetName()] = struct{}{}
+			}
+		}
+	}
+	return remediatedNodes, nil
+}
+
+// isStaleRemediation reports whether an in-flight remediation entry has neither
+// a backing remediation CR nor an existing node anymore
+func isStaleRemediation(ctx context.Context, reader client.Reader, nodeName string, remediatedNodes map[string]struct{}) (bool, error) {
+	if _, remediated := remediatedNodes[nodeName]; remediated {
+		return false, nil
+	}
+	node := &v1.Node{}
+	err := reader.Get(ctx, client.ObjectKey{Name: nodeName}, node)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to get node %s", nodeName)
+	}
+	// the node exists but its remediation CR is gone, the entry is stale;
+	// the reconciler will start remediation again in case the node is still unhealthy
+	return true, nil
+}
+
+func isOwnedBy(cr *unstructured.Unstructured, nhc *remediationv1alpha1.NodeHealthCheck) bool {
+	for _, owner := range cr.GetOwnerReferences() {
+		if owner.Kind == "NodeHealthCheck" && owner.Name == nhc.GetName() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+const (
+	// nhcFinalizer enforces the spec.deletionPolicy before an NHC with in-flight
+	// remediations is removed, see handleDeletion
+	nhcFinalizer = "remediation.medik8s.io/nhc-finalizer"
+
+	eventReasonDeletionBlocked = "DeletionBlocked"
+	eventReasonOrphaned        = "RemediationOrphaned"
+
+	// deletionRequeueInterval is how often a blocked deletion re-checks its
+	// in-flight remediations, in addition to the watch driven reconciles
+	deletionRequeueInterval = time.Minute
+)
+
+// ensureFinalizer adds the deletion finalizer, so an NHC can't disappear while
+// remediations are in flight without its spec.deletionPolicy being applied
+func (r *NodeHealthCheckReconciler) ensureFinalizer(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	if controllerutil.ContainsFinalizer(nhc, nhcFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(nhc, nhcFinalizer)
+	if err := r.Client.Update(ctx, nhc); err != nil {
+		return errors.Wrap(err, "failed to add the deletion finalizer")
+	}
+	return nil
+}
+
+// handleDeletion applies the spec.deletionPolicy of a deleted NHC and removes the
+// finalizer once the policy allows it. It returns true when reconciling must stop,
+// false when the normal loop has to keep running to finish in-flight remediations
+// (WaitForCompletion), a crash between any two steps is safe to repeat.
+func (r *NodeHealthCheckReconciler) handleDeletion(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, result *ctrl.Result) (bool, error) {
+	if !controllerutil.ContainsFinalizer(nhc, nhcFinalizer) {
+		// nothing to enforce, e.g. an NHC created before the finalizer existed
+		return true, nil
+	}
+	log := utils.GetLogWithNHC(r.Log, nhc)
+	switch nhc.Spec.DeletionPolicy {
+	case remediationv1alpha1.DeletionPolicyOrphan:
+		if err := r.orphanRemediationCRs(ctx, nhc); err != nil {
+			return true, err
+		}
+	case remediationv1alpha1.DeletionPolicyWaitForCompletion:
+		if len(nhc.Status.InFlightRemediations) > 0 {
+			log.Info("blocking NHC deletion until its in-flight remediations completed",
+				"inFlightRemediations", len(nhc.Status.InFlightRemediations))
+			r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonDeletionBlocked,
+				"Deletion waits for %d in-flight remediation(s) to complete", len(nhc.Status.InFlightRemediations))
+			updateResultNextReconcile(result, deletionRequeueInterval)
+			return false, nil
+		}
+	default:
+		// Abort: clean up like a deleted node with the AbortAndCleanup policy
+		if err := r.abortRemediations(ctx, nhc); err != nil {
+			return true, err
+		}
+	}
+	log.Info("allowing NHC deletion", "deletionPolicy", nhc.Spec.DeletionPolicy)
+	controllerutil.RemoveFinalizer(nhc, nhcFinalizer)
+	if err := r.Client.Update(ctx, nhc); err != nil {
+		return true, errors.Wrap(err, "failed to remove the deletion finalizer")
+	}
+	// drop the NHC's contribution from the remediation gauges, including the
+	// cluster wide capacity signal
+	metrics.ClearNodeHealthCheckOngoingRemediations(nhc.GetName())
+	return true, nil
+}
+
+// abortRemediations deletes the in-flight remediation CRs and invalidates their
+// node leases, so remediators stop their work instead of fencing unmanaged
+func (r *NodeHealthCheckReconciler) abortRemediations(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	for nodeName := range nhc.Status.InFlightRemediations {
+		if err := r.deleteRemediationCRs(ctx, nhc, nodeName); err != nil {
+			return err
+		}
+		if err := r.LeaseManager.InvalidateNodeLease(ctx, nodeName); err != nil {
+			return err
+		}
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonRemediationRemoved,
+			"Aborted remediation of node %s, the NodeHealthCheck is being deleted", nodeName)
+	}
+	return nil
+}
+
+// orphanRemediationCRs strips this NHC's owner reference from its in-flight
+// remediation CRs, so they survive the garbage collection after the NHC is gone
+func (r *NodeHealthCheckReconciler) orphanRemediationCRs(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	templateRefs := []*v1.ObjectReference{nhc.Spec.RemediationTemplate, nhc.Spec.FallbackRemediationTemplate}
+	for i := range nhc.Spec.RemediationTemplates {
+		templateRefs = append(templateRefs, nhc.Spec.RemediationTemplates[i].RemediationTemplate)
+	}
+	for _, ref := range nhc.Spec.OSRemediationTemplates {
+		templateRefs = append(templateRefs, ref)
+	}
+	for nodeName := range nhc.Status.InFlightRemediations {
+		for _, ref := range templateRefs {
+			if ref == nil {
+				continue
+			}
+			gvk, err := remediationv1alpha1.RemediationGVK(ref)
+			if err != nil {
+				// the webhook rejects such templates, old objects might still have them
+				utils.GetLogWithNHC(r.Log, nhc).Error(err, "skipping orphaning for a template not following the kind convention")
+				continue
+			}
+			cr := &unstructured.Unstructured{}
+			cr.SetGroupVersionKind(gvk)
+			cr.SetName(nodeName)
+			cr.SetNamespace(ref.Namespace)
+			if err := r.Client.Get(ctx, client.ObjectKeyFromObject(cr), cr); err != nil {
+				if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+					continue
+				}
+				return errors.Wrapf(err, "failed to get the remediation CR of node %s for orphaning", nodeName)
+			}
+			if !isOwner(cr, nhc) {
+				continue
+			}
+			var owners []metav1.OwnerReference
+			for _, owner := range cr.GetOwnerReferences() {
+				if owner.Kind == nhc.Kind && owner.APIVersion == nhc.APIVersion && owner.Name == nhc.Name {
+					continue
+				}
+				owners = append(owners, owner)
+			}
+			cr.SetOwnerReferences(owners)
+			if err := r.Client.Update(ctx, cr); err != nil && !apierrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to orphan the remediation CR of node %s", nodeName)
+			}
+			r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonOrphaned,
+				"Orphaned the remediation CR of node %s, clean it up manually once the remediation completed", nodeName)
+		}
+	}
+	return nil
+}
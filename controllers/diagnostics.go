@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/api/machine/v1beta1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	diagnosticsConfigMapPrefix = "nhc-diagnostics-"
+
+	// kubeNodeLeaseNamespace is where the kubelet maintains its node lease
+	kubeNodeLeaseNamespace = "kube-node-lease"
+	// nodeEventsNamespace is where events of nodes are recorded
+	nodeEventsNamespace = "default"
+	// nodeMachineAnnotation is set on nodes by the machine API, pointing to the node's machine
+	nodeMachineAnnotation = "machine.openshift.io/machine"
+)
+
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;update
+
+// captureDiagnostics snapshots diagnostic data of the given node into a ConfigMap named
+// "nhc-diagnostics-<node name>" in the given namespace, for root cause analysis after
+// remediation destroyed the evidence on the node itself. It is best effort: failures
+// are logged but must not block remediation.
+func (r *NodeHealthCheckReconciler) captureDiagnostics(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck, namespace string) {
+	log := utils.GetLogWithNHC(r.Log, nhc).WithValues("Node name", node.GetName())
+
+	data := map[string]string{}
+	addEntry := func(key string, value interface{}) {
+		marshalled, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			log.Error(err, "failed to marshal diagnostic data", "key", key)
+			return
+		}
+		data[key] = string(marshalled)
+	}
+
+	addEntry("conditions", node.Status.Conditions)
+	addEntry("resources", map[string]v1.ResourceList{
+		"capacity":    node.Status.Capacity,
+		"allocatable": node.Status.Allocatable,
+	})
+
+	// node events and the kubelet lease live in the node's cluster, which differs
+	// from the operator's cluster on Hypershift
+	events := &v1.EventList{}
+	if err := r.nodeClient().List(ctx, events, client.InNamespace(nodeEventsNamespace)); err != nil {
+		log.Error(err, "failed to list events for the diagnostic bundle")
+	} else {
+		nodeEvents := make([]v1.Event, 0)
+		for _, event := range events.Items {
+			if event.InvolvedObject.Kind == "Node" && event.InvolvedObject.Name == node.GetName() {
+				nodeEvents = append(nodeEvents, event)
+			}
+		}
+		addEntry("events", nodeEvents)
+	}
+
+	kubeletLease := &coordv1.Lease{}
+	if err := r.nodeClient().Get(ctx, client.ObjectKey{Namespace: kubeNodeLeaseNamespace, Name: node.GetName()}, kubeletLease); err != nil {
+		log.Error(err, "failed to get the kubelet lease for the diagnostic bundle")
+	} else {
+		addEntry("kubelet-lease", kubeletLease.Spec)
+	}
+
+	if machineNsName, exists := node.GetAnnotations()[nodeMachineAnnotation]; exists {
+		if parts := strings.SplitN(machineNsName, "/", 2); len(parts) == 2 {
+			machine := &v1beta1.Machine{}
+			if err := r.Client.Get(ctx, client.ObjectKey{Namespace: parts[0], Name: parts[1]}, machine); err != nil {
+				log.Error(err, "failed to get the node's machine for the diagnostic bundle", "Machine", machineNsName)
+			} else {
+				addEntry("machine-status", machine.Status)
+			}
+		}
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      diagnosticsConfigMapPrefix + node.GetName(),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of": "node-healthcheck-controller",
+			},
+		},
+		Data: data,
+	}
+	if err := r.Client.Create(ctx, cm); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			log.Error(err, "failed to create the diagnostics ConfigMap")
+			return
+		}
+		// the node was remediated before, overwrite the old bundle
+		existing := &v1.ConfigMap{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(cm), existing); err != nil {
+			log.Error(err, "failed to get the existing diagnostics ConfigMap")
+			return
+		}
+		existing.Data = data
+		if err := r.Client.Update(ctx, existing); err != nil {
+			log.Error(err, "failed to update the diagnostics ConfigMap")
+			return
+		}
+	}
+	log.Info("captured diagnostic bundle", "ConfigMap name", cm.GetName(), "ns", namespace)
+}
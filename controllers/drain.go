@@ -0,0 +1,107 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;update;patch
+
+const eventReasonDrainStarted = "PreFenceDrainStarted"
+
+// applyPreFenceDrain implements spec.preFenceDrain: before the remediation CR of a node
+// is created, the node's endpoints are marked not ready for the configured duration, so
+// service traffic moves to other nodes instead of being blackholed while the node is
+// fenced. It returns whether fencing may proceed, and a requeue delay while it may not.
+func (r *NodeHealthCheckReconciler) applyPreFenceDrain(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) (*time.Duration, bool) {
+	drain := nhc.Spec.PreFenceDrain
+	if drain == nil || drain.Duration <= 0 {
+		return nil, true
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	started, exists := nhc.Status.PendingFenceDrains[node.GetName()]
+	if !exists {
+		started = metav1.Time{Time: r.now()}
+		if nhc.Status.PendingFenceDrains == nil {
+			nhc.Status.PendingFenceDrains = map[string]metav1.Time{}
+		}
+		nhc.Status.PendingFenceDrains[node.GetName()] = started
+		log.Info("starting pre-fence connection draining", "Node name", node.GetName(), "duration", drain.Duration)
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonDrainStarted,
+			"Draining connections of node %s for %s before fencing", node.GetName(), drain.Duration)
+	}
+
+	// re-applied on every reconcile, the endpoint slice controller may have flipped
+	// endpoints back to ready in the meantime
+	if err := r.drainNodeEndpoints(ctx, node); err != nil {
+		// draining is best effort, a node which can't be drained must still be fenced
+		log.Error(err, "failed to mark the node's endpoints not ready", "Node name", node.GetName())
+	}
+
+	if remaining := drain.Duration - r.now().Sub(started.Time); remaining > 0 {
+		return &remaining, false
+	}
+	delete(nhc.Status.PendingFenceDrains, node.GetName())
+	return nil, true
+}
+
+// drainNodeEndpoints marks all EndpointSlice endpoints located on the given node as not
+// ready, so services stop routing new connections to it
+func (r *NodeHealthCheckReconciler) drainNodeEndpoints(ctx context.Context, node *v1.Node) error {
+	slices := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, slices); err != nil {
+		return errors.Wrap(err, "failed to list EndpointSlices")
+	}
+	notReady := false
+	for i := range slices.Items {
+		slice := &slices.Items[i]
+		orig := slice.DeepCopy()
+		changed := false
+		for j := range slice.Endpoints {
+			endpoint := &slice.Endpoints[j]
+			if endpoint.NodeName == nil || *endpoint.NodeName != node.GetName() {
+				continue
+			}
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			endpoint.Conditions.Ready = &notReady
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := r.Client.Patch(ctx, slice, client.MergeFrom(orig)); err != nil {
+			return errors.Wrapf(err, "failed to patch EndpointSlice %s/%s", slice.GetNamespace(), slice.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package evaluate exposes the operator's health verdict for a node over HTTP,
+// so external automation (e.g. CI systems deciding where to schedule jobs) can
+// query NHC's view without reading NodeHealthCheck CRs themselves.
+package evaluate
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// secretTokenEntry is the entry of the token secret holding the bearer token
+const secretTokenEntry = "token"
+
+// tokenCacheTTL bounds how long a read token is reused before it is fetched again,
+// so authorization isn't an API server round trip per request while rotating the
+// secret still takes effect quickly
+const tokenCacheTTL = time.Minute
+
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+
+// Verdict is the response of the evaluate endpoint for a node
+type Verdict struct {
+	// Node is the evaluated node's name
+	Node string `json:"node"`
+	// Observed is true when at least one NodeHealthCheck selects the node
+	Observed bool `json:"observed"`
+	// Healthy is true when no NodeHealthCheck considers the node unhealthy.
+	// An unobserved node is reported healthy, check Observed to tell the cases apart.
+	Healthy bool `json:"healthy"`
+	// Remediating is true when at least one NodeHealthCheck is remediating the node
+	Remediating bool `json:"remediating"`
+	// NodeHealthChecks holds the per NodeHealthCheck verdicts the summary fields
+	// above are derived from, for the NHCs selecting the node
+	NodeHealthChecks []NodeHealthCheckVerdict `json:"nodeHealthChecks,omitempty"`
+}
+
+// NodeHealthCheckVerdict is the verdict of a single NodeHealthCheck for a node
+type NodeHealthCheckVerdict struct {
+	// Name is the NodeHealthCheck's name
+	Name string `json:"name"`
+	// Phase is the NodeHealthCheck's current phase
+	Phase remediationv1alpha1.NHCPhase `json:"phase,omitempty"`
+	// Unhealthy is true when the node currently matches the NodeHealthCheck's
+	// unhealthy conditions, or when it is being remediated
+	Unhealthy bool `json:"unhealthy"`
+	// Remediating is true when the NodeHealthCheck has an in-flight remediation
+	// or a pending fence drain for the node
+	Remediating bool `json:"remediating"`
+	// LastRemediationOutcome is the most recent remediation attempt for the node, if any
+	LastRemediationOutcome *remediationv1alpha1.RemediationOutcome `json:"lastRemediationOutcome,omitempty"`
+}
+
+// Handler serves GET /evaluate?node=<name>, returning a Verdict as JSON. Requests
+// must authenticate with the bearer token stored in the token secret's "token"
+// entry; the token is cached for a short TTL, so rotating the secret takes effect
+// without a restart after at most tokenCacheTTL.
+type Handler struct {
+	client       client.Client
+	secretReader client.Reader
+	namespace    string
+	tokenSecret  string
+	log          logr.Logger
+
+	tokenMutex  sync.Mutex
+	cachedToken []byte
+	tokenReadAt time.Time
+}
+
+// NewHandler returns a Handler verifying requests against the token stored in
+// the given secret in the deployment namespace. Node and NodeHealthCheck reads go
+// through the given client; the token secret is read through the given reader,
+// which must be uncached: the granted RBAC is a plain get, the informer a cached
+// secret read would start could never sync.
+func NewHandler(c client.Client, secretReader client.Reader, namespace string, tokenSecret string, log logr.Logger) *Handler {
+	return &Handler{
+		client:       c,
+		secretReader: secretReader,
+		namespace:    namespace,
+		tokenSecret:  tokenSecret,
+		log:          log,
+	}
+}
+
+var _ http.Handler = &Handler{}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path != "/evaluate" {
+		http.NotFound(writer, request)
+		return
+	}
+	if request.Method != http.MethodGet {
+		http.Error(writer, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(request) {
+		http.Error(writer, "invalid or missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	nodeName := request.URL.Query().Get("node")
+	if nodeName == "" {
+		http.Error(writer, "missing node query parameter", http.StatusBadRequest)
+		return
+	}
+
+	verdict, err := h.evaluate(request.Context(), nodeName)
+	if apierrors.IsNotFound(err) {
+		http.Error(writer, "node not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.log.Error(err, "failed to evaluate node health", "Node name", nodeName)
+		http.Error(writer, "failed to evaluate node health", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(verdict); err != nil {
+		h.log.Error(err, "failed to write the verdict", "Node name", nodeName)
+	}
+}
+
+// authorized checks the request's bearer token against the token secret. It fails
+// closed: when the secret can't be read no request is authorized.
+func (h *Handler) authorized(request *http.Request) bool {
+	presented := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if presented == "" {
+		return false
+	}
+
+	expected, err := h.token(request.Context())
+	if err != nil {
+		h.log.Error(err, "failed to get the token secret", "secret", h.tokenSecret)
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(presented), expected) == 1
+}
+
+// token returns the expected bearer token, reading the token secret at most once
+// per tokenCacheTTL. Errors are not cached, the next request retries the read.
+func (h *Handler) token(ctx context.Context) ([]byte, error) {
+	h.tokenMutex.Lock()
+	defer h.tokenMutex.Unlock()
+	if h.cachedToken != nil && time.Since(h.tokenReadAt) < tokenCacheTTL {
+		return h.cachedToken, nil
+	}
+
+	secret := &v1.Secret{}
+	key := types.NamespacedName{Namespace: h.namespace, Name: h.tokenSecret}
+	if err := h.secretReader.Get(ctx, key, secret); err != nil {
+		return nil, err
+	}
+	expected, exists := secret.Data[secretTokenEntry]
+	if !exists || len(expected) == 0 {
+		return nil, errors.Errorf("the token secret %s/%s has no %q entry", h.namespace, h.tokenSecret, secretTokenEntry)
+	}
+	h.cachedToken = expected
+	h.tokenReadAt = time.Now()
+	return h.cachedToken, nil
+}
+
+// evaluate builds the node's Verdict from the NodeHealthChecks selecting it.
+// Unhealthiness is judged from the node's current conditions with each NHC's
+// unhealthy conditions, the same test the reconciler applies; event, CEL and
+// other triggers only surface here once they led to a remediation.
+func (h *Handler) evaluate(ctx context.Context, nodeName string) (*Verdict, error) {
+	node := &v1.Node{}
+	if err := h.client.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		return nil, err
+	}
+
+	nhcList := &remediationv1alpha1.NodeHealthCheckList{}
+	if err := h.client.List(ctx, nhcList); err != nil {
+		return nil, err
+	}
+
+	verdict := &Verdict{Node: nodeName, Healthy: true}
+	for i := range nhcList.Items {
+		nhc := &nhcList.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(&nhc.Spec.Selector)
+		if err != nil {
+			// the webhook validates the selector, so just skip this NHC
+			h.log.Error(err, "failed to parse the NHC's selector", "NodeHealthCheck name", nhc.GetName())
+			continue
+		}
+		if !selector.Matches(labels.Set(node.GetLabels())) {
+			continue
+		}
+
+		_, inFlight := nhc.Status.InFlightRemediations[nodeName]
+		_, pendingDrain := nhc.Status.PendingFenceDrains[nodeName]
+		remediating := inFlight || pendingDrain
+		healthy, _ := utils.IsNodeHealthy(nhc.Spec.UnhealthyConditions, node.Status.Conditions, time.Now())
+
+		nhcVerdict := NodeHealthCheckVerdict{
+			Name:        nhc.GetName(),
+			Phase:       nhc.Status.Phase,
+			Unhealthy:   !healthy || remediating,
+			Remediating: remediating,
+		}
+		if outcome, exists := nhc.Status.LastRemediationOutcomes[nodeName]; exists {
+			nhcVerdict.LastRemediationOutcome = &outcome
+		}
+		verdict.NodeHealthChecks = append(verdict.NodeHealthChecks, nhcVerdict)
+
+		verdict.Observed = true
+		verdict.Healthy = verdict.Healthy && !nhcVerdict.Unhealthy
+		verdict.Remediating = verdict.Remediating || remediating
+	}
+	return verdict, nil
+}
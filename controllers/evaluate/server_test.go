@@ -0,0 +1,138 @@
+package evaluate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+const (
+	testNamespace = "test-ns"
+	testSecret    = "evaluation-token"
+	testToken     = "test-token"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := remediationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func newTestNode(name string, readyStatus corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:               corev1.NodeReady,
+					Status:             readyStatus,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-10 * time.Minute)},
+				},
+			},
+		},
+	}
+}
+
+func newTestHandler(t *testing.T, objects ...client.Object) *Handler {
+	t.Helper()
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecret},
+		Data:       map[string][]byte{secretTokenEntry: []byte(testToken)},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(append(objects, tokenSecret)...).
+		Build()
+	return NewHandler(fakeClient, fakeClient, testNamespace, testSecret, ctrl.Log.WithName("test"))
+}
+
+func getVerdict(t *testing.T, handler *Handler, nodeName string, token string) (*Verdict, int) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, "/evaluate?node="+nodeName, nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		return nil, recorder.Code
+	}
+	verdict := &Verdict{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), verdict); err != nil {
+		t.Fatal(err)
+	}
+	return verdict, recorder.Code
+}
+
+func TestAuthentication(t *testing.T) {
+	handler := newTestHandler(t, newTestNode("node-1", corev1.ConditionTrue))
+
+	if _, code := getVerdict(t, handler, "node-1", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected a request without token to be rejected, got status %d", code)
+	}
+	if _, code := getVerdict(t, handler, "node-1", "wrong-token"); code != http.StatusUnauthorized {
+		t.Errorf("expected a request with a wrong token to be rejected, got status %d", code)
+	}
+	if _, code := getVerdict(t, handler, "node-1", testToken); code != http.StatusOK {
+		t.Errorf("expected a request with the right token to succeed, got status %d", code)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	nhc := &remediationv1alpha1.NodeHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nhc"},
+		Spec: remediationv1alpha1.NodeHealthCheckSpec{
+			Selector: metav1.LabelSelector{},
+			UnhealthyConditions: []remediationv1alpha1.UnhealthyCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Duration: metav1.Duration{Duration: 5 * time.Minute}},
+			},
+		},
+		Status: remediationv1alpha1.NodeHealthCheckStatus{
+			Phase:                remediationv1alpha1.PhaseRemediating,
+			InFlightRemediations: map[string]metav1.Time{"unhealthy-node": metav1.Now()},
+		},
+	}
+	handler := newTestHandler(t,
+		nhc,
+		newTestNode("healthy-node", corev1.ConditionTrue),
+		newTestNode("unhealthy-node", corev1.ConditionFalse),
+	)
+
+	if _, code := getVerdict(t, handler, "missing-node", testToken); code != http.StatusNotFound {
+		t.Errorf("expected an unknown node to return 404, got status %d", code)
+	}
+
+	verdict, _ := getVerdict(t, handler, "healthy-node", testToken)
+	if verdict == nil || !verdict.Observed || !verdict.Healthy || verdict.Remediating {
+		t.Errorf("expected the healthy node to be observed, healthy and not remediating, got %+v", verdict)
+	}
+
+	verdict, _ = getVerdict(t, handler, "unhealthy-node", testToken)
+	if verdict == nil || !verdict.Observed || verdict.Healthy || !verdict.Remediating {
+		t.Errorf("expected the unhealthy node to be observed, unhealthy and remediating, got %+v", verdict)
+	}
+	if len(verdict.NodeHealthChecks) != 1 {
+		t.Fatalf("expected one NHC verdict, got %+v", verdict.NodeHealthChecks)
+	}
+	if nhcVerdict := verdict.NodeHealthChecks[0]; nhcVerdict.Name != "test-nhc" ||
+		nhcVerdict.Phase != remediationv1alpha1.PhaseRemediating || !nhcVerdict.Unhealthy || !nhcVerdict.Remediating {
+		t.Errorf("unexpected NHC verdict: %+v", nhcVerdict)
+	}
+}
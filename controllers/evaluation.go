@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// evaluationWorkers bounds the goroutines evaluating node health in parallel.
+// Evaluation is CPU bound, CEL expressions in particular, more workers than
+// cores wouldn't help.
+const evaluationWorkers = 8
+
+// nodeEvaluation is the side effect free health verdict of one node
+type nodeEvaluation struct {
+	// healthy is whether the node matches none of the unhealthy criteria
+	healthy bool
+
+	// nextUnhealthy is the time left until a matching condition's duration
+	// elapses, nil when no condition is about to match
+	nextUnhealthy *time.Duration
+}
+
+// evaluateNodes computes the health verdict of every node. The verdict of one
+// node is independent of the others and free of side effects, so for NHCs
+// selecting thousands of nodes the work is spread over a bounded worker pool.
+// The results are indexed by the node's position, the caller aggregates them in
+// node order and stays deterministic.
+func (r *NodeHealthCheckReconciler) evaluateNodes(nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node, histories conditionHistories) []nodeEvaluation {
+	return r.evaluateNodesWithWorkers(nhc, nodes, histories, evaluationWorkers)
+}
+
+func (r *NodeHealthCheckReconciler) evaluateNodesWithWorkers(nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node, histories conditionHistories, workers int) []nodeEvaluation {
+	evaluations := make([]nodeEvaluation, len(nodes))
+	if len(nodes) < workers {
+		workers = len(nodes)
+	}
+	// one timestamp from the injected clock for the whole evaluation, so all
+	// nodes are judged against the same instant
+	now := r.now()
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				node := &nodes[i]
+				// the recorded history replaces transition times reset by flaps,
+				// see updateConditionHistories
+				healthy, nextUnhealthy := utils.IsNodeHealthy(nhc.Spec.UnhealthyConditions, histories.adjustedConditions(node), now)
+				healthy = healthy && !r.hasUnhealthyEvents(nhc, node) && !r.hasUnhealthyCEL(nhc, node)
+				evaluations[i] = nodeEvaluation{healthy: healthy, nextUnhealthy: nextUnhealthy}
+			}
+		}()
+	}
+	for i := range nodes {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return evaluations
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+
+	"github.com/medik8s/node-healthcheck-operator/test/framework"
+)
+
+// benchmarkEvaluateNodes measures the node evaluation with the given worker count,
+// run with -bench to compare the pooled evaluation against the sequential one
+func benchmarkEvaluateNodes(b *testing.B, workers int) {
+	reconciler := &NodeHealthCheckReconciler{Log: controllerruntime.Log.WithName("benchmark")}
+	nhc := framework.NewNodeHealthCheck()
+	// a CEL expression makes the per node work resemble a loaded configuration
+	cel := `node.status.conditions.exists(c, c.type == "Ready" && c.status != "True")`
+	nhc.Spec.UnhealthyCEL = &cel
+
+	nodes := make([]v1.Node, 2000)
+	for i := range nodes {
+		node := framework.NewNode(fmt.Sprintf("node-%d", i), v1.NodeReady, v1.ConditionTrue, 10*time.Minute)
+		nodes[i] = *node.(*v1.Node)
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		evaluations := reconciler.evaluateNodesWithWorkers(nhc, nodes, nil, workers)
+		if !evaluations[0].healthy {
+			b.Fatal("expected the nodes to evaluate as healthy")
+		}
+	}
+}
+
+func BenchmarkEvaluateNodesSequential(b *testing.B) {
+	benchmarkEvaluateNodes(b, 1)
+}
+
+func BenchmarkEvaluateNodesPooled(b *testing.B) {
+	benchmarkEvaluateNodes(b, evaluationWorkers)
+}
@@ -0,0 +1,139 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// maxEventAge limits how long observed events are kept, windows larger than
+	// this are effectively capped
+	maxEventAge = 24 * time.Hour
+
+	// maxObservationsPerKey caps the per node and reason history, to bound memory
+	// usage on noisy nodes
+	maxObservationsPerKey = 100
+)
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch
+
+// Aggregator tracks recent node and NodeHealthCheck events with per-object sliding
+// windows, for the event based unhealthy criteria in spec.unhealthyEvents and the
+// RecentActivity condition
+type Aggregator interface {
+	Start(context.Context) error
+	// CountRecent returns how many events with the given reason were observed for
+	// the given node within the given window
+	CountRecent(nodeName string, reason string, window time.Duration) int
+	// CountRecentFor is CountRecent for any tracked object kind
+	CountRecentFor(kind string, name string, reason string, window time.Duration) int
+}
+
+// NewAggregator creates a new Aggregator watching the events of the given manager's cache
+func NewAggregator(mgr manager.Manager) Aggregator {
+	return &aggregator{
+		mgr:          mgr,
+		log:          mgr.GetLogger().WithName("EventsAggregator"),
+		observations: map[string][]time.Time{},
+	}
+}
+
+type aggregator struct {
+	mgr manager.Manager
+	log logr.Logger
+
+	mutex        sync.Mutex
+	observations map[string][]time.Time
+}
+
+var _ Aggregator = &aggregator{}
+
+// Start registers the event handler and blocks until the context is done
+func (a *aggregator) Start(ctx context.Context) error {
+	informer, err := a.mgr.GetCache().GetInformer(ctx, &v1.Event{})
+	if err != nil {
+		return err
+	}
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: a.observe,
+		UpdateFunc: func(_, newObj interface{}) {
+			a.observe(newObj)
+		},
+	})
+
+	select {
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (a *aggregator) observe(obj interface{}) {
+	event, isEvent := obj.(*v1.Event)
+	if !isEvent || (event.InvolvedObject.Kind != "Node" && event.InvolvedObject.Kind != "NodeHealthCheck") {
+		return
+	}
+
+	observed := event.LastTimestamp.Time
+	if observed.IsZero() {
+		observed = event.EventTime.Time
+	}
+	if observed.IsZero() || time.Since(observed) > maxEventAge {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	key := observationKey(event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason)
+	// informer resyncs hand us the same event again, only count new occurrences
+	if existing := a.observations[key]; len(existing) > 0 && !observed.After(existing[len(existing)-1]) {
+		return
+	}
+	observations := prune(append(a.observations[key], observed))
+	if len(observations) > maxObservationsPerKey {
+		observations = observations[len(observations)-maxObservationsPerKey:]
+	}
+	a.observations[key] = observations
+}
+
+// CountRecent implements Aggregator
+func (a *aggregator) CountRecent(nodeName string, reason string, window time.Duration) int {
+	return a.CountRecentFor("Node", nodeName, reason, window)
+}
+
+// CountRecentFor implements Aggregator
+func (a *aggregator) CountRecentFor(kind string, name string, reason string, window time.Duration) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	key := observationKey(kind, name, reason)
+	a.observations[key] = prune(a.observations[key])
+	count := 0
+	for _, observed := range a.observations[key] {
+		if time.Since(observed) <= window {
+			count++
+		}
+	}
+	return count
+}
+
+// prune drops observations older than maxEventAge
+func prune(observations []time.Time) []time.Time {
+	recent := observations[:0]
+	for _, observed := range observations {
+		if time.Since(observed) <= maxEventAge {
+			recent = append(recent, observed)
+		}
+	}
+	return recent
+}
+
+func observationKey(kind string, name string, reason string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, name, reason)
+}
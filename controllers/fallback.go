@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+const (
+	eventReasonRemediationFallback = "RemediationFallback"
+
+	// createFailureFallbackThreshold is how many consecutive failed creation attempts
+	// of a node's remediation CR engage the fallback template
+	createFailureFallbackThreshold = 3
+)
+
+// templateForNode returns the node's remediation template, with the fallback
+// template taking over once the node's remediation CR couldn't be created
+// createFailureFallbackThreshold times in a row, see
+// spec.fallbackRemediationTemplate. The fallback stays engaged until the node is
+// healthy again, which resets the counter, so a single successful creation of the
+// fallback CR doesn't flip the node back to the broken template.
+func (r *NodeHealthCheckReconciler) templateForNode(nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node, resolver *templateResolver) *unstructured.Unstructured {
+	template := resolver.TemplateForNode(node)
+	fallback := resolver.FallbackTemplate()
+	if fallback == nil || nhc.Status.RemediationCreateFailures[node.GetName()] < createFailureFallbackThreshold {
+		return template
+	}
+	return fallback
+}
+
+// recordRemediationCreateFailure counts a failed creation attempt of the node's
+// remediation CR, and announces the switch to the fallback template when the
+// threshold is crossed
+func (r *NodeHealthCheckReconciler) recordRemediationCreateFailure(nhc *remediationv1alpha1.NodeHealthCheck, nodeName string, remediator string) {
+	if nhc.Status.RemediationCreateFailures == nil {
+		nhc.Status.RemediationCreateFailures = map[string]int{}
+	}
+	nhc.Status.RemediationCreateFailures[nodeName]++
+	if nhc.Status.RemediationCreateFailures[nodeName] != createFailureFallbackThreshold ||
+		nhc.Spec.FallbackRemediationTemplate == nil {
+		return
+	}
+	utils.GetLogWithNHC(r.Log, nhc).Info("creating the remediation CR persistently fails, falling back",
+		"Node name", nodeName, "remediator", remediator, "fallback", nhc.Spec.FallbackRemediationTemplate.Kind)
+	metrics.ObserveNodeHealthCheckRemediationFallback(remediator)
+	r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonRemediationFallback,
+		"Creating the %s remediation CR for node %s failed %d times, falling back to %s",
+		remediator, nodeName, createFailureFallbackThreshold, nhc.Spec.FallbackRemediationTemplate.Kind)
+}
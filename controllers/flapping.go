@@ -0,0 +1,129 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+const (
+	// defaults match the kubebuilder defaults of spec.flappingDetection
+	defaultFlapWindow   = 20 * time.Minute
+	defaultFlapMaxFlips = 3
+
+	eventReasonNodeFlapping = "NodeFlapping"
+)
+
+// detectFlappingNodes implements spec.flappingDetection: it tracks in the NHC status
+// every observed Ready condition transition per node, and returns the names of the
+// nodes which flipped at least maxFlips times within the window. Those nodes are
+// treated as unhealthy even while currently Ready, flapping nodes degrade workloads
+// without ever staying NotReady long enough to match the duration based criteria.
+// For flapping nodes a requeue at the oldest flip's expiry is scheduled, so they
+// recover without another condition change.
+func (r *NodeHealthCheckReconciler) detectFlappingNodes(nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node, result *ctrl.Result) map[string]struct{} {
+	if nhc.Spec.FlappingDetection == nil {
+		for name := range nhc.Status.FlapObservations {
+			metrics.ClearNodeHealthCheckNodeFlapping(nhc.GetName(), name)
+		}
+		nhc.Status.FlapObservations = nil
+		return nil
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	window := defaultFlapWindow
+	if nhc.Spec.FlappingDetection.Window != nil {
+		window = nhc.Spec.FlappingDetection.Window.Duration
+	}
+	maxFlips := defaultFlapMaxFlips
+	if nhc.Spec.FlappingDetection.MaxFlips != nil {
+		maxFlips = *nhc.Spec.FlappingDetection.MaxFlips
+	}
+
+	now := r.now()
+	observations := map[string]remediationv1alpha1.FlapObservation{}
+	flapping := map[string]struct{}{}
+	for i := range nodes {
+		node := &nodes[i]
+		ready := nodeReadyCondition(node)
+		if ready == nil {
+			continue
+		}
+		previous, seen := nhc.Status.FlapObservations[node.GetName()]
+		observation := remediationv1alpha1.FlapObservation{LastTransition: ready.LastTransitionTime}
+		if seen {
+			observation.Flips = previous.Flips
+			if !previous.LastTransition.Equal(&ready.LastTransitionTime) {
+				observation.Flips = append(observation.Flips, ready.LastTransitionTime)
+			}
+		}
+		// the first observation only records the baseline, it is no flip
+
+		var recent []metav1.Time
+		for _, flip := range observation.Flips {
+			if now.Sub(flip.Time) < window {
+				recent = append(recent, flip)
+			}
+		}
+		observation.Flips = recent
+		observations[node.GetName()] = observation
+
+		isFlapping := len(observation.Flips) >= maxFlips
+		metrics.SetNodeHealthCheckNodeFlapping(nhc.GetName(), node.GetName(), isFlapping)
+		if !isFlapping {
+			continue
+		}
+		flapping[node.GetName()] = struct{}{}
+		if len(previous.Flips) < maxFlips {
+			log.Info("node is unhealthy, its Ready condition is flapping",
+				"Node name", node.GetName(), "flips", len(observation.Flips), "window", window)
+			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonNodeFlapping,
+				"Node %s is flapping: its Ready condition changed %d times within %s, treating it as unhealthy",
+				node.GetName(), len(observation.Flips), window)
+		}
+		// check back when the oldest flip ages out of the window
+		updateResultNextReconcile(result, observation.Flips[0].Add(window).Sub(now))
+	}
+
+	// entries of nodes which aren't selected anymore are dropped here
+	for name := range nhc.Status.FlapObservations {
+		if _, selected := observations[name]; !selected {
+			metrics.ClearNodeHealthCheckNodeFlapping(nhc.GetName(), name)
+		}
+	}
+	nhc.Status.FlapObservations = observations
+	return flapping
+}
+
+// nodeReadyCondition returns the node's Ready condition, or nil when it has none
+func nodeReadyCondition(node *v1.Node) *v1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == v1.NodeReady {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
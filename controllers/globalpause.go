@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+const (
+	// GlobalPauseConfigMapName is the cluster wide "big red button" for incident
+	// response: while a ConfigMap with this name exists in the operator's namespace,
+	// no new remediations are started by any NHC. In-flight remediations continue,
+	// delete them manually if they have to stop as well.
+	GlobalPauseConfigMapName = "nhc-global-pause"
+
+	// globalPauseReasonKey is an optional data entry of the global pause ConfigMap
+	// explaining why the pause was set, it is surfaced in the NHC conditions
+	globalPauseReasonKey = "reason"
+
+	eventReasonGloballyPaused    = "GloballyPaused"
+	eventReasonGlobalPauseLifted = "GlobalPauseLifted"
+)
+
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+// checkGlobalPause reports whether the global pause ConfigMap exists, and keeps
+// the NHC's GloballyPaused condition, events and the metric in sync with it
+func (r *NodeHealthCheckReconciler) checkGlobalPause(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck) (bool, error) {
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	ns, err := utils.GetDeploymentNamespace()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get the operator namespace for the global pause check")
+	}
+
+	cm := &v1.ConfigMap{}
+	err = r.Client.Get(ctx, client.ObjectKey{Namespace: ns, Name: GlobalPauseConfigMapName}, cm)
+	if apierrors.IsNotFound(err) {
+		metrics.SetGlobalPause(false)
+		if utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeGloballyPaused, remediationv1alpha1.ConditionReasonGlobalPauseConfigMap) {
+			log.Info("the global remediation pause was lifted")
+			setStatusCondition(nhc, metav1.Condition{
+				Type:    remediationv1alpha1.ConditionTypeGloballyPaused,
+				Status:  metav1.ConditionFalse,
+				Reason:  remediationv1alpha1.ConditionReasonGlobalPauseNotActive,
+				Message: "The global remediation pause is not active",
+			})
+			r.Recorder.Event(nhc, eventTypeNormal, eventReasonGlobalPauseLifted, "The global remediation pause was lifted")
+		}
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to get the global pause ConfigMap")
+	}
+
+	msg := fmt.Sprintf("All remediation is paused by ConfigMap %s/%s", ns, GlobalPauseConfigMapName)
+	if reason := cm.Data[globalPauseReasonKey]; reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, reason)
+	}
+	metrics.SetGlobalPause(true)
+	if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeGloballyPaused, remediationv1alpha1.ConditionReasonGlobalPauseConfigMap) {
+		log.Info("all remediation is globally paused", "ConfigMap name", GlobalPauseConfigMapName)
+		setStatusCondition(nhc, metav1.Condition{
+			Type:    remediationv1alpha1.ConditionTypeGloballyPaused,
+			Status:  metav1.ConditionTrue,
+			Reason:  remediationv1alpha1.ConditionReasonGlobalPauseConfigMap,
+			Message: msg,
+		})
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonGloballyPaused, msg)
+	}
+	return true, nil
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hub implements the optional multi-cluster hub mode on top of Open
+// Cluster Management: NodeHealthChecks marked for distribution are wrapped into
+// ManifestWorks for every available ManagedCluster, and the spokes' NHC status
+// is aggregated back on the hub through ManifestWork status feedback. The OCM
+// types are not vendored, so ManagedClusters and ManifestWorks are handled as
+// unstructured objects, like the MachineConfigPool support does.
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+const (
+	// DistributeAnnotation marks a NodeHealthCheck on the hub for distribution
+	// to all available ManagedClusters
+	DistributeAnnotation = "hub.remediation.medik8s.io/distribute"
+
+	// SpokePhasesAnnotation summarizes the distributed NHC's phase per spoke on
+	// the hub NHC, as comma-separated "<cluster>=<phase>" entries. Spokes whose
+	// feedback hasn't arrived yet are reported as "Unknown".
+	SpokePhasesAnnotation = "hub.remediation.medik8s.io/spoke-phases"
+
+	// manifestWorkPrefix prefixes the ManifestWorks owned by this distributor
+	manifestWorkPrefix = "nhc-"
+
+	// syncPeriod is how often the distributor reconciles ManifestWorks and
+	// aggregates spoke feedback
+	syncPeriod = 2 * time.Minute
+)
+
+var (
+	managedClusterListGVK = schema.GroupVersionKind{
+		Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedClusterList"}
+	manifestWorkGVK = schema.GroupVersionKind{
+		Group: "work.open-cluster-management.io", Version: "v1", Kind: "ManifestWork"}
+)
+
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch;create;update;patch;delete
+
+// Distributor distributes marked NodeHealthChecks to the managed clusters and
+// aggregates their status feedback, see the package doc. It runs on the leader
+// only, like the other singleton background components.
+type Distributor struct {
+	client client.Client
+	log    logr.Logger
+}
+
+var _ manager.Runnable = &Distributor{}
+var _ manager.LeaderElectionRunnable = &Distributor{}
+
+// NewDistributor returns a Distributor backed by the given client
+func NewDistributor(c client.Client, log logr.Logger) *Distributor {
+	return &Distributor{
+		client: c,
+		log:    log,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (d *Distributor) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable
+func (d *Distributor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(syncPeriod)
+	defer ticker.Stop()
+	for {
+		if err := d.Sync(ctx); err != nil {
+			// hub mode is best effort, local remediation must keep working
+			d.log.Error(err, "failed to sync NodeHealthChecks to the managed clusters")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync distributes the marked NodeHealthChecks to all available ManagedClusters
+// and aggregates the spokes' status feedback on the hub NHCs
+func (d *Distributor) Sync(ctx context.Context) error {
+	clusters, err := d.availableClusters(ctx)
+	if err != nil {
+		return err
+	}
+	if clusters == nil {
+		// OCM is not installed, nothing to distribute to
+		return nil
+	}
+
+	nhcList := &remediationv1alpha1.NodeHealthCheckList{}
+	if err := d.client.List(ctx, nhcList); err != nil {
+		return errors.Wrap(err, "failed to list NodeHealthChecks")
+	}
+
+	for i := range nhcList.Items {
+		nhc := &nhcList.Items[i]
+		if nhc.GetAnnotations()[DistributeAnnotation] != "true" {
+			continue
+		}
+		phases := map[string]string{}
+		for _, cluster := range clusters {
+			phase, err := d.syncManifestWork(ctx, nhc, cluster)
+			if err != nil {
+				d.log.Error(err, "failed to sync the NHC to a managed cluster",
+					"NodeHealthCheck name", nhc.GetName(), "cluster", cluster)
+				continue
+			}
+			phases[cluster] = phase
+		}
+		if err := d.recordSpokePhases(ctx, nhc, phases); err != nil {
+			d.log.Error(err, "failed to record the spoke phases", "NodeHealthCheck name", nhc.GetName())
+		}
+	}
+	return nil
+}
+
+// availableClusters returns the names of the ManagedClusters which are currently
+// available, or nil when OCM is not installed
+func (d *Distributor) availableClusters(ctx context.Context) ([]string, error) {
+	clusterList := &unstructured.UnstructuredList{}
+	clusterList.SetGroupVersionKind(managedClusterListGVK)
+	if err := d.client.List(ctx, clusterList); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to list ManagedClusters")
+	}
+
+	var clusters []string
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		conditions, _, err := unstructured.NestedSlice(cluster.Object, "status", "conditions")
+		if err != nil {
+			continue
+		}
+		for _, c := range conditions {
+			condition, isMap := c.(map[string]interface{})
+			if isMap && condition["type"] == "ManagedClusterConditionAvailable" && condition["status"] == "True" {
+				clusters = append(clusters, cluster.GetName())
+				break
+			}
+		}
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
+// syncManifestWork creates or updates the ManifestWork carrying the NHC in the
+// cluster's namespace, and returns the spoke NHC's phase from the work's status
+// feedback, or "Unknown" while no feedback arrived yet
+func (d *Distributor) syncManifestWork(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, cluster string) (string, error) {
+	desired, err := d.renderManifestWork(nhc, cluster)
+	if err != nil {
+		return "", err
+	}
+
+	work := &unstructured.Unstructured{}
+	work.SetGroupVersionKind(manifestWorkGVK)
+	err = d.client.Get(ctx, types.NamespacedName{Namespace: cluster, Name: desired.GetName()}, work)
+	if apierrors.IsNotFound(err) {
+		if err := d.client.Create(ctx, desired); err != nil {
+			return "", errors.Wrap(err, "failed to create the ManifestWork")
+		}
+		return "Unknown", nil
+	} else if err != nil {
+		return "", errors.Wrap(err, "failed to get the ManifestWork")
+	}
+
+	work.Object["spec"] = desired.Object["spec"]
+	if err := d.client.Update(ctx, work); err != nil {
+		return "", errors.Wrap(err, "failed to update the ManifestWork")
+	}
+	return spokePhase(work), nil
+}
+
+// renderManifestWork wraps the NHC into a ManifestWork for the given cluster's
+// namespace, with a feedback rule surfacing the spoke NHC's phase
+func (d *Distributor) renderManifestWork(nhc *remediationv1alpha1.NodeHealthCheck, cluster string) (*unstructured.Unstructured, error) {
+	manifest := &remediationv1alpha1.NodeHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: remediationv1alpha1.GroupVersion.String(),
+			Kind:       "NodeHealthCheck",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nhc.GetName(),
+			Labels:      nhc.GetLabels(),
+			Annotations: map[string]string{},
+		},
+		Spec: *nhc.Spec.DeepCopy(),
+	}
+	// the distribute annotation must not travel to the spokes, a spoke which is
+	// itself a hub would fan the NHC out again
+	for key, value := range nhc.GetAnnotations() {
+		if key != DistributeAnnotation && key != SpokePhasesAnnotation {
+			manifest.Annotations[key] = value
+		}
+	}
+
+	manifestMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert the NHC to a manifest")
+	}
+
+	work := &unstructured.Unstructured{}
+	work.SetGroupVersionKind(manifestWorkGVK)
+	work.SetNamespace(cluster)
+	work.SetName(manifestWorkPrefix + nhc.GetName())
+	work.Object["spec"] = map[string]interface{}{
+		"workload": map[string]interface{}{
+			"manifests": []interface{}{manifestMap},
+		},
+		"manifestConfigs": []interface{}{
+			map[string]interface{}{
+				"resourceIdentifier": map[string]interface{}{
+					"group":    remediationv1alpha1.GroupVersion.Group,
+					"resource": "nodehealthchecks",
+					"name":     nhc.GetName(),
+				},
+				"feedbackRules": []interface{}{
+					map[string]interface{}{
+						"type": "JSONPaths",
+						"jsonPaths": []interface{}{
+							map[string]interface{}{"name": "phase", "path": ".status.phase"},
+						},
+					},
+				},
+			},
+		},
+	}
+	return work, nil
+}
+
+// spokePhase extracts the spoke NHC's phase from the ManifestWork's status
+// feedback, "Unknown" when it isn't reported
+func spokePhase(work *unstructured.Unstructured) string {
+	manifests, _, err := unstructured.NestedSlice(work.Object, "status", "resourceStatus", "manifests")
+	if err != nil {
+		return "Unknown"
+	}
+	for _, m := range manifests {
+		manifest, isMap := m.(map[string]interface{})
+		if !isMap {
+			continue
+		}
+		values, _, err := unstructured.NestedSlice(manifest, "statusFeedback", "values")
+		if err != nil {
+			continue
+		}
+		for _, v := range values {
+			value, isMap := v.(map[string]interface{})
+			if !isMap || value["name"] != "phase" {
+				continue
+			}
+			if phase, _, err := unstructured.NestedString(value, "fieldValue", "string"); err == nil && phase != "" {
+				return phase
+			}
+		}
+	}
+	return "Unknown"
+}
+
+// recordSpokePhases writes the aggregated per-spoke phases into the hub NHC's
+// spoke-phases annotation, only patching when the summary changed
+func (d *Distributor) recordSpokePhases(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, phases map[string]string) error {
+	var entries []string
+	for cluster, phase := range phases {
+		entries = append(entries, fmt.Sprintf("%s=%s", cluster, phase))
+	}
+	sort.Strings(entries)
+	summary := strings.Join(entries, ",")
+
+	if nhc.GetAnnotations()[SpokePhasesAnnotation] == summary {
+		return nil
+	}
+	patch := client.MergeFrom(nhc.DeepCopy())
+	annotations := nhc.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[SpokePhasesAnnotation] = summary
+	nhc.SetAnnotations(annotations)
+	d.log.Info("spoke phases changed", "NodeHealthCheck name", nhc.GetName(), "phases", summary)
+	return d.client.Patch(ctx, nhc, patch)
+}
@@ -0,0 +1,168 @@
+package hub
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := remediationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	// the OCM types are not vendored, register them as unstructured
+	for kind, obj := range map[string]runtime.Object{
+		"ManagedCluster":     &unstructured.Unstructured{},
+		"ManagedClusterList": &unstructured.UnstructuredList{},
+	} {
+		scheme.AddKnownTypeWithName(schema.GroupVersionKind{
+			Group: "cluster.open-cluster-management.io", Version: "v1", Kind: kind}, obj)
+	}
+	for kind, obj := range map[string]runtime.Object{
+		"ManifestWork":     &unstructured.Unstructured{},
+		"ManifestWorkList": &unstructured.UnstructuredList{},
+	} {
+		scheme.AddKnownTypeWithName(schema.GroupVersionKind{
+			Group: "work.open-cluster-management.io", Version: "v1", Kind: kind}, obj)
+	}
+	return scheme
+}
+
+func newManagedCluster(name string, available bool) *unstructured.Unstructured {
+	status := "False"
+	if available {
+		status = "True"
+	}
+	cluster := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "ManagedClusterConditionAvailable", "status": status},
+				},
+			},
+		},
+	}
+	cluster.SetGroupVersionKind(schema.GroupVersionKind{
+		Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedCluster"})
+	cluster.SetName(name)
+	return cluster
+}
+
+func newDistributedNHC() *remediationv1alpha1.NodeHealthCheck {
+	return &remediationv1alpha1.NodeHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-nhc",
+			Annotations: map[string]string{DistributeAnnotation: "true"},
+		},
+	}
+}
+
+func getManifestWork(t *testing.T, c client.Client, cluster string) *unstructured.Unstructured {
+	t.Helper()
+	work := &unstructured.Unstructured{}
+	work.SetGroupVersionKind(manifestWorkGVK)
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: cluster, Name: "nhc-test-nhc"}, work); err != nil {
+		t.Fatalf("failed to get the ManifestWork in cluster %s: %v", cluster, err)
+	}
+	return work
+}
+
+func TestSyncDistributesToAvailableClusters(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(
+		newDistributedNHC(),
+		&remediationv1alpha1.NodeHealthCheck{ObjectMeta: metav1.ObjectMeta{Name: "local-only-nhc"}},
+		newManagedCluster("spoke-1", true),
+		newManagedCluster("spoke-2", false),
+	).Build()
+	distributor := NewDistributor(c, ctrl.Log.WithName("test"))
+
+	if err := distributor.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	work := getManifestWork(t, c, "spoke-1")
+	manifests, _, err := unstructured.NestedSlice(work.Object, "spec", "workload", "manifests")
+	if err != nil || len(manifests) != 1 {
+		t.Fatalf("expected one manifest in the work, got %v (%v)", manifests, err)
+	}
+	manifest := manifests[0].(map[string]interface{})
+	if kind, _, _ := unstructured.NestedString(manifest, "kind"); kind != "NodeHealthCheck" {
+		t.Errorf("expected a NodeHealthCheck manifest, got kind %q", kind)
+	}
+	annotations, _, _ := unstructured.NestedStringMap(manifest, "metadata", "annotations")
+	if _, distributes := annotations[DistributeAnnotation]; distributes {
+		t.Errorf("expected the distribute annotation to be stripped from the spoke manifest")
+	}
+
+	workList := &unstructured.UnstructuredList{}
+	workList.SetGroupVersionKind(schema.GroupVersionKind{
+		Group: "work.open-cluster-management.io", Version: "v1", Kind: "ManifestWorkList"})
+	if err := c.List(context.Background(), workList); err != nil {
+		t.Fatal(err)
+	}
+	for i := range workList.Items {
+		if ns := workList.Items[i].GetNamespace(); ns != "spoke-1" {
+			t.Errorf("expected works for the available cluster only, found one in %s", ns)
+		}
+	}
+}
+
+func TestSyncAggregatesSpokePhases(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(
+		newDistributedNHC(),
+		newManagedCluster("spoke-1", true),
+	).Build()
+	distributor := NewDistributor(c, ctrl.Log.WithName("test"))
+	ctx := context.Background()
+
+	if err := distributor.Sync(ctx); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	// simulate the spoke reporting its phase through status feedback
+	work := getManifestWork(t, c, "spoke-1")
+	feedback := map[string]interface{}{
+		"resourceStatus": map[string]interface{}{
+			"manifests": []interface{}{
+				map[string]interface{}{
+					"statusFeedback": map[string]interface{}{
+						"values": []interface{}{
+							map[string]interface{}{
+								"name":       "phase",
+								"fieldValue": map[string]interface{}{"type": "String", "string": "Remediating"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	work.Object["status"] = feedback
+	if err := c.Update(ctx, work); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := distributor.Sync(ctx); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+
+	nhc := &remediationv1alpha1.NodeHealthCheck{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "test-nhc"}, nhc); err != nil {
+		t.Fatal(err)
+	}
+	if summary := nhc.GetAnnotations()[SpokePhasesAnnotation]; summary != "spoke-1=Remediating" {
+		t.Errorf("expected the spoke phase summary %q, got %q", "spoke-1=Remediating", summary)
+	}
+}
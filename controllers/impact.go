@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+
+const (
+	eventReasonImpactEstimated = "RemediationImpactEstimated"
+
+	// podNodeNameIndexField indexes pods by the node they run on, see registerImpactIndexes
+	podNodeNameIndexField = "spec.nodeName"
+	// endpointSliceNodesIndexField indexes EndpointSlices by the nodes their endpoints
+	// sit on, see registerImpactIndexes
+	endpointSliceNodesIndexField = "endpoints.nodeName"
+)
+
+// registerImpactIndexes registers the pod and EndpointSlice field indexes the impact
+// estimates are computed from, scanning all pods of the cluster per estimate would be
+// too expensive
+func registerImpactIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &v1.Pod{}, podNodeNameIndexField, func(obj client.Object) []string {
+		pod := obj.(*v1.Pod)
+		if pod.Spec.NodeName == "" {
+			return nil
+		}
+		return []string{pod.Spec.NodeName}
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &discoveryv1.EndpointSlice{}, endpointSliceNodesIndexField, func(obj client.Object) []string {
+		slice := obj.(*discoveryv1.EndpointSlice)
+		nodes := map[string]struct{}{}
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.NodeName != nil && *endpoint.NodeName != "" {
+				nodes[*endpoint.NodeName] = struct{}{}
+			}
+		}
+		var names []string
+		for name := range nodes {
+			names = append(names, name)
+		}
+		return names
+	})
+}
+
+// recordRemediationImpact estimates the blast radius of fencing the given node and
+// records it in status.remediationImpacts, so approvers and post-mortems can judge
+// the impact. The snapshot is taken once per remediation, before anything happens to
+// the node, and is best effort: a node whose impact can't be estimated is still fenced.
+func (r *NodeHealthCheckReconciler) recordRemediationImpact(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) {
+	if _, exists := nhc.Status.RemediationImpacts[node.GetName()]; exists {
+		return
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+	impact, err := r.estimateImpact(ctx, node)
+	if err != nil {
+		log.Error(err, "failed to estimate the remediation impact", "Node name", node.GetName())
+		return
+	}
+
+	if nhc.Status.RemediationImpacts == nil {
+		nhc.Status.RemediationImpacts = map[string]remediationv1alpha1.RemediationImpact{}
+	}
+	nhc.Status.RemediationImpacts[node.GetName()] = impact
+	log.Info("estimated the remediation impact", "Node name", node.GetName(),
+		"pods", impact.Pods, "bound volumes", impact.BoundVolumes, "single point services", impact.SinglePointServices)
+	r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonImpactEstimated,
+		"Fencing node %s impacts %d pods, %d bound volumes and %d services with all endpoints on it",
+		node.GetName(), impact.Pods, impact.BoundVolumes, impact.SinglePointServices)
+}
+
+// estimateImpact counts the pods running on the node, the distinct persistent volume
+// claims they mount, and the services whose endpoints all sit on the node
+func (r *NodeHealthCheckReconciler) estimateImpact(ctx context.Context, node *v1.Node) (remediationv1alpha1.RemediationImpact, error) {
+	impact := remediationv1alpha1.RemediationImpact{EstimatedAt: metav1.Time{Time: r.now()}}
+
+	pods := &v1.PodList{}
+	if err := r.Client.List(ctx, pods, client.MatchingFields{podNodeNameIndexField: node.GetName()}); err != nil {
+		return impact, errors.Wrap(err, "failed to list the node's pods")
+	}
+	impact.Pods = len(pods.Items)
+	claims := map[string]struct{}{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				claims[pod.GetNamespace()+"/"+volume.PersistentVolumeClaim.ClaimName] = struct{}{}
+			}
+		}
+	}
+	impact.BoundVolumes = len(claims)
+
+	slices := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, slices, client.MatchingFields{endpointSliceNodesIndexField: node.GetName()}); err != nil {
+		return impact, errors.Wrap(err, "failed to list the node's EndpointSlices")
+	}
+	checked := map[string]struct{}{}
+	for i := range slices.Items {
+		slice := &slices.Items[i]
+		service := slice.GetLabels()[discoveryv1.LabelServiceName]
+		if service == "" {
+			continue
+		}
+		key := slice.GetNamespace() + "/" + service
+		if _, done := checked[key]; done {
+			continue
+		}
+		checked[key] = struct{}{}
+		only, err := r.serviceOnlyOnNode(ctx, slice.GetNamespace(), service, node.GetName())
+		if err != nil {
+			return impact, err
+		}
+		if only {
+			impact.SinglePointServices++
+		}
+	}
+	return impact, nil
+}
+
+// serviceOnlyOnNode reports whether all endpoints of the given service sit on the
+// given node, such a service loses all its backends while the node is fenced
+func (r *NodeHealthCheckReconciler) serviceOnlyOnNode(ctx context.Context, namespace, service, nodeName string) (bool, error) {
+	slices := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, slices, client.InNamespace(namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: service}); err != nil {
+		return false, errors.Wrapf(err, "failed to list the EndpointSlices of service %s/%s", namespace, service)
+	}
+	hasEndpoints := false
+	for i := range slices.Items {
+		for _, endpoint := range slices.Items[i].Endpoints {
+			if endpoint.NodeName == nil || *endpoint.NodeName == "" {
+				continue
+			}
+			if *endpoint.NodeName != nodeName {
+				return false, nil
+			}
+			hasEndpoints = true
+		}
+	}
+	return hasEndpoints, nil
+}
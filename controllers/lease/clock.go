@@ -0,0 +1,113 @@
+package lease
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// how often the offset to the API server time is refreshed
+	clockSyncPeriod = 10 * time.Minute
+)
+
+// Clock provides the current time for lease expiry and overdue calculations.
+// It aliases the upstream clock interface, so tests can inject a fake clock
+// and fast-forward time deterministically. Implementations must be safe for
+// concurrent use.
+type Clock = clock.PassiveClock
+
+// NewLocalClock returns a Clock based on the local time. Only meant for tests,
+// on real clusters use the API server based clock.
+func NewLocalClock() Clock {
+	return clock.RealClock{}
+}
+
+// SkewChecker reports the measured offset between the local clock and the API
+// server's clock. The API server based Clock implements it, lease math already
+// compensates the offset, but large skews are worth surfacing: timeout math
+// based on local timestamps silently breaks with them.
+type SkewChecker interface {
+	// Skew returns the current offset of the API server's clock to the local one,
+	// positive when the API server is ahead
+	Skew() time.Duration
+}
+
+// apiServerClock returns the time as seen by the API server, so lease math
+// doesn't break when the clock of the node the operator runs on drifts.
+// The offset to the local clock is derived from the creationTimestamp the API
+// server puts on a short-lived dummy write, and is cached for clockSyncPeriod.
+type apiServerClock struct {
+	client    client.Client
+	namespace string
+	log       logr.Logger
+
+	mutex    sync.Mutex
+	offset   time.Duration
+	lastSync time.Time
+}
+
+// NewAPIServerClock returns a Clock based on the API server's time.
+// The given namespace is used for the dummy writes needed for deriving the time.
+func NewAPIServerClock(c client.Client, namespace string, log logr.Logger) Clock {
+	return &apiServerClock{
+		client:    c,
+		namespace: namespace,
+		log:       log,
+	}
+}
+
+func (c *apiServerClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if time.Since(c.lastSync) > clockSyncPeriod {
+		c.sync()
+	}
+	return time.Now().Add(c.offset)
+}
+
+func (c *apiServerClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Skew implements SkewChecker with the cached offset, refreshing it like Now does
+func (c *apiServerClock) Skew() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if time.Since(c.lastSync) > clockSyncPeriod {
+		c.sync()
+	}
+	return c.offset
+}
+
+// sync refreshes the offset to the API server time. In case the dummy write
+// fails the last known offset is kept, that is still better than local time only.
+func (c *apiServerClock) sync() {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "nhc-time-sync-",
+			Namespace:    c.namespace,
+		},
+	}
+	before := time.Now()
+	if err := c.client.Create(context.Background(), cm); err != nil {
+		c.log.Error(err, "failed to sync time with the API server, keeping last known offset", "offset", c.offset)
+		c.lastSync = time.Now()
+		return
+	}
+	// the creationTimestamp has seconds granularity only, and the local time of
+	// the write is somewhere between before and now; this is accurate enough for
+	// lease durations in the range of minutes
+	c.offset = cm.CreationTimestamp.Time.Sub(before.Truncate(time.Second))
+	c.lastSync = time.Now()
+	if err := c.client.Delete(context.Background(), cm); err != nil {
+		c.log.Error(err, "failed to delete time sync object", "name", cm.Name)
+	}
+}
@@ -0,0 +1,312 @@
+package lease
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+const (
+	// holderIdentity marks the leases held by this operator
+	holderIdentity = "NodeHealthCheck"
+
+	// remediatorAnnotation records which remediator kind the lease was obtained
+	// for, so escalation step transitions can be detected across reconciles and
+	// operator restarts
+	remediatorAnnotation = "remediation.medik8s.io/remediator"
+
+	// leaseBuffer is the default buffer added to the requested lease duration,
+	// to give the holder time to renew before expiry
+	leaseBuffer = 1 * time.Minute
+
+	// maxTimesToExtendLease is the default limit for how long a lease can be
+	// renewed before it is considered overdue and invalidated
+	maxTimesToExtendLease = 2
+
+	// RequeueIfLeaseTaken is the initial requeue time returned when the node's
+	// lease is held by someone else
+	RequeueIfLeaseTaken = 1 * time.Minute
+
+	// maxRequeueIfLeaseTaken caps the exponential backoff applied when the
+	// node's lease is persistently held by someone else
+	maxRequeueIfLeaseTaken = 16 * time.Minute
+)
+
+// Policy tunes lease sizing and overdue detection per obtained lease, unset
+// fields fall back to the operator defaults. It mirrors spec.leasePolicy of the
+// NodeHealthCheck the lease is obtained for.
+type Policy struct {
+	// Buffer is added to the requested lease duration, to give the holder time
+	// to renew before expiry. Defaults to 1 minute.
+	Buffer *time.Duration
+	// MaxExtensions is how often the lease may be extended before it is
+	// considered overdue and invalidated. Defaults to 2.
+	MaxExtensions *int
+}
+
+// buffer returns the policy's buffer, or the default
+func (p Policy) buffer() time.Duration {
+	if p.Buffer != nil {
+		return *p.Buffer
+	}
+	return leaseBuffer
+}
+
+// maxExtensions returns the policy's extension limit, or the default
+func (p Policy) maxExtensions() int {
+	if p.MaxExtensions != nil {
+		return *p.MaxExtensions
+	}
+	return maxTimesToExtendLease
+}
+
+// AlreadyHeldError is returned when the node's lease is held by another holder
+type AlreadyHeldError struct {
+	holder string
+}
+
+func (e AlreadyHeldError) Error() string {
+	return fmt.Sprintf("the lease is already held by %s", e.holder)
+}
+
+// OverdueError is returned when our own lease was held for longer than allowed,
+// which indicates that remediation doesn't make progress
+type OverdueError struct {
+	nodeName string
+}
+
+func (e OverdueError) Error() string {
+	return fmt.Sprintf("the lease for node %s was held for too long and was invalidated", e.nodeName)
+}
+
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=create;delete
+
+// Manager manages the node leases taken while remediating a node, in order to
+// coordinate with remediators and other actors which respect those leases
+type Manager interface {
+	// ObtainNodeLease gets, creates or renews the lease for the given node, on
+	// behalf of the given remediator kind. When the remediator changed since the
+	// lease was obtained, the lease is re-based: the new escalation step gets its
+	// full time allowance, and the lease duration is re-evaluated, shrinking
+	// included. It returns a requeue duration in case the lease is held by
+	// someone else (with AlreadyHeldError), or when the lease needs to be
+	// renewed later. The policy tunes lease sizing and overdue detection,
+	// Policy{} keeps the defaults.
+	ObtainNodeLease(ctx context.Context, nodeName string, remediator string, duration time.Duration, policy Policy) (*time.Duration, error)
+	// InvalidateNodeLease releases the lease of the given node if we hold it
+	InvalidateNodeLease(ctx context.Context, nodeName string) error
+}
+
+type nhcLeaseManager struct {
+	client    client.Client
+	namespace string
+	clock     Clock
+	log       logr.Logger
+
+	// denialBackoffs tracks the current requeue backoff per node while its
+	// lease is held by someone else, guarded by mutex
+	denialBackoffs map[string]time.Duration
+	mutex          sync.Mutex
+}
+
+var _ Manager = &nhcLeaseManager{}
+
+// NewManager returns a lease Manager creating the leases in the given namespace,
+// using the given clock for all expiry and overdue calculations
+func NewManager(c client.Client, namespace string, clock Clock, log logr.Logger) Manager {
+	return &nhcLeaseManager{
+		client:         c,
+		namespace:      namespace,
+		clock:          clock,
+		log:            log,
+		denialBackoffs: map[string]time.Duration{},
+	}
+}
+
+func (m *nhcLeaseManager) ObtainNodeLease(ctx context.Context, nodeName string, remediator string, duration time.Duration, policy Policy) (*time.Duration, error) {
+
+	now := m.clock.Now()
+	durationWithBuffer := duration + policy.buffer()
+
+	lease := &coordv1.Lease{}
+	err := m.client.Get(ctx, client.ObjectKey{Name: leaseName(nodeName), Namespace: m.namespace}, lease)
+	if apierrors.IsNotFound(err) {
+		return m.createLease(ctx, nodeName, remediator, now, durationWithBuffer, policy)
+	} else if err != nil {
+		metrics.ObserveNodeLeaseRequestFailed("get")
+		return nil, errors.Wrapf(err, "failed to get lease for node %s", nodeName)
+	}
+
+	if !m.isHeldByUs(lease) && !m.isExpired(lease, now) {
+		holder := pointer.StringDeref(lease.Spec.HolderIdentity, "unknown")
+		metrics.ObserveNodeLeaseDenied(holder)
+		requeue := m.nextDenialBackoff(nodeName)
+		m.log.Info("node lease is held by someone else, backing off",
+			"node", nodeName, "holder", holder, "requeue after", requeue)
+		return &requeue, AlreadyHeldError{holder: holder}
+	}
+
+	// a changed remediator means the escalation moved to another step, the lease
+	// follows the step instead of counting the previous step's time against it
+	transition := m.isHeldByUs(lease) && lease.GetAnnotations()[remediatorAnnotation] != remediator
+
+	if m.isHeldByUs(lease) && !transition && m.isOverdue(lease, now, policy) {
+		if err := m.client.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+			metrics.ObserveNodeLeaseRequestFailed("delete")
+			return nil, errors.Wrapf(err, "failed to delete overdue lease for node %s", nodeName)
+		}
+		m.log.Info("invalidated overdue lease", "node", nodeName)
+		metrics.ObserveNodeLeaseReleased()
+		metrics.ObserveNodeLeaseOverdue()
+		return nil, OverdueError{nodeName: nodeName}
+	}
+
+	// take over an expired lease, or renew our own
+	takeover := !m.isHeldByUs(lease)
+	if takeover {
+		lease.Spec.HolderIdentity = pointer.String(holderIdentity)
+		lease.Spec.AcquireTime = &metav1.MicroTime{Time: now}
+		lease.Spec.LeaseTransitions = pointer.Int32(pointer.Int32Deref(lease.Spec.LeaseTransitions, 0) + 1)
+	} else if transition {
+		m.log.Info("escalation step transition, re-basing the lease",
+			"node", nodeName,
+			"previous remediator", lease.GetAnnotations()[remediatorAnnotation],
+			"remediator", remediator,
+			"duration", durationWithBuffer)
+		lease.Spec.AcquireTime = &metav1.MicroTime{Time: now}
+	}
+	if lease.GetAnnotations() == nil {
+		lease.SetAnnotations(map[string]string{})
+	}
+	lease.Annotations[remediatorAnnotation] = remediator
+	lease.Spec.RenewTime = &metav1.MicroTime{Time: now}
+	// always write the requested duration, so a step with a shorter timeout
+	// shrinks the lease instead of keeping the previous step's longer one
+	lease.Spec.LeaseDurationSeconds = pointer.Int32(int32(durationWithBuffer.Seconds()))
+	if err := m.client.Update(ctx, lease); err != nil {
+		metrics.ObserveNodeLeaseRequestFailed("update")
+		return nil, errors.Wrapf(err, "failed to renew lease for node %s", nodeName)
+	}
+	if takeover {
+		metrics.ObserveNodeLeaseAcquired()
+	}
+	m.resetDenialBackoff(nodeName)
+	return requeueBeforeExpiry(durationWithBuffer, policy), nil
+}
+
+func (m *nhcLeaseManager) InvalidateNodeLease(ctx context.Context, nodeName string) error {
+	m.resetDenialBackoff(nodeName)
+	lease := &coordv1.Lease{}
+	err := m.client.Get(ctx, client.ObjectKey{Name: leaseName(nodeName), Namespace: m.namespace}, lease)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		metrics.ObserveNodeLeaseRequestFailed("get")
+		return errors.Wrapf(err, "failed to get lease for node %s", nodeName)
+	}
+	if !m.isHeldByUs(lease) {
+		return nil
+	}
+	if err := m.client.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+		metrics.ObserveNodeLeaseRequestFailed("delete")
+		return errors.Wrapf(err, "failed to delete lease for node %s", nodeName)
+	}
+	metrics.ObserveNodeLeaseReleased()
+	return nil
+}
+
+func (m *nhcLeaseManager) createLease(ctx context.Context, nodeName string, remediator string, now time.Time, duration time.Duration, policy Policy) (*time.Duration, error) {
+	lease := &coordv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        leaseName(nodeName),
+			Namespace:   m.namespace,
+			Annotations: map[string]string{remediatorAnnotation: remediator},
+		},
+		Spec: coordv1.LeaseSpec{
+			HolderIdentity:       pointer.String(holderIdentity),
+			AcquireTime:          &metav1.MicroTime{Time: now},
+			RenewTime:            &metav1.MicroTime{Time: now},
+			LeaseDurationSeconds: pointer.Int32(int32(duration.Seconds())),
+		},
+	}
+	if err := m.client.Create(ctx, lease); err != nil {
+		metrics.ObserveNodeLeaseRequestFailed("create")
+		return nil, errors.Wrapf(err, "failed to create lease for node %s", nodeName)
+	}
+	metrics.ObserveNodeLeaseAcquired()
+	m.resetDenialBackoff(nodeName)
+	return requeueBeforeExpiry(duration, policy), nil
+}
+
+// nextDenialBackoff doubles the requeue duration for each consecutive denial of
+// the node's lease, capped at maxRequeueIfLeaseTaken
+func (m *nhcLeaseManager) nextDenialBackoff(nodeName string) time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	backoff, known := m.denialBackoffs[nodeName]
+	if !known {
+		backoff = RequeueIfLeaseTaken
+	} else if backoff < maxRequeueIfLeaseTaken {
+		backoff *= 2
+		if backoff > maxRequeueIfLeaseTaken {
+			backoff = maxRequeueIfLeaseTaken
+		}
+	}
+	m.denialBackoffs[nodeName] = backoff
+	metrics.SetNodeLeaseDenialBackoff(nodeName, backoff)
+	return backoff
+}
+
+// resetDenialBackoff forgets the node's denial backoff once its lease isn't
+// held by someone else anymore
+func (m *nhcLeaseManager) resetDenialBackoff(nodeName string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, known := m.denialBackoffs[nodeName]; !known {
+		return
+	}
+	delete(m.denialBackoffs, nodeName)
+	metrics.ClearNodeLeaseDenialBackoff(nodeName)
+}
+
+func (m *nhcLeaseManager) isHeldByUs(lease *coordv1.Lease) bool {
+	return pointer.StringDeref(lease.Spec.HolderIdentity, "") == holderIdentity
+}
+
+func (m *nhcLeaseManager) isExpired(lease *coordv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return now.After(lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+func (m *nhcLeaseManager) isOverdue(lease *coordv1.Lease, now time.Time, policy Policy) bool {
+	if lease.Spec.AcquireTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	maxDuration := time.Duration(policy.maxExtensions()+1) * time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	return now.After(lease.Spec.AcquireTime.Add(maxDuration))
+}
+
+// requeueBeforeExpiry schedules the renewal a bit before the lease expires
+func requeueBeforeExpiry(duration time.Duration, policy Policy) *time.Duration {
+	requeue := duration - policy.buffer()/2
+	return &requeue
+}
+
+func leaseName(nodeName string) string {
+	return leaseNamePrefix + nodeName
+}
@@ -0,0 +1,178 @@
+package lease
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "test-ns"
+
+func newTestManager(t *testing.T) (Manager, client.Client, *clocktesting.FakeClock) {
+	t.Helper()
+	c := fake.NewClientBuilder().Build()
+	// a fake clock lets overdue and expiry behavior be tested without sleeping
+	clock := clocktesting.NewFakeClock(time.Now())
+	return NewManager(c, testNamespace, clock, ctrl.Log.WithName("test lease manager")), c, clock
+}
+
+func getLease(t *testing.T, c client.Client, nodeName string) *coordv1.Lease {
+	t.Helper()
+	lease := &coordv1.Lease{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: leaseName(nodeName), Namespace: testNamespace}, lease); err != nil {
+		t.Fatalf("failed to get lease of node %s: %v", nodeName, err)
+	}
+	return lease
+}
+
+func TestObtainNodeLeaseRecordsRemediator(t *testing.T) {
+	manager, c, _ := newTestManager(t)
+
+	if _, err := manager.ObtainNodeLease(context.Background(), "node-1", "TestRemediation", 10*time.Minute, Policy{}); err != nil {
+		t.Fatalf("failed to obtain lease: %v", err)
+	}
+
+	lease := getLease(t, c, "node-1")
+	if remediator := lease.GetAnnotations()[remediatorAnnotation]; remediator != "TestRemediation" {
+		t.Errorf("expected remediator annotation %q, got %q", "TestRemediation", remediator)
+	}
+	wantSeconds := int32((10*time.Minute + leaseBuffer).Seconds())
+	if seconds := pointer.Int32Deref(lease.Spec.LeaseDurationSeconds, 0); seconds != wantSeconds {
+		t.Errorf("expected lease duration %d seconds, got %d", wantSeconds, seconds)
+	}
+}
+
+func TestObtainNodeLeaseShrinksDurationOnTransition(t *testing.T) {
+	manager, c, clock := newTestManager(t)
+	ctx := context.Background()
+
+	if _, err := manager.ObtainNodeLease(ctx, "node-1", "FirstRemediation", 10*time.Minute, Policy{}); err != nil {
+		t.Fatalf("failed to obtain lease for the first step: %v", err)
+	}
+	firstAcquire := getLease(t, c, "node-1").Spec.AcquireTime
+
+	clock.Step(5 * time.Minute)
+	if _, err := manager.ObtainNodeLease(ctx, "node-1", "SecondRemediation", 2*time.Minute, Policy{}); err != nil {
+		t.Fatalf("failed to obtain lease for the second step: %v", err)
+	}
+
+	lease := getLease(t, c, "node-1")
+	wantSeconds := int32((2*time.Minute + leaseBuffer).Seconds())
+	if seconds := pointer.Int32Deref(lease.Spec.LeaseDurationSeconds, 0); seconds != wantSeconds {
+		t.Errorf("expected the lease duration to shrink to %d seconds, got %d", wantSeconds, seconds)
+	}
+	if !lease.Spec.AcquireTime.After(firstAcquire.Time) {
+		t.Errorf("expected the acquire time to be re-based on the step transition")
+	}
+	if remediator := lease.GetAnnotations()[remediatorAnnotation]; remediator != "SecondRemediation" {
+		t.Errorf("expected remediator annotation %q, got %q", "SecondRemediation", remediator)
+	}
+}
+
+func TestObtainNodeLeaseMultiStepChainGetsFullAllowancePerStep(t *testing.T) {
+	manager, _, clock := newTestManager(t)
+	ctx := context.Background()
+
+	duration := 2 * time.Minute
+	// each step exhausts almost its whole overdue allowance before escalating;
+	// without re-basing on the transition the later steps would be cut short
+	stepTime := time.Duration(maxTimesToExtendLease) * (duration + leaseBuffer)
+	for _, remediator := range []string{"FirstRemediation", "SecondRemediation", "ThirdRemediation"} {
+		if _, err := manager.ObtainNodeLease(ctx, "node-1", remediator, duration, Policy{}); err != nil {
+			t.Fatalf("failed to obtain lease for %s: %v", remediator, err)
+		}
+		clock.Step(stepTime)
+		if _, err := manager.ObtainNodeLease(ctx, "node-1", remediator, duration, Policy{}); err != nil {
+			t.Fatalf("failed to renew lease for %s after %s: %v", remediator, stepTime, err)
+		}
+	}
+}
+
+func TestObtainNodeLeaseOverdueWithoutTransition(t *testing.T) {
+	manager, _, clock := newTestManager(t)
+	ctx := context.Background()
+
+	duration := 2 * time.Minute
+	if _, err := manager.ObtainNodeLease(ctx, "node-1", "TestRemediation", duration, Policy{}); err != nil {
+		t.Fatalf("failed to obtain lease: %v", err)
+	}
+
+	// exceed the overdue allowance while staying on the same step
+	clock.Step(time.Duration(maxTimesToExtendLease+2) * (duration + leaseBuffer))
+	_, err := manager.ObtainNodeLease(ctx, "node-1", "TestRemediation", duration, Policy{})
+	if _, isOverdue := err.(OverdueError); !isOverdue {
+		t.Fatalf("expected an OverdueError, got %v", err)
+	}
+}
+
+func TestObtainNodeLeaseCustomPolicy(t *testing.T) {
+	manager, c, clock := newTestManager(t)
+	ctx := context.Background()
+
+	duration := 2 * time.Minute
+	buffer := 30 * time.Second
+	maxExtensions := 5
+	policy := Policy{Buffer: &buffer, MaxExtensions: &maxExtensions}
+
+	if _, err := manager.ObtainNodeLease(ctx, "node-1", "TestRemediation", duration, policy); err != nil {
+		t.Fatalf("failed to obtain lease: %v", err)
+	}
+	wantSeconds := int32((duration + buffer).Seconds())
+	if seconds := pointer.Int32Deref(getLease(t, c, "node-1").Spec.LeaseDurationSeconds, 0); seconds != wantSeconds {
+		t.Errorf("expected the custom buffer to yield a duration of %d seconds, got %d", wantSeconds, seconds)
+	}
+
+	// with the default limit of 2 extensions this renewal would be overdue
+	clock.Step(4 * (duration + buffer))
+	if _, err := manager.ObtainNodeLease(ctx, "node-1", "TestRemediation", duration, policy); err != nil {
+		t.Fatalf("expected the raised extension limit to allow the renewal, got %v", err)
+	}
+
+	// but the raised limit still runs out eventually
+	clock.Step(time.Duration(maxExtensions) * (duration + buffer))
+	_, err := manager.ObtainNodeLease(ctx, "node-1", "TestRemediation", duration, policy)
+	if _, isOverdue := err.(OverdueError); !isOverdue {
+		t.Fatalf("expected an OverdueError, got %v", err)
+	}
+}
+
+func TestObtainNodeLeaseDeniedWhileHeldBySomeoneElse(t *testing.T) {
+	manager, c, clock := newTestManager(t)
+	ctx := context.Background()
+
+	lease := &coordv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leaseName("node-1"),
+			Namespace: testNamespace,
+		},
+		Spec: coordv1.LeaseSpec{
+			HolderIdentity:       pointer.String("SomeoneElse"),
+			RenewTime:            &metav1.MicroTime{Time: clock.Now()},
+			LeaseDurationSeconds: pointer.Int32(600),
+		},
+	}
+	if err := c.Create(ctx, lease); err != nil {
+		t.Fatalf("failed to create the foreign lease: %v", err)
+	}
+
+	requeue, err := manager.ObtainNodeLease(ctx, "node-1", "TestRemediation", 2*time.Minute, Policy{})
+	if _, isHeld := err.(AlreadyHeldError); !isHeld {
+		t.Fatalf("expected an AlreadyHeldError, got %v", err)
+	}
+	if requeue == nil || *requeue != RequeueIfLeaseTaken {
+		t.Errorf("expected the initial denial requeue of %s, got %v", RequeueIfLeaseTaken, requeue)
+	}
+
+	// consecutive denials back off exponentially
+	if requeue, _ = manager.ObtainNodeLease(ctx, "node-1", "TestRemediation", 2*time.Minute, Policy{}); requeue == nil || *requeue != 2*RequeueIfLeaseTaken {
+		t.Errorf("expected the denial requeue to double to %s, got %v", 2*RequeueIfLeaseTaken, requeue)
+	}
+}
@@ -0,0 +1,109 @@
+package lease
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+const (
+	// sweepPeriod is how often the held leases are checked for deleted nodes
+	sweepPeriod = 10 * time.Minute
+
+	// leaseNamePrefix is the prefix leaseName puts before the node name
+	leaseNamePrefix = "node-"
+)
+
+// Sweeper periodically deletes the node leases held by the operator whose node
+// no longer exists. Such leases are left behind when a node is deleted while
+// its remediation is in flight, e.g. by a remediator which deprovisions the
+// machine, and would otherwise stay around forever. It runs on the leader
+// only, like the other singleton background components.
+type Sweeper struct {
+	client     client.Client
+	nodeReader client.Reader
+	namespace  string
+	log        logr.Logger
+}
+
+var _ manager.Runnable = &Sweeper{}
+var _ manager.LeaderElectionRunnable = &Sweeper{}
+
+// NewSweeper returns a Sweeper cleaning up the leases in the given namespace.
+// Nodes are read through the given reader, which must target the cluster the
+// nodes live in: the hosted cluster on Hypershift, the local cluster otherwise.
+func NewSweeper(c client.Client, nodeReader client.Reader, namespace string, log logr.Logger) *Sweeper {
+	return &Sweeper{
+		client:     c,
+		nodeReader: nodeReader,
+		namespace:  namespace,
+		log:        log,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (s *Sweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable
+func (s *Sweeper) Start(ctx context.Context) error {
+	ticker := time.NewTicker(sweepPeriod)
+	defer ticker.Stop()
+	for {
+		if err := s.Sweep(ctx); err != nil {
+			// sweeping is best effort, remediation must keep working
+			s.log.Error(err, "failed to sweep stale node leases")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes every lease held by the operator whose node doesn't exist
+// anymore
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	leases := &coordv1.LeaseList{}
+	if err := s.client.List(ctx, leases, client.InNamespace(s.namespace)); err != nil {
+		return err
+	}
+
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if pointer.StringDeref(lease.Spec.HolderIdentity, "") != holderIdentity ||
+			!strings.HasPrefix(lease.GetName(), leaseNamePrefix) {
+			continue
+		}
+		nodeName := strings.TrimPrefix(lease.GetName(), leaseNamePrefix)
+		err := s.nodeReader.Get(ctx, client.ObjectKey{Name: nodeName}, &v1.Node{})
+		if err == nil {
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			s.log.Error(err, "failed to get the node of a held lease", "node", nodeName)
+			continue
+		}
+		if err := s.client.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+			metrics.ObserveNodeLeaseRequestFailed("delete")
+			s.log.Error(err, "failed to delete the stale lease of a deleted node", "node", nodeName)
+			continue
+		}
+		s.log.Info("swept the stale lease of a deleted node", "node", nodeName)
+		metrics.ObserveNodeLeaseSwept()
+		metrics.ObserveNodeLeaseReleased()
+	}
+	return nil
+}
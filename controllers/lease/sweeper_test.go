@@ -0,0 +1,82 @@
+package lease
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestLease(nodeName string, holder string) *coordv1.Lease {
+	return &coordv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leaseName(nodeName),
+			Namespace: testNamespace,
+		},
+		Spec: coordv1.LeaseSpec{
+			HolderIdentity: pointer.String(holder),
+			RenewTime:      &metav1.MicroTime{Time: time.Now()},
+		},
+	}
+}
+
+func TestSweepDeletesLeasesOfDeletedNodesOnly(t *testing.T) {
+	existingNode := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "existing-node"}}
+	c := fake.NewClientBuilder().WithObjects(
+		existingNode,
+		newTestLease("existing-node", holderIdentity),
+		newTestLease("deleted-node", holderIdentity),
+	).Build()
+	sweeper := NewSweeper(c, c, testNamespace, ctrl.Log.WithName("test lease sweeper"))
+
+	if err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatalf("failed to sweep: %v", err)
+	}
+
+	getLease(t, c, "existing-node")
+	err := c.Get(context.Background(), client.ObjectKey{Name: leaseName("deleted-node"), Namespace: testNamespace}, &coordv1.Lease{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the lease of the deleted node to be swept, got %v", err)
+	}
+}
+
+func TestSweepKeepsForeignLeases(t *testing.T) {
+	c := fake.NewClientBuilder().WithObjects(
+		newTestLease("deleted-node", "SomeoneElse"),
+	).Build()
+	sweeper := NewSweeper(c, c, testNamespace, ctrl.Log.WithName("test lease sweeper"))
+
+	if err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatalf("failed to sweep: %v", err)
+	}
+
+	// someone else's lease isn't ours to clean up, even when the node is gone
+	getLease(t, c, "deleted-node")
+}
+
+func TestSweepReadsNodesFromTheNodeReader(t *testing.T) {
+	// on Hypershift the leases live in the management cluster while the nodes
+	// live in the hosted cluster, a lease must not look stale just because its
+	// node is missing from the lease cluster
+	c := fake.NewClientBuilder().WithObjects(
+		newTestLease("hosted-node", holderIdentity),
+	).Build()
+	nodeReader := fake.NewClientBuilder().WithObjects(
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "hosted-node"}},
+	).Build()
+	sweeper := NewSweeper(c, nodeReader, testNamespace, ctrl.Log.WithName("test lease sweeper"))
+
+	if err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatalf("failed to sweep: %v", err)
+	}
+
+	getLease(t, c, "hosted-node")
+}
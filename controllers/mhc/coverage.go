@@ -0,0 +1,83 @@
+package mhc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/api/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// coverage caches which nodes are covered by which MHC selectors. Matching every
+// node's machine labels against every MHC selector on each reconcile gets expensive
+// on large clusters, so the result is computed once and reused until an MHC, Machine
+// or Node change invalidates it.
+type coverage struct {
+	client client.Client
+	logger logr.Logger
+
+	mutex   sync.Mutex
+	valid   bool
+	covered map[string][]string
+}
+
+func newCoverage(c client.Client, logger logr.Logger) *coverage {
+	return &coverage{
+		client: c,
+		logger: logger,
+	}
+}
+
+// invalidate drops the cached result, it is rebuilt on the next lookup
+func (cov *coverage) invalidate() {
+	cov.mutex.Lock()
+	defer cov.mutex.Unlock()
+	cov.valid = false
+}
+
+// coveringMHCs returns the namespaced names of the MHCs whose machine selector
+// covers the given node, rebuilding the cache when needed
+func (cov *coverage) coveringMHCs(nodeName string) ([]string, error) {
+	cov.mutex.Lock()
+	defer cov.mutex.Unlock()
+	if !cov.valid {
+		if err := cov.rebuild(); err != nil {
+			return nil, err
+		}
+	}
+	return cov.covered[nodeName], nil
+}
+
+func (cov *coverage) rebuild() error {
+	mhcList := &v1beta1.MachineHealthCheckList{}
+	if err := cov.client.List(context.Background(), mhcList); err != nil {
+		return err
+	}
+	machineList := &v1beta1.MachineList{}
+	if err := cov.client.List(context.Background(), machineList); err != nil {
+		return err
+	}
+
+	covered := map[string][]string{}
+	for _, mhc := range mhcList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+		if err != nil {
+			cov.logger.Error(err, "failed to convert MHC selector, skipping it", "MachineHealthCheck", fmt.Sprintf("%s/%s", mhc.Namespace, mhc.Name))
+			continue
+		}
+		for _, machine := range machineList.Items {
+			if machine.Status.NodeRef == nil || !selector.Matches(labels.Set(machine.Labels)) {
+				continue
+			}
+			nodeName := machine.Status.NodeRef.Name
+			covered[nodeName] = append(covered[nodeName], fmt.Sprintf("%s/%s", mhc.Namespace, mhc.Name))
+		}
+	}
+	cov.covered = covered
+	cov.valid = true
+	return nil
+}
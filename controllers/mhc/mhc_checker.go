@@ -2,11 +2,19 @@ package mhc
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+	"github.com/medik8s/node-healthcheck-operator/metrics"
 	"github.com/openshift/api/machine/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	toolscache "k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -14,12 +22,39 @@ import (
 // NodeConditionTerminating is the node condition type used by the termination handler MHC
 const NodeConditionTerminating = "Terminating"
 
+const (
+	// machineAnnotation is set on nodes by the machine API, pointing to the node's machine
+	machineAnnotation = "machine.openshift.io/machine"
+	// externalRemediationAnnotation is set on machines by MHC while an external remediation is in flight
+	externalRemediationAnnotation = "host.metal3.io/external-remediation"
+)
+
+const (
+	// watchdogPeriod is how often the watchdog forces an UpdateStatus, in case the
+	// event driven updates stopped coming
+	watchdogPeriod = 5 * time.Minute
+
+	// watchdogStaleAfter is how long without a successful UpdateStatus the checker
+	// reports itself unhealthy, several watchdog periods so a single failed run
+	// doesn't restart the operator
+	watchdogStaleAfter = 3 * watchdogPeriod
+)
+
 // Checker provides functions for checking for conflicts with MachineHealthCheck
 type Checker interface {
 	Start(context.Context) error
 	UpdateStatus() error
+	// HealthCheck reports whether the checker still evaluates the MHC status,
+	// meant to be wired into the manager's healthz endpoint
+	HealthCheck(*http.Request) error
 	NeedDisableNHC() bool
 	NeedIgnoreNode(*v1.Node) bool
+	// ConflictingMHCs returns the namespaced names of the MHCs which cause NHC to be disabled
+	ConflictingMHCs() []string
+	// CoveringMHCs returns the namespaced names of the MHCs whose machine selector covers the given node
+	CoveringMHCs(*v1.Node) []string
+	// HasMachineRemediation returns true when the given node's machine already has an in-flight MHC remediation
+	HasMachineRemediation(*v1.Node) bool
 }
 
 // NewMHCChecker creates a new Checker
@@ -33,10 +68,13 @@ func NewMHCChecker(mgr manager.Manager) (Checker, error) {
 		return DummyChecker{}, nil
 	}
 
+	logger := mgr.GetLogger().WithName("MHCChecker")
 	c := &checker{
+		mgr:       mgr,
 		client:    mgr.GetClient(),
-		logger:    mgr.GetLogger().WithName("MHCChecker"),
+		logger:    logger,
 		mhcStatus: unknown,
+		coverage:  newCoverage(mgr.GetClient(), logger),
 	}
 	return c, nil
 }
@@ -51,27 +89,86 @@ const (
 )
 
 type checker struct {
-	client     client.Client
-	logger     logr.Logger
-	mhcStatus  mhcStatus
-	mhcRunning bool
+	mgr             manager.Manager
+	client          client.Client
+	logger          logr.Logger
+	mhcStatus       mhcStatus
+	mhcRunning      bool
+	conflictingMHCs []string
+	coverage        *coverage
+
+	// lastSuccess is when UpdateStatus last succeeded, guarded by mutex,
+	// HealthCheck reads it from the healthz endpoint's goroutine
+	mutex       sync.Mutex
+	lastSuccess time.Time
 }
 
 var _ Checker = &checker{}
 
 // Start will start the component and update the initial status
 func (c *checker) Start(ctx context.Context) error {
+	// invalidate the cached MHC node coverage whenever the objects it is derived from change
+	for _, obj := range []client.Object{&v1beta1.MachineHealthCheck{}, &v1beta1.Machine{}, &v1.Node{}} {
+		informer, err := c.mgr.GetCache().GetInformer(ctx, obj)
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { c.coverage.invalidate() },
+			UpdateFunc: func(interface{}, interface{}) { c.coverage.invalidate() },
+			DeleteFunc: func(interface{}) { c.coverage.invalidate() },
+		})
+	}
+
 	if err := c.UpdateStatus(); err != nil {
 		return err
 	}
 
-	select {
-	case <-ctx.Done():
+	// the event driven updates can silently stop, e.g. when an informer loses its
+	// watch for good; the watchdog forces a periodic re-evaluation so a stale
+	// status is bounded by watchdogPeriod, and feeds the staleness HealthCheck
+	ticker := time.NewTicker(watchdogPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.UpdateStatus(); err != nil {
+				c.logger.Error(err, "the MHC status watchdog failed to update the status")
+			}
+		}
+	}
+}
+
+// HealthCheck implements healthz.Checker: it fails once no UpdateStatus succeeded
+// for watchdogStaleAfter, so the kubelet restarts an operator whose MHC evaluation
+// stopped making progress
+func (c *checker) HealthCheck(*http.Request) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.lastSuccess.IsZero() {
+		// not started yet
+		return nil
+	}
+	if stale := time.Since(c.lastSuccess); stale > watchdogStaleAfter {
+		return fmt.Errorf("the last successful MHC evaluation was %s ago", stale)
 	}
 	return nil
 }
 
 func (c *checker) UpdateStatus() error {
+	if err := c.updateStatus(); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	c.lastSuccess = time.Now()
+	c.mutex.Unlock()
+	metrics.SetLastMHCEvaluationTime(time.Now())
+	return nil
+}
+
+func (c *checker) updateStatus() error {
 	mhcList := &v1beta1.MachineHealthCheckList{}
 	if err := c.client.List(context.Background(), mhcList); err != nil {
 		c.logger.Error(err, "failed to list MHC")
@@ -84,6 +181,7 @@ func (c *checker) UpdateStatus() error {
 			c.logger.Info("no MHC found")
 		}
 		c.mhcStatus = noMHC
+		c.conflictingMHCs = nil
 		return nil
 	} else if len(mhcList.Items) > 1 {
 		// multiple MHCs found, disable NHC
@@ -92,6 +190,7 @@ func (c *checker) UpdateStatus() error {
 			c.logger.Info("found custom MHC, will disable NHC")
 		}
 		c.mhcStatus = customMHC
+		c.conflictingMHCs = namespacedNames(mhcList)
 		return nil
 	}
 
@@ -104,6 +203,7 @@ func (c *checker) UpdateStatus() error {
 			c.logger.Info("found termination handler MHC, will ignore Nodes with Terminating condition")
 			c.mhcStatus = terminationMHCOnly
 		}
+		c.conflictingMHCs = nil
 		return nil
 	}
 
@@ -113,10 +213,23 @@ func (c *checker) UpdateStatus() error {
 		c.logger.Info("found custom MHC, will disable NHC")
 	}
 	c.mhcStatus = customMHC
+	c.conflictingMHCs = namespacedNames(mhcList)
 	return nil
 
 }
 
+func namespacedNames(mhcList *v1beta1.MachineHealthCheckList) []string {
+	names := make([]string, 0, len(mhcList.Items))
+	for _, mhc := range mhcList.Items {
+		// the termination handler MHC isn't a conflict, see UpdateStatus
+		if len(mhc.Spec.UnhealthyConditions) == 1 && mhc.Spec.UnhealthyConditions[0].Type == NodeConditionTerminating {
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s/%s", mhc.Namespace, mhc.Name))
+	}
+	return names
+}
+
 // NeedDisableNHC checks if NHC needs to be disabled, because custom MHCs are configured in the cluster,
 // in order to avoid conflicts
 func (c *checker) NeedDisableNHC() bool {
@@ -130,6 +243,11 @@ func (c *checker) NeedDisableNHC() bool {
 	}
 }
 
+// ConflictingMHCs returns the namespaced names of the custom MHCs found by the last UpdateStatus run
+func (c *checker) ConflictingMHCs() []string {
+	return c.conflictingMHCs
+}
+
 // NeedIgnoreNode checks if remediation of a certain node needs to be ignored, because it is handled the default
 // termination handler MHC, see https://github.com/openshift/enhancements/blob/master/enhancements/machine-api/spot-instances.md
 func (c *checker) NeedIgnoreNode(node *v1.Node) bool {
@@ -139,9 +257,20 @@ func (c *checker) NeedIgnoreNode(node *v1.Node) bool {
 		return false
 	}
 
-	// ignore node with condition "Terminating"
+	// ignore node with condition "Terminating", when the MHC's selector actually covers it
 	for _, cond := range node.Status.Conditions {
 		if cond.Type == NodeConditionTerminating {
+			covering, err := c.coverage.coveringMHCs(node.GetName())
+			if err != nil {
+				// can't tell whether the MHC covers this node, err on the safe side
+				// and keep ignoring it, to not interfere with the termination handler
+				c.logger.Error(err, "failed to evaluate MHC node coverage, ignoring terminating Node", "NodeName", node.GetName())
+				return true
+			}
+			if len(covering) == 0 {
+				// no MHC selects this node's machine, NHC keeps handling it
+				return false
+			}
 			c.logger.Info("ignoring unhealthy Node, it is terminating and will be handled by MHC", "NodeName", node.GetName())
 			return true
 		}
@@ -150,6 +279,45 @@ func (c *checker) NeedIgnoreNode(node *v1.Node) bool {
 	return false
 }
 
+// CoveringMHCs returns the namespaced names of the MHCs whose machine selector covers the given node
+func (c *checker) CoveringMHCs(node *v1.Node) []string {
+	covering, err := c.coverage.coveringMHCs(node.GetName())
+	if err != nil {
+		c.logger.Error(err, "failed to evaluate MHC node coverage", "NodeName", node.GetName())
+		return nil
+	}
+	return covering
+}
+
+// HasMachineRemediation returns true when the given node's machine already has an in-flight
+// MHC remediation: either the machine is being deleted by the default remediation strategy,
+// or it carries the external remediation annotation
+func (c *checker) HasMachineRemediation(node *v1.Node) bool {
+	machineNsName, exists := node.GetAnnotations()[machineAnnotation]
+	if !exists {
+		return false
+	}
+	parts := strings.SplitN(machineNsName, "/", 2)
+	if len(parts) != 2 {
+		c.logger.Info("malformed machine annotation on node", "NodeName", node.GetName(), "annotation", machineNsName)
+		return false
+	}
+	machine := &v1beta1.Machine{}
+	if err := c.client.Get(context.Background(), client.ObjectKey{Namespace: parts[0], Name: parts[1]}, machine); err != nil {
+		if !apierrors.IsNotFound(err) {
+			c.logger.Error(err, "failed to get the node's machine", "NodeName", node.GetName(), "Machine", machineNsName)
+		}
+		return false
+	}
+	if machine.GetDeletionTimestamp() != nil {
+		return true
+	}
+	if _, exists := machine.GetAnnotations()[externalRemediationAnnotation]; exists {
+		return true
+	}
+	return false
+}
+
 // DummyChecker can be used in non Openshift clusters or in tests
 // Using NewMHCChecker is recommended though
 type DummyChecker struct{}
@@ -169,6 +337,11 @@ func (d DummyChecker) UpdateStatus() error {
 	return nil
 }
 
+// HealthCheck always succeeds on non openshift clusters
+func (d DummyChecker) HealthCheck(*http.Request) error {
+	return nil
+}
+
 // NeedDisableNHC always return false on non openshift clusters
 func (d DummyChecker) NeedDisableNHC() bool {
 	return false
@@ -178,3 +351,18 @@ func (d DummyChecker) NeedDisableNHC() bool {
 func (d DummyChecker) NeedIgnoreNode(node *v1.Node) bool {
 	return false
 }
+
+// ConflictingMHCs always returns nothing on non openshift clusters
+func (d DummyChecker) ConflictingMHCs() []string {
+	return nil
+}
+
+// CoveringMHCs always returns nothing on non openshift clusters
+func (d DummyChecker) CoveringMHCs(node *v1.Node) []string {
+	return nil
+}
+
+// HasMachineRemediation always return false on non openshift clusters
+func (d DummyChecker) HasMachineRemediation(node *v1.Node) bool {
+	return false
+}
@@ -0,0 +1,219 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+// nhcCRDName is the name of the NodeHealthCheck CRD
+const nhcCRDName = "nodehealthchecks.remediation.medik8s.io"
+
+// templateSuffix mirrors the naming convention of remediation templates, the CR kind
+// is the template kind without it
+const templateSuffix = "Template"
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update;patch
+
+// Migrator re-persists NodeHealthCheck CRs at the current storage version after an
+// operator upgrade changed the CRD schema, and rewrites the owner references of
+// in-flight remediation CRs to the current API version. Without it, objects stored at
+// an old version would block pruning that version from the CRD, and remediation CRs
+// owned by a pruned version would be orphaned mid-flight.
+type Migrator struct {
+	client client.Client
+	log    logr.Logger
+}
+
+// NewMigrator returns a Migrator, meant to be added to the manager so it runs once
+// after leader election
+func NewMigrator(c client.Client, log logr.Logger) *Migrator {
+	return &Migrator{client: c, log: log}
+}
+
+var _ manager.Runnable = &Migrator{}
+var _ manager.LeaderElectionRunnable = &Migrator{}
+
+// NeedLeaderElection ensures only the leader migrates
+func (m *Migrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the migration once and waits for shutdown
+func (m *Migrator) Start(ctx context.Context) error {
+	if err := m.migrate(ctx); err != nil {
+		// don't take the operator down over this, remediation keeps working on the
+		// current version and the migration is retried on the next start; the
+		// dangerous cases are caught by PreUpgradeCheck before the upgrade
+		m.log.Error(err, "storage version migration failed")
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (m *Migrator) migrate(ctx context.Context) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.client.Get(ctx, client.ObjectKey{Name: nhcCRDName}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to get the NodeHealthCheck CRD")
+	}
+	storageVersion := storageVersion(crd)
+	if storageVersion == "" {
+		return errors.New("the NodeHealthCheck CRD has no storage version")
+	}
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		// nothing is stored at an old version
+		return nil
+	}
+	m.log.Info("migrating NodeHealthCheck CRs to the current storage version",
+		"storage version", storageVersion, "stored versions", crd.Status.StoredVersions)
+
+	nhcList := &remediationv1alpha1.NodeHealthCheckList{}
+	if err := m.client.List(ctx, nhcList); err != nil {
+		return errors.Wrap(err, "failed to list NHCs")
+	}
+	for i := range nhcList.Items {
+		nhc := &nhcList.Items[i]
+		if err := m.migrateOwnerReferences(ctx, nhc); err != nil {
+			return err
+		}
+		// an empty patch makes the API server re-persist the object at the
+		// current storage version
+		if err := m.client.Patch(ctx, nhc, client.RawPatch(types.MergePatchType, []byte("{}"))); err != nil {
+			return errors.Wrapf(err, "failed to re-persist NHC %s", nhc.GetName())
+		}
+	}
+
+	// everything is stored at the current version now, prune the old ones so a
+	// later CRD update may drop them
+	crd.Status.StoredVersions = []string{storageVersion}
+	if err := m.client.Status().Update(ctx, crd); err != nil {
+		return errors.Wrap(err, "failed to prune the stored versions of the NodeHealthCheck CRD")
+	}
+	return nil
+}
+
+// migrateOwnerReferences rewrites the NHC owner references of the given NHC's
+// in-flight remediation CRs to the current API version, so garbage collection keeps
+// working when the old version goes away
+func (m *Migrator) migrateOwnerReferences(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	for nodeName := range nhc.Status.InFlightRemediations {
+		for _, ref := range templateRefs(nhc) {
+			cr := &unstructured.Unstructured{}
+			cr.SetAPIVersion(ref.APIVersion)
+			cr.SetKind(strings.TrimSuffix(ref.Kind, templateSuffix))
+			key := client.ObjectKey{Namespace: ref.Namespace, Name: nodeName}
+			if err := m.client.Get(ctx, key, cr); err != nil {
+				if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+					continue
+				}
+				return errors.Wrapf(err, "failed to get the remediation CR of node %s", nodeName)
+			}
+			owners := cr.GetOwnerReferences()
+			changed := false
+			for j := range owners {
+				if owners[j].Kind != "NodeHealthCheck" || owners[j].Name != nhc.GetName() {
+					continue
+				}
+				gv, err := schema.ParseGroupVersion(owners[j].APIVersion)
+				if err != nil || gv.Group != remediationv1alpha1.GroupVersion.Group {
+					continue
+				}
+				if owners[j].APIVersion != remediationv1alpha1.GroupVersion.String() {
+					owners[j].APIVersion = remediationv1alpha1.GroupVersion.String()
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+			cr.SetOwnerReferences(owners)
+			if err := m.client.Update(ctx, cr); err != nil {
+				return errors.Wrapf(err, "failed to update the owner references of the remediation CR of node %s", nodeName)
+			}
+		}
+	}
+	return nil
+}
+
+// PreUpgradeCheck verifies an operator upgrade can't orphan in-flight remediations:
+// every version NHC objects are stored at must still be served by the CRD. It is meant
+// to run from a pre-upgrade hook of the deployment mechanism, see the
+// pre-upgrade-check flag.
+func PreUpgradeCheck(ctx context.Context, c client.Client) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(ctx, client.ObjectKey{Name: nhcCRDName}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			// fresh install, nothing can be orphaned
+			return nil
+		}
+		return errors.Wrap(err, "failed to get the NodeHealthCheck CRD")
+	}
+	served := map[string]bool{}
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			served[version.Name] = true
+		}
+	}
+	var unservable []string
+	for _, version := range crd.Status.StoredVersions {
+		if !served[version] {
+			unservable = append(unservable, version)
+		}
+	}
+	if len(unservable) > 0 {
+		return fmt.Errorf("NodeHealthCheck objects are stored at version(s) %s which are not served anymore, "+
+			"let the currently installed operator finish its storage version migration before upgrading",
+			strings.Join(unservable, ", "))
+	}
+	return nil
+}
+
+// storageVersion returns the version the CRD currently persists objects at
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+// templateRefs collects all remediation template references of the NHC
+func templateRefs(nhc *remediationv1alpha1.NodeHealthCheck) []*corev1.ObjectReference {
+	refs := []*corev1.ObjectReference{}
+	if nhc.Spec.RemediationTemplate != nil {
+		refs = append(refs, nhc.Spec.RemediationTemplate)
+	}
+	for i := range nhc.Spec.RemediationTemplates {
+		if nhc.Spec.RemediationTemplates[i].RemediationTemplate != nil {
+			refs = append(refs, nhc.Spec.RemediationTemplates[i].RemediationTemplate)
+		}
+	}
+	for _, ref := range nhc.Spec.OSRemediationTemplates {
+		if ref != nil {
+			refs = append(refs, ref)
+		}
+	}
+	if nhc.Spec.FallbackRemediationTemplate != nil {
+		refs = append(refs, nhc.Spec.FallbackRemediationTemplate)
+	}
+	return refs
+}
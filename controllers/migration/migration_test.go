@@ -0,0 +1,127 @@
+package migration
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := remediationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func newNHCCRD(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: nhcCRDName},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: remediationv1alpha1.GroupVersion.Group,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: remediationv1alpha1.GroupVersion.Version, Served: true, Storage: true},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+	}
+}
+
+func TestPreUpgradeCheck(t *testing.T) {
+	scheme := newTestScheme(t)
+
+	t.Run("no CRD means a fresh install and passes", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		if err := PreUpgradeCheck(context.Background(), c); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("all stored versions served passes", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(newNHCCRD(remediationv1alpha1.GroupVersion.Version)).Build()
+		if err := PreUpgradeCheck(context.Background(), c); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("a stored version which is not served fails", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(newNHCCRD("v1alpha0", remediationv1alpha1.GroupVersion.Version)).Build()
+		if err := PreUpgradeCheck(context.Background(), c); err == nil {
+			t.Error("expected an error for the unserved stored version")
+		}
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	scheme := newTestScheme(t)
+
+	oldAPIVersion := remediationv1alpha1.GroupVersion.Group + "/v1alpha0"
+	nhc := &remediationv1alpha1.NodeHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nhc", UID: "nhc-uid"},
+		Spec: remediationv1alpha1.NodeHealthCheckSpec{
+			RemediationTemplate: &corev1.ObjectReference{
+				APIVersion: "test.medik8s.io/v1alpha1",
+				Kind:       "TestRemediationTemplate",
+				Namespace:  "default",
+				Name:       "template",
+			},
+		},
+		Status: remediationv1alpha1.NodeHealthCheckStatus{
+			InFlightRemediations: map[string]metav1.Time{"node-1": metav1.Now()},
+		},
+	}
+	cr := &unstructured.Unstructured{}
+	cr.SetAPIVersion("test.medik8s.io/v1alpha1")
+	cr.SetKind("TestRemediation")
+	cr.SetNamespace("default")
+	cr.SetName("node-1")
+	cr.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: oldAPIVersion,
+		Kind:       "NodeHealthCheck",
+		Name:       nhc.GetName(),
+		UID:        nhc.GetUID(),
+	}})
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(newNHCCRD("v1alpha0", remediationv1alpha1.GroupVersion.Version), nhc, cr).Build()
+	m := NewMigrator(c, ctrl.Log.WithName("test migrator"))
+	if err := m.migrate(context.Background()); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: nhcCRDName}, crd); err != nil {
+		t.Fatal(err)
+	}
+	if len(crd.Status.StoredVersions) != 1 || crd.Status.StoredVersions[0] != remediationv1alpha1.GroupVersion.Version {
+		t.Errorf("expected the stored versions to be pruned, got %v", crd.Status.StoredVersions)
+	}
+
+	migratedCR := &unstructured.Unstructured{}
+	migratedCR.SetAPIVersion("test.medik8s.io/v1alpha1")
+	migratedCR.SetKind("TestRemediation")
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "node-1"}, migratedCR); err != nil {
+		t.Fatal(err)
+	}
+	owners := migratedCR.GetOwnerReferences()
+	if len(owners) != 1 || owners[0].APIVersion != remediationv1alpha1.GroupVersion.String() {
+		t.Errorf("expected the owner reference to be migrated to %s, got %v",
+			remediationv1alpha1.GroupVersion.String(), owners)
+	}
+}
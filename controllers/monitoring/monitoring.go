@@ -0,0 +1,140 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	metricsServiceName = "node-healthcheck-operator-controller-manager-metrics-service"
+	serviceMonitorName = "node-healthcheck-operator-metrics-monitor"
+
+	// servingCertSecretName is the secret the service-ca operator stores the
+	// serving cert in, it needs to match the volume in the manager deployment
+	servingCertSecretName    = "nhc-metrics-serving-cert"
+	servingCertAnnotationKey = "service.beta.openshift.io/serving-cert-secret-name"
+)
+
+var serviceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;create;update
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;update
+
+// CreateOrUpdateMonitoring makes metrics scraping work out of the box:
+// on OpenShift it requests a serving cert from the service-ca operator for the
+// metrics service, and when the Prometheus operator API is available it creates
+// a ServiceMonitor for the metrics endpoint.
+func CreateOrUpdateMonitoring(mgr ctrl.Manager, namespace string, log logr.Logger) error {
+
+	onOpenshift, err := utils.IsOnOpenshift(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	if onOpenshift {
+		if err := annotateMetricsService(mgr, namespace, log); err != nil {
+			return err
+		}
+	}
+
+	hasServiceMonitor, err := utils.IsAPIAvailable(mgr.GetConfig(), serviceMonitorGVK)
+	if err != nil {
+		return err
+	}
+	if !hasServiceMonitor {
+		log.Info("ServiceMonitor API not available, skipping ServiceMonitor creation")
+		return nil
+	}
+	return createOrUpdateServiceMonitor(mgr, namespace, onOpenshift, log)
+}
+
+// annotateMetricsService asks the service-ca operator for a serving cert,
+// which it will also rotate before expiry
+func annotateMetricsService(mgr ctrl.Manager, namespace string, log logr.Logger) error {
+	svc := &corev1.Service{}
+	key := client.ObjectKey{Name: metricsServiceName, Namespace: namespace}
+	if err := mgr.GetAPIReader().Get(context.Background(), key, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			// don't fail bootstrap, metrics just won't be scrapable with a trusted cert
+			log.Info("metrics service not found, skipping serving cert annotation", "service", metricsServiceName)
+			return nil
+		}
+		return err
+	}
+	if svc.Annotations[servingCertAnnotationKey] == servingCertSecretName {
+		return nil
+	}
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+	svc.Annotations[servingCertAnnotationKey] = servingCertSecretName
+	log.Info("annotating metrics service for service-ca serving cert", "service", metricsServiceName)
+	return mgr.GetClient().Update(context.Background(), svc)
+}
+
+func createOrUpdateServiceMonitor(mgr ctrl.Manager, namespace string, onOpenshift bool, log logr.Logger) error {
+	sm := getServiceMonitor(namespace, onOpenshift)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(serviceMonitorGVK)
+	err := mgr.GetAPIReader().Get(context.Background(), client.ObjectKeyFromObject(sm), existing)
+	if apierrors.IsNotFound(err) {
+		log.Info("creating ServiceMonitor", "name", serviceMonitorName)
+		return mgr.GetClient().Create(context.Background(), sm)
+	} else if err != nil {
+		return fmt.Errorf("failed to get ServiceMonitor: %v", err)
+	}
+
+	existing.Object["spec"] = sm.Object["spec"]
+	return mgr.GetClient().Update(context.Background(), existing)
+}
+
+func getServiceMonitor(namespace string, onOpenshift bool) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"path":   "/metrics",
+		"port":   "https",
+		"scheme": "https",
+	}
+	if onOpenshift {
+		// the service-ca bundle is injected into every pod, Prometheus can
+		// verify the serving cert with it
+		endpoint["tlsConfig"] = map[string]interface{}{
+			"caFile":     "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt",
+			"serverName": fmt.Sprintf("%s.%s.svc", metricsServiceName, namespace),
+		}
+	} else {
+		// self-signed fallback cert can't be verified
+		endpoint["tlsConfig"] = map[string]interface{}{
+			"insecureSkipVerify": true,
+		}
+	}
+
+	sm := &unstructured.Unstructured{}
+	sm.SetGroupVersionKind(serviceMonitorGVK)
+	sm.SetName(serviceMonitorName)
+	sm.SetNamespace(namespace)
+	sm.Object["spec"] = map[string]interface{}{
+		"endpoints": []interface{}{endpoint},
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				"control-plane": "controller-manager",
+			},
+		},
+	}
+	return sm
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	// nodeHealthyConditionType is the node condition written by NHC when
+	// spec.exportNodeCondition is enabled
+	nodeHealthyConditionType v1.NodeConditionType = "medik8s.io/NodeHealthy"
+
+	nodeConditionReasonHealthy     = "Healthy"
+	nodeConditionReasonUnhealthy   = "Unhealthy"
+	nodeConditionReasonRemediating = "Remediating"
+)
+
+// exportNodeConditions writes the medik8s.io/NodeHealthy condition onto the selected
+// nodes, reflecting the NHC's verdict: True while the node passes all criteria, False
+// with reason Unhealthy or Remediating otherwise. Updates are suppressed while status,
+// reason and message are unchanged, the condition doesn't heartbeat.
+func (r *NodeHealthCheckReconciler) exportNodeConditions(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node, unhealthyNodes []v1.Node) error {
+	if !nhc.Spec.ExportNodeCondition {
+		return nil
+	}
+	unhealthyByName := map[string]bool{}
+	for i := range unhealthyNodes {
+		unhealthyByName[unhealthyNodes[i].GetName()] = true
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		condition := v1.NodeCondition{
+			Type:   nodeHealthyConditionType,
+			Status: v1.ConditionTrue,
+			Reason: nodeConditionReasonHealthy,
+			Message: fmt.Sprintf("Node passes all criteria of NodeHealthCheck %s",
+				nhc.GetName()),
+		}
+		if unhealthyByName[node.GetName()] {
+			condition.Status = v1.ConditionFalse
+			condition.Reason = nodeConditionReasonUnhealthy
+			condition.Message = fmt.Sprintf("Node matches the unhealthy criteria of NodeHealthCheck %s", nhc.GetName())
+			if _, inFlight := nhc.Status.InFlightRemediations[node.GetName()]; inFlight {
+				condition.Reason = nodeConditionReasonRemediating
+				condition.Message = fmt.Sprintf("Node is being remediated by NodeHealthCheck %s", nhc.GetName())
+			}
+		}
+		if err := r.patchNodeCondition(ctx, nhc, node, condition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchNodeCondition updates the node's NHC condition if its content changed
+func (r *NodeHealthCheckReconciler) patchNodeCondition(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node, condition v1.NodeCondition) error {
+	now := metav1.Time{Time: r.now()}
+	condition.LastHeartbeatTime = now
+	condition.LastTransitionTime = now
+	patch := client.MergeFrom(node.DeepCopy())
+	exists := false
+	for i := range node.Status.Conditions {
+		current := &node.Status.Conditions[i]
+		if current.Type != condition.Type {
+			continue
+		}
+		exists = true
+		if current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+			return nil
+		}
+		if current.Status == condition.Status {
+			// only the reason changed, e.g. from Unhealthy to Remediating
+			condition.LastTransitionTime = current.LastTransitionTime
+		}
+		node.Status.Conditions[i] = condition
+	}
+	if !exists {
+		node.Status.Conditions = append(node.Status.Conditions, condition)
+	}
+	if err := r.nodeClient().Status().Patch(ctx, node, patch); err != nil {
+		return errors.Wrapf(err, "failed to update the %s condition of node %s", condition.Type, node.GetName())
+	}
+	utils.GetLogWithNHC(r.Log, nhc).Info("updated the exported node condition",
+		"Node name", node.GetName(), "status", condition.Status, "reason", condition.Reason)
+	return nil
+}
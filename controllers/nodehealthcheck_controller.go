@@ -18,7 +18,10 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,18 +36,33 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecluster "sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/capabilities"
 	"github.com/medik8s/node-healthcheck-operator/controllers/cluster"
+	"github.com/medik8s/node-healthcheck-operator/controllers/events"
+	"github.com/medik8s/node-healthcheck-operator/controllers/lease"
 	"github.com/medik8s/node-healthcheck-operator/controllers/mhc"
+	"github.com/medik8s/node-healthcheck-operator/controllers/notify"
+	"github.com/medik8s/node-healthcheck-operator/controllers/pooldisable"
+	"github.com/medik8s/node-healthcheck-operator/controllers/safemode"
+	"github.com/medik8s/node-healthcheck-operator/controllers/signing"
+	"github.com/medik8s/node-healthcheck-operator/controllers/storage"
 	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
 	"github.com/medik8s/node-healthcheck-operator/metrics"
+	"github.com/medik8s/node-healthcheck-operator/reason"
 )
 
 const (
@@ -54,11 +72,60 @@ const (
 	eventReasonRemediationCreated = "RemediationCreated"
 	eventReasonRemediationSkipped = "RemediationSkipped"
 	eventReasonRemediationRemoved = "RemediationRemoved"
-	eventReasonDisabled           = "Disabled"
-	eventReasonEnabled            = "Enabled"
-	eventTypeNormal               = "Normal"
-	eventTypeWarning              = "Warning"
-	enabledMessage                = "No issues found, NodeHealthCheck is enabled."
+	// eventReasonRemediationEscalated and eventReasonRemediationTimedOut also feed
+	// the RecentActivity condition, see updateRecentActivityCondition
+	eventReasonRemediationEscalated = "RemediationEscalated"
+	eventReasonRemediationTimedOut  = "RemediationTimedOut"
+	eventReasonDisabled             = "Disabled"
+	eventReasonEnabled              = "Enabled"
+	eventReasonDegraded             = "Degraded"
+	eventReasonDegradedResolved     = "DegradedResolved"
+	eventTypeNormal                 = "Normal"
+	eventTypeWarning                = "Warning"
+	enabledMessage                  = "No issues found, NodeHealthCheck is enabled."
+
+	// outOfServiceTaintKey is the taint of the k8s non-graceful node shutdown feature
+	outOfServiceTaintKey           = "node.kubernetes.io/out-of-service"
+	outOfServiceTaintValue         = "nodeshutdown"
+	eventReasonOutOfServiceTaint   = "OutOfServiceTaintAdded"
+	eventReasonOutOfServiceUntaint = "OutOfServiceTaintRemoved"
+
+	// nodeLeaseDuration is how long the node lease is requested for while remediating
+	nodeLeaseDuration = 10 * time.Minute
+
+	// minThrottleBackoff and maxThrottleBackoff bound the requeue delay used when
+	// the API server throttles this operator with 429 responses
+	minThrottleBackoff = 5 * time.Second
+	maxThrottleBackoff = 2 * time.Minute
+
+	// statusTimestampResolution is the granularity below which timestamp-only status
+	// changes are considered churn and suppressed
+	statusTimestampResolution = time.Minute
+
+	// zoneTopologyLabel is the well-known label used for grouping nodes by failure domain
+	zoneTopologyLabel = "topology.kubernetes.io/zone"
+
+	// labelPropagationPrefix marks the NHC labels which are propagated to remediation CRs
+	labelPropagationPrefix = "remediation.medik8s.io/"
+
+	// decisionAnnotationKey holds the remediation decision record on the remediation CR,
+	// decisionSignatureAnnotationKey its signature, when decision signing is configured
+	decisionAnnotationKey          = "remediation.medik8s.io/decision"
+	decisionSignatureAnnotationKey = "remediation.medik8s.io/decision-signature"
+	eventReasonSigningFailed       = "DecisionSigningFailed"
+
+	// scaleDownDisabledAnnotation prevents the cluster autoscaler from scaling down
+	// a node, it is set while remediation is in flight
+	scaleDownDisabledAnnotation = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+	// scaleDownDisabledMarkerAnnotation marks that it was this operator which disabled
+	// scale-down, so only annotations set by itself are removed again
+	scaleDownDisabledMarkerAnnotation = "remediation.medik8s.io/scale-down-disabled"
+	// autoscalerDeletionTaintKey is set by the cluster autoscaler on nodes it is removing
+	autoscalerDeletionTaintKey = "ToBeDeletedByClusterAutoscaler"
+
+	// fieldManager is the dedicated field manager used for writes to shared objects,
+	// so server side apply can resolve conflicts with remediators writing to the same CRs
+	fieldManager = "node-healthcheck-operator"
 )
 
 // NodeHealthCheckReconciler reconciles a NodeHealthCheck object
@@ -68,14 +135,79 @@ type NodeHealthCheckReconciler struct {
 	Scheme                      *runtime.Scheme
 	Recorder                    record.EventRecorder
 	ClusterUpgradeStatusChecker cluster.UpgradeChecker
-	MHCChecker                  mhc.Checker
+	// PlatformHealthChecker holds remediation back while cluster operators are
+	// degraded and the platform itself looks unhealthy, optional
+	PlatformHealthChecker cluster.PlatformHealthChecker
+	MHCChecker            mhc.Checker
+	LeaseManager          lease.Manager
+	EventsAggregator      events.Aggregator
+	// DecisionSigner signs remediation decision records when set, see the
+	// decision-signing-secret flag
+	DecisionSigner signing.Signer
+	// Notifier posts remediation lifecycle notifications to external chat systems
+	// when set, see the slack-webhook-secret and teams-webhook-secret flags
+	Notifier notify.Notifier
+	// HostedCluster is set on Hypershift hosted control planes, where Nodes live in the
+	// hosted cluster while Machines and this operator live in the management cluster,
+	// see the hosted-cluster-kubeconfig-secret flag
+	HostedCluster runtimecluster.Cluster
+	// throttleBackoff is the current requeue delay after API server throttling,
+	// it grows with consecutive 429 responses and resets on success
+	throttleBackoff time.Duration
+	// celEvaluator evaluates spec.unhealthyCEL expressions, caching the compiled programs
+	celEvaluator celEvaluator
+	// StorageDetachChecker tells whether volumes are still being detached from a node,
+	// see spec.waitForStorageDetach
+	StorageDetachChecker storage.DetachChecker
+	// Capabilities reads the capabilities remediators declare on their template CRDs,
+	// used for sanity checking remediation chains and sizing node leases
+	Capabilities *capabilities.Reader
+	// PoolDisableResolver tells whether a node's machine pool opted out of
+	// remediation, see pooldisable.DisableAnnotation
+	PoolDisableResolver pooldisable.Resolver
+	// SafeModeChecker tells whether the operator runs in post-crash-loop safe mode,
+	// in which no new remediations are started, see the safe-mode-marker-file flag;
+	// nil disables the check
+	SafeModeChecker safemode.Checker
+	// ClockSkewChecker reports the offset between the local clock and the API
+	// server's clock, a too large one is surfaced as Degraded; nil disables the check
+	ClockSkewChecker lease.SkewChecker
+	// templateCache serves remediation template CRs from memory, it is set up with
+	// the manager and nil in tests, which then read live
+	templateCache *templateCache
+	// specAudit remembers the last reconciled spec per NHC for diffing spec updates
+	specAudit specAudit
+	// clock provides the current time for all timing decisions like observation
+	// windows, drains and escalations; nil means real time, tests inject a fake
+	// clock to fast-forward deterministically
+	clock clock.PassiveClock
 }
 
-// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// now returns the current time from the injected clock, falling back to real time
+func (r *NodeHealthCheckReconciler) now() time.Time {
+	if r.clock != nil {
+		return r.clock.Now()
+	}
+	return time.Now()
+}
+
+// nodeClient returns the client used for reading and writing Nodes, which targets the
+// hosted cluster on Hypershift and the local cluster everywhere else
+func (r *NodeHealthCheckReconciler) nodeClient() client.Client {
+	if r.HostedCluster != nil {
+		return r.HostedCluster.GetClient()
+	}
+	return r.Client
+}
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=nodes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=remediation.medik8s.io,resources=nodehealthchecks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=remediation.medik8s.io,resources=nodehealthchecks/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=remediation.medik8s.io,resources=nodehealthchecks/finalizers,verbs=update
 // +kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=config.openshift.io,resources=clusteroperators,verbs=get;list;watch
 // +kubebuilder:rbac:groups=machine.openshift.io,resources=machinehealthchecks,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -83,6 +215,24 @@ type NodeHealthCheckReconciler struct {
 func (r *NodeHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	log := r.Log.WithValues("NodeHealthCheck name", req.Name)
 
+	// turn API server throttling into a growing requeue delay instead of an error loop
+	defer func() {
+		result, err = r.backoffOnThrottling(result, err)
+	}()
+
+	// per NHC performance and error metrics, so problems with a single config are
+	// attributable. This defer runs after the status patch defer below, which
+	// keeps the body's error in err, so the category reflects the reconcile
+	// outcome and not just patch failures; it runs before the throttling defer
+	// above, so throttled errors are recorded before they turn into a requeue.
+	reconcileStart := time.Now()
+	defer func() {
+		metrics.ObserveNodeHealthCheckReconcileDuration(req.Name, time.Since(reconcileStart))
+		if err != nil {
+			metrics.ObserveNodeHealthCheckReconcileError(req.Name, reconcileErrorCategory(err))
+		}
+	}()
+
 	// fetch nhc
 	nhc := &remediationv1alpha1.NodeHealthCheck{}
 	err = r.Get(ctx, req.NamespacedName, nhc)
@@ -95,22 +245,44 @@ func (r *NodeHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return result, err
 	}
 
-	// check if we need to patch status before we exit Reconcile
+	// the spec.deletionPolicy decides what happens to in-flight remediations when
+	// the NHC is deleted, enforced with a finalizer
+	if nhc.GetDeletionTimestamp() != nil {
+		var done bool
+		if done, err = r.handleDeletion(ctx, nhc, &result); done || err != nil {
+			return result, err
+		}
+		// WaitForCompletion: keep the loop below running, it cleans up the CRs of
+		// nodes turning healthy again, while no new remediation is started
+	} else if err = r.ensureFinalizer(ctx, nhc); err != nil {
+		return result, err
+	}
+
+	// check if we need to patch status before we exit Reconcile. The body's error
+	// must survive the patch: the throttling and metrics defers above run after
+	// this one and inspect it.
 	nhcOrig := nhc.DeepCopy()
 	defer func() {
-		err = r.patchStatus(nhc, nhcOrig)
-		if err != nil {
-			log.Error(err, "failed to patch NHC status")
+		patchErr := r.patchStatus(nhc, nhcOrig)
+		if patchErr != nil {
+			log.Error(patchErr, "failed to patch NHC status")
+			if err == nil {
+				err = patchErr
+			}
 		}
 	}()
 
+	// record spec changes in events and status, so post-incident review can tie
+	// fencing behavior changes to config changes
+	r.auditSpecChange(nhc)
+
 	// check if we need to disable NHC because of invalid configuration
 	// Remove this and corresponding test when kubebuilder supports minimum on IntOrStr types
 	if err = utils.ValidateMinHealthy(nhc); err != nil {
 		// update status if needed
 		if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled, remediationv1alpha1.ConditionReasonDisabledInvalidConfig) {
 			log.Info("disabling NHC because of invalid config")
-			meta.SetStatusCondition(&nhc.Status.Conditions, metav1.Condition{
+			setStatusCondition(nhc, metav1.Condition{
 				Type:    remediationv1alpha1.ConditionTypeDisabled,
 				Status:  metav1.ConditionTrue,
 				Reason:  remediationv1alpha1.ConditionReasonDisabledInvalidConfig,
@@ -122,48 +294,62 @@ func (r *NodeHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return result, nil
 	}
 
-	// check if we need to disable NHC because of existing MHCs
-	if disable := r.MHCChecker.NeedDisableNHC(); disable {
+	// check if we need to disable NHC because of existing MHCs, unless the
+	// IgnoreCoveredNodes policy wants NHC to run alongside them
+	if disable := r.MHCChecker.NeedDisableNHC(); disable &&
+		nhc.Spec.MachineHealthCheckPolicy != remediationv1alpha1.MachineHealthCheckPolicyIgnoreCoveredNodes {
 		// update status if needed
 		if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled, remediationv1alpha1.ConditionReasonDisabledMHC) {
 			log.Info("disabling NHC in order to avoid conflict with custom MHCs configured in the cluster")
-			meta.SetStatusCondition(&nhc.Status.Conditions, metav1.Condition{
+			msg := "Custom MachineHealthCheck(s) detected, disabling NodeHealthCheck to avoid conflicts"
+			// name the conflicting MHCs so users know what to delete or reconfigure
+			if conflictingMHCs := r.MHCChecker.ConflictingMHCs(); len(conflictingMHCs) > 0 {
+				msg = fmt.Sprintf("%s. Conflicting MHCs: %s", msg, strings.Join(conflictingMHCs, ", "))
+			}
+			setStatusCondition(nhc, metav1.Condition{
 				Type:    remediationv1alpha1.ConditionTypeDisabled,
 				Status:  metav1.ConditionTrue,
 				Reason:  remediationv1alpha1.ConditionReasonDisabledMHC,
-				Message: "Custom MachineHealthCheck(s) detected, disabling NodeHealthCheck to avoid conflicts",
+				Message: msg,
 			})
-			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonDisabled, "Custom MachineHealthCheck(s) detected, disabling NodeHealthCheck to avoid conflicts")
+			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonDisabled, msg)
 		}
 		// stop reconciling
 		return result, nil
 	}
 
-	// check if we need to disable NHC because of missing template CR
-	var template *unstructured.Unstructured
-	if template, err = r.fetchTemplate(nhc); err != nil && apierrors.IsNotFound(errors.Cause(err)) {
-		if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled, remediationv1alpha1.ConditionReasonDisabledTemplateNotFound) {
-			rt := nhc.Spec.RemediationTemplate
-			meta.SetStatusCondition(&nhc.Status.Conditions, metav1.Condition{
+	// the selector guardrails are enforced by the webhook on admission, but the
+	// operator level allow- and denylists can change afterwards, so re-check them here
+	if err := nhc.ValidateSelectorLabels(); err != nil {
+		if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled, remediationv1alpha1.ConditionReasonDisabledInvalidConfig) {
+			log.Info("disabling NHC because its selector violates the label guardrails")
+			setStatusCondition(nhc, metav1.Condition{
 				Type:    remediationv1alpha1.ConditionTypeDisabled,
 				Status:  metav1.ConditionTrue,
-				Reason:  remediationv1alpha1.ConditionReasonDisabledTemplateNotFound,
-				Message: fmt.Sprintf("Remediation Template not found. Kind %s, Namespace: %s, Name %s", rt.GroupVersionKind().Kind, rt.Namespace, rt.Name),
+				Reason:  remediationv1alpha1.ConditionReasonDisabledInvalidConfig,
+				Message: err.Error(),
 			})
-			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonDisabled, "Remediation Template not found. Kind: %s, Namespace: %s, Name %s", rt.GroupVersionKind().Kind, rt.Namespace, rt.Name)
+			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonDisabled, "Invalid configuration: %s", err.Error())
 		}
-		// requeue for checking back if template exists later
-		result.RequeueAfter = 15 * time.Second
+		// stop reconciling
 		return result, nil
-	} else if err != nil {
+	}
+
+	// fetch the remediation templates; a missing or misplaced template disables the
+	// NHC through the typed error mapping, see surfaceReconcileError
+	resolver, err := r.newTemplateResolver(nhc)
+	if err != nil {
+		if r.surfaceReconcileError(nhc, err, &result) {
+			return result, nil
+		}
 		log.Error(err, "failed to get remediation template")
-		return result, err
+		return result, categorized(err, metrics.ReconcileErrorCategoryTemplate)
 	}
 
 	// all checks passed, update status if needed
 	if !meta.IsStatusConditionFalse(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled) {
 		log.Info("enabling NHC, valid config, no conflicting MHC configured in the cluster")
-		meta.SetStatusCondition(&nhc.Status.Conditions, metav1.Condition{
+		setStatusCondition(nhc, metav1.Condition{
 			Type:    remediationv1alpha1.ConditionTypeDisabled,
 			Status:  metav1.ConditionFalse,
 			Reason:  remediationv1alpha1.ConditionReasonEnabled,
@@ -172,15 +358,63 @@ func (r *NodeHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonEnabled, enabledMessage)
 	}
 
+	// remediators can declare their capabilities on their template CRDs, warn when the
+	// declared capabilities of this NHC's remediation chain can't fence a node
+	r.checkRemediationCapabilities(ctx, nhc, resolver)
+
+	// the global pause is the cluster wide big red button for incident response,
+	// while it is active no new remediations are started
+	globallyPaused, err := r.checkGlobalPause(ctx, nhc)
+	if err != nil {
+		return result, err
+	}
+	if globallyPaused {
+		// there is no watch on the pause ConfigMap, poll for its removal
+		updateResultNextReconcile(&result, 1*time.Minute)
+	}
+
+	// after an operator crash loop, safe mode holds fencing back until the operator
+	// proved stable again, observation and reporting continue
+	safeMode := r.checkSafeMode(nhc)
+	if safeMode {
+		// safe mode ends by time alone, no event would retrigger reconciliation
+		updateResultNextReconcile(&result, 1*time.Minute)
+	}
+
+	// surface a too large clock skew to the API server, lease and timeout math
+	// silently breaks with skewed clocks
+	r.checkClockSkew(nhc)
+
 	// select nodes using the nhc.selector
 	nodes, err := r.fetchNodes(ctx, nhc.Spec.Selector)
 	if err != nil {
 		return result, err
 	}
+
+	// with pool refs configured, only nodes belonging to the referenced MachineSets
+	// or NodePools are health-checked, see spec.machineSetRefs and spec.nodePoolRefs
+	nodes, err = r.filterPoolRefNodes(ctx, nhc, nodes)
+	if err != nil {
+		return result, err
+	}
+
+	// pool owners can opt their nodes out of remediation by annotating their
+	// MachineSet or MachineConfigPool, those nodes are skipped entirely
+	nodes, err = r.filterDisabledPoolNodes(ctx, nhc, nodes)
+	if err != nil {
+		return result, err
+	}
 	nhc.Status.ObservedNodes = len(nodes)
 
+	// the persisted condition history keeps the unhealthy duration evaluation
+	// accurate across flaps and server side transition time resets
+	histories, err := r.updateConditionHistories(ctx, nhc, nodes)
+	if err != nil {
+		return result, err
+	}
+
 	// check nodes health
-	unhealthyNodes, err := r.checkNodesHealth(nodes, nhc, template)
+	unhealthyNodes, err := r.checkNodesHealth(nodes, nhc, resolver, histories, &result)
 	if err != nil {
 		return result, err
 	}
@@ -193,11 +427,47 @@ func (r *NodeHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return result, err
 	}
 
+	// a node which rebooted since its unhealthiness was first observed may already
+	// have recovered on its own, restart its observation instead of fencing it again
+	rebootedNodes := r.trackNodeBoots(nhc, unhealthyNodes)
+
+	// nodes which didn't match the unhealthy conditions for the whole observation
+	// window yet are not remediated, they might just be flapping
+	remediationCandidates := r.filterObservedNodes(nhc, unhealthyNodes, &result)
+	remediationCandidates = filterRebootedNodes(remediationCandidates, rebootedNodes)
+
+	// with minHealthyPerZone set, pause remediation in the failure domains which
+	// fell below the threshold, instead of globally
+	remediationCandidates, err = r.filterZoneCandidates(nhc, nodes, unhealthyNodes, remediationCandidates)
+	if err != nil {
+		log.Error(err, "failed to calculate min healthy allowed nodes per zone",
+			"minHealthyPerZone", nhc.Spec.MinHealthyPerZone)
+		return result, err
+	}
+
+	// with a change rollout policy, changed unhealthy criteria are phased in: matching
+	// nodes are held back for the grace period first, then remediated with limited
+	// concurrency
+	remediationCandidates = r.applyChangeRollout(nhc, unhealthyNodes, remediationCandidates, &result)
+
+	// with canary enabled, new or changed unhealthy criteria remediate a single node
+	// first, the rest is held back until the canary recovered
+	remediationCandidates = r.applyCanary(nhc, unhealthyNodes, remediationCandidates, &result)
+
+	// namespaced remediation CRs silently disappear when their namespace is deleted,
+	// check the template namespaces and report a Degraded condition instead of failing
+	// creation over and over; once the namespace is back the CRs are recreated below
+	degraded, err := r.checkRemediationNamespaces(ctx, nhc, resolver, &result)
+	if err != nil {
+		return result, err
+	}
+
 	var reconcileErr error
-	if r.shouldTryRemediation(nhc, nodes, unhealthyNodes, minHealthy, &result) {
-		for i := range unhealthyNodes {
+	if !degraded && !globallyPaused && !safeMode && nhc.GetDeletionTimestamp() == nil &&
+		r.shouldTryRemediation(nhc, nodes, unhealthyNodes, minHealthy, &result) {
+		for i := range remediationCandidates {
 			var nextReconcile *time.Duration
-			nextReconcile, reconcileErr = r.remediate(ctx, &unhealthyNodes[i], nhc, template)
+			nextReconcile, reconcileErr = r.remediate(ctx, &remediationCandidates[i], nhc, r.templateForNode(nhc, &remediationCandidates[i], resolver))
 			if reconcileErr != nil {
 				// don't try to remediate other nodes
 				break
@@ -208,12 +478,31 @@ func (r *NodeHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		}
 	}
 
+	// react to nodes which were deleted while their remediation is still in flight
+	if err = r.handleDeletedNodes(ctx, nhc, nodes); err != nil {
+		return result, err
+	}
+
 	// update inFlightRemediations before checking reconcile error
-	inFlightRemediations, err := r.getInflightRemediations(nhc, template)
+	inFlightRemediations, inFlightDetails, err := r.getInflightRemediations(nhc, resolver, nodes)
 	if err != nil {
 		return result, errors.Wrapf(err, "failed fetching remediation objects of the NHC")
 	}
 	nhc.Status.InFlightRemediations = inFlightRemediations
+	nhc.Status.InFlightRemediationDetails = inFlightDetails
+	metrics.SetNodeHealthCheckOngoingRemediations(nhc.Name, len(inFlightRemediations))
+
+	// keep the per node state machine CRs in sync, they exist for fine-grained
+	// watches and debugging
+	if err = r.updateNodeRemediationStates(ctx, nhc, unhealthyNodes); err != nil {
+		return result, err
+	}
+
+	// optionally mirror the verdict onto the node objects,
+	// see spec.exportNodeCondition
+	if err = r.exportNodeConditions(ctx, nhc, nodes, unhealthyNodes); err != nil {
+		return result, err
+	}
 
 	if reconcileErr != nil {
 		return result, reconcileErr
@@ -246,14 +535,73 @@ func (r *NodeHealthCheckReconciler) shouldTryRemediation(
 			r.Recorder.Event(nhc, eventTypeNormal, eventReasonRemediationSkipped, "Skipped remediation because the cluster is upgrading")
 			return false
 		}
+		if degraded, operators := r.isPlatformDegraded(); degraded {
+			updateResultNextReconcile(result, 1*time.Minute)
+			r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonRemediationSkipped,
+				"Skipped remediation because cluster operator(s) %s are degraded, the platform itself looks unhealthy", operators)
+			return false
+		}
 		return true
 	}
-	msg := fmt.Sprintf("Skipped remediation because the number of healthy nodes selected by the selector is %d and should equal or exceed %d", healthyNodes, minHealthy)
-	log.Info(msg, "healthyNodes", healthyNodes, "minHealthy", minHealthy)
-	r.Recorder.Event(nhc, eventTypeWarning, eventReasonRemediationSkipped, msg)
+	r.surfaceReconcileError(nhc, minHealthyViolationError{healthyNodes: healthyNodes, minHealthy: minHealthy}, result)
 	return false
 }
 
+// checkRemediationNamespaces verifies that the namespaces the remediation templates
+// live in still exist, and keeps the Degraded condition in sync. While a namespace
+// is missing or terminating, remediation is skipped and a requeue is scheduled so
+// recovery is noticed without a watch on namespaces.
+func (r *NodeHealthCheckReconciler) checkRemediationNamespaces(
+	ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, resolver *templateResolver, result *ctrl.Result) (bool, error) {
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	var missing []string
+	for _, template := range resolver.Templates() {
+		ns := template.GetNamespace()
+		if ns == "" {
+			continue
+		}
+		namespace := &v1.Namespace{}
+		err := r.Client.Get(ctx, client.ObjectKey{Name: ns}, namespace)
+		if apierrors.IsNotFound(err) || (err == nil && namespace.GetDeletionTimestamp() != nil) {
+			missing = append(missing, ns)
+			continue
+		} else if err != nil {
+			return false, errors.Wrapf(err, "failed to check the remediation namespace %s", ns)
+		}
+	}
+
+	if len(missing) == 0 {
+		if utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDegraded, remediationv1alpha1.ConditionReasonDegradedNamespaceMissing) {
+			log.Info("the remediation namespaces are back, resuming remediation")
+			setStatusCondition(nhc, metav1.Condition{
+				Type:    remediationv1alpha1.ConditionTypeDegraded,
+				Status:  metav1.ConditionFalse,
+				Reason:  remediationv1alpha1.ConditionReasonNotDegraded,
+				Message: "All remediation namespaces exist",
+			})
+			r.Recorder.Event(nhc, eventTypeNormal, eventReasonDegradedResolved, "The remediation namespaces are back, resuming remediation")
+		}
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("Cannot remediate, the namespace(s) holding the remediation CRs do not exist: %s", strings.Join(missing, ", "))
+	if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDegraded, remediationv1alpha1.ConditionReasonDegradedNamespaceMissing) {
+		log.Info("remediation namespace is missing, skipping remediation until it is back", "namespaces", missing)
+		setStatusCondition(nhc, metav1.Condition{
+			Type:    remediationv1alpha1.ConditionTypeDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  remediationv1alpha1.ConditionReasonDegradedNamespaceMissing,
+			Message: msg,
+		})
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonDegraded, msg)
+	}
+	// there is no watch on namespaces, poll for recovery
+	updateResultNextReconcile(result, 15*time.Second)
+	return true, nil
+}
+
 func (r *NodeHealthCheckReconciler) isClusterUpgrading() bool {
 	clusterUpgrading, err := r.ClusterUpgradeStatusChecker.Check()
 	if err != nil {
@@ -269,6 +617,27 @@ func (r *NodeHealthCheckReconciler) isClusterUpgrading() bool {
 	return false
 }
 
+// isPlatformDegraded tells whether cluster operators are degraded, in which case
+// unhealthy looking nodes are likely a platform problem and fencing them would make
+// the outage worse
+func (r *NodeHealthCheckReconciler) isPlatformDegraded() (bool, string) {
+	if r.PlatformHealthChecker == nil {
+		return false, ""
+	}
+	degraded, err := r.PlatformHealthChecker.DegradedOperators()
+	if err != nil {
+		// log the error but don't return - if we can't reliably tell whether the
+		// platform is healthy then just continue with remediation.
+		r.Log.Error(err, "failed to check for degraded cluster operators. Proceed with remediation as if the platform is healthy")
+		return false, ""
+	}
+	if len(degraded) > 0 {
+		r.Log.Info("Skipping remediation because cluster operators are degraded.", "operators", degraded)
+		return true, strings.Join(degraded, ", ")
+	}
+	return false, ""
+}
+
 func (r *NodeHealthCheckReconciler) fetchNodes(ctx context.Context, labelSelector metav1.LabelSelector) ([]v1.Node, error) {
 	var nodes v1.NodeList
 	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
@@ -276,7 +645,7 @@ func (r *NodeHealthCheckReconciler) fetchNodes(ctx context.Context, labelSelecto
 		err = errors.Wrapf(err, "failed converting a selector from NHC selector")
 		return []v1.Node{}, err
 	}
-	err = r.List(
+	err = r.nodeClient().List(
 		ctx,
 		&nodes,
 		&client.ListOptions{LabelSelector: selector},
@@ -284,12 +653,49 @@ func (r *NodeHealthCheckReconciler) fetchNodes(ctx context.Context, labelSelecto
 	return nodes.Items, err
 }
 
-func (r *NodeHealthCheckReconciler) checkNodesHealth(nodes []v1.Node, nhc *remediationv1alpha1.NodeHealthCheck, template *unstructured.Unstructured) ([]v1.Node, error) {
+// filterDisabledPoolNodes drops nodes whose machine pool opted out of remediation via
+// the pooldisable.DisableAnnotation on its MachineSet or MachineConfigPool. The pool
+// objects are served from the client's cache, annotation changes take effect on the
+// next reconcile.
+func (r *NodeHealthCheckReconciler) filterDisabledPoolNodes(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node) ([]v1.Node, error) {
+	if r.PoolDisableResolver == nil {
+		return nodes, nil
+	}
+	log := utils.GetLogWithNHC(r.Log, nhc)
+	filtered := make([]v1.Node, 0, len(nodes))
+	for i := range nodes {
+		node := &nodes[i]
+		pool, err := r.PoolDisableResolver.DisabledPoolForNode(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		if pool != "" {
+			log.Info("skipping node, its machine pool opted out of remediation",
+				"Node name", node.GetName(), "pool", pool)
+			continue
+		}
+		filtered = append(filtered, *node)
+	}
+	return filtered, nil
+}
+
+func (r *NodeHealthCheckReconciler) checkNodesHealth(nodes []v1.Node, nhc *remediationv1alpha1.NodeHealthCheck, resolver *templateResolver, histories conditionHistories, result *ctrl.Result) ([]v1.Node, error) {
 	var unhealthy []v1.Node
+	degradedResources := r.observeNodeResources(nhc, nodes, result)
+	flappingNodes := r.detectFlappingNodes(nhc, nodes, result)
+	evaluations := r.evaluateNodes(nhc, nodes, histories)
 	for i := range nodes {
 		node := &nodes[i]
-		if isHealthy(nhc.Spec.UnhealthyConditions, node.Status.Conditions) {
-			err := r.markHealthy(node, nhc, template)
+		_, resourceDegraded := degradedResources[node.GetName()]
+		_, nodeFlapping := flappingNodes[node.GetName()]
+		healthy, nextUnhealthy := evaluations[i].healthy, evaluations[i].nextUnhealthy
+		if healthy && !resourceDegraded && !nodeFlapping {
+			// a condition matches but its duration didn't elapse yet,
+			// check back when it does
+			if nextUnhealthy != nil {
+				updateResultNextReconcile(result, *nextUnhealthy)
+			}
+			err := r.markHealthy(node, nhc, r.templateForNode(nhc, node, resolver), result)
 			if err != nil {
 				return nil, err
 			}
@@ -298,16 +704,213 @@ func (r *NodeHealthCheckReconciler) checkNodesHealth(nodes []v1.Node, nhc *remed
 			if r.MHCChecker.NeedIgnoreNode(node) {
 				continue
 			}
+			// the autoscaler is removing this node anyway, don't fence it in parallel
+			if isScalingDown(node) {
+				utils.GetLogWithNHC(r.Log, nhc).Info("ignoring unhealthy Node, the cluster autoscaler is removing it",
+					"Node name", node.GetName())
+				continue
+			}
+			// with the IgnoreCoveredNodes policy NHC runs alongside custom MHCs, but it
+			// stands down for nodes the MHC covers or already started to remediate
+			if nhc.Spec.MachineHealthCheckPolicy == remediationv1alpha1.MachineHealthCheckPolicyIgnoreCoveredNodes &&
+				(len(r.MHCChecker.CoveringMHCs(node)) > 0 || r.MHCChecker.HasMachineRemediation(node)) {
+				utils.GetLogWithNHC(r.Log, nhc).Info("ignoring unhealthy Node, it is covered by an MHC",
+					"Node name", node.GetName())
+				continue
+			}
 			unhealthy = append(unhealthy, *node)
 		}
 	}
 	return unhealthy, nil
 }
 
-func (r *NodeHealthCheckReconciler) markHealthy(node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck, template *unstructured.Unstructured) error {
+// hasUnhealthyEvents applies spec.unhealthyEvents: it checks with the events aggregator
+// whether one of the configured event criteria is met for the given node
+func (r *NodeHealthCheckReconciler) hasUnhealthyEvents(nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node) bool {
+	if len(nhc.Spec.UnhealthyEvents) == 0 || r.EventsAggregator == nil {
+		return false
+	}
+	for _, criterion := range nhc.Spec.UnhealthyEvents {
+		count := r.EventsAggregator.CountRecent(node.GetName(), criterion.Reason, criterion.Window.Duration)
+		if count >= criterion.MinCount {
+			utils.GetLogWithNHC(r.Log, nhc).Info("node is unhealthy because of repeated events",
+				"Node name", node.GetName(), "reason", criterion.Reason, "count", count)
+			return true
+		}
+	}
+	return false
+}
+
+// observeNodeResources applies spec.unhealthyResources: it tracks in the NHC status when an
+// extended resource a node advertised before dropped to zero allocatable, and returns the
+// names of the nodes whose resource stayed at zero for longer than the configured duration.
+// For nodes still within the duration a requeue at its expiry is scheduled.
+func (r *NodeHealthCheckReconciler) observeNodeResources(nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node, result *ctrl.Result) map[string]struct{} {
+	if len(nhc.Spec.UnhealthyResources) == 0 {
+		nhc.Status.ResourceObservations = nil
+		return nil
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	now := r.now()
+	observations := map[string]remediationv1alpha1.ResourceObservation{}
+	degraded := map[string]struct{}{}
+	for i := range nodes {
+		node := &nodes[i]
+		for _, resource := range nhc.Spec.UnhealthyResources {
+			key := fmt.Sprintf("%s/%s", node.GetName(), resource.Name)
+			if quantity, advertised := node.Status.Allocatable[resource.Name]; advertised && !quantity.IsZero() {
+				observations[key] = remediationv1alpha1.ResourceObservation{}
+				continue
+			}
+			observation, seen := nhc.Status.ResourceObservations[key]
+			if !seen {
+				// the node never advertised this resource, nothing to watch
+				continue
+			}
+			if observation.ZeroSince == nil {
+				observation.ZeroSince = &metav1.Time{Time: now}
+			}
+			observations[key] = observation
+			if deadline := observation.ZeroSince.Add(resource.Duration.Duration); now.Before(deadline) {
+				log.Info("node stopped advertising a watched resource, remediating when it doesn't recover in time",
+					"Node name", node.GetName(), "resource", resource.Name, "deadline in", deadline.Sub(now))
+				updateResultNextReconcile(result, deadline.Sub(now))
+				continue
+			}
+			log.Info("node is unhealthy, it stopped advertising a watched resource for too long",
+				"Node name", node.GetName(), "resource", resource.Name)
+			degraded[node.GetName()] = struct{}{}
+		}
+	}
+	// entries of nodes which aren't selected anymore are dropped here
+	nhc.Status.ResourceObservations = observations
+	return degraded
+}
+
+// filterObservedNodes applies the spec.observationWindow: it records in the NHC status when
+// a node was first observed unhealthy, and only returns the nodes which stayed unhealthy for
+// the whole window. For the other nodes a requeue at their window expiry is scheduled.
+func (r *NodeHealthCheckReconciler) filterObservedNodes(nhc *remediationv1alpha1.NodeHealthCheck, unhealthyNodes []v1.Node, result *ctrl.Result) []v1.Node {
+	if nhc.Spec.ObservationWindow == nil {
+		nhc.Status.UnhealthyNodeObservations = nil
+		return unhealthyNodes
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	now := r.now()
+	window := nhc.Spec.ObservationWindow.Duration
+	var observations map[string]metav1.Time
+	var candidates []v1.Node
+	for _, node := range unhealthyNodes {
+		firstObserved, observed := nhc.Status.UnhealthyNodeObservations[node.GetName()]
+		if !observed {
+			firstObserved = metav1.Time{Time: now}
+		}
+		if observations == nil {
+			observations = make(map[string]metav1.Time, len(unhealthyNodes))
+		}
+		observations[node.GetName()] = firstObserved
+		if deadline := firstObserved.Add(window); now.Before(deadline) {
+			log.Info("node is unhealthy but still in the observation window, skipping remediation for now",
+				"Node name", node.GetName(), "window ends in", deadline.Sub(now))
+			updateResultNextReconcile(result, deadline.Sub(now))
+			continue
+		}
+		candidates = append(candidates, node)
+	}
+	// nodes which turned healthy again are dropped here, which resets their window
+	nhc.Status.UnhealthyNodeObservations = observations
+	return candidates
+}
+
+// filterZoneCandidates applies spec.minHealthyPerZone: it groups the selected nodes by
+// their topology.kubernetes.io/zone label, and drops the remediation candidates located
+// in zones whose number of healthy nodes fell below the threshold. Nodes without the
+// zone label form their own group.
+func (r *NodeHealthCheckReconciler) filterZoneCandidates(nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node, unhealthyNodes []v1.Node, candidates []v1.Node) ([]v1.Node, error) {
+	if nhc.Spec.MinHealthyPerZone == nil || len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	totalPerZone := map[string]int{}
+	for _, node := range nodes {
+		totalPerZone[node.GetLabels()[zoneTopologyLabel]]++
+	}
+	unhealthyPerZone := map[string]int{}
+	for _, node := range unhealthyNodes {
+		unhealthyPerZone[node.GetLabels()[zoneTopologyLabel]]++
+	}
+
+	allowedZones := map[string]bool{}
+	for zone, total := range totalPerZone {
+		minHealthy, err := intstr.GetScaledValueFromIntOrPercent(nhc.Spec.MinHealthyPerZone, total, true)
+		if err != nil {
+			return nil, err
+		}
+		healthyNodes := total - unhealthyPerZone[zone]
+		if healthyNodes >= minHealthy {
+			allowedZones[zone] = true
+			continue
+		}
+		if unhealthyPerZone[zone] > 0 {
+			r.surfaceReconcileError(nhc, minHealthyViolationError{healthyNodes: healthyNodes, minHealthy: minHealthy, zone: zone}, nil)
+		}
+	}
+
+	var filtered []v1.Node
+	for _, node := range candidates {
+		if allowedZones[node.GetLabels()[zoneTopologyLabel]] {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered, nil
+}
+
+func (r *NodeHealthCheckReconciler) markHealthy(node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck, template *unstructured.Unstructured, result *ctrl.Result) error {
 
 	log := utils.GetLogWithNHC(r.Log, nhc)
 
+	// a pre-fence drain which never led to fencing must not leave tracking behind,
+	// the endpoint slice controller recovers the endpoints of a healthy node itself
+	delete(nhc.Status.PendingFenceDrains, node.GetName())
+	delete(nhc.Status.PendingLiveMigrations, node.GetName())
+	delete(nhc.Status.RemediationCreateFailures, node.GetName())
+	delete(nhc.Status.PendingApprovals, node.GetName())
+	delete(nhc.Status.RemediationImpacts, node.GetName())
+
+	// with storage detach confirmation enabled, keep the remediation and the
+	// out-of-service taint in place until no volume is mid-detach from this node
+	// anymore, failing over an RWO volume before the detach is confirmed by the
+	// storage driver risks data corruption
+	if nhc.Spec.WaitForStorageDetach && r.StorageDetachChecker != nil {
+		pending, err := r.StorageDetachChecker.PendingDetachments(context.Background(), node.GetName())
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			log.Info("keeping remediation in place, volumes are still being detached from the node",
+				"Node name", node.GetName(), "VolumeAttachments", pending)
+			// there is no watch on VolumeAttachments, poll for the detach confirmation
+			updateResultNextReconcile(result, 30*time.Second)
+			return nil
+		}
+	}
+
+	// the node is healthy again, it must not keep the out-of-service taint
+	if nhc.Spec.UseOutOfServiceTaint {
+		if err := r.removeOutOfServiceTaint(context.Background(), node, nhc); err != nil {
+			return err
+		}
+	}
+
+	// and the autoscaler may scale it down again
+	if err := r.enableScaleDown(context.Background(), node, nhc); err != nil {
+		return err
+	}
+
 	cr, err := r.generateRemediationCR(node, nhc, template)
 	if err != nil {
 		return err
@@ -339,46 +942,152 @@ func (r *NodeHealthCheckReconciler) markHealthy(node *v1.Node, nhc *remediationv
 		// deleted an actual object
 		log.Info("deleted node external remediation object", "Node name", node.Name)
 		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonRemediationRemoved, "Deleted remediation object for node %s", node.Name)
+		metrics.ObserveNodeHealthCheckRemediationSucceeded(cr.GetKind(), time.Since(cr.GetCreationTimestamp().Time))
+		r.endRemediationOutcome(nhc, node, remediationv1alpha1.RemediationOutcomeSucceeded)
+		r.sendNotification(context.Background(), notify.ReasonRemediationSucceeded, nhc, node.GetName(), cr.GetKind(),
+			fmt.Sprintf("Node %s is healthy again, remediation with %s succeeded", node.GetName(), cr.GetKind()))
+		if err := r.LeaseManager.InvalidateNodeLease(context.Background(), node.GetName()); err != nil {
+			return categorized(err, metrics.ReconcileErrorCategoryLease)
+		}
 	}
 	return nil
 }
 
-func isHealthy(conditionTests []remediationv1alpha1.UnhealthyCondition, nodeConditions []v1.NodeCondition) bool {
-	now := time.Now()
-	nodeConditionByType := make(map[v1.NodeConditionType]v1.NodeCondition)
-	for _, nc := range nodeConditions {
-		nodeConditionByType[nc.Type] = nc
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeHealthCheckReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index the status summary, so other controllers can list NHCs with unhealthy
+	// nodes via field selectors instead of scanning everything
+	if err := remediationv1alpha1.RegisterSummaryIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
 	}
 
-	for _, c := range conditionTests {
-		n, exists := nodeConditionByType[c.Type]
-		if !exists {
-			continue
-		}
-		if n.Status == c.Status && now.After(n.LastTransitionTime.Add(c.Duration.Duration)) {
-			return false
-		}
+	// index pods and EndpointSlices by node, the impact estimates are computed from them
+	if err := registerImpactIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
 	}
-	return true
-}
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *NodeHealthCheckReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	// templates are read per reconcile per node, serve them from memory
+	r.templateCache = newTemplateCache(mgr.GetClient(), mgr.GetCache(), r.Log.WithName("templateCache"))
+
+	nodeSource := source.Source(&source.Kind{Type: &v1.Node{}})
+	if r.HostedCluster != nil {
+		// on Hypershift Nodes need to be watched in the hosted cluster
+		nodeSource = source.NewKindWithCache(&v1.Node{}, r.HostedCluster.GetCache())
+	}
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&remediationv1alpha1.NodeHealthCheck{}).
-		Watches(&source.Kind{Type: &v1.Node{}}, handler.EnqueueRequestsFromMapFunc(utils.NHCByNodeMapperFunc(mgr.GetClient(), mgr.GetLogger()))).
-		Complete(r)
+		Watches(nodeSource, handler.EnqueueRequestsFromMapFunc(utils.NHCByNodeMapperFunc(mgr.GetClient(), mgr.GetLogger())),
+			builder.WithPredicates(nodeUpdatePredicate())).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	// remediation CR kinds are watched at runtime, their CRDs may be installed later
+	return mgr.Add(newWatchManager(c, mgr.GetClient(), mgr.GetRESTMapper(), r.Log.WithName("watchManager")))
+}
+
+// nodeUpdatePredicate trims the node watch down to updates which can change a health
+// check result: condition transitions, label, taint and allocatable changes enqueue
+// immediately, keeping the time-to-detect sub-second, while kubelet heartbeats and
+// other status noise don't wake the reconcilers up at all.
+func nodeUpdatePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, oldIsNode := e.ObjectOld.(*v1.Node)
+			newNode, newIsNode := e.ObjectNew.(*v1.Node)
+			if !oldIsNode || !newIsNode {
+				return true
+			}
+			return nodeConditionsChanged(oldNode.Status.Conditions, newNode.Status.Conditions) ||
+				!reflect.DeepEqual(oldNode.GetLabels(), newNode.GetLabels()) ||
+				!reflect.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+				!reflect.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable)
+		},
+	}
+}
+
+// nodeConditionsChanged compares node conditions while ignoring the heartbeat
+// timestamps, which the kubelet refreshes continuously without semantic change
+func nodeConditionsChanged(old, new []v1.NodeCondition) bool {
+	if len(old) != len(new) {
+		return true
+	}
+	for i := range old {
+		if old[i].Type != new[i].Type ||
+			old[i].Status != new[i].Status ||
+			old[i].Reason != new[i].Reason ||
+			old[i].Message != new[i].Message ||
+			!old[i].LastTransitionTime.Equal(&new[i].LastTransitionTime) {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *NodeHealthCheckReconciler) remediate(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck, template *unstructured.Unstructured) (*time.Duration, error) {
 
 	log := utils.GetLogWithNHC(r.Log, nhc)
 
+	// make sure we hold the node's lease before remediating, in order not to
+	// interfere with other actors remediating the same node; passing the
+	// remediator kind lets the lease manager re-base the lease when the
+	// escalation moves to another step
+	remediatorKind, err := remediationv1alpha1.ParseRemediationKind(template.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
+	// never fence the node this operator runs on with an external remediator: the
+	// fencing would take the operator down mid-remediation. This matters on single
+	// node and compact topologies where the operator has no other node to move to.
+	// Self Node Remediation fences its own node safely via watchdog, it is exempt.
+	if operatorNode := utils.GetOperatorNodeName(); operatorNode == node.GetName() &&
+		!strings.HasPrefix(template.GetKind(), remediationv1alpha1.SelfNodeRemediationKindPrefix) {
+		msg := fmt.Sprintf("Skipped remediation of node %s, the operator runs on it and only Self Node Remediation can fence it safely", node.GetName())
+		log.Info(msg)
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonRemediationSkipped, msg)
+		return nil, nil
+	}
+	requeue, err := r.LeaseManager.ObtainNodeLease(ctx, node.GetName(), remediatorKind.String(),
+		r.remediationLeaseDuration(ctx, nhc, template), leasePolicy(nhc))
+	if err != nil {
+		if _, isHeld := err.(lease.AlreadyHeldError); isHeld {
+			r.surfaceReconcileError(nhc, leaseHeldError{nodeName: node.GetName()}, nil)
+			return requeue, nil
+		}
+		if _, isOverdue := err.(lease.OverdueError); isOverdue {
+			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonRemediationSkipped, "Remediation of node %s was held for too long without the node getting healthy, releasing its lease", node.GetName())
+			return nil, nil
+		}
+		return nil, categorized(err, metrics.ReconcileErrorCategoryLease)
+	}
+
 	cr, err := r.generateRemediationCR(node, nhc, template)
 	if err != nil {
 		return nil, err
 	}
 
+	// the escalation step of this attempt: 1 for the first remediator of an
+	// unhealthiness, growing when remediation moves to another remediator
+	escalationStep := 1
+
+	// an open remediation attempt of another kind is being escalated away from,
+	// e.g. because the NHC's remediation template changed mid-remediation
+	if previous, exists := nhc.Status.LastRemediationOutcomes[node.GetName()]; exists &&
+		previous.Result == "" && previous.Remediator != cr.GetKind() {
+		escalationStep = previous.Step + 1
+		if escalationStep == 1 {
+			// the previous attempt predates step tracking
+			escalationStep = 2
+		}
+		r.endRemediationOutcome(nhc, node, remediationv1alpha1.RemediationOutcomeEscalated)
+		metrics.ObserveNodeHealthCheckEscalated(previous.Remediator)
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonRemediationEscalated,
+			"Remediation of node %s escalated from %s to %s", node.GetName(), previous.Remediator, cr.GetKind())
+		r.sendNotification(ctx, notify.ReasonRemediationEscalated, nhc, node.GetName(), cr.GetKind(),
+			fmt.Sprintf("Remediation of node %s escalated from %s to %s", node.GetName(), previous.Remediator, cr.GetKind()))
+	}
+
 	// check if CR already exists
 	if err = r.Client.Get(ctx, client.ObjectKeyFromObject(cr), cr); err != nil {
 		if !apierrors.IsNotFound(err) {
@@ -386,14 +1095,98 @@ func (r *NodeHealthCheckReconciler) remediate(ctx context.Context, node *v1.Node
 			return nil, err
 		}
 
+		// estimate the blast radius before anything happens to the node, so
+		// approvers and post-mortems can judge the impact
+		r.recordRemediationImpact(ctx, node, nhc)
+
+		// with approvalRequired nothing happens to the node before an admin
+		// signed the remediation off, see applyApproval
+		if requeue, fence := r.applyApproval(node, nhc); !fence {
+			return requeue, nil
+		}
+
+		// with pre-fence draining the CR creation is delayed, so service traffic
+		// moves away from the node before fencing blackholes it
+		if requeue, fence := r.applyPreFenceDrain(ctx, node, nhc); !fence {
+			return requeue, nil
+		}
+
+		// with waitForLiveMigration the node's virtual machine instances get a
+		// chance to live-migrate away before fencing interrupts them
+		if requeue, fence := r.applyLiveMigration(ctx, node, nhc); !fence {
+			return requeue, nil
+		}
+
+		// capture a diagnostic bundle before remediation destroys the evidence on the node
+		if nhc.Spec.CaptureDiagnostics {
+			r.captureDiagnostics(ctx, node, nhc, cr.GetNamespace())
+		}
+
+		// tell the remediator why this node is remediated, so it can choose a
+		// fitting action, see the reason package for the contract
+		annotations := cr.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for key, value := range r.remediationReasonAnnotations(nhc, node, escalationStep) {
+			annotations[key] = value
+		}
+		// the idempotency token of this attempt: re-creating a CR for the still open
+		// attempt, e.g. after an operator restart, must carry the same token, so a
+		// remediator which already acted on it can detect the duplicate
+		attemptID := r.remediationAttemptID(nhc, node.GetName(), cr.GetKind())
+		annotations[reason.AttemptIDAnnotation] = attemptID
+		cr.SetAnnotations(annotations)
+
+		// sign the remediation decision for tamper evident audit trails
+		if r.DecisionSigner != nil {
+			if err := r.signDecision(ctx, cr, node, nhc); err != nil {
+				// don't block fencing on a signing problem, but make it visible
+				log.Error(err, "failed to sign the remediation decision")
+				r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonSigningFailed,
+					"Failed to sign the remediation decision for node %s: %s", node.GetName(), err.Error())
+			}
+		}
+
+		// pre-flight check of the rendered CR against the installed CRD schema,
+		// the API server's own errors for mismatches are cryptic or silent
+		r.checkRemediationSchema(ctx, nhc, cr)
+
 		// create CR
 		log.Info("node seems unhealthy. Creating an external remediation object",
 			"nodeName", node.Name, "CR name", cr.GetName(), "CR gvk", cr.GroupVersionKind(), "ns", cr.GetNamespace())
-		if err = r.Client.Create(ctx, cr); err != nil {
+		createStart := time.Now()
+		if err = r.Client.Create(ctx, cr, client.FieldOwner(fieldManager)); err != nil {
 			log.Error(err, "failed to create an external remediation object")
+			// persistent creation failures engage the fallback template, see templateForNode
+			if !apierrors.IsAlreadyExists(err) {
+				r.recordRemediationCreateFailure(nhc, node.GetName(), cr.GetKind())
+			}
+			if apierrors.IsForbidden(err) {
+				// usually a missing or mislabeled remediator role, see the rbac package
+				denied := rbacDeniedError{cause: err}
+				r.surfaceReconcileError(nhc, denied, nil)
+				return nil, denied
+			}
 			return nil, err
 		}
+		metrics.ObserveNodeHealthCheckRemediationCreation(nhc.Name, time.Since(createStart))
 		r.Recorder.Event(nhc, eventTypeNormal, eventReasonRemediationCreated, fmt.Sprintf("Created remediation object for node %s", node.Name))
+		metrics.ObserveNodeHealthCheckRemediationCreated(cr.GetKind())
+		if latency := detectionLatency(nhc, node); latency != nil {
+			metrics.ObserveNodeHealthCheckDetectionLatency(nhc.Name, *latency)
+		}
+		r.startRemediationOutcome(nhc, node, cr.GetKind(), escalationStep, attemptID)
+		r.sendNotification(ctx, notify.ReasonRemediationStarted, nhc, node.GetName(), cr.GetKind(),
+			fmt.Sprintf("Remediation of node %s started with %s", node.GetName(), cr.GetKind()))
+		if nhc.Spec.UseOutOfServiceTaint {
+			if err = r.addOutOfServiceTaint(ctx, node, nhc); err != nil {
+				return nil, err
+			}
+		}
+		if err = r.disableScaleDown(ctx, node, nhc); err != nil {
+			return nil, err
+		}
 		return nil, nil
 	}
 
@@ -408,26 +1201,356 @@ func (r *NodeHealthCheckReconciler) remediate(ctx context.Context, node *v1.Node
 		return nil, nil
 	}
 
+	// verify the idempotency token of the existing CR: a token of another attempt
+	// means the CR wasn't created for the unhealthiness being tracked, e.g. it
+	// survived from an earlier one, which the remediator should notice via the
+	// token contract, see the reason package
+	if outcome, exists := nhc.Status.LastRemediationOutcomes[node.GetName()]; exists && outcome.AttemptID != "" {
+		if crID := cr.GetAnnotations()[reason.AttemptIDAnnotation]; crID != "" && crID != outcome.AttemptID {
+			log.Info("the existing remediation CR carries another attempt's idempotency token",
+				"Node name", node.GetName(), "CR attempt", crID, "recorded attempt", outcome.AttemptID)
+		}
+	}
+
+	// make sure the taint and the scale-down protection are in place while remediation
+	// is in flight, they might be missing after an operator restart
+	if nhc.Spec.UseOutOfServiceTaint {
+		if err = r.addOutOfServiceTaint(ctx, node, nhc); err != nil {
+			return nil, err
+		}
+	}
+	if err = r.disableScaleDown(ctx, node, nhc); err != nil {
+		return nil, err
+	}
+
 	isAlert, nextReconcile := r.alertOldRemediationCR(cr)
 	if isAlert {
 		metrics.ObserveNodeHealthCheckOldRemediationCR(node.Name, node.Namespace)
+		// emit the event on the transition only, repeating it every reconcile would
+		// inflate the RecentActivity failure count
+		if outcome, exists := nhc.Status.LastRemediationOutcomes[node.GetName()]; exists && outcome.Result != remediationv1alpha1.RemediationOutcomeTimedOut {
+			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonRemediationTimedOut,
+				"Remediation of node %s runs for too long without the node getting healthy", node.GetName())
+		}
+		r.endRemediationOutcome(nhc, node, remediationv1alpha1.RemediationOutcomeTimedOut)
 	}
 	return nextReconcile, nil
 }
 
+// detectionLatency returns how long ago the node condition matching the NHC's unhealthy
+// criteria transitioned, the cluster's time-to-detect. Event, resource and CEL based
+// criteria carry no transition timestamp, so nil is returned when no condition matches.
+func detectionLatency(nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node) *time.Duration {
+	var earliest *metav1.Time
+	for _, unhealthy := range nhc.Spec.UnhealthyConditions {
+		for i := range node.Status.Conditions {
+			condition := &node.Status.Conditions[i]
+			if condition.Type != unhealthy.Type || condition.Status != unhealthy.Status {
+				continue
+			}
+			if earliest == nil || condition.LastTransitionTime.Before(earliest) {
+				earliest = &condition.LastTransitionTime
+			}
+		}
+	}
+	if earliest == nil {
+		return nil
+	}
+	latency := time.Since(earliest.Time)
+	return &latency
+}
+
+// sendNotification posts a remediation lifecycle notification when notification
+// providers are configured
+func (r *NodeHealthCheckReconciler) sendNotification(ctx context.Context, reason string, nhc *remediationv1alpha1.NodeHealthCheck, nodeName string, remediator string, message string) {
+	if r.Notifier == nil {
+		return
+	}
+	r.Notifier.Notify(ctx, notify.Notification{
+		Reason:          reason,
+		NodeHealthCheck: nhc.GetName(),
+		Node:            nodeName,
+		Remediator:      remediator,
+		Message:         message,
+		Timestamp:       time.Now(),
+	})
+}
+
+// startRemediationOutcome opens the node's entry in status.lastRemediationOutcomes for
+// a new remediation attempt
+func (r *NodeHealthCheckReconciler) startRemediationOutcome(nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node, remediator string, step int, attemptID string) {
+	if nhc.Status.LastRemediationOutcomes == nil {
+		nhc.Status.LastRemediationOutcomes = map[string]remediationv1alpha1.RemediationOutcome{}
+	}
+	nhc.Status.LastRemediationOutcomes[node.GetName()] = remediationv1alpha1.RemediationOutcome{
+		Remediator: remediator,
+		Started:    metav1.Time{Time: r.now()},
+		Step:       step,
+		AttemptID:  attemptID,
+		BootID:     node.Status.NodeInfo.BootID,
+	}
+}
+
+// remediationAttemptID returns the idempotency token for remediating the node with
+// / the given remediator kind: the token of the still open attempt with that kind when
+// one is recorded, a fresh one otherwise. Reusing the open attempt's token makes a
+// re-created CR recognizable as a duplicate, see the reason package for the contract.
+func (r *NodeHealthCheckReconciler) remediationAttemptID(nhc *remediationv1alpha1.NodeHealthCheck, nodeName string, remediator string) string {
+	if outcome, exists := nhc.Status.LastRemediationOutcomes[nodeName]; exists &&
+		outcome.Result == "" && outcome.Remediator == remediator && outcome.AttemptID != "" {
+		return outcome.AttemptID
+	}
+	return string(uuid.NewUUID())
+}
+
+// endRemediationOutcome records the result of the node's last remediation attempt.
+// The entry is kept after the remediation CR is deleted, so recurring offenders stay
+// identifiable. A TimedOut result is overwritten with Succeeded in case the node
+// turns healthy after all.
+func (r *NodeHealthCheckReconciler) endRemediationOutcome(nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node, result remediationv1alpha1.RemediationOutcomeResult) {
+	outcome, exists := nhc.Status.LastRemediationOutcomes[node.GetName()]
+	if !exists {
+		// a remediation started before outcome tracking existed
+		return
+	}
+	now := metav1.Time{Time: r.now()}
+	// a node which turned healthy with the boot it started remediation with was
+	// never fenced, it recovered on its own
+	selfRecovered := result == remediationv1alpha1.RemediationOutcomeSucceeded &&
+		outcome.BootID != "" && node.Status.NodeInfo.BootID == outcome.BootID
+	if recordOutcomeStats(nhc, outcome, result, selfRecovered, now.Time) {
+		r.updateTuningReport(context.Background(), nhc)
+	}
+	outcome.Ended = &now
+	outcome.Result = result
+	nhc.Status.LastRemediationOutcomes[node.GetName()] = outcome
+}
+
+// handleDeletedNodes applies spec.nodeDeletedPolicy to nodes which have an in-flight
+// remediation but whose Node object does not exist anymore
+func (r *NodeHealthCheckReconciler) handleDeletedNodes(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node) error {
+	if len(nhc.Status.InFlightRemediations) == 0 {
+		return nil
+	}
+	observed := make(map[string]struct{}, len(nodes))
+	for i := range nodes {
+		observed[nodes[i].GetName()] = struct{}{}
+	}
+	log := utils.GetLogWithNHC(r.Log, nhc)
+	for nodeName := range nhc.Status.InFlightRemediations {
+		if _, exists := observed[nodeName]; exists {
+			continue
+		}
+		node := &v1.Node{}
+		err := r.nodeClient().Get(ctx, client.ObjectKey{Name: nodeName}, node)
+		if err == nil {
+			// the node still exists, it just stopped matching the selector
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to check whether node %s still exists", nodeName)
+		}
+		switch nhc.Spec.NodeDeletedPolicy {
+		case remediationv1alpha1.NodeDeletedPolicyAbortAndCleanup:
+			log.Info("node was deleted during remediation, aborting and cleaning up", "Node name", nodeName)
+			if err := r.deleteRemediationCRs(ctx, nhc, nodeName); err != nil {
+				return err
+			}
+			delete(nhc.Status.UnhealthyNodeObservations, nodeName)
+			if err := r.LeaseManager.InvalidateNodeLease(ctx, nodeName); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonRemediationRemoved,
+				"Aborted remediation of node %s, the node was deleted", nodeName)
+		case remediationv1alpha1.NodeDeletedPolicyWaitForReplacement:
+			log.Info("node was deleted during remediation, waiting for its replacement", "Node name", nodeName)
+		default:
+			// CompleteRemediation: leave the remediation CR alone, the remediator finishes its work
+		}
+	}
+	return nil
+}
+
+// deleteRemediationCRs deletes the remediation CRs of the given node for all configured
+// templates, tolerating templates whose CRD isn't deployed
+func (r *NodeHealthCheckReconciler) deleteRemediationCRs(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, nodeName string) error {
+	templateRefs := []*v1.ObjectReference{nhc.Spec.RemediationTemplate, nhc.Spec.FallbackRemediationTemplate}
+	for i := range nhc.Spec.RemediationTemplates {
+		templateRefs = append(templateRefs, nhc.Spec.RemediationTemplates[i].RemediationTemplate)
+	}
+	for _, ref := range templateRefs {
+		if ref == nil {
+			continue
+		}
+		gvk, err := remediationv1alpha1.RemediationGVK(ref)
+		if err != nil {
+			// the webhook rejects such templates, old objects might still have them
+			utils.GetLogWithNHC(r.Log, nhc).Error(err, "skipping cleanup for a template not following the kind convention")
+			continue
+		}
+		cr := &unstructured.Unstructured{}
+		cr.SetGroupVersionKind(gvk)
+		cr.SetName(nodeName)
+		cr.SetNamespace(ref.Namespace)
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(cr), cr); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get the remediation CR of deleted node %s", nodeName)
+		}
+		if !isOwner(cr, nhc) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, cr); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete the remediation CR of deleted node %s", nodeName)
+		}
+	}
+	return nil
+}
+
+// isScalingDown returns true when the cluster autoscaler is actively removing the node
+func isScalingDown(node *v1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == autoscalerDeletionTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+// disableScaleDown prevents the cluster autoscaler from scaling down the node while
+// remediation is in flight, the autoscaler might otherwise remove it in parallel
+func (r *NodeHealthCheckReconciler) disableScaleDown(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	annotations := node.GetAnnotations()
+	if _, exists := annotations[scaleDownDisabledAnnotation]; exists {
+		return nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[scaleDownDisabledAnnotation] = "true"
+	annotations[scaleDownDisabledMarkerAnnotation] = "true"
+	node.SetAnnotations(annotations)
+	if err := r.nodeClient().Patch(ctx, node, patch); err != nil {
+		return errors.Wrapf(err, "failed to disable autoscaler scale-down of node %s", node.GetName())
+	}
+	utils.GetLogWithNHC(r.Log, nhc).Info("disabled autoscaler scale-down while remediation is in flight", "Node name", node.GetName())
+	return nil
+}
+
+// enableScaleDown removes the scale-down protection again, but only when it was this
+// operator which added it
+func (r *NodeHealthCheckReconciler) enableScaleDown(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	annotations := node.GetAnnotations()
+	if _, marked := annotations[scaleDownDisabledMarkerAnnotation]; !marked {
+		return nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	delete(annotations, scaleDownDisabledAnnotation)
+	delete(annotations, scaleDownDisabledMarkerAnnotation)
+	node.SetAnnotations(annotations)
+	if err := r.nodeClient().Patch(ctx, node, patch); err != nil {
+		return errors.Wrapf(err, "failed to re-enable autoscaler scale-down of node %s", node.GetName())
+	}
+	utils.GetLogWithNHC(r.Log, nhc).Info("re-enabled autoscaler scale-down", "Node name", node.GetName())
+	return nil
+}
+
+func hasOutOfServiceTaint(node *v1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == outOfServiceTaintKey && taint.Effect == v1.TaintEffectNoExecute {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *NodeHealthCheckReconciler) addOutOfServiceTaint(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	if hasOutOfServiceTaint(node) {
+		return nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	now := metav1.Now()
+	node.Spec.Taints = append(node.Spec.Taints, v1.Taint{
+		Key:       outOfServiceTaintKey,
+		Value:     outOfServiceTaintValue,
+		Effect:    v1.TaintEffectNoExecute,
+		TimeAdded: &now,
+	})
+	if err := r.nodeClient().Patch(ctx, node, patch); err != nil {
+		return errors.Wrapf(err, "failed to add the out-of-service taint to node %s", node.GetName())
+	}
+	utils.GetLogWithNHC(r.Log, nhc).Info("added out-of-service taint", "Node name", node.GetName())
+	r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonOutOfServiceTaint, "Added out-of-service taint to node %s", node.GetName())
+	return nil
+}
+
+func (r *NodeHealthCheckReconciler) removeOutOfServiceTaint(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	if !hasOutOfServiceTaint(node) {
+		return nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	taints := make([]v1.Taint, 0, len(node.Spec.Taints)-1)
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == outOfServiceTaintKey && taint.Effect == v1.TaintEffectNoExecute {
+			continue
+		}
+		taints = append(taints, taint)
+	}
+	node.Spec.Taints = taints
+	if err := r.nodeClient().Patch(ctx, node, patch); err != nil {
+		return errors.Wrapf(err, "failed to remove the out-of-service taint from node %s", node.GetName())
+	}
+	utils.GetLogWithNHC(r.Log, nhc).Info("removed out-of-service taint", "Node name", node.GetName())
+	r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonOutOfServiceUntaint, "Removed out-of-service taint from node %s", node.GetName())
+	return nil
+}
+
+// signDecision stamps a signed decision record onto the remediation CR, so auditors
+// can verify the fencing decision was made by this operator and was not tampered with
+func (r *NodeHealthCheckReconciler) signDecision(ctx context.Context, cr *unstructured.Unstructured, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) error {
+	decision, err := json.Marshal(map[string]string{
+		"nodeHealthCheck": nhc.GetName(),
+		"node":            node.GetName(),
+		"kind":            cr.GetKind(),
+		"timestamp":       time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	signature, err := r.DecisionSigner.Sign(ctx, decision)
+	if err != nil {
+		return err
+	}
+	annotations := cr.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[decisionAnnotationKey] = string(decision)
+	annotations[decisionSignatureAnnotationKey] = signature
+	cr.SetAnnotations(annotations)
+	return nil
+}
+
 func (r *NodeHealthCheckReconciler) generateRemediationCR(n *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck, template *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	templateSpec, found, err := unstructured.NestedMap(template.Object, "spec", "template")
 	if !found || err != nil {
 		return nil, errors.Errorf("Failed to retrieve Spec.Template on %v %q %v", template.GroupVersionKind(), template.GetName(), err)
 	}
 
+	remediationKind, err := remediationv1alpha1.ParseRemediationKind(template.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
 	u := unstructured.Unstructured{Object: templateSpec}
 	u.SetName(n.Name)
 	u.SetNamespace(template.GetNamespace())
 	u.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   template.GroupVersionKind().Group,
 		Version: template.GroupVersionKind().Version,
-		Kind:    strings.TrimSuffix(template.GetKind(), templateSuffix),
+		Kind:    remediationKind.String(),
 	})
 	u.SetOwnerReferences([]metav1.OwnerReference{
 		{
@@ -439,9 +1562,31 @@ func (r *NodeHealthCheckReconciler) generateRemediationCR(n *v1.Node, nhc *remed
 			BlockOwnerDeletion: nil,
 		},
 	})
-	u.SetLabels(map[string]string{
+	labels := map[string]string{
 		"app.kubernetes.io/part-of": "node-healthcheck-controller",
-	})
+	}
+	// propagate the NHC's own remediation labels, for tracking and policy engines
+	for key, value := range nhc.GetLabels() {
+		if strings.HasPrefix(key, labelPropagationPrefix) {
+			labels[key] = value
+		}
+	}
+	if nhc.Spec.RemediationCRTemplateMetadata != nil {
+		for key, value := range nhc.Spec.RemediationCRTemplateMetadata.Labels {
+			labels[key] = value
+		}
+		if len(nhc.Spec.RemediationCRTemplateMetadata.Annotations) > 0 {
+			annotations := u.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			for key, value := range nhc.Spec.RemediationCRTemplateMetadata.Annotations {
+				annotations[key] = value
+			}
+			u.SetAnnotations(annotations)
+		}
+	}
+	u.SetLabels(labels)
 	u.SetResourceVersion("")
 	u.SetFinalizers(nil)
 	u.SetUID("")
@@ -450,8 +1595,15 @@ func (r *NodeHealthCheckReconciler) generateRemediationCR(n *v1.Node, nhc *remed
 	return &u, nil
 }
 
-func (r *NodeHealthCheckReconciler) fetchTemplate(nhc *remediationv1alpha1.NodeHealthCheck) (*unstructured.Unstructured, error) {
-	t := nhc.Spec.RemediationTemplate.DeepCopy()
+func (r *NodeHealthCheckReconciler) fetchTemplate(templateRef *v1.ObjectReference) (*unstructured.Unstructured, error) {
+	if r.templateCache != nil {
+		obj, err := r.templateCache.get(context.Background(), templateRef)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve %s external remdiation template %q/%q", templateRef.Kind, templateRef.Namespace, templateRef.Name)
+		}
+		return obj, nil
+	}
+	t := templateRef.DeepCopy()
 	obj := new(unstructured.Unstructured)
 	obj.SetAPIVersion(t.APIVersion)
 	obj.SetGroupVersionKind(t.GroupVersionKind())
@@ -463,15 +1615,105 @@ func (r *NodeHealthCheckReconciler) fetchTemplate(nhc *remediationv1alpha1.NodeH
 	return obj, nil
 }
 
+// categorizedError tags an error with the subsystem it came from, for the reconcile
+// errors metric
+type categorizedError struct {
+	error
+	category string
+}
+
+func categorized(err error, category string) error {
+	if err == nil {
+		return nil
+	}
+	return categorizedError{error: err, category: category}
+}
+
+// Cause implements the pkg/errors causer interface, the category tag is transparent
+// for callers inspecting the underlying error
+func (e categorizedError) Cause() error {
+	return e.error
+}
+
+// reconcileErrorCategory buckets a reconcile error for the reconcile errors metric
+func reconcileErrorCategory(err error) string {
+	if cerr, isCategorized := err.(categorizedError); isCategorized {
+		return cerr.category
+	}
+	cause := errors.Cause(err)
+	switch cause.(type) {
+	case templateNotFoundError, templateLocationError:
+		return metrics.ReconcileErrorCategoryTemplate
+	case leaseHeldError:
+		return metrics.ReconcileErrorCategoryLease
+	case rbacDeniedError:
+		return metrics.ReconcileErrorCategoryRBAC
+	}
+	if apierrors.IsForbidden(cause) || apierrors.IsUnauthorized(cause) {
+		return metrics.ReconcileErrorCategoryRBAC
+	}
+	return metrics.ReconcileErrorCategoryOther
+}
+
+// backoffOnThrottling converts 429 responses from the API server into a growing requeue
+// delay. Returning the error instead would make the workqueue retry quickly and add even
+// more load to an already throttled API server. The delay doubles with consecutive 429s,
+// honors a Retry-After suggested by the server, and resets on the first successful reconcile.
+func (r *NodeHealthCheckReconciler) backoffOnThrottling(result ctrl.Result, err error) (ctrl.Result, error) {
+	if err == nil {
+		r.throttleBackoff = 0
+		return result, nil
+	}
+	if !apierrors.IsTooManyRequests(errors.Cause(err)) {
+		return result, err
+	}
+	if r.throttleBackoff < minThrottleBackoff {
+		r.throttleBackoff = minThrottleBackoff
+	} else if r.throttleBackoff *= 2; r.throttleBackoff > maxThrottleBackoff {
+		r.throttleBackoff = maxThrottleBackoff
+	}
+	backoff := r.throttleBackoff
+	if seconds, suggested := apierrors.SuggestsClientDelay(errors.Cause(err)); suggested {
+		if suggestedDelay := time.Duration(seconds) * time.Second; suggestedDelay > backoff {
+			backoff = suggestedDelay
+		}
+	}
+	r.Log.Info("the API server is throttling requests, backing off", "requeue after", backoff, "error", err.Error())
+	return ctrl.Result{RequeueAfter: backoff}, nil
+}
+
+// setStatusCondition sets the condition with the NHC's current generation recorded,
+// GitOps tools compare it with metadata.generation to tell stale conditions apart
+func setStatusCondition(nhc *remediationv1alpha1.NodeHealthCheck, condition metav1.Condition) {
+	condition.ObservedGeneration = nhc.Generation
+	meta.SetStatusCondition(&nhc.Status.Conditions, condition)
+}
+
 func (r *NodeHealthCheckReconciler) patchStatus(nhc, nhcOrig *remediationv1alpha1.NodeHealthCheck) error {
 
 	log := utils.GetLogWithNHC(r.Log, nhc)
 
+	// the spec of this generation was fully processed when the status is written
+	nhc.Status.ObservedGeneration = nhc.Generation
+
+	// roll the per node details up into the indexable summary,
+	// see remediationv1alpha1.RegisterSummaryIndexes
+	nhc.Status.Summary = &remediationv1alpha1.RemediationSummary{
+		UnhealthyCount: nhc.Status.ObservedNodes - nhc.Status.HealthyNodes,
+		InFlightCount:  len(nhc.Status.InFlightRemediations),
+	}
+
+	r.updateRecentActivityCondition(nhc)
+	r.updateApprovalPendingCondition(nhc)
+
 	// calculate phase and reason
 	disabledCondition := meta.FindStatusCondition(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled)
 	if disabledCondition != nil && disabledCondition.Status == metav1.ConditionTrue {
 		nhc.Status.Phase = remediationv1alpha1.PhaseDisabled
 		nhc.Status.Reason = fmt.Sprintf("NHC is disabled: %s: %s", disabledCondition.Reason, disabledCondition.Message)
+	} else if utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeGloballyPaused, remediationv1alpha1.ConditionReasonGlobalPauseConfigMap) {
+		nhc.Status.Phase = remediationv1alpha1.PhasePaused
+		nhc.Status.Reason = "NHC is paused: the global remediation pause is active"
 	} else if len(nhc.Spec.PauseRequests) > 0 {
 		nhc.Status.Phase = remediationv1alpha1.PhasePaused
 		nhc.Status.Reason = fmt.Sprintf("NHC is paused: %s", strings.Join(nhc.Spec.PauseRequests, ","))
@@ -487,41 +1729,131 @@ func (r *NodeHealthCheckReconciler) patchStatus(nhc, nhcOrig *remediationv1alpha
 
 	// check if there are any changes.
 	// reflect.DeepEqual does not work, it has many false positives!
-	if patchBytes, err := mergeFrom.Data(nhc); err != nil {
+	patchBytes, err := mergeFrom.Data(nhc)
+	if err != nil {
 		log.Error(err, "failed to create patch")
 		return err
-	} else if string(patchBytes) == "{}" {
+	}
+	if string(patchBytes) == "{}" {
 		// no change
 		return nil
 	}
 
+	// drop pure timestamp churn below the resolution before patching, it has no
+	// semantic value but spams the API server and watchers on every reconcile
+	normalizeStatusTimestamps(nhc, nhcOrig)
+	if patchBytes, err = mergeFrom.Data(nhc); err != nil {
+		log.Error(err, "failed to create patch")
+		return err
+	} else if string(patchBytes) == "{}" {
+		metrics.ObserveNodeHealthCheckSuppressedStatusUpdate(nhc.Name)
+		return nil
+	}
+
 	log.Info("Patching NHC status", "new status", nhc.Status)
-	return r.Client.Status().Patch(context.Background(), nhc, mergeFrom, &client.PatchOptions{})
+	// the status is only written by this operator, a merge patch with our field manager
+	// attributes the ownership without having to apply the full object
+	return r.Client.Status().Patch(context.Background(), nhc, mergeFrom, &client.PatchOptions{FieldManager: fieldManager})
 }
 
-func (r *NodeHealthCheckReconciler) getInflightRemediations(nhc *remediationv1alpha1.NodeHealthCheck, template *unstructured.Unstructured) (map[string]metav1.Time, error) {
-	cr, err := r.generateRemediationCR(&v1.Node{}, nhc, template)
-	if err != nil {
-		return nil, err
+func (r *NodeHealthCheckReconciler) getInflightRemediations(nhc *remediationv1alpha1.NodeHealthCheck, resolver *templateResolver, nodes []v1.Node) (map[string]metav1.Time, []remediationv1alpha1.InFlightRemediation, error) {
+	observed := make(map[string]struct{}, len(nodes))
+	for i := range nodes {
+		observed[nodes[i].GetName()] = struct{}{}
 	}
-	crList := &unstructured.UnstructuredList{Object: cr.Object}
-	err = r.Client.List(context.Background(), crList)
 
-	if err != nil && !apierrors.IsNotFound(err) {
-		return nil,
-			errors.Wrapf(err, "failed to fetch all remediation objects from kind %s and apiVersion %s",
-				cr.GroupVersionKind(),
-				cr.GetAPIVersion())
+	remediations := make(map[string]metav1.Time)
+	var details []remediationv1alpha1.InFlightRemediation
+	for _, template := range resolver.Templates() {
+		cr, err := r.generateRemediationCR(&v1.Node{}, nhc, template)
+		if err != nil {
+			return nil, nil, err
+		}
+		crList := &unstructured.UnstructuredList{Object: cr.Object}
+		err = r.Client.List(context.Background(), crList)
+
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, nil,
+				errors.Wrapf(err, "failed to fetch all remediation objects from kind %s and apiVersion %s",
+					cr.GroupVersionKind(),
+					cr.GetAPIVersion())
+		}
+
+		for _, remediationCR := range crList.Items {
+			if isOwner(&remediationCR, nhc) {
+				remediations[remediationCR.GetName()] = remediationCR.GetCreationTimestamp()
+				details = append(details, r.inFlightRemediationDetail(nhc, &remediationCR, observed))
+				continue
+			}
+		}
 	}
+	sort.Slice(details, func(i, j int) bool {
+		if details[i].NodeName != details[j].NodeName {
+			return details[i].NodeName < details[j].NodeName
+		}
+		return details[i].Resource.Kind < details[j].Resource.Kind
+	})
+	return remediations, details, nil
+}
 
-	remediations := make(map[string]metav1.Time)
-	for _, remediationCR := range crList.Items {
-		if isOwner(&remediationCR, nhc) {
-			remediations[remediationCR.GetName()] = remediationCR.GetCreationTimestamp()
-			continue
+// inFlightRemediationDetail builds the structured status entry of one in-flight
+// remediation CR, see remediationv1alpha1.InFlightRemediation
+func (r *NodeHealthCheckReconciler) inFlightRemediationDetail(nhc *remediationv1alpha1.NodeHealthCheck, cr *unstructured.Unstructured, observed map[string]struct{}) remediationv1alpha1.InFlightRemediation {
+	detail := remediationv1alpha1.InFlightRemediation{
+		NodeName: cr.GetName(),
+		Resource: v1.ObjectReference{
+			APIVersion: cr.GetAPIVersion(),
+			Kind:       cr.GetKind(),
+			Name:       cr.GetName(),
+			Namespace:  cr.GetNamespace(),
+		},
+		Started: cr.GetCreationTimestamp(),
+		State:   remediationv1alpha1.InFlightStateRemediating,
+	}
+	if outcome, exists := nhc.Status.LastRemediationOutcomes[detail.NodeName]; exists && outcome.Result == "" {
+		detail.Step = outcome.Step
+	}
+	if _, exists := observed[detail.NodeName]; !exists {
+		// the node might just have stopped matching the selector, only a missing
+		// Node object makes the remediation NodeDeleted
+		err := r.nodeClient().Get(context.Background(), client.ObjectKey{Name: detail.NodeName}, &v1.Node{})
+		if apierrors.IsNotFound(err) {
+			detail.State = remediationv1alpha1.InFlightStateNodeDeleted
+		}
+	}
+	return detail
+}
+
+// normalizeStatusTimestamps keeps the previous timestamps in the new status when they
+// moved by less than statusTimestampResolution, so pure timestamp churn doesn't cause
+// a status update on every reconcile
+func normalizeStatusTimestamps(nhc, nhcOrig *remediationv1alpha1.NodeHealthCheck) {
+	for name, observed := range nhc.Status.InFlightRemediations {
+		if orig, exists := nhcOrig.Status.InFlightRemediations[name]; exists && isSameTime(observed, orig) {
+			nhc.Status.InFlightRemediations[name] = orig
 		}
 	}
-	return remediations, nil
+	for name, observed := range nhc.Status.UnhealthyNodeObservations {
+		if orig, exists := nhcOrig.Status.UnhealthyNodeObservations[name]; exists && isSameTime(observed, orig) {
+			nhc.Status.UnhealthyNodeObservations[name] = orig
+		}
+	}
+	for key, observation := range nhc.Status.ResourceObservations {
+		orig, exists := nhcOrig.Status.ResourceObservations[key]
+		if exists && observation.ZeroSince != nil && orig.ZeroSince != nil && isSameTime(*observation.ZeroSince, *orig.ZeroSince) {
+			observation.ZeroSince = orig.ZeroSince
+			nhc.Status.ResourceObservations[key] = observation
+		}
+	}
+}
+
+// isSameTime returns true when the two timestamps differ by less than the resolution
+func isSameTime(a, b metav1.Time) bool {
+	diff := a.Time.Sub(b.Time)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff < statusTimestampResolution
 }
 
 func (r *NodeHealthCheckReconciler) alertOldRemediationCR(remediationCR *unstructured.Unstructured) (bool, *time.Duration) {
@@ -529,7 +1861,7 @@ func (r *NodeHealthCheckReconciler) alertOldRemediationCR(remediationCR *unstruc
 	isSendAlert := false
 	var nextReconcile *time.Duration = nil
 	//verify remediationCR is old
-	now := time.Now()
+	now := r.now()
 	if now.After(remediationCR.GetCreationTimestamp().Add(remediationCRAlertTimeout)) {
 		var remediationCrAnnotations map[string]string
 		if remediationCrAnnotations = remediationCR.GetAnnotations(); remediationCrAnnotations == nil {
@@ -537,12 +1869,17 @@ func (r *NodeHealthCheckReconciler) alertOldRemediationCR(remediationCR *unstruc
 		}
 		//verify this is the first alert for this remediationCR
 		if _, isAlertedSent := remediationCrAnnotations[oldRemediationCRAnnotationKey]; !isAlertedSent {
-			remediationCrAnnotations[oldRemediationCRAnnotationKey] = "flagon"
-			remediationCR.SetAnnotations(remediationCrAnnotations)
-			if err := r.Client.Update(context.TODO(), remediationCR); err == nil {
+			// server side apply only the annotation with our field manager, so we neither
+			// conflict with nor overwrite fields the remediator owns on the same CR
+			flag := &unstructured.Unstructured{}
+			flag.SetGroupVersionKind(remediationCR.GroupVersionKind())
+			flag.SetName(remediationCR.GetName())
+			flag.SetNamespace(remediationCR.GetNamespace())
+			flag.SetAnnotations(map[string]string{oldRemediationCRAnnotationKey: "flagon"})
+			if err := r.Client.Patch(context.TODO(), flag, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err == nil {
 				isSendAlert = true
 			} else {
-				r.Log.Error(err, "Setting `old remediationCR` annotation on remediation CR %s: failed to update: %v", remediationCR.GetName(), err)
+				r.Log.Error(err, "Setting `old remediationCR` annotation on remediation CR %s: failed to patch: %v", remediationCR.GetName(), err)
 			}
 
 		}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -10,8 +11,11 @@ import (
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -20,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,10 +32,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+
 	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
 	"github.com/medik8s/node-healthcheck-operator/controllers/cluster"
+	"github.com/medik8s/node-healthcheck-operator/controllers/events"
+	"github.com/medik8s/node-healthcheck-operator/controllers/lease"
 	"github.com/medik8s/node-healthcheck-operator/controllers/mhc"
+	"github.com/medik8s/node-healthcheck-operator/controllers/pooldisable"
+	"github.com/medik8s/node-healthcheck-operator/controllers/rbac"
+	"github.com/medik8s/node-healthcheck-operator/controllers/safemode"
+	"github.com/medik8s/node-healthcheck-operator/controllers/storage"
 	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+	"github.com/medik8s/node-healthcheck-operator/reason"
+	"github.com/medik8s/node-healthcheck-operator/test/framework"
 )
 
 var _ = Describe("Node Health Check CR", func() {
@@ -160,28 +175,45 @@ var _ = Describe("Node Health Check CR", func() {
 			objects         []runtime.Object
 			reconciler      NodeHealthCheckReconciler
 			upgradeChecker  fakeClusterUpgradeChecker
+			platformChecker fakePlatformHealthChecker
 			mhcChecker      mhc.DummyChecker
+			safeModeChecker safemode.Checker
+			eventCounts     map[string]int
+			fakeClock       *clocktesting.FakeClock
 			reconcileError  error
 			reconcileResult controllerruntime.Result
 			getNHCError     error
 		)
 
 		var setupObjects = func(unhealthy int, healthy int) {
-			objects = newNodes(unhealthy, healthy)
-			underTest = newNodeHealthCheck()
-			remediationTemplate := newRemediationTemplate()
-			objects = append(objects, underTest, remediationTemplate)
+			objects = framework.NewNodes(unhealthy, healthy)
+			underTest = framework.NewNodeHealthCheck()
+			remediationTemplate := framework.NewRemediationTemplate()
+			// the template's namespace, the controller verifies it exists
+			templateNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+			objects = append(objects, underTest, remediationTemplate, templateNamespace)
+			eventCounts = nil
+			platformChecker = fakePlatformHealthChecker{}
+			safeModeChecker = nil
+			fakeClock = clocktesting.NewFakeClock(time.Now())
 		}
 
 		JustBeforeEach(func() {
-			client := fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+			client := ssaFakeClient{fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()}
 			reconciler = NodeHealthCheckReconciler{
 				Client:                      client,
 				Log:                         controllerruntime.Log.WithName("NHC Test Reconciler"),
 				Scheme:                      scheme.Scheme,
 				ClusterUpgradeStatusChecker: &upgradeChecker,
+				PlatformHealthChecker:       &platformChecker,
 				MHCChecker:                  mhcChecker,
+				SafeModeChecker:             safeModeChecker,
+				LeaseManager:                lease.NewManager(client, "default", fakeClock, controllerruntime.Log.WithName("NHC Test Lease Manager")),
+				EventsAggregator:            fakeEventsAggregator{counts: eventCounts},
+				StorageDetachChecker:        storage.NewDetachChecker(client),
+				PoolDisableResolver:         pooldisable.NewOpenshiftResolver(client),
 				Recorder:                    record.NewFakeRecorder(20),
+				clock:                       fakeClock,
 			}
 			reconcileResult, reconcileError = reconciler.Reconcile(
 				context.Background(),
@@ -199,7 +231,7 @@ var _ = Describe("Node Health Check CR", func() {
 
 			It("create a remediation CR for each unhealthy node", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				cr := newRemediationCR("unhealthy-node-1")
+				cr := framework.NewRemediationCR("unhealthy-node-1")
 				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(cr.Object).To(ContainElement(map[string]interface{}{"size": "foo"}))
@@ -211,6 +243,10 @@ var _ = Describe("Node Health Check CR", func() {
 						Controller: pointer.BoolPtr(false),
 					}))
 				Expect(cr.GetAnnotations()[oldRemediationCRAnnotationKey]).To(BeEmpty())
+				Expect(cr.GetAnnotations()[reason.TriggerAnnotation]).To(Equal(reason.TriggerCondition))
+				Expect(cr.GetAnnotations()[reason.UnhealthyConditionsAnnotation]).To(Equal("Ready=False:5m0s"))
+				Expect(cr.GetAnnotations()[reason.EscalationStepAnnotation]).To(Equal("1"))
+				Expect(cr.GetAnnotations()[reason.AttemptIDAnnotation]).NotTo(BeEmpty())
 			})
 
 			It("succeeds and correctly updates the status", func() {
@@ -219,6 +255,14 @@ var _ = Describe("Node Health Check CR", func() {
 				Expect(underTest.Status.HealthyNodes).To(Equal(2))
 				Expect(underTest.Status.ObservedNodes).To(Equal(3))
 				Expect(underTest.Status.InFlightRemediations).To(HaveLen(1))
+				Expect(underTest.Status.InFlightRemediationDetails).To(HaveLen(1))
+				detail := underTest.Status.InFlightRemediationDetails[0]
+				Expect(detail.NodeName).To(Equal("unhealthy-node-1"))
+				Expect(detail.Resource.Kind).To(Equal("InfrastructureRemediation"))
+				Expect(detail.Resource.APIVersion).To(Equal("test.medik8s.io/v1alpha1"))
+				Expect(detail.Step).To(Equal(1))
+				Expect(detail.Started.IsZero()).To(BeFalse())
+				Expect(detail.State).To(Equal(v1alpha1.InFlightStateRemediating))
 				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseRemediating))
 				Expect(underTest.Status.Reason).ToNot(BeEmpty())
 				Expect(underTest.Status.Conditions).To(ContainElement(
@@ -230,402 +274,1498 @@ var _ = Describe("Node Health Check CR", func() {
 
 			})
 
+			It("records an open remediation outcome for the unhealthy node", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+				outcome, exists := underTest.Status.LastRemediationOutcomes["unhealthy-node-1"]
+				Expect(exists).To(BeTrue())
+				Expect(outcome.Remediator).To(Equal("InfrastructureRemediation"))
+				Expect(outcome.Started.IsZero()).To(BeFalse())
+				Expect(outcome.Result).To(BeEmpty())
+				Expect(outcome.Ended).To(BeNil())
+				Expect(outcome.AttemptID).NotTo(BeEmpty())
+			})
+
+			It("stamps the open attempt's idempotency token on a re-created CR", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+				attemptID := underTest.Status.LastRemediationOutcomes["unhealthy-node-1"].AttemptID
+				Expect(attemptID).NotTo(BeEmpty())
+
+				// simulate the CR getting lost, e.g. deleted while the operator was down
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)).To(Succeed())
+				Expect(cr.GetAnnotations()[reason.AttemptIDAnnotation]).To(Equal(attemptID))
+				Expect(reconciler.Delete(context.Background(), &cr)).To(Succeed())
+
+				_, err := reconciler.Reconcile(
+					context.Background(),
+					controllerruntime.Request{NamespacedName: types.NamespacedName{Name: underTest.Name}})
+				Expect(err).NotTo(HaveOccurred())
+
+				recreated := framework.NewRemediationCR("unhealthy-node-1")
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: recreated.GetNamespace(), Name: recreated.GetName()}, &recreated)).To(Succeed())
+				Expect(recreated.GetAnnotations()[reason.AttemptIDAnnotation]).To(Equal(attemptID))
+			})
+
 		})
 
-		When("few nodes are unhealthy and healthy nodes above min healthy", func() {
+		When("the out-of-service taint is enabled", func() {
 			BeforeEach(func() {
-				setupObjects(4, 3)
+				setupObjects(1, 2)
+				underTest.Spec.UseOutOfServiceTaint = true
 			})
 
-			It("skips remediation - CR is not created, status updated correctly", func() {
+			It("taints the unhealthy node while remediating", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				Expect(getNHCError).NotTo(HaveOccurred())
-				o := newRemediationCR("unhealthy-node-1")
-				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: o.GetNamespace(),
-					Name: o.GetName()}, &o)
-				Expect(errors.IsNotFound(err)).To(BeTrue())
-				Expect(underTest.Status.HealthyNodes).To(Equal(3))
-				Expect(underTest.Status.ObservedNodes).To(Equal(7))
-				Expect(underTest.Status.InFlightRemediations).To(BeEmpty())
-				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseEnabled))
-				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+				node := &v1.Node{}
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Name: "unhealthy-node-1"}, node)).To(Succeed())
+				Expect(node.Spec.Taints).To(ContainElement(HaveField("Key", outOfServiceTaintKey)))
 			})
 
+			It("does not taint the healthy nodes", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				node := &v1.Node{}
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Name: "healthy-node-1"}, node)).To(Succeed())
+				Expect(node.Spec.Taints).To(BeEmpty())
+			})
 		})
 
-		When("few nodes become healthy", func() {
+		When("the autoscaler interferes with remediation", func() {
 			BeforeEach(func() {
 				setupObjects(1, 2)
-				remediationCR := newRemediationCR("healthy-node-2")
-				remediationCROther := newRemediationCR("healthy-node-1")
-				refs := remediationCROther.GetOwnerReferences()
-				refs[0].Name = "other"
-				remediationCROther.SetOwnerReferences(refs)
-				objects = append(objects, remediationCR.DeepCopy(), remediationCROther.DeepCopy())
 			})
 
-			It("deletes an existing remediation CR", func() {
+			It("disables autoscaler scale-down of the node under remediation", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				Expect(getNHCError).NotTo(HaveOccurred())
+				node := &v1.Node{}
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Name: "unhealthy-node-1"}, node)).To(Succeed())
+				Expect(node.GetAnnotations()).To(HaveKeyWithValue(scaleDownDisabledAnnotation, "true"))
+			})
 
-				cr := newRemediationCR("unhealthy-node-1")
-				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
-				Expect(err).NotTo(HaveOccurred())
+			When("the autoscaler is already removing the unhealthy node", func() {
+				BeforeEach(func() {
+					for _, o := range objects {
+						if node, isNode := o.(*v1.Node); isNode && node.GetName() == "unhealthy-node-1" {
+							node.Spec.Taints = append(node.Spec.Taints, v1.Taint{
+								Key:    autoscalerDeletionTaintKey,
+								Effect: v1.TaintEffectNoSchedule,
+							})
+						}
+					}
+				})
+
+				It("does not remediate it in parallel", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("unhealthy-node-1")
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+				})
+			})
+		})
 
-				cr = newRemediationCR("healthy-node-2")
-				err = reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
-				Expect(errors.IsNotFound(err)).To(BeTrue())
+		When("canary remediation is enabled", func() {
+			BeforeEach(func() {
+				setupObjects(2, 2)
+				underTest.Spec.Canary = &v1alpha1.CanarySpec{Enabled: true}
+			})
 
-				// owned by other NHC, should not be deleted
-				cr = newRemediationCR("healthy-node-1")
-				err = reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
-				Expect(err).NotTo(HaveOccurred())
+			It("remediates a single node first and holds the rest back", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(underTest.Status.Canary).NotTo(BeNil())
+				canaryNode := underTest.Status.Canary.Node
+				Expect(canaryNode).NotTo(BeEmpty())
+				for _, name := range []string{"unhealthy-node-1", "unhealthy-node-2"} {
+					cr := framework.NewRemediationCR(name)
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					if name == canaryNode {
+						Expect(err).NotTo(HaveOccurred())
+					} else {
+						Expect(errors.IsNotFound(err)).To(BeTrue())
+					}
+				}
 			})
 
-			It("updates the NHC status correctly", func() {
+			When("the canary already passed", func() {
+				BeforeEach(func() {
+					underTest.Status.Canary = &v1alpha1.CanaryStatus{
+						ObservedConditionsHash: unhealthyCriteriaHash(underTest),
+						Node:                   "unhealthy-node-1",
+						Passed:                 true,
+					}
+				})
+
+				It("remediates all unhealthy nodes", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					for _, name := range []string{"unhealthy-node-1", "unhealthy-node-2"} {
+						cr := framework.NewRemediationCR(name)
+						Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)).To(Succeed())
+					}
+				})
+			})
+		})
+
+		When("the unhealthy criteria changed under a change rollout policy", func() {
+			BeforeEach(func() {
+				setupObjects(2, 2)
+				underTest.Spec.ChangeRolloutPolicy = &v1alpha1.ChangeRolloutPolicy{}
+				underTest.Status.ChangeRollout = &v1alpha1.ChangeRolloutStatus{ObservedConditionsHash: "outdated"}
+			})
+
+			It("holds back remediation during the grace period", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				Expect(getNHCError).NotTo(HaveOccurred())
-				Expect(underTest.Status.HealthyNodes).To(Equal(2))
-				Expect(underTest.Status.ObservedNodes).To(Equal(3))
-				Expect(underTest.Status.InFlightRemediations).To(HaveLen(1))
-				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseRemediating))
-				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+				for _, name := range []string{"unhealthy-node-1", "unhealthy-node-2"} {
+					cr := framework.NewRemediationCR(name)
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+				}
+				Expect(underTest.Status.ChangeRollout.ChangedAt).NotTo(BeNil())
+				Expect(underTest.Status.ChangeRollout.Completed).To(BeFalse())
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically("<=", 5*time.Minute))
+			})
+
+			When("the grace period is over", func() {
+				BeforeEach(func() {
+					changedAt := metav1.Time{Time: time.Now().Add(-10 * time.Minute)}
+					underTest.Status.ChangeRollout = &v1alpha1.ChangeRolloutStatus{
+						ObservedConditionsHash: unhealthyCriteriaHash(underTest),
+						ChangedAt:              &changedAt,
+					}
+				})
+
+				It("remediates with limited concurrency", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					created := 0
+					for _, name := range []string{"unhealthy-node-1", "unhealthy-node-2"} {
+						cr := framework.NewRemediationCR(name)
+						if err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr); err == nil {
+							created++
+						}
+					}
+					Expect(created).To(Equal(1))
+					Expect(underTest.Status.ChangeRollout.Completed).To(BeFalse())
+				})
 			})
 		})
 
-		When("an old remediation cr exist", func() {
+		When("diagnostics capture is enabled", func() {
 			BeforeEach(func() {
 				setupObjects(1, 2)
-				remediationCR := newRemediationCR("unhealthy-node-1")
-				remediationCR.SetCreationTimestamp(metav1.Time{Time: time.Now().Add(-remediationCRAlertTimeout - 2*time.Minute)})
-				objects = append(objects, remediationCR.DeepCopyObject())
+				underTest.Spec.CaptureDiagnostics = true
 			})
 
-			It("an alert flag is set on remediation cr", func() {
+			It("snapshots the unhealthy node's data into a ConfigMap", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				Expect(getNHCError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				cm := &v1.ConfigMap{}
+				Expect(reconciler.Get(context.Background(),
+					ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: "nhc-diagnostics-unhealthy-node-1"}, cm)).To(Succeed())
+				Expect(cm.Data).To(HaveKey("conditions"))
+				Expect(cm.Data["conditions"]).To(ContainSubstring("Ready"))
+			})
 
-				actualRemediationCR := new(unstructured.Unstructured)
-				actualRemediationCR.SetKind(strings.TrimSuffix(underTest.Spec.RemediationTemplate.Kind, templateSuffix))
-				actualRemediationCR.SetAPIVersion(underTest.Spec.RemediationTemplate.APIVersion)
-				key := client.ObjectKey{Name: "unhealthy-node-1", Namespace: "default"}
-				err := reconciler.Client.Get(context.Background(), key, actualRemediationCR)
-				Expect(err).NotTo(HaveOccurred())
-				Expect(actualRemediationCR.GetAnnotations()[oldRemediationCRAnnotationKey]).To(Equal("flagon"))
+			It("does not snapshot healthy nodes", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cm := &v1.ConfigMap{}
+				err := reconciler.Get(context.Background(),
+					ctrlruntimeclient.ObjectKey{Namespace: "default", Name: "nhc-diagnostics-healthy-node-1"}, cm)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
 			})
 		})
 
-		When("remediation is needed but pauseRequests exists", func() {
+		When("a node turns unhealthy and an observation window is configured", func() {
 			BeforeEach(func() {
 				setupObjects(1, 2)
-				underTest.Spec.PauseRequests = []string{"I'm an admin, asking you to stop remediating this group of nodes"}
+				underTest.Spec.ObservationWindow = &metav1.Duration{Duration: time.Minute * 5}
 			})
 
-			It("should reconcile successfully", func() {
-				Expect(reconcileError).ShouldNot(HaveOccurred())
-			})
+			It("records the observation and delays remediation until the window passed", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
 
-			It("skips remediation - CR is not created", func() {
-				o := newRemediationCR("unhealthy-node-1")
+				o := framework.NewRemediationCR("unhealthy-node-1")
 				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: o.GetNamespace(),
 					Name: o.GetName()}, &o)
 				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				Expect(underTest.Status.UnhealthyNodeObservations).To(HaveKey("unhealthy-node-1"))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
 			})
 
-			It("updates the NHC status", func() {
-				Expect(getNHCError).NotTo(HaveOccurred())
-				Expect(underTest.Status.HealthyNodes).To(Equal(2))
-				Expect(underTest.Status.ObservedNodes).To(Equal(3))
-				Expect(underTest.Status.InFlightRemediations).To(BeEmpty())
-				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhasePaused))
-				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+			It("remediates once the fast-forwarded clock passed the window", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+
+				fakeClock.Step(time.Minute * 6)
+				_, err := reconciler.Reconcile(
+					context.Background(),
+					controllerruntime.Request{NamespacedName: types.NamespacedName{Name: underTest.Name}})
+				Expect(err).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err = reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(),
+					Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
-		When("Nodes are candidates for remediation and cluster is upgrading", func() {
+		When("a node stayed unhealthy for the whole observation window", func() {
 			BeforeEach(func() {
 				setupObjects(1, 2)
-				upgradeChecker = fakeClusterUpgradeChecker{upgrading: true}
+				underTest.Spec.ObservationWindow = &metav1.Duration{Duration: time.Minute * 5}
+				underTest.Status.UnhealthyNodeObservations = map[string]metav1.Time{
+					"unhealthy-node-1": {Time: time.Now().Add(-time.Minute * 6)},
+				}
 			})
 
-			It("requeues reconciliation to 1 minute from now and updates status", func() {
+			It("creates the remediation CR", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				Expect(reconcileResult.RequeueAfter).To(Equal(1 * time.Minute))
-				Expect(underTest.Status.HealthyNodes).To(Equal(2))
-				Expect(underTest.Status.ObservedNodes).To(Equal(3))
-				Expect(underTest.Status.InFlightRemediations).To(HaveLen(0))
-				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseEnabled))
-				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("a node's Ready condition is flapping", func() {
+			BeforeEach(func() {
+				setupObjects(0, 2)
+				maxFlips := 2
+				underTest.Spec.FlappingDetection = &v1alpha1.FlappingPolicy{
+					Window:   &metav1.Duration{Duration: time.Minute * 15},
+					MaxFlips: &maxFlips,
+				}
+				// earlier reconciles already observed two flips, and the node's current
+				// Ready transition is a third one
+				underTest.Status.FlapObservations = map[string]v1alpha1.FlapObservation{
+					"healthy-node-1": {
+						LastTransition: metav1.Time{Time: time.Now().Add(-time.Hour)},
+						Flips: []metav1.Time{
+							{Time: time.Now().Add(-time.Minute * 2)},
+							{Time: time.Now().Add(-time.Minute)},
+						},
+					},
+				}
+			})
+
+			It("remediates the node even though it is currently Ready", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("healthy-node-1")
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(),
+					Name: cr.GetName()}, &cr)).To(Succeed())
+
+				Expect(underTest.Status.FlapObservations).To(HaveKey("healthy-node-1"))
+				Expect(underTest.Status.FlapObservations["healthy-node-1"].Flips).To(HaveLen(3))
+				// the other node only got its baseline recorded, no flips
+				Expect(underTest.Status.FlapObservations).To(HaveKey("healthy-node-2"))
+				Expect(underTest.Status.FlapObservations["healthy-node-2"].Flips).To(BeEmpty())
 			})
 
+			It("recovers once the flips aged out of the window", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+
+				fakeClock.Step(time.Minute * 20)
+				_, err := reconciler.Reconcile(
+					context.Background(),
+					controllerruntime.Request{NamespacedName: types.NamespacedName{Name: underTest.Name}})
+				Expect(err).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("healthy-node-1")
+				err = reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(),
+					Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
 		})
 
-		When("Nodes are candidates for remediation but remediation template is broken", func() {
+		When("an unhealthy node rebooted since it was first observed", func() {
 			BeforeEach(func() {
 				setupObjects(1, 2)
-				underTest.Spec.RemediationTemplate.Name = "dummy"
+				node := objects[0].(*v1.Node)
+				node.Status.NodeInfo.BootID = "boot-2"
+				// an earlier reconcile recorded the boot the node was unhealthy on
+				underTest.Status.BootObservations = map[string]v1alpha1.BootObservation{
+					"unhealthy-node-1": {BootID: "boot-1"},
+				}
 			})
 
-			It("should set corresponding condition", func() {
+			It("restarts the observation instead of remediating", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseDisabled))
-				Expect(underTest.Status.Reason).To(
-					And(
-						ContainSubstring("dummy"),
-						ContainSubstring("not found"),
-					))
-				Expect(underTest.Status.Conditions).To(ContainElement(
-					And(
-						HaveField("Type", v1alpha1.ConditionTypeDisabled),
-						HaveField("Status", metav1.ConditionTrue),
-						HaveField("Reason", v1alpha1.ConditionReasonDisabledTemplateNotFound),
-					)))
+				Expect(getNHCError).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(),
+					Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				Expect(underTest.Status.BootObservations["unhealthy-node-1"].BootID).To(Equal("boot-2"))
+			})
+
+			It("remediates when the node stays unhealthy on the new boot", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+
+				_, err := reconciler.Reconcile(
+					context.Background(),
+					controllerruntime.Request{NamespacedName: types.NamespacedName{Name: underTest.Name}})
+				Expect(err).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(),
+					Name: cr.GetName()}, &cr)).To(Succeed())
 			})
 		})
 
-		// Remove this and corresponding code when kubebuilder supports minimum on IntOrStr types
-		// and don't skip earlier validation test anymore
-		When("minHealthy is negative", func() {
+		When("unhealthy nodes match a remediation template pool entry", func() {
 			BeforeEach(func() {
 				setupObjects(1, 2)
-				underTest.Spec.RemediationTemplate.Name = "dummy"
-				mh := intstr.FromInt(-10)
-				underTest.Spec.MinHealthy = &mh
+				poolTemplate := framework.NewRemediationTemplate().(*unstructured.Unstructured)
+				poolTemplate.SetName("pool-template")
+				Expect(unstructured.SetNestedField(poolTemplate.Object, "bar", "spec", "template", "spec", "size")).To(Succeed())
+				objects = append(objects, poolTemplate)
+
+				node := objects[0].(*v1.Node)
+				node.SetLabels(map[string]string{"pool": "special"})
+
+				underTest.Spec.RemediationTemplates = []v1alpha1.NodePoolRemediationTemplate{{
+					NodeSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "special"}},
+					RemediationTemplate: &v1.ObjectReference{
+						Kind:       "InfrastructureRemediationTemplate",
+						APIVersion: "test.medik8s.io/v1alpha1",
+						Namespace:  "default",
+						Name:       "pool-template",
+					},
+				}}
 			})
 
-			It("should set corresponding phase and condition", func() {
+			It("creates the remediation CR from the pool's template", func() {
 				Expect(reconcileError).NotTo(HaveOccurred())
-				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseDisabled))
-				Expect(underTest.Status.Reason).To(ContainSubstring("MinHealthy is negative"))
-				Expect(underTest.Status.Conditions).To(ContainElement(
-					And(
-						HaveField("Type", v1alpha1.ConditionTypeDisabled),
-						HaveField("Status", metav1.ConditionTrue),
-						HaveField("Reason", v1alpha1.ConditionReasonDisabledInvalidConfig),
-					)))
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cr.Object["spec"]).To(HaveKeyWithValue("size", "bar"))
 			})
 		})
-	})
 
-	// TODO move to new suite in utils package
-	Context("Controller Watches", func() {
-		var (
-			underTest1 *v1alpha1.NodeHealthCheck
-			underTest2 *v1alpha1.NodeHealthCheck
-			objects    []runtime.Object
-			client     ctrlruntimeclient.Client
-		)
+		When("an unhealthy node runs an operating system with its own template", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				osTemplate := framework.NewRemediationTemplate().(*unstructured.Unstructured)
+				osTemplate.SetName("windows-template")
+				Expect(unstructured.SetNestedField(osTemplate.Object, "windows", "spec", "template", "spec", "size")).To(Succeed())
+				objects = append(objects, osTemplate)
+
+				node := objects[0].(*v1.Node)
+				node.SetLabels(map[string]string{"kubernetes.io/os": "windows"})
+
+				underTest.Spec.OSRemediationTemplates = map[string]*v1.ObjectReference{
+					"windows": {
+						Kind:       "InfrastructureRemediationTemplate",
+						APIVersion: "test.medik8s.io/v1alpha1",
+						Namespace:  "default",
+						Name:       "windows-template",
+					},
+				}
+			})
 
-		JustBeforeEach(func() {
-			client = fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+			It("creates the remediation CR from the OS template", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cr.Object["spec"]).To(HaveKeyWithValue("size", "windows"))
+			})
 		})
 
-		When("a node changes status and is selectable by one NHC selector", func() {
+		When("creating the remediation CR failed often enough to trigger the fallback", func() {
 			BeforeEach(func() {
-				objects = newNodes(3, 10)
-				underTest1 = newNodeHealthCheck()
-				underTest2 = newNodeHealthCheck()
-				underTest2.Name = "test-2"
-				emptySelector, _ := metav1.ParseToLabelSelector("fooLabel=bar")
-				underTest2.Spec.Selector = *emptySelector
-				objects = append(objects, underTest1, underTest2)
+				setupObjects(1, 2)
+				fallbackTemplate := framework.NewRemediationTemplate().(*unstructured.Unstructured)
+				fallbackTemplate.SetName("fallback-template")
+				Expect(unstructured.SetNestedField(fallbackTemplate.Object, "fallback", "spec", "template", "spec", "size")).To(Succeed())
+				objects = append(objects, fallbackTemplate)
+
+				underTest.Spec.FallbackRemediationTemplate = &v1.ObjectReference{
+					Kind:       "InfrastructureRemediationTemplate",
+					APIVersion: "test.medik8s.io/v1alpha1",
+					Namespace:  "default",
+					Name:       "fallback-template",
+				}
+				underTest.Status.RemediationCreateFailures = map[string]int{
+					"unhealthy-node-1": createFailureFallbackThreshold,
+				}
 			})
 
-			It("creates a reconcile request", func() {
-				handler := utils.NHCByNodeMapperFunc(client, controllerruntime.Log)
-				updatedNode := v1.Node{
-					ObjectMeta: controllerruntime.ObjectMeta{Name: "healthy-node-1"},
-				}
-				requests := handler(&updatedNode)
-				Expect(len(requests)).To(Equal(1))
-				Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: underTest1.GetName()}}))
+			It("creates the remediation CR from the fallback template", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cr.Object["spec"]).To(HaveKeyWithValue("size", "fallback"))
 			})
 		})
 
-		When("a node changes status and is selectable by the more 2 NHC selector", func() {
+		When("a node condition matches but its duration didn't elapse yet", func() {
 			BeforeEach(func() {
-				objects = newNodes(3, 10)
-				underTest1 = newNodeHealthCheck()
-				underTest2 = newNodeHealthCheck()
-				underTest2.Name = "test-2"
-				objects = append(objects, underTest1, underTest2)
+				setupObjects(0, 2)
+				objects = append(objects, framework.NewNode("pending-node", v1.NodeReady, v1.ConditionFalse, time.Minute))
 			})
 
-			It("creates 2 reconcile requests", func() {
-				handler := utils.NHCByNodeMapperFunc(client, controllerruntime.Log)
-				updatedNode := v1.Node{
-					ObjectMeta: controllerruntime.ObjectMeta{Name: "healthy-node-1"},
-				}
-				requests := handler(&updatedNode)
-				Expect(len(requests)).To(Equal(2))
-				Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: underTest1.GetName()}}))
-				Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: underTest2.GetName()}}))
+			It("skips remediation and requeues when the duration expires", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("pending-node")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically("<=", 4*time.Minute))
 			})
 		})
-		When("a node changes status and there are no NHC objects", func() {
+
+		When("a node has repeated events matching an unhealthy event criterion", func() {
 			BeforeEach(func() {
-				objects = newNodes(3, 10)
+				setupObjects(0, 3)
+				underTest.Spec.UnhealthyEvents = []v1alpha1.UnhealthyEvent{{
+					Reason:   "SystemOOM",
+					MinCount: 3,
+					Window:   metav1.Duration{Duration: time.Minute * 10},
+				}}
+				eventCounts = map[string]int{"healthy-node-1/SystemOOM": 3}
 			})
 
-			It("doesn't create reconcile requests", func() {
-				handler := utils.NHCByNodeMapperFunc(client, controllerruntime.Log)
-				updatedNode := v1.Node{
-					ObjectMeta: controllerruntime.ObjectMeta{Name: "healthy-node-1"},
+			It("creates a remediation CR for it", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("healthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+			})
+		})
+
+		When("the NHC emitted remediation events within the last day", func() {
+			BeforeEach(func() {
+				setupObjects(0, 3)
+				eventCounts = map[string]int{
+					"NodeHealthCheck/test/RemediationCreated":   3,
+					"NodeHealthCheck/test/RemediationEscalated": 1,
+					"NodeHealthCheck/test/RemediationTimedOut":  2,
 				}
-				requests := handler(&updatedNode)
-				Expect(requests).To(BeEmpty())
+			})
+
+			It("summarizes them in the RecentActivity condition", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(underTest.Status.Conditions).To(ContainElement(And(
+					HaveField("Type", v1alpha1.ConditionTypeRecentActivity),
+					HaveField("Status", metav1.ConditionTrue),
+					HaveField("Reason", v1alpha1.ConditionReasonRecentActivity),
+					HaveField("Message", "3 remediations, 1 escalations and 2 failures in the last 24h"),
+				)))
 			})
 		})
-	})
-})
 
-func newRemediationCR(nodeName string) unstructured.Unstructured {
-	cr := unstructured.Unstructured{}
-	cr.SetName(nodeName)
-	cr.SetNamespace("default")
-	cr.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   TestRemediationCRD.Spec.Group,
-		Version: TestRemediationCRD.Spec.Versions[0].Name,
-		Kind:    TestRemediationCRD.Spec.Names.Kind,
-	})
-	cr.SetOwnerReferences([]metav1.OwnerReference{
-		{
-			APIVersion: "remediation.medik8s.io/v1alpha1",
-			Kind:       "NodeHealthCheck",
-			Name:       "test",
-		},
-	})
-	return cr
-}
+		When("the NHC emitted no remediation events within the last day", func() {
+			BeforeEach(func() {
+				setupObjects(0, 3)
+			})
 
-func newRemediationTemplate() runtime.Object {
-	r := map[string]interface{}{
-		"kind":       "InfrastructureRemediation",
-		"apiVersion": "test.medik8s.io/v1alpha1",
-		"metadata":   map[string]interface{}{},
-		"spec": map[string]interface{}{
-			"size": "foo",
-		},
-	}
-	template := &unstructured.Unstructured{
-		Object: map[string]interface{}{
-			"spec": map[string]interface{}{
-				"template": r,
-			},
-		},
-	}
-	template.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   "test.medik8s.io",
-		Version: "v1alpha1",
-		Kind:    "InfrastructureRemediationTemplate",
-	})
-	template.SetGenerateName("remediation-template-name-")
-	template.SetNamespace("default")
-	template.SetName("template")
-	return template.DeepCopyObject()
-}
+			It("reports no recent activity", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(underTest.Status.Conditions).To(ContainElement(And(
+					HaveField("Type", v1alpha1.ConditionTypeRecentActivity),
+					HaveField("Status", metav1.ConditionFalse),
+					HaveField("Reason", v1alpha1.ConditionReasonNoRecentActivity),
+				)))
+			})
+		})
+
+		When("the remediation template namespace was deleted", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				for i, object := range objects {
+					if _, isNamespace := object.(*v1.Namespace); isNamespace {
+						objects = append(objects[:i], objects[i+1:]...)
+						break
+					}
+				}
+			})
+
+			It("sets the Degraded condition and skips remediation until it is back", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				Expect(underTest.Status.Conditions).To(ContainElement(And(
+					HaveField("Type", v1alpha1.ConditionTypeDegraded),
+					HaveField("Status", metav1.ConditionTrue),
+					HaveField("Reason", v1alpha1.ConditionReasonDegradedNamespaceMissing),
+				)))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically("<=", 15*time.Second))
+			})
+		})
+
+		When("a node matches the unhealthy CEL expression", func() {
+			BeforeEach(func() {
+				setupObjects(0, 3)
+				node := objects[0].(*v1.Node)
+				node.SetLabels(map[string]string{"quarantine": "true"})
+				underTest.Spec.UnhealthyCEL = pointer.String(
+					"'quarantine' in node.metadata.labels && node.metadata.labels['quarantine'] == 'true'")
+			})
+
+			It("creates a remediation CR for it", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR(objects[0].(*v1.Node).GetName())
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+			})
+		})
+
+		When("remediation CR metadata is configured", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.SetLabels(map[string]string{
+					"remediation.medik8s.io/team": "storage",
+					"unrelated":                   "label",
+				})
+				underTest.Spec.RemediationCRTemplateMetadata = &v1alpha1.RemediationCRMetadata{
+					Labels:      map[string]string{"cost-center": "42"},
+					Annotations: map[string]string{"policy.example.com/audit": "true"},
+				}
+			})
+
+			It("stamps the metadata onto the remediation CR", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cr.GetLabels()).To(HaveKeyWithValue("cost-center", "42"))
+				Expect(cr.GetLabels()).To(HaveKeyWithValue("remediation.medik8s.io/team", "storage"))
+				Expect(cr.GetLabels()).NotTo(HaveKey("unrelated"))
+				Expect(cr.GetAnnotations()).To(HaveKeyWithValue("policy.example.com/audit", "true"))
+			})
+		})
+
+		When("a node stopped advertising a watched extended resource for too long", func() {
+			BeforeEach(func() {
+				setupObjects(0, 3)
+				underTest.Spec.UnhealthyResources = []v1alpha1.UnhealthyResource{{
+					Name:     "nvidia.com/gpu",
+					Duration: metav1.Duration{Duration: time.Minute * 5},
+				}}
+				underTest.Status.ResourceObservations = map[string]v1alpha1.ResourceObservation{
+					"healthy-node-1/nvidia.com/gpu": {ZeroSince: &metav1.Time{Time: time.Now().Add(-time.Minute * 10)}},
+				}
+			})
+
+			It("creates a remediation CR for it", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("healthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+			})
+		})
+
+		When("minHealthyPerZone is configured and a zone fell below the threshold", func() {
+			BeforeEach(func() {
+				setupObjects(2, 3)
+				zoneByNode := map[string]string{
+					"unhealthy-node-1": "zone-a",
+					"healthy-node-1":   "zone-a",
+					"healthy-node-2":   "zone-a",
+					"unhealthy-node-2": "zone-b",
+					"healthy-node-3":   "zone-b",
+				}
+				for _, o := range objects {
+					if node, isNode := o.(*v1.Node); isNode {
+						node.SetLabels(map[string]string{"topology.kubernetes.io/zone": zoneByNode[node.GetName()]})
+					}
+				}
+				minHealthyPerZone := intstr.FromInt(2)
+				underTest.Spec.MinHealthyPerZone = &minHealthyPerZone
+			})
+
+			It("remediates in the healthy zone only", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				cr = framework.NewRemediationCR("unhealthy-node-2")
+				err = reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		When("few nodes are unhealthy and healthy nodes above min healthy", func() {
+			BeforeEach(func() {
+				setupObjects(4, 3)
+			})
+
+			It("skips remediation - CR is not created, status updated correctly", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+				o := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: o.GetNamespace(),
+					Name: o.GetName()}, &o)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				Expect(underTest.Status.HealthyNodes).To(Equal(3))
+				Expect(underTest.Status.ObservedNodes).To(Equal(7))
+				Expect(underTest.Status.InFlightRemediations).To(BeEmpty())
+				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseEnabled))
+				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+			})
+
+		})
+
+		When("few nodes become healthy", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				remediationCR := framework.NewRemediationCR("healthy-node-2")
+				remediationCROther := framework.NewRemediationCR("healthy-node-1")
+				refs := remediationCROther.GetOwnerReferences()
+				refs[0].Name = "other"
+				remediationCROther.SetOwnerReferences(refs)
+				objects = append(objects, remediationCR.DeepCopy(), remediationCROther.DeepCopy())
+			})
+
+			It("deletes an existing remediation CR", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+
+				cr = framework.NewRemediationCR("healthy-node-2")
+				err = reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				// owned by other NHC, should not be deleted
+				cr = framework.NewRemediationCR("healthy-node-1")
+				err = reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("updates the NHC status correctly", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+				Expect(underTest.Status.ObservedNodes).To(Equal(3))
+				Expect(underTest.Status.InFlightRemediations).To(HaveLen(1))
+				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseRemediating))
+				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+			})
+		})
+
+		When("a volume is still detaching from a node which became healthy", func() {
+			BeforeEach(func() {
+				setupObjects(0, 2)
+				underTest.Spec.WaitForStorageDetach = true
+				remediationCR := framework.NewRemediationCR("healthy-node-1")
+				attachment := &storagev1.VolumeAttachment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "pending-attachment",
+						DeletionTimestamp: &metav1.Time{Time: time.Now()},
+						Finalizers:        []string{"external-attacher/test"},
+					},
+					Spec: storagev1.VolumeAttachmentSpec{
+						Attacher: "test",
+						NodeName: "healthy-node-1",
+						Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: pointer.String("pv-1")},
+					},
+				}
+				objects = append(objects, remediationCR.DeepCopy(), attachment)
+			})
+
+			It("keeps the remediation CR until the detach is confirmed", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("healthy-node-1")
+				err := reconciler.Client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+			})
+		})
+
+		When("pre-fence draining is configured", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.Spec.PreFenceDrain = &metav1.Duration{Duration: 2 * time.Minute}
+				slice := &discoveryv1.EndpointSlice{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-slice",
+						Namespace: "default",
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{
+							Addresses:  []string{"10.0.0.1"},
+							NodeName:   pointer.String("unhealthy-node-1"),
+							Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(true)},
+						},
+						{
+							Addresses:  []string{"10.0.0.2"},
+							NodeName:   pointer.String("healthy-node-1"),
+							Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(true)},
+						},
+					},
+				}
+				objects = append(objects, slice)
+			})
+
+			It("marks the node's endpoints not ready and delays the remediation CR", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				slice := &discoveryv1.EndpointSlice{}
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "default", Name: "test-slice"}, slice)).To(Succeed())
+				Expect(slice.Endpoints[0].Conditions.Ready).To(Equal(pointer.Bool(false)))
+				Expect(slice.Endpoints[1].Conditions.Ready).To(Equal(pointer.Bool(true)))
+
+				Expect(underTest.Status.PendingFenceDrains).To(HaveKey("unhealthy-node-1"))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically("<=", 2*time.Minute))
+			})
+
+			When("the drain window passed", func() {
+				BeforeEach(func() {
+					underTest.Status.PendingFenceDrains = map[string]metav1.Time{
+						"unhealthy-node-1": {Time: time.Now().Add(-3 * time.Minute)},
+					}
+				})
+
+				It("creates the remediation CR and cleans up the tracking", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("unhealthy-node-1")
+					Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)).To(Succeed())
+					Expect(underTest.Status.PendingFenceDrains).NotTo(HaveKey("unhealthy-node-1"))
+				})
+			})
+		})
+
+		When("waiting for live-migration is configured and the node hosts VM instances", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.Spec.Virtualization = &v1alpha1.VirtualizationOptions{WaitForLiveMigration: true}
+				vmi := &unstructured.Unstructured{}
+				vmi.SetGroupVersionKind(schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachineInstance"})
+				vmi.SetNamespace("default")
+				vmi.SetName("test-vmi")
+				Expect(unstructured.SetNestedField(vmi.Object, "unhealthy-node-1", "status", "nodeName")).To(Succeed())
+				objects = append(objects, vmi)
+			})
+
+			It("requests a live-migration and delays the remediation CR", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				migration := &unstructured.Unstructured{}
+				migration.SetGroupVersionKind(vmiMigrationGVK)
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "default", Name: "nhc-test-vmi"}, migration)).To(Succeed())
+				vmiName, _, _ := unstructured.NestedString(migration.Object, "spec", "vmiName")
+				Expect(vmiName).To(Equal("test-vmi"))
+
+				Expect(underTest.Status.PendingLiveMigrations).To(HaveKey("unhealthy-node-1"))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically("<=", liveMigrationPollInterval))
+			})
+
+			When("the live-migration timeout passed", func() {
+				BeforeEach(func() {
+					underTest.Status.PendingLiveMigrations = map[string]metav1.Time{
+						"unhealthy-node-1": {Time: time.Now().Add(-defaultLiveMigrationTimeout - time.Minute)},
+					}
+				})
+
+				It("fences the node anyway and cleans up the tracking", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("unhealthy-node-1")
+					Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)).To(Succeed())
+					Expect(underTest.Status.PendingLiveMigrations).NotTo(HaveKey("unhealthy-node-1"))
+				})
+			})
+		})
+
+		When("remediation requires manual approval", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.Spec.ApprovalRequired = true
+			})
+
+			It("waits for approval instead of creating the remediation CR", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				Expect(underTest.Status.PendingApprovals).To(HaveKey("unhealthy-node-1"))
+				Expect(underTest.Status.Conditions).To(ContainElement(And(
+					HaveField("Type", v1alpha1.ConditionTypeApprovalPending),
+					HaveField("Status", metav1.ConditionTrue),
+					HaveField("Reason", v1alpha1.ConditionReasonApprovalPending),
+				)))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(reconcileResult.RequeueAfter).To(BeNumerically("<=", defaultApprovalTimeout))
+			})
+
+			When("the remediation was approved", func() {
+				BeforeEach(func() {
+					underTest.Annotations = map[string]string{v1alpha1.ApprovedNodesAnnotation: "unhealthy-node-1"}
+					underTest.Status.PendingApprovals = map[string]v1alpha1.ApprovalRecord{
+						"unhealthy-node-1": {RequestedAt: metav1.Time{Time: time.Now()}},
+					}
+				})
+
+				It("creates the remediation CR and cleans up the tracking", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("unhealthy-node-1")
+					Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)).To(Succeed())
+					Expect(underTest.Status.PendingApprovals).NotTo(HaveKey("unhealthy-node-1"))
+				})
+			})
+
+			When("the approval timed out", func() {
+				BeforeEach(func() {
+					underTest.Status.PendingApprovals = map[string]v1alpha1.ApprovalRecord{
+						"unhealthy-node-1": {RequestedAt: metav1.Time{Time: time.Now().Add(-defaultApprovalTimeout - time.Minute)}},
+					}
+				})
+
+				It("denies the remediation", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("unhealthy-node-1")
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+					Expect(underTest.Status.PendingApprovals["unhealthy-node-1"].Denied).To(BeTrue())
+				})
+			})
+		})
+
+		When("the unhealthy node runs workloads", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				pod := &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "impacted-pod", Namespace: "default"},
+					Spec: v1.PodSpec{
+						NodeName:   "unhealthy-node-1",
+						Containers: []v1.Container{{Name: "main", Image: "busybox"}},
+						Volumes: []v1.Volume{{
+							Name: "data",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data-claim"},
+							},
+						}},
+					},
+				}
+				slice := &discoveryv1.EndpointSlice{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "impacted-slice",
+						Namespace: "default",
+						Labels:    map[string]string{discoveryv1.LabelServiceName: "impacted-service"},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{{
+						Addresses: []string{"10.0.0.3"},
+						NodeName:  pointer.String("unhealthy-node-1"),
+					}},
+				}
+				objects = append(objects, pod, slice)
+			})
+
+			It("records the remediation impact estimate", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(underTest.Status.RemediationImpacts).To(HaveKey("unhealthy-node-1"))
+				impact := underTest.Status.RemediationImpacts["unhealthy-node-1"]
+				Expect(impact.Pods).To(Equal(1))
+				Expect(impact.BoundVolumes).To(Equal(1))
+				Expect(impact.SinglePointServices).To(Equal(1))
+			})
+		})
+
+		When("the unhealthy node's MachineSet opted out of remediation", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				machineSet := &machinev1beta1.MachineSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "disabled-pool",
+						Namespace:   "openshift-machine-api",
+						Annotations: map[string]string{pooldisable.DisableAnnotation: "true"},
+					},
+				}
+				machine := &machinev1beta1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "disabled-machine",
+						Namespace: "openshift-machine-api",
+						OwnerReferences: []metav1.OwnerReference{{
+							APIVersion: "machine.openshift.io/v1beta1",
+							Kind:       "MachineSet",
+							Name:       machineSet.Name,
+						}},
+					},
+				}
+				for _, o := range objects {
+					if node, ok := o.(*v1.Node); ok && node.Name == "unhealthy-node-1" {
+						if node.Annotations == nil {
+							node.Annotations = map[string]string{}
+						}
+						node.Annotations["machine.openshift.io/machine"] = "openshift-machine-api/disabled-machine"
+					}
+				}
+				objects = append(objects, machineSet, machine)
+			})
+
+			It("skips the node as if it wasn't selected", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				Expect(getNHCError).NotTo(HaveOccurred())
+				Expect(underTest.Status.ObservedNodes).To(Equal(2))
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+			})
+		})
+
+		When("exporting the verdict as a node condition is enabled", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.Spec.ExportNodeCondition = true
+			})
+
+			It("writes the NodeHealthy condition onto the nodes", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				unhealthyNode := &v1.Node{}
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Name: "unhealthy-node-1"}, unhealthyNode)).To(Succeed())
+				Expect(unhealthyNode.Status.Conditions).To(ContainElement(And(
+					HaveField("Type", nodeHealthyConditionType),
+					HaveField("Status", v1.ConditionFalse),
+					HaveField("Reason", nodeConditionReasonRemediating),
+				)))
+				healthyNode := &v1.Node{}
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Name: "healthy-node-1"}, healthyNode)).To(Succeed())
+				Expect(healthyNode.Status.Conditions).To(ContainElement(And(
+					HaveField("Type", nodeHealthyConditionType),
+					HaveField("Status", v1.ConditionTrue),
+					HaveField("Reason", nodeConditionReasonHealthy),
+				)))
+			})
+		})
+
+		When("the operator starts in safe mode after a crash loop", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				safeModeChecker = fakeSafeModeChecker{reason: "The operator restarted 3 times within 10m0s and runs in safe mode"}
+			})
+
+			It("observes the nodes but doesn't fence", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(reconcileResult.RequeueAfter).To(Equal(1 * time.Minute))
+				Expect(getNHCError).NotTo(HaveOccurred())
+				Expect(underTest.Status.ObservedNodes).To(Equal(3))
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+				Expect(underTest.Status.InFlightRemediations).To(BeEmpty())
+				Expect(underTest.Status.Conditions).To(ContainElement(
+					And(
+						HaveField("Type", v1alpha1.ConditionTypeGloballyPaused),
+						HaveField("Status", metav1.ConditionTrue),
+						HaveField("Reason", v1alpha1.ConditionReasonGlobalPauseSafeMode),
+					)))
+			})
+		})
+
+		When("the template validation controller inspects the references", func() {
+			var permissionsChecker fakePermissionsChecker
+			var validationResult controllerruntime.Result
+			var validationError error
+
+			BeforeEach(func() {
+				setupObjects(0, 2)
+				permissionsChecker = fakePermissionsChecker{}
+			})
+
+			JustBeforeEach(func() {
+				validator := TemplateValidationReconciler{
+					Client:             reconciler.Client,
+					Log:                controllerruntime.Log.WithName("NHC Test Template Validator"),
+					Recorder:           record.NewFakeRecorder(20),
+					PermissionsChecker: permissionsChecker,
+				}
+				validationResult, validationError = validator.Reconcile(
+					context.Background(),
+					controllerruntime.Request{NamespacedName: types.NamespacedName{Name: underTest.Name}})
+				getNHCError = reconciler.Get(
+					context.Background(),
+					ctrlruntimeclient.ObjectKey{Namespace: underTest.Namespace, Name: underTest.Name},
+					underTest)
+			})
+
+			It("reports the template valid and schedules a revalidation", func() {
+				Expect(validationError).NotTo(HaveOccurred())
+				Expect(validationResult.RequeueAfter).To(Equal(templateValidationPeriod))
+				Expect(getNHCError).NotTo(HaveOccurred())
+				Expect(underTest.Status.RemediationTemplateStatuses).To(HaveLen(1))
+				status := underTest.Status.RemediationTemplateStatuses[0]
+				Expect(status.TemplateRef.Kind).To(Equal("InfrastructureRemediationTemplate"))
+				Expect(status.Valid).To(BeTrue())
+				Expect(status.Reason).To(Equal(v1alpha1.TemplateReasonValid))
+				Expect(status.LastTransitionTime.IsZero()).To(BeFalse())
+			})
+
+			When("the referenced template doesn't exist", func() {
+				BeforeEach(func() {
+					underTest.Spec.RemediationTemplate.Name = "missing-template"
+				})
+
+				It("reports the template as not found", func() {
+					Expect(validationError).NotTo(HaveOccurred())
+					Expect(underTest.Status.RemediationTemplateStatuses).To(HaveLen(1))
+					status := underTest.Status.RemediationTemplateStatuses[0]
+					Expect(status.Valid).To(BeFalse())
+					Expect(status.Reason).To(Equal(v1alpha1.TemplateReasonNotFound))
+					Expect(status.Message).To(ContainSubstring("missing-template"))
+				})
+			})
+
+			When("the operator lacks remediation permissions", func() {
+				BeforeEach(func() {
+					permissionsChecker = fakePermissionsChecker{
+						missing: []string{"create infrastructureremediations.test.medik8s.io"},
+					}
+				})
+
+				It("reports the missing permissions", func() {
+					Expect(validationError).NotTo(HaveOccurred())
+					Expect(underTest.Status.RemediationTemplateStatuses).To(HaveLen(1))
+					status := underTest.Status.RemediationTemplateStatuses[0]
+					Expect(status.Valid).To(BeFalse())
+					Expect(status.Reason).To(Equal(v1alpha1.TemplateReasonPermissionsMissing))
+					Expect(status.Message).To(ContainSubstring("create infrastructureremediations.test.medik8s.io"))
+				})
+			})
+		})
+
+		When("nodes are targeted by MachineSet and NodePool references", func() {
+			BeforeEach(func() {
+				setupObjects(1, 3)
+				underTest.Spec.MachineSetRefs = []v1alpha1.PoolRef{{Namespace: "openshift-machine-api", Name: "target-pool"}}
+				underTest.Spec.NodePoolRefs = []v1alpha1.PoolRef{{Namespace: "clusters", Name: "target-nodepool"}}
+				// unhealthy-node-1 and healthy-node-1 belong to the referenced MachineSet,
+				// healthy-node-2 to the referenced NodePool, healthy-node-3 to neither
+				poolMembers := map[string]string{"unhealthy-node-1": "machine-1", "healthy-node-1": "machine-2"}
+				for _, o := range objects {
+					node, ok := o.(*v1.Node)
+					if !ok {
+						continue
+					}
+					if node.Annotations == nil {
+						node.Annotations = map[string]string{}
+					}
+					if machineName, exists := poolMembers[node.Name]; exists {
+						node.Annotations["machine.openshift.io/machine"] = "openshift-machine-api/" + machineName
+					} else if node.Name == "healthy-node-2" {
+						node.Annotations[nodePoolAnnotation] = "clusters/target-nodepool"
+					}
+				}
+				for _, machineName := range poolMembers {
+					objects = append(objects, &machinev1beta1.Machine{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      machineName,
+							Namespace: "openshift-machine-api",
+							OwnerReferences: []metav1.OwnerReference{{
+								APIVersion: "machine.openshift.io/v1beta1",
+								Kind:       "MachineSet",
+								Name:       "target-pool",
+							}},
+						},
+					})
+				}
+			})
+
+			It("health-checks only the members of the referenced pools", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
+				Expect(underTest.Status.ObservedNodes).To(Equal(3))
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+			})
+		})
+
+		When("a node is deleted while its remediation is in flight", func() {
+			BeforeEach(func() {
+				setupObjects(0, 2)
+				remediationCR := framework.NewRemediationCR("gone-node")
+				objects = append(objects, remediationCR.DeepCopyObject())
+				underTest.Status.InFlightRemediations = map[string]metav1.Time{"gone-node": metav1.Now()}
+			})
+
+			When("the AbortAndCleanup policy is configured", func() {
+				BeforeEach(func() {
+					underTest.Spec.NodeDeletedPolicy = v1alpha1.NodeDeletedPolicyAbortAndCleanup
+				})
+
+				It("deletes the remediation CR and cleans up the tracking", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("gone-node")
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+					Expect(getNHCError).NotTo(HaveOccurred())
+					Expect(underTest.Status.InFlightRemediations).To(BeEmpty())
+				})
+			})
+
+			When("the default CompleteRemediation policy is configured", func() {
+				It("leaves the remediation CR in place", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("gone-node")
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		When("the NHC is deleted while remediation is in flight", func() {
+			BeforeEach(func() {
+				setupObjects(0, 2)
+				underTest.Finalizers = []string{nhcFinalizer}
+				underTest.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				remediationCR := framework.NewRemediationCR("fenced-node")
+				objects = append(objects, remediationCR.DeepCopyObject())
+				underTest.Status.InFlightRemediations = map[string]metav1.Time{"fenced-node": metav1.Now()}
+			})
+
+			When("the default Abort policy is configured", func() {
+				It("deletes the remediation CR and allows the deletion", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("fenced-node")
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+					Expect(errors.IsNotFound(getNHCError)).To(BeTrue())
+				})
+			})
+
+			When("the Orphan policy is configured", func() {
+				BeforeEach(func() {
+					underTest.Spec.DeletionPolicy = v1alpha1.DeletionPolicyOrphan
+				})
+
+				It("leaves the remediation CR without this NHC's owner reference", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("fenced-node")
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cr.GetOwnerReferences()).To(BeEmpty())
+					Expect(errors.IsNotFound(getNHCError)).To(BeTrue())
+				})
+			})
+
+			When("the WaitForCompletion policy is configured", func() {
+				BeforeEach(func() {
+					underTest.Spec.DeletionPolicy = v1alpha1.DeletionPolicyWaitForCompletion
+				})
+
+				It("blocks the deletion until the remediation completed", func() {
+					Expect(reconcileError).NotTo(HaveOccurred())
+					cr := framework.NewRemediationCR("fenced-node")
+					err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &cr)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(getNHCError).NotTo(HaveOccurred())
+					Expect(underTest.Finalizers).To(ContainElement(nhcFinalizer))
+					Expect(reconcileResult.RequeueAfter).To(BeNumerically(">", 0))
+				})
+			})
+		})
+
+		When("an old remediation cr exist", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				remediationCR := framework.NewRemediationCR("unhealthy-node-1")
+				remediationCR.SetCreationTimestamp(metav1.Time{Time: time.Now().Add(-remediationCRAlertTimeout - 2*time.Minute)})
+				// the remediator writes to the same CR, the alert flag must not clobber it
+				remediationCR.SetAnnotations(map[string]string{"remediation.example.com/progress": "rebooting"})
+				objects = append(objects, remediationCR.DeepCopyObject())
+			})
 
-func newNodeHealthCheck() *v1alpha1.NodeHealthCheck {
-	unhealthy := intstr.FromString("51%")
-	return &v1alpha1.NodeHealthCheck{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "NodeHealthCheck",
-			APIVersion: "remediation.medik8s.io/v1alpha1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "test",
-		},
-		Spec: v1alpha1.NodeHealthCheckSpec{
-			Selector:   metav1.LabelSelector{},
-			MinHealthy: &unhealthy,
-			UnhealthyConditions: []v1alpha1.UnhealthyCondition{
-				{
-					Type:     v1.NodeReady,
-					Status:   v1.ConditionFalse,
-					Duration: metav1.Duration{Duration: time.Second * 300},
-				},
-			},
-			RemediationTemplate: &v1.ObjectReference{
-				Kind:       "InfrastructureRemediationTemplate",
-				APIVersion: "test.medik8s.io/v1alpha1",
-				Namespace:  "default",
-				Name:       "template",
-			},
-		},
-	}
-}
+			It("an alert flag is set on remediation cr", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(getNHCError).NotTo(HaveOccurred())
 
-func newNodes(unhealthy int, healthy int) []runtime.Object {
-	o := make([]runtime.Object, 0, healthy+unhealthy)
-	for i := unhealthy; i > 0; i-- {
-		node := newNode(fmt.Sprintf("unhealthy-node-%d", i), v1.NodeReady, v1.ConditionFalse, time.Minute*10)
-		o = append(o, node)
-	}
-	for i := healthy; i > 0; i-- {
-		o = append(o, newNode(fmt.Sprintf("healthy-node-%d", i), v1.NodeReady, v1.ConditionTrue, time.Minute*10))
-	}
-	return o
-}
+				actualRemediationCR := new(unstructured.Unstructured)
+				actualRemediationCR.SetKind(strings.TrimSuffix(underTest.Spec.RemediationTemplate.Kind, templateSuffix))
+				actualRemediationCR.SetAPIVersion(underTest.Spec.RemediationTemplate.APIVersion)
+				key := client.ObjectKey{Name: "unhealthy-node-1", Namespace: "default"}
+				err := reconciler.Client.Get(context.Background(), key, actualRemediationCR)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actualRemediationCR.GetAnnotations()[oldRemediationCRAnnotationKey]).To(Equal("flagon"))
+			})
 
-func newNode(name string, t v1.NodeConditionType, s v1.ConditionStatus, d time.Duration) runtime.Object {
-	return runtime.Object(
-		&v1.Node{
-			TypeMeta:   metav1.TypeMeta{Kind: "Node"},
-			ObjectMeta: metav1.ObjectMeta{Name: name},
-			Status: v1.NodeStatus{
-				Conditions: []v1.NodeCondition{
-					{
-						Type:               t,
-						Status:             s,
-						LastTransitionTime: metav1.Time{Time: time.Now().Add(-d)},
-					},
-				},
-			},
+			It("keeps the annotations owned by the remediator", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+
+				actualRemediationCR := new(unstructured.Unstructured)
+				actualRemediationCR.SetKind(strings.TrimSuffix(underTest.Spec.RemediationTemplate.Kind, templateSuffix))
+				actualRemediationCR.SetAPIVersion(underTest.Spec.RemediationTemplate.APIVersion)
+				key := client.ObjectKey{Name: "unhealthy-node-1", Namespace: "default"}
+				Expect(reconciler.Client.Get(context.Background(), key, actualRemediationCR)).To(Succeed())
+				Expect(actualRemediationCR.GetAnnotations()).To(HaveKeyWithValue("remediation.example.com/progress", "rebooting"))
+			})
 		})
-}
 
-var TestRemediationCRD = &apiextensions.CustomResourceDefinition{
-	TypeMeta: metav1.TypeMeta{
-		APIVersion: apiextensions.SchemeGroupVersion.String(),
-		Kind:       "CustomResourceDefinition",
-	},
-	ObjectMeta: metav1.ObjectMeta{
-		Name: "infrastructureremediations.medik8s.io",
-	},
-	Spec: apiextensions.CustomResourceDefinitionSpec{
-		Group: "test.medik8s.io",
-		Scope: apiextensions.NamespaceScoped,
-		Names: apiextensions.CustomResourceDefinitionNames{
-			Kind:   "InfrastructureRemediation",
-			Plural: "infrastructureremediations",
-		},
-		Versions: []apiextensions.CustomResourceDefinitionVersion{
-			{
-				Name:    "v1alpha1",
-				Served:  true,
-				Storage: true,
-				Subresources: &apiextensions.CustomResourceSubresources{
-					Status: &apiextensions.CustomResourceSubresourceStatus{},
-				},
-				Schema: &apiextensions.CustomResourceValidation{
-					OpenAPIV3Schema: &apiextensions.JSONSchemaProps{
-						Type: "object",
-						Properties: map[string]apiextensions.JSONSchemaProps{
-							"spec": {
-								Type:                   "object",
-								XPreserveUnknownFields: pointer.BoolPtr(true),
-							},
-							"status": {
-								Type:                   "object",
-								XPreserveUnknownFields: pointer.BoolPtr(true),
+		When("remediation is needed but pauseRequests exists", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.Spec.PauseRequests = []string{"I'm an admin, asking you to stop remediating this group of nodes"}
+			})
+
+			It("should reconcile successfully", func() {
+				Expect(reconcileError).ShouldNot(HaveOccurred())
+			})
+
+			It("skips remediation - CR is not created", func() {
+				o := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: o.GetNamespace(),
+					Name: o.GetName()}, &o)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("updates the NHC status", func() {
+				Expect(getNHCError).NotTo(HaveOccurred())
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+				Expect(underTest.Status.ObservedNodes).To(Equal(3))
+				Expect(underTest.Status.InFlightRemediations).To(BeEmpty())
+				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhasePaused))
+				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+			})
+		})
+
+		When("Nodes are candidates for remediation and cluster is upgrading", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				upgradeChecker = fakeClusterUpgradeChecker{upgrading: true}
+			})
+
+			It("requeues reconciliation to 1 minute from now and updates status", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(reconcileResult.RequeueAfter).To(Equal(1 * time.Minute))
+				Expect(underTest.Status.HealthyNodes).To(Equal(2))
+				Expect(underTest.Status.ObservedNodes).To(Equal(3))
+				Expect(underTest.Status.InFlightRemediations).To(HaveLen(0))
+				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseEnabled))
+				Expect(underTest.Status.Reason).ToNot(BeEmpty())
+			})
+
+		})
+
+		When("the remediation template does not fit the installed CRD schema", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				preserveUnknown := false
+				crd := &apiextensionsv1.CustomResourceDefinition{
+					ObjectMeta: metav1.ObjectMeta{Name: "infrastructureremediations.test.medik8s.io"},
+					Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+						Group: "test.medik8s.io",
+						Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "InfrastructureRemediation"},
+						Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+							Name:    "v1alpha1",
+							Served:  true,
+							Storage: true,
+							Schema: &apiextensionsv1.CustomResourceValidation{
+								OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+									Type:                   "object",
+									XPreserveUnknownFields: &preserveUnknown,
+									Properties: map[string]apiextensionsv1.JSONSchemaProps{
+										// the installed schema doesn't know the template's "size" field
+										"spec": {
+											Type: "object",
+											Properties: map[string]apiextensionsv1.JSONSchemaProps{
+												"replicas": {Type: "integer"},
+											},
+										},
+									},
+								},
 							},
-						},
+						}},
 					},
-				},
-			},
-		},
-	},
-}
+				}
+				objects = append(objects, crd)
+			})
+
+			It("still remediates but reports the offending fields", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+
+				cr := framework.NewRemediationCR("unhealthy-node-1")
+				err := reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: cr.GetNamespace(),
+					Name: cr.GetName()}, &cr)
+				Expect(err).NotTo(HaveOccurred())
+
+				condition := meta.FindStatusCondition(underTest.Status.Conditions, v1alpha1.ConditionTypeSchemaMismatch)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Message).To(ContainSubstring("spec.size"))
+			})
+		})
+
+		When("Nodes are candidates for remediation and cluster operators are degraded", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				platformChecker = fakePlatformHealthChecker{degraded: []string{"network"}}
+			})
+
+			It("skips remediation and requeues reconciliation to 1 minute from now", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(reconcileResult.RequeueAfter).To(Equal(1 * time.Minute))
+				Expect(underTest.Status.InFlightRemediations).To(HaveLen(0))
+				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseEnabled))
+			})
+		})
+
+		When("the spec is updated between reconciles", func() {
+			BeforeEach(func() {
+				setupObjects(0, 2)
+			})
+
+			It("records a new spec revision in the status", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(underTest.Status.SpecRevisions).To(HaveLen(1))
+				firstHash := underTest.Status.SpecRevisions[0].Hash
+
+				minHealthy := intstr.FromString("30%")
+				underTest.Spec.MinHealthy = &minHealthy
+				Expect(reconciler.Update(context.Background(), underTest)).To(Succeed())
+				_, err := reconciler.Reconcile(
+					context.Background(),
+					controllerruntime.Request{NamespacedName: types.NamespacedName{Name: underTest.Name}})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(reconciler.Get(context.Background(), ctrlruntimeclient.ObjectKey{Name: underTest.Name}, underTest)).To(Succeed())
+				Expect(underTest.Status.SpecRevisions).To(HaveLen(2))
+				Expect(underTest.Status.SpecRevisions[1].Hash).NotTo(Equal(firstHash))
+			})
+		})
+
+		When("Nodes are candidates for remediation but remediation template is broken", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.Spec.RemediationTemplate.Name = "dummy"
+			})
+
+			It("should set corresponding condition", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseDisabled))
+				Expect(underTest.Status.Reason).To(
+					And(
+						ContainSubstring("dummy"),
+						ContainSubstring("not found"),
+					))
+				Expect(underTest.Status.Conditions).To(ContainElement(
+					And(
+						HaveField("Type", v1alpha1.ConditionTypeDisabled),
+						HaveField("Status", metav1.ConditionTrue),
+						HaveField("Reason", v1alpha1.ConditionReasonDisabledTemplateNotFound),
+					)))
+			})
+		})
+
+		// Remove this and corresponding code when kubebuilder supports minimum on IntOrStr types
+		// and don't skip earlier validation test anymore
+		When("minHealthy is negative", func() {
+			BeforeEach(func() {
+				setupObjects(1, 2)
+				underTest.Spec.RemediationTemplate.Name = "dummy"
+				mh := intstr.FromInt(-10)
+				underTest.Spec.MinHealthy = &mh
+			})
+
+			It("should set corresponding phase and condition", func() {
+				Expect(reconcileError).NotTo(HaveOccurred())
+				Expect(underTest.Status.Phase).To(Equal(v1alpha1.PhaseDisabled))
+				Expect(underTest.Status.Reason).To(ContainSubstring("MinHealthy is negative"))
+				Expect(underTest.Status.Conditions).To(ContainElement(
+					And(
+						HaveField("Type", v1alpha1.ConditionTypeDisabled),
+						HaveField("Status", metav1.ConditionTrue),
+						HaveField("Reason", v1alpha1.ConditionReasonDisabledInvalidConfig),
+					)))
+			})
+		})
+	})
+
+	// TODO move to new suite in utils package
+	Context("Controller Watches", func() {
+		var (
+			underTest1 *v1alpha1.NodeHealthCheck
+			underTest2 *v1alpha1.NodeHealthCheck
+			objects    []runtime.Object
+			client     ctrlruntimeclient.Client
+		)
+
+		JustBeforeEach(func() {
+			client = fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+		})
+
+		When("a node changes status and is selectable by one NHC selector", func() {
+			BeforeEach(func() {
+				objects = framework.NewNodes(3, 10)
+				underTest1 = framework.NewNodeHealthCheck()
+				underTest2 = framework.NewNodeHealthCheck()
+				underTest2.Name = "test-2"
+				emptySelector, _ := metav1.ParseToLabelSelector("fooLabel=bar")
+				underTest2.Spec.Selector = *emptySelector
+				objects = append(objects, underTest1, underTest2)
+			})
+
+			It("creates a reconcile request", func() {
+				handler := utils.NHCByNodeMapperFunc(client, controllerruntime.Log)
+				updatedNode := v1.Node{
+					ObjectMeta: controllerruntime.ObjectMeta{Name: "healthy-node-1"},
+				}
+				requests := handler(&updatedNode)
+				Expect(len(requests)).To(Equal(1))
+				Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: underTest1.GetName()}}))
+			})
+		})
+
+		When("a node changes status and is selectable by the more 2 NHC selector", func() {
+			BeforeEach(func() {
+				objects = framework.NewNodes(3, 10)
+				underTest1 = framework.NewNodeHealthCheck()
+				underTest2 = framework.NewNodeHealthCheck()
+				underTest2.Name = "test-2"
+				objects = append(objects, underTest1, underTest2)
+			})
+
+			It("creates 2 reconcile requests", func() {
+				handler := utils.NHCByNodeMapperFunc(client, controllerruntime.Log)
+				updatedNode := v1.Node{
+					ObjectMeta: controllerruntime.ObjectMeta{Name: "healthy-node-1"},
+				}
+				requests := handler(&updatedNode)
+				Expect(len(requests)).To(Equal(2))
+				Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: underTest1.GetName()}}))
+				Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: underTest2.GetName()}}))
+			})
+		})
+		When("a node changes status and there are no NHC objects", func() {
+			BeforeEach(func() {
+				objects = framework.NewNodes(3, 10)
+			})
+
+			It("doesn't create reconcile requests", func() {
+				handler := utils.NHCByNodeMapperFunc(client, controllerruntime.Log)
+				updatedNode := v1.Node{
+					ObjectMeta: controllerruntime.ObjectMeta{Name: "healthy-node-1"},
+				}
+				requests := handler(&updatedNode)
+				Expect(requests).To(BeEmpty())
+			})
+		})
+	})
+})
 
 type fakeClusterUpgradeChecker struct {
 	upgrading bool
@@ -638,3 +1778,74 @@ var _ cluster.UpgradeChecker = fakeClusterUpgradeChecker{}
 func (c fakeClusterUpgradeChecker) Check() (bool, error) {
 	return c.upgrading, c.err
 }
+
+type fakePlatformHealthChecker struct {
+	degraded []string
+	err      error
+}
+
+// force implementation of interface
+var _ cluster.PlatformHealthChecker = fakePlatformHealthChecker{}
+
+func (c fakePlatformHealthChecker) DegradedOperators() ([]string, error) {
+	return c.degraded, c.err
+}
+
+type fakeSafeModeChecker struct {
+	reason string
+}
+
+// force implementation of interface
+var _ safemode.Checker = fakeSafeModeChecker{}
+
+func (c fakeSafeModeChecker) Active() (bool, string) {
+	return true, c.reason
+}
+
+type fakePermissionsChecker struct {
+	missing []string
+	err     error
+}
+
+// force implementation of interface
+var _ rbac.PermissionsChecker = fakePermissionsChecker{}
+
+func (c fakePermissionsChecker) MissingPermissions(_ context.Context, _ *v1.ObjectReference) ([]string, error) {
+	return c.missing, c.err
+}
+
+type fakeEventsAggregator struct {
+	counts map[string]int
+}
+
+// force implementation of interface
+var _ events.Aggregator = fakeEventsAggregator{}
+
+func (a fakeEventsAggregator) Start(_ context.Context) error {
+	return nil
+}
+
+func (a fakeEventsAggregator) CountRecent(nodeName string, reason string, _ time.Duration) int {
+	return a.counts[fmt.Sprintf("%s/%s", nodeName, reason)]
+}
+
+func (a fakeEventsAggregator) CountRecentFor(kind string, name string, reason string, _ time.Duration) int {
+	return a.counts[fmt.Sprintf("%s/%s/%s", kind, name, reason)]
+}
+
+// ssaFakeClient translates server side apply patches into merge patches,
+// the fake client doesn't support apply patches
+type ssaFakeClient struct {
+	ctrlruntimeclient.Client
+}
+
+func (c ssaFakeClient) Patch(ctx context.Context, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.PatchOption) error {
+	if patch == ctrlruntimeclient.Apply {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		patch = ctrlruntimeclient.RawPatch(types.MergePatchType, data)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
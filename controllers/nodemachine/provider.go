@@ -0,0 +1,170 @@
+package nodemachine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/api/machine/v1beta1"
+)
+
+const (
+	// openshiftMachineAnnotation is set on nodes by the OpenShift machine API,
+	// it holds the namespaced name of the machine backing the node
+	openshiftMachineAnnotation = "machine.openshift.io/machine"
+
+	// capiMachineAnnotation is set on nodes by the Cluster API,
+	// it holds the name of the machine backing the node
+	capiMachineAnnotation = "cluster.x-k8s.io/machine"
+	// capiNamespaceAnnotation is set on nodes by the Cluster API,
+	// it holds the namespace of the machine backing the node
+	capiNamespaceAnnotation = "cluster.x-k8s.io/cluster-namespace"
+
+	// bareMetalHostAnnotation is set on machines by the baremetal operator,
+	// it holds the namespaced name of the BareMetalHost backing the machine
+	bareMetalHostAnnotation = "metal3.io/BareMetalHost"
+)
+
+// MachineRef identifies the machine or host backing a node
+type MachineRef struct {
+	types.NamespacedName
+	// Kind is the kind of the backing object, e.g. Machine or BareMetalHost
+	Kind string
+}
+
+// +kubebuilder:rbac:groups=machine.openshift.io,resources=machines,verbs=get;list;watch
+
+// Provider maps a node to the machine or host identity backing it.
+// Features like terminating node detection or escalation to fencing agents need
+// this mapping, and how to get it depends on the platform the cluster runs on.
+type Provider interface {
+	// MachineForNode returns a reference to the machine or host backing the given
+	// node, or nil when this provider can't map the node
+	MachineForNode(ctx context.Context, node *v1.Node) (*MachineRef, error)
+}
+
+// NewDefaultProvider returns a Provider which tries all known mappings in order:
+// BareMetalHost lookup, OpenShift machine annotations, and CAPI annotations
+func NewDefaultProvider(c client.Client) Provider {
+	return chainProvider{
+		NewBareMetalHostProvider(c),
+		NewOpenshiftMachineProvider(),
+		NewCAPIProvider(),
+	}
+}
+
+// NewMachineProvider returns a Provider which only maps nodes to Machine objects,
+// via the OpenShift machine or CAPI annotations, skipping the BareMetalHost lookup.
+// Use it when the machine's owner chain matters, e.g. for MachineSet membership.
+func NewMachineProvider() Provider {
+	return chainProvider{
+		NewOpenshiftMachineProvider(),
+		NewCAPIProvider(),
+	}
+}
+
+// chainProvider tries multiple providers in order and returns the first match
+type chainProvider []Provider
+
+func (p chainProvider) MachineForNode(ctx context.Context, node *v1.Node) (*MachineRef, error) {
+	for _, provider := range p {
+		ref, err := provider.MachineForNode(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		if ref != nil {
+			return ref, nil
+		}
+	}
+	return nil, nil
+}
+
+// openshiftMachineProvider maps nodes via the OpenShift machine API annotation
+type openshiftMachineProvider struct{}
+
+// NewOpenshiftMachineProvider returns a Provider based on the OpenShift machine annotation
+func NewOpenshiftMachineProvider() Provider {
+	return openshiftMachineProvider{}
+}
+
+func (p openshiftMachineProvider) MachineForNode(_ context.Context, node *v1.Node) (*MachineRef, error) {
+	annotation, exists := node.GetAnnotations()[openshiftMachineAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	namespacedName, err := splitNamespacedName(annotation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s annotation on node %s", openshiftMachineAnnotation, node.GetName())
+	}
+	return &MachineRef{NamespacedName: *namespacedName, Kind: "Machine"}, nil
+}
+
+// capiProvider maps nodes via the Cluster API annotations
+type capiProvider struct{}
+
+// NewCAPIProvider returns a Provider based on the Cluster API node annotations
+func NewCAPIProvider() Provider {
+	return capiProvider{}
+}
+
+func (p capiProvider) MachineForNode(_ context.Context, node *v1.Node) (*MachineRef, error) {
+	name, exists := node.GetAnnotations()[capiMachineAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	namespace := node.GetAnnotations()[capiNamespaceAnnotation]
+	return &MachineRef{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}, Kind: "Machine"}, nil
+}
+
+// bareMetalHostProvider maps nodes to their BareMetalHost, by looking up the
+// backing machine first and following its BareMetalHost annotation
+type bareMetalHostProvider struct {
+	client  client.Client
+	machine Provider
+}
+
+// NewBareMetalHostProvider returns a Provider resolving nodes to BareMetalHosts
+func NewBareMetalHostProvider(c client.Client) Provider {
+	return &bareMetalHostProvider{
+		client:  c,
+		machine: NewOpenshiftMachineProvider(),
+	}
+}
+
+func (p *bareMetalHostProvider) MachineForNode(ctx context.Context, node *v1.Node) (*MachineRef, error) {
+	machineRef, err := p.machine.MachineForNode(ctx, node)
+	if err != nil || machineRef == nil {
+		return nil, err
+	}
+	machine := &v1beta1.Machine{}
+	if err := p.client.Get(ctx, machineRef.NamespacedName, machine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get machine %s of node %s", machineRef.Name, node.GetName())
+	}
+	annotation, exists := machine.GetAnnotations()[bareMetalHostAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	namespacedName, err := splitNamespacedName(annotation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s annotation on machine %s", bareMetalHostAnnotation, machine.GetName())
+	}
+	return &MachineRef{NamespacedName: *namespacedName, Kind: "BareMetalHost"}, nil
+}
+
+func splitNamespacedName(value string) (*types.NamespacedName, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("expected format <namespace>/<name>, got %q", value)
+	}
+	return &types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
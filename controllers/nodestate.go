@@ -0,0 +1,183 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// +kubebuilder:rbac:groups=remediation.medik8s.io,resources=noderemediationstates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=remediation.medik8s.io,resources=noderemediationstates/status,verbs=get;update;patch
+
+const (
+	// nodeStateNHCLabel links a NodeRemediationState to the NHC maintaining it
+	nodeStateNHCLabel = "remediation.medik8s.io/nodehealthcheck"
+
+	// nodeStateCooldown is how long the state of a node which turned healthy again is
+	// kept around for debugging before it is cleaned up
+	nodeStateCooldown = 10 * time.Minute
+)
+
+// updateNodeRemediationStates maintains the per node NodeRemediationState CRs of the
+// NHC: one per node which is observed unhealthy, remediated, or cooling down, living in
+// the operator's namespace. They exist for fine-grained watches and debugging, all
+// decisions are made from the NHC itself.
+func (r *NodeHealthCheckReconciler) updateNodeRemediationStates(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, unhealthyNodes []v1.Node) error {
+	namespace, err := utils.GetDeploymentNamespace()
+	if err != nil {
+		// without a known namespace there is nowhere to put the states, the NHC
+		// itself still carries all information
+		return nil
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	existing := &remediationv1alpha1.NodeRemediationStateList{}
+	if err := r.Client.List(ctx, existing, client.InNamespace(namespace),
+		client.MatchingLabels{nodeStateNHCLabel: nhc.GetName()}); err != nil {
+		return errors.Wrap(err, "failed to list NodeRemediationStates")
+	}
+	existingByNode := map[string]*remediationv1alpha1.NodeRemediationState{}
+	for i := range existing.Items {
+		existingByNode[existing.Items[i].Spec.NodeName] = &existing.Items[i]
+	}
+
+	unhealthyByName := map[string]bool{}
+	for i := range unhealthyNodes {
+		unhealthyByName[unhealthyNodes[i].GetName()] = true
+	}
+
+	// nodes which need a state right now: the unhealthy ones, plus the ones whose
+	// remediation outcome ended recently enough for the cooldown
+	desired := map[string]remediationv1alpha1.NodeRemediationStateStatus{}
+	for nodeName := range unhealthyByName {
+		desired[nodeName] = r.nodeStatePhase(nhc, nodeName)
+	}
+	for nodeName, outcome := range nhc.Status.LastRemediationOutcomes {
+		if unhealthyByName[nodeName] {
+			continue
+		}
+		if outcome.Ended != nil && r.now().Sub(outcome.Ended.Time) < nodeStateCooldown {
+			desired[nodeName] = remediationv1alpha1.NodeRemediationStateStatus{Phase: remediationv1alpha1.NodeStateCooldown}
+		}
+	}
+
+	for nodeName, status := range desired {
+		state, exists := existingByNode[nodeName]
+		if !exists {
+			state = &remediationv1alpha1.NodeRemediationState{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      nodeName,
+					Namespace: namespace,
+					Labels:    map[string]string{nodeStateNHCLabel: nhc.GetName()},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(nhc, remediationv1alpha1.GroupVersion.WithKind("NodeHealthCheck")),
+					},
+				},
+				Spec: remediationv1alpha1.NodeRemediationStateSpec{NodeName: nodeName},
+			}
+			if err := r.Client.Create(ctx, state); err != nil {
+				return errors.Wrapf(err, "failed to create the NodeRemediationState of node %s", nodeName)
+			}
+		}
+		if state.Status.Phase == status.Phase && state.Status.Step == status.Step && state.Status.Remediator == status.Remediator {
+			continue
+		}
+		// the step counter survives phase recalculation: it grows by one whenever
+		// remediation starts or moves to another remediator within one unhealthiness
+		if status.Phase == remediationv1alpha1.NodeStateRemediating {
+			status.Step = state.Status.Step
+			if state.Status.Phase != remediationv1alpha1.NodeStateRemediating {
+				status.Step++
+			} else if state.Status.Remediator != status.Remediator {
+				status.Step++
+			}
+			if status.Step == 0 {
+				status.Step = 1
+			}
+		}
+		now := metav1.Time{Time: r.now()}
+		status.LastTransition = &now
+		if state.Status.Phase == status.Phase {
+			// only step or remediator changed, keep the phase transition time
+			status.LastTransition = state.Status.LastTransition
+		}
+		log.Info("node changed its health check state", "Node name", nodeName,
+			"previous phase", state.Status.Phase, "phase", status.Phase, "step", status.Step)
+		// the condition history is maintained separately, see updateConditionHistories
+		status.ConditionHistory = state.Status.ConditionHistory
+		state.Status = status
+		if err := r.Client.Status().Update(ctx, state); err != nil {
+			return errors.Wrapf(err, "failed to update the NodeRemediationState of node %s", nodeName)
+		}
+	}
+
+	// drop states of nodes which are healthy and out of the cooldown, unless their
+	// condition history is still fresh: it must survive the healthy interludes of
+	// flapping nodes, see updateConditionHistories
+	for nodeName, state := range existingByNode {
+		if _, needed := desired[nodeName]; needed {
+			continue
+		}
+		if hasFreshConditionHistory(state, r.now()) {
+			if state.Status.Phase != remediationv1alpha1.NodeStateObserving {
+				now := metav1.Time{Time: r.now()}
+				state.Status.Phase = remediationv1alpha1.NodeStateObserving
+				state.Status.Step = 0
+				state.Status.Remediator = ""
+				state.Status.LastTransition = &now
+				if err := r.Client.Status().Update(ctx, state); err != nil {
+					return errors.Wrapf(err, "failed to update the NodeRemediationState of node %s", nodeName)
+				}
+			}
+			continue
+		}
+		if err := r.Client.Delete(ctx, state); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete the NodeRemediationState of node %s", nodeName)
+		}
+	}
+	return nil
+}
+
+// nodeStatePhase derives the state machine status of one unhealthy node from the NHC
+func (r *NodeHealthCheckReconciler) nodeStatePhase(nhc *remediationv1alpha1.NodeHealthCheck, nodeName string) remediationv1alpha1.NodeRemediationStateStatus {
+	if _, inFlight := nhc.Status.InFlightRemediations[nodeName]; inFlight {
+		status := remediationv1alpha1.NodeRemediationStateStatus{Phase: remediationv1alpha1.NodeStateRemediating}
+		if outcome, exists := nhc.Status.LastRemediationOutcomes[nodeName]; exists && outcome.Result == "" {
+			status.Remediator = outcome.Remediator
+		}
+		return status
+	}
+	if nhc.Spec.ObservationWindow != nil {
+		if firstObserved, observed := nhc.Status.UnhealthyNodeObservations[nodeName]; observed &&
+			r.now().Sub(firstObserved.Time) < nhc.Spec.ObservationWindow.Duration {
+			return remediationv1alpha1.NodeRemediationStateStatus{Phase: remediationv1alpha1.NodeStateObserving}
+		}
+	}
+	return remediationv1alpha1.NodeRemediationStateStatus{Phase: remediationv1alpha1.NodeStateUnhealthy}
+}
@@ -0,0 +1,74 @@
+// Package notify posts remediation lifecycle notifications to external chat systems
+// like Slack or MS Teams. Notifications are best effort and never block remediation.
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// ReasonRemediationStarted is sent when a remediation CR was created for a node
+	ReasonRemediationStarted = "RemediationStarted"
+	// ReasonRemediationSucceeded is sent when a remediated node turned healthy again
+	ReasonRemediationSucceeded = "RemediationSucceeded"
+	// ReasonRemediationEscalated is sent when remediation moved to another remediator kind
+	ReasonRemediationEscalated = "RemediationEscalated"
+
+	// postTimeout bounds how long a single notification may delay reconciliation
+	postTimeout = 5 * time.Second
+)
+
+// Notification is the payload posted to the configured providers
+type Notification struct {
+	// Reason is one of the Reason* constants
+	Reason string
+	// NodeHealthCheck is the name of the NHC the notification belongs to
+	NodeHealthCheck string
+	// Node is the name of the affected node
+	Node string
+	// Remediator is the kind of the remediation CR involved
+	Remediator string
+	// Message is a human readable description of what happened
+	Message string
+	// Timestamp is when the notified event happened
+	Timestamp time.Time
+}
+
+// Provider posts a notification to one external channel. Implementations must respect
+// the context deadline.
+type Provider interface {
+	Post(ctx context.Context, notification Notification) error
+}
+
+// Notifier fans notifications out to all configured providers
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification)
+}
+
+type notifier struct {
+	providers []Provider
+	log       logr.Logger
+}
+
+// NewNotifier returns a Notifier posting to all the given providers
+func NewNotifier(log logr.Logger, providers ...Provider) Notifier {
+	return &notifier{
+		providers: providers,
+		log:       log,
+	}
+}
+
+func (n *notifier) Notify(ctx context.Context, notification Notification) {
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+	for _, provider := range n.providers {
+		if err := provider.Post(ctx, notification); err != nil {
+			// notifications are best effort, log and move on
+			n.log.Error(err, "failed to post notification",
+				"reason", notification.Reason, "Node name", notification.Node)
+		}
+	}
+}
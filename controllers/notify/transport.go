@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TrustedCABundleFile is where the cluster-wide trusted CA bundle is mounted by
+// default, see the trusted-ca ConfigMap in the deployment manifest. OpenShift's
+// network operator injects the Proxy object's trustedCA into labeled ConfigMaps.
+const TrustedCABundleFile = "/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem"
+
+// reloadingTransport is the shared transport of all outbound notifications. It
+// honors the cluster-wide proxy via the standard proxy environment variables,
+// which OLM propagates from the Proxy object into the operator deployment, and
+// trusts the CAs of the configured bundle file on top of the system pool. The
+// bundle is re-read whenever the mounted file changes, so a rotated custom CA
+// doesn't need an operator restart.
+type reloadingTransport struct {
+	caBundleFile string
+
+	mutex     sync.Mutex
+	modTime   time.Time
+	transport *http.Transport
+}
+
+// NewTransport returns the RoundTripper outbound notification providers must use,
+// see reloadingTransport. An empty or missing caBundleFile falls back to the
+// system pool, clusters without a custom CA don't mount a bundle.
+func NewTransport(caBundleFile string) http.RoundTripper {
+	return &reloadingTransport{caBundleFile: caBundleFile}
+}
+
+func (t *reloadingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	transport, err := t.currentTransport()
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(request)
+}
+
+func (t *reloadingTransport) currentTransport() (*http.Transport, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var modTime time.Time
+	if t.caBundleFile != "" {
+		if info, err := os.Stat(t.caBundleFile); err == nil {
+			modTime = info.ModTime()
+		}
+		// a missing bundle file is fine, the system pool is used then
+	}
+	if t.transport != nil && modTime.Equal(t.modTime) {
+		return t.transport, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the system cert pool")
+	}
+	if !modTime.IsZero() {
+		bundle, err := os.ReadFile(t.caBundleFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the CA bundle %q", t.caBundleFile)
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, errors.Errorf("the CA bundle %q contains no certificates", t.caBundleFile)
+		}
+	}
+	t.transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}
+	t.modTime = modTime
+	return t.transport, nil
+}
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReloadingTransport(t *testing.T) {
+	t.Run("a missing bundle file falls back to the system pool", func(t *testing.T) {
+		transport := NewTransport(filepath.Join(t.TempDir(), "missing.pem")).(*reloadingTransport)
+		if _, err := transport.currentTransport(); err != nil {
+			t.Errorf("expected the system pool fallback, got %v", err)
+		}
+	})
+
+	t.Run("a bundle without certificates is an error", func(t *testing.T) {
+		bundleFile := filepath.Join(t.TempDir(), "bundle.pem")
+		if err := os.WriteFile(bundleFile, []byte("not a certificate"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		transport := NewTransport(bundleFile).(*reloadingTransport)
+		if _, err := transport.currentTransport(); err == nil {
+			t.Error("expected an error for the unparsable bundle")
+		}
+	})
+
+	t.Run("the transport is rebuilt when the bundle changes", func(t *testing.T) {
+		bundleFile := filepath.Join(t.TempDir(), "bundle.pem")
+		if err := os.WriteFile(bundleFile, testCertificatePEM(t), 0600); err != nil {
+			t.Fatal(err)
+		}
+		transport := NewTransport(bundleFile).(*reloadingTransport)
+		first, err := transport.currentTransport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached, err := transport.currentTransport(); err != nil || cached != first {
+			t.Errorf("expected the unchanged bundle to reuse the transport, got %v, %v", cached, err)
+		}
+		// rewrite the bundle with a newer timestamp, like a rotated ConfigMap mount
+		if err := os.WriteFile(bundleFile, testCertificatePEM(t), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(bundleFile, time.Now(), time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		reloaded, err := transport.currentTransport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reloaded == first {
+			t.Error("expected the changed bundle to rebuild the transport")
+		}
+	})
+}
+
+// testCertificatePEM returns a freshly generated self-signed certificate
+func testCertificatePEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-ca"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// webhookSecretEntry is the secret entry holding the webhook URL
+const webhookSecretEntry = "webhook-url"
+
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+
+// webhookProvider posts JSON payloads to a webhook URL read from a secret in the
+// deployment namespace. The concrete chat payload format is pluggable, so Slack and
+// Teams share the transport.
+type webhookProvider struct {
+	client     client.Reader
+	namespace  string
+	secretName string
+	format     func(notification Notification) interface{}
+	httpClient *http.Client
+
+	// the url is read lazily and cached, changing the secret needs an operator restart
+	mutex sync.Mutex
+	url   string
+}
+
+// NewSlackProvider returns a Provider posting to a Slack incoming webhook. The webhook
+// URL is read from the "webhook-url" entry of the given secret; the reader must be
+// uncached, the granted RBAC is a plain get and a cached secret read would start an
+// informer that never syncs. The transport carries the cluster-wide proxy and CA
+// settings, see NewTransport.
+func NewSlackProvider(c client.Reader, namespace string, secretName string, transport http.RoundTripper) Provider {
+	return &webhookProvider{
+		client:     c,
+		namespace:  namespace,
+		secretName: secretName,
+		format:     slackPayload,
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+// NewTeamsProvider returns a Provider posting to an MS Teams incoming webhook. The
+// webhook URL is read from the "webhook-url" entry of the given secret; the reader
+// must be uncached, see NewSlackProvider. The transport carries the cluster-wide
+// proxy and CA settings, see NewTransport.
+func NewTeamsProvider(c client.Reader, namespace string, secretName string, transport http.RoundTripper) Provider {
+	return &webhookProvider{
+		client:     c,
+		namespace:  namespace,
+		secretName: secretName,
+		format:     teamsPayload,
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+func (p *webhookProvider) Post(ctx context.Context, notification Notification) error {
+	url, err := p.webhookURL(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(p.format(notification))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the notification payload")
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to build the webhook request")
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to post to the webhook")
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return errors.Errorf("the webhook returned status %q", response.Status)
+	}
+	return nil
+}
+
+func (p *webhookProvider) webhookURL(ctx context.Context) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.url != "" {
+		return p.url, nil
+	}
+	secret := &v1.Secret{}
+	if err := p.client.Get(ctx, client.ObjectKey{Namespace: p.namespace, Name: p.secretName}, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to get the webhook secret %q/%q", p.namespace, p.secretName)
+	}
+	url, exists := secret.Data[webhookSecretEntry]
+	if !exists || len(url) == 0 {
+		return "", errors.Errorf("the webhook secret %q/%q has no %q entry", p.namespace, p.secretName, webhookSecretEntry)
+	}
+	p.url = string(url)
+	return p.url, nil
+}
+
+// slackPayload is the minimal payload accepted by Slack incoming webhooks
+func slackPayload(notification Notification) interface{} {
+	return map[string]string{
+		"text": fmt.Sprintf("*%s* NodeHealthCheck %s: %s",
+			notification.Reason, notification.NodeHealthCheck, notification.Message),
+	}
+}
+
+// teamsPayload is the legacy MessageCard format accepted by Teams incoming webhooks
+func teamsPayload(notification Notification) interface{} {
+	title := fmt.Sprintf("%s: NodeHealthCheck %s", notification.Reason, notification.NodeHealthCheck)
+	return map[string]string{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  title,
+		"title":    title,
+		"text":     notification.Message,
+	}
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	eventReasonSafeMode       = "OperatorSafeMode"
+	eventReasonSafeModeLifted = "OperatorSafeModeLifted"
+)
+
+// checkSafeMode reports whether the operator runs in post-crash-loop safe mode,
+// and keeps the NHC's GloballyPaused condition and events in sync with it. In
+// safe mode the operator observes and reports but starts no new remediations
+// until it ran stable for a while, see the safe-mode-marker-file flag.
+func (r *NodeHealthCheckReconciler) checkSafeMode(nhc *remediationv1alpha1.NodeHealthCheck) bool {
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	active, reason := false, ""
+	if r.SafeModeChecker != nil {
+		active, reason = r.SafeModeChecker.Active()
+	}
+
+	if !active {
+		if utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeGloballyPaused, remediationv1alpha1.ConditionReasonGlobalPauseSafeMode) {
+			log.Info("the operator left safe mode, fencing resumes")
+			setStatusCondition(nhc, metav1.Condition{
+				Type:    remediationv1alpha1.ConditionTypeGloballyPaused,
+				Status:  metav1.ConditionFalse,
+				Reason:  remediationv1alpha1.ConditionReasonGlobalPauseNotActive,
+				Message: "The global remediation pause is not active",
+			})
+			r.Recorder.Event(nhc, eventTypeNormal, eventReasonSafeModeLifted, "The operator left safe mode, fencing resumed")
+		}
+		return false
+	}
+
+	if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeGloballyPaused, remediationv1alpha1.ConditionReasonGlobalPauseSafeMode) {
+		log.Info("the operator runs in safe mode after a crash loop, fencing is suspended", "reason", reason)
+		setStatusCondition(nhc, metav1.Condition{
+			Type:    remediationv1alpha1.ConditionTypeGloballyPaused,
+			Status:  metav1.ConditionTrue,
+			Reason:  remediationv1alpha1.ConditionReasonGlobalPauseSafeMode,
+			Message: reason,
+		})
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonSafeMode, reason)
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package pooldisable
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+
+	"github.com/medik8s/node-healthcheck-operator/controllers/nodemachine"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// DisableAnnotation opts a whole machine pool out of remediation when set to "true"
+// on its MachineSet or MachineConfigPool. Nodes of disabled pools are skipped by NHC
+// as if they weren't selected at all.
+const DisableAnnotation = "remediation.medik8s.io/disable"
+
+// +kubebuilder:rbac:groups=machine.openshift.io,resources=machinesets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigpools,verbs=get;list;watch
+
+// machineConfigPoolListGVK identifies MachineConfigPools, whose typed API is not
+// vendored here, so they are read as unstructured objects
+var machineConfigPoolListGVK = schema.GroupVersionKind{
+	Group:   "machineconfiguration.openshift.io",
+	Version: "v1",
+	Kind:    "MachineConfigPoolList",
+}
+
+// Resolver tells whether a node belongs to a machine pool which opted out of
+// remediation with the DisableAnnotation
+type Resolver interface {
+	// DisabledPoolForNode returns a description of the disabled pool the given node
+	// belongs to, or an empty string when no pool disables it
+	DisabledPoolForNode(ctx context.Context, node *v1.Node) (string, error)
+}
+
+// NewResolver returns a Resolver fitting the current platform: on OpenShift pool
+// membership is resolved via the machine API and MachineConfigPools, elsewhere there
+// are no machine pools and nothing is ever disabled
+func NewResolver(mgr manager.Manager) (Resolver, error) {
+	openshift, err := utils.IsOnOpenshift(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	if !openshift {
+		return DummyResolver{}, nil
+	}
+	return NewOpenshiftResolver(mgr.GetClient()), nil
+}
+
+// DummyResolver is used on clusters without machine pools, it never disables a node
+type DummyResolver struct{}
+
+var _ Resolver = DummyResolver{}
+
+// DisabledPoolForNode always reports the node as not disabled
+func (DummyResolver) DisabledPoolForNode(_ context.Context, _ *v1.Node) (string, error) {
+	return "", nil
+}
+
+// openshiftResolver resolves pool membership via the OpenShift machine API: the node's
+// machine annotation leads to its Machine, whose owning MachineSet is the pool. On top
+// of that MachineConfigPools are matched against the node labels.
+type openshiftResolver struct {
+	client  client.Client
+	machine nodemachine.Provider
+}
+
+// NewOpenshiftResolver returns a Resolver for OpenShift clusters
+func NewOpenshiftResolver(c client.Client) Resolver {
+	return &openshiftResolver{
+		client:  c,
+		machine: nodemachine.NewOpenshiftMachineProvider(),
+	}
+}
+
+func (p *openshiftResolver) DisabledPoolForNode(ctx context.Context, node *v1.Node) (string, error) {
+	pool, err := p.disabledMachineSet(ctx, node)
+	if err != nil || pool != "" {
+		return pool, err
+	}
+	return p.disabledMachineConfigPool(ctx, node)
+}
+
+// disabledMachineSet follows the node's machine annotation to its Machine and checks
+// the owning MachineSet for the DisableAnnotation
+func (p *openshiftResolver) disabledMachineSet(ctx context.Context, node *v1.Node) (string, error) {
+	machineRef, err := p.machine.MachineForNode(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	if machineRef == nil {
+		return "", nil
+	}
+	machine := &machinev1beta1.Machine{}
+	if err := p.client.Get(ctx, machineRef.NamespacedName, machine); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to get the machine of node %s", node.GetName())
+	}
+	for _, owner := range machine.GetOwnerReferences() {
+		if owner.Kind != "MachineSet" {
+			continue
+		}
+		machineSet := &machinev1beta1.MachineSet{}
+		key := client.ObjectKey{Namespace: machine.GetNamespace(), Name: owner.Name}
+		if err := p.client.Get(ctx, key, machineSet); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", errors.Wrapf(err, "failed to get MachineSet %s of node %s", owner.Name, node.GetName())
+		}
+		if machineSet.GetAnnotations()[DisableAnnotation] == "true" {
+			return fmt.Sprintf("MachineSet %s/%s", machineSet.GetNamespace(), machineSet.GetName()), nil
+		}
+	}
+	return "", nil
+}
+
+// disabledMachineConfigPool matches the node labels against the node selectors of
+// MachineConfigPools carrying the DisableAnnotation
+func (p *openshiftResolver) disabledMachineConfigPool(ctx context.Context, node *v1.Node) (string, error) {
+	pools := &unstructured.UnstructuredList{}
+	pools.SetGroupVersionKind(machineConfigPoolListGVK)
+	if err := p.client.List(ctx, pools); err != nil {
+		if meta.IsNoMatchError(err) {
+			// not every OpenShift variant runs the machine config operator
+			return "", nil
+		}
+		return "", errors.Wrap(err, "failed to list MachineConfigPools")
+	}
+	for i := range pools.Items {
+		pool := &pools.Items[i]
+		if pool.GetAnnotations()[DisableAnnotation] != "true" {
+			continue
+		}
+		selectorMap, exists, err := unstructured.NestedMap(pool.Object, "spec", "nodeSelector")
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid node selector on MachineConfigPool %s", pool.GetName())
+		}
+		if !exists {
+			continue
+		}
+		labelSelector := &metav1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, labelSelector); err != nil {
+			return "", errors.Wrapf(err, "invalid node selector on MachineConfigPool %s", pool.GetName())
+		}
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid node selector on MachineConfigPool %s", pool.GetName())
+		}
+		if selector.Matches(labels.Set(node.GetLabels())) {
+			return fmt.Sprintf("MachineConfigPool %s", pool.GetName()), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/nodemachine"
+)
+
+// nodePoolAnnotation is set on nodes by Hypershift, it holds the namespaced name
+// of the NodePool the node belongs to
+const nodePoolAnnotation = "hypershift.openshift.io/nodePool"
+
+// filterPoolRefNodes reduces the selected nodes to the members of the pools
+// referenced in spec.machineSetRefs and spec.nodePoolRefs. With no refs configured
+// the label selector alone decides and all nodes pass.
+func (r *NodeHealthCheckReconciler) filterPoolRefNodes(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, nodes []v1.Node) ([]v1.Node, error) {
+	if len(nhc.Spec.MachineSetRefs) == 0 && len(nhc.Spec.NodePoolRefs) == 0 {
+		return nodes, nil
+	}
+	var selected []v1.Node
+	for i := range nodes {
+		member, err := r.isPoolMember(ctx, nhc, &nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		if member {
+			selected = append(selected, nodes[i])
+		}
+	}
+	return selected, nil
+}
+
+// isPoolMember reports whether the node belongs to one of the referenced pools:
+// NodePool membership is read from the node's Hypershift annotation, MachineSet
+// membership is resolved via the machine ownership chain
+func (r *NodeHealthCheckReconciler) isPoolMember(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node) (bool, error) {
+	if nodePool, exists := node.GetAnnotations()[nodePoolAnnotation]; exists {
+		for _, ref := range nhc.Spec.NodePoolRefs {
+			if nodePool == ref.Namespace+"/"+ref.Name {
+				return true, nil
+			}
+		}
+	}
+	if len(nhc.Spec.MachineSetRefs) == 0 {
+		return false, nil
+	}
+	machineRef, err := nodemachine.NewMachineProvider().MachineForNode(ctx, node)
+	if err != nil {
+		return false, err
+	}
+	if machineRef == nil {
+		// unmanaged nodes can't belong to a MachineSet
+		return false, nil
+	}
+	machine := &machinev1beta1.Machine{}
+	if err := r.Client.Get(ctx, machineRef.NamespacedName, machine); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get the machine of node %s", node.GetName())
+	}
+	for _, owner := range machine.GetOwnerReferences() {
+		if owner.Kind != "MachineSet" {
+			continue
+		}
+		for _, ref := range nhc.Spec.MachineSetRefs {
+			if ref.Namespace == machine.GetNamespace() && ref.Name == owner.Name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,234 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+const (
+	// verifyPeriod is how often the held permissions are compared against the
+	// remediation kinds the NodeHealthChecks reference
+	verifyPeriod = 10 * time.Minute
+
+	eventReasonPermissionsMissing = "RemediationPermissionsMissing"
+	eventTypeWarning              = "Warning"
+)
+
+// the verbs the controller needs on remediation CRs and on their templates
+var (
+	remediationVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+	templateVerbs    = []string{"get", "list", "watch"}
+)
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
+
+// PermissionsChecker reports the remediation permissions the operator lacks for
+// one template reference, implemented by the Verifier
+type PermissionsChecker interface {
+	MissingPermissions(ctx context.Context, ref *v1.ObjectReference) ([]string, error)
+}
+
+// Verifier periodically compares the permissions the operator's service account
+// actually holds against the remediation kinds the NodeHealthChecks reference.
+// The remediation permissions come from the aggregated cluster role, so a missing
+// or mislabeled remediator role only surfaces at fencing time, the worst moment
+// to find out. The verifier reports missing permissions ahead of time, and flags
+// aggregated permissions no NHC needs anymore, which hardened clusters want
+// removed. It runs on the leader only, like the other singleton background
+// components.
+type Verifier struct {
+	client   client.Client
+	mapper   meta.RESTMapper
+	recorder record.EventRecorder
+	log      logr.Logger
+}
+
+var _ manager.Runnable = &Verifier{}
+var _ manager.LeaderElectionRunnable = &Verifier{}
+var _ PermissionsChecker = &Verifier{}
+
+// NewVerifier returns a Verifier backed by the given manager
+func NewVerifier(mgr ctrl.Manager, log logr.Logger) *Verifier {
+	return &Verifier{
+		client:   mgr.GetClient(),
+		mapper:   mgr.GetRESTMapper(),
+		recorder: mgr.GetEventRecorderFor("rbacVerifier"),
+		log:      log,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (v *Verifier) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable
+func (v *Verifier) Start(ctx context.Context) error {
+	ticker := time.NewTicker(verifyPeriod)
+	defer ticker.Stop()
+	for {
+		if err := v.Verify(ctx); err != nil {
+			// verification is best effort, remediation must keep working
+			v.log.Error(err, "failed to verify the remediation permissions")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Verify checks the permissions for every remediation kind referenced by the
+// NodeHealthChecks, reports missing ones per NHC, and logs aggregated permissions
+// which no NHC references anymore
+func (v *Verifier) Verify(ctx context.Context) error {
+	nhcList := &remediationv1alpha1.NodeHealthCheckList{}
+	if err := v.client.List(ctx, nhcList); err != nil {
+		return err
+	}
+
+	referencedGroups := map[string]bool{}
+	for i := range nhcList.Items {
+		nhc := &nhcList.Items[i]
+		var missing []string
+		for _, ref := range templateRefs(nhc) {
+			referencedGroups[ref.GroupVersionKind().Group] = true
+			refMissing, err := v.MissingPermissions(ctx, ref)
+			if err != nil {
+				v.log.Error(err, "failed to check the permissions for a remediation template",
+					"NodeHealthCheck name", nhc.GetName(), "template kind", ref.Kind)
+				continue
+			}
+			missing = append(missing, refMissing...)
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		sort.Strings(missing)
+		msg := fmt.Sprintf("The operator lacks permissions needed for remediation: %s. Check that the remediator's cluster role carries the %s label.",
+			strings.Join(missing, ", "), aggregationLabelKey)
+		v.log.Info("missing remediation permissions", "NodeHealthCheck name", nhc.GetName(), "missing", missing)
+		v.recorder.Event(nhc, eventTypeWarning, eventReasonPermissionsMissing, msg)
+	}
+
+	v.reportExcessPermissions(ctx, referencedGroups)
+	return nil
+}
+
+// MissingPermissions returns the denied verb/resource pairs for the given template
+// reference, covering both the template and the remediation CRs derived from it
+func (v *Verifier) MissingPermissions(ctx context.Context, ref *v1.ObjectReference) ([]string, error) {
+	var missing []string
+
+	templateGVK := ref.GroupVersionKind()
+	templateMissing, err := v.deniedVerbs(ctx, templateGVK, ref.Namespace, templateVerbs)
+	if err != nil {
+		return nil, err
+	}
+	missing = append(missing, templateMissing...)
+
+	remediationGVK, err := remediationv1alpha1.RemediationGVK(ref)
+	if err != nil {
+		return nil, err
+	}
+	remediationMissing, err := v.deniedVerbs(ctx, remediationGVK, ref.Namespace, remediationVerbs)
+	if err != nil {
+		return nil, err
+	}
+	return append(missing, remediationMissing...), nil
+}
+
+// deniedVerbs resolves the kind to its resource and asks the API server which of
+// the given verbs the operator's service account is denied on it
+func (v *Verifier) deniedVerbs(ctx context.Context, gvk schema.GroupVersionKind, namespace string, verbs []string) ([]string, error) {
+	mapping, err := v.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// the CRD isn't installed, which the template resolver reports already
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var denied []string
+	for _, verb := range verbs {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:     mapping.Resource.Group,
+					Version:   mapping.Resource.Version,
+					Resource:  mapping.Resource.Resource,
+					Namespace: namespace,
+					Verb:      verb,
+				},
+			},
+		}
+		if err := v.client.Create(ctx, review); err != nil {
+			return nil, err
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, fmt.Sprintf("%s %s.%s", verb, mapping.Resource.Resource, mapping.Resource.Group))
+		}
+	}
+	return denied, nil
+}
+
+// reportExcessPermissions logs the rules of the aggregated cluster role whose API
+// groups no NodeHealthCheck references, so cluster admins can unlabel the stale
+// remediator roles
+func (v *Verifier) reportExcessPermissions(ctx context.Context, referencedGroups map[string]bool) {
+	role := &rbacv1.ClusterRole{}
+	if err := v.client.Get(ctx, client.ObjectKey{Name: roleName}, role); err != nil {
+		if !apierrors.IsNotFound(err) {
+			v.log.Error(err, "failed to get the aggregated cluster role")
+		}
+		return
+	}
+	for _, rule := range role.Rules {
+		for _, group := range rule.APIGroups {
+			if !referencedGroups[group] {
+				v.log.Info("the aggregated cluster role grants permissions no NodeHealthCheck references, "+
+					"consider removing the aggregation label from the granting role",
+					"apiGroup", group, "resources", rule.Resources, "verbs", rule.Verbs)
+			}
+		}
+	}
+}
+
+// templateRefs collects the remediation template references from all spec fields
+// which hold one
+func templateRefs(nhc *remediationv1alpha1.NodeHealthCheck) []*v1.ObjectReference {
+	refs := []*v1.ObjectReference{nhc.Spec.RemediationTemplate, nhc.Spec.FallbackRemediationTemplate}
+	for i := range nhc.Spec.RemediationTemplates {
+		refs = append(refs, nhc.Spec.RemediationTemplates[i].RemediationTemplate)
+	}
+	for _, ref := range nhc.Spec.OSRemediationTemplates {
+		refs = append(refs, ref)
+	}
+	var set []*v1.ObjectReference
+	for _, ref := range refs {
+		if ref != nil {
+			set = append(set, ref)
+		}
+	}
+	return set
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+	"github.com/medik8s/node-healthcheck-operator/reason"
+)
+
+// remediationReasonAnnotations derives the annotations stamped on a new remediation
+// CR which tell the remediator why the node is remediated, so it can choose softer or
+// harder actions. The reason package defines the contract.
+func (r *NodeHealthCheckReconciler) remediationReasonAnnotations(nhc *remediationv1alpha1.NodeHealthCheck, node *v1.Node, escalationStep int) map[string]string {
+	annotations := map[string]string{
+		reason.EscalationStepAnnotation: strconv.Itoa(escalationStep),
+	}
+	var triggers []string
+	if matched := utils.MatchedUnhealthyConditions(nhc.Spec.UnhealthyConditions, node.Status.Conditions, r.now()); len(matched) > 0 {
+		triggers = append(triggers, reason.TriggerCondition)
+		entries := make([]string, 0, len(matched))
+		for _, c := range matched {
+			entries = append(entries, fmt.Sprintf("%s=%s:%s", c.Type, c.Status, c.Duration.Duration))
+		}
+		annotations[reason.UnhealthyConditionsAnnotation] = strings.Join(entries, ",")
+	}
+	if r.hasUnhealthyEvents(nhc, node) {
+		triggers = append(triggers, reason.TriggerEvent)
+	}
+	if r.hasUnhealthyCEL(nhc, node) {
+		triggers = append(triggers, reason.TriggerCEL)
+	}
+	if len(triggers) == 0 {
+		// conditions, events and CEL don't match, a degraded resource observation
+		// is the only remaining unhealthy criterion
+		triggers = append(triggers, reason.TriggerResourceDegraded)
+	}
+	annotations[reason.TriggerAnnotation] = strings.Join(triggers, ",")
+	return annotations
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// The subsystems feeding the reconciler return typed errors for the failure
+// classes which need more than a workqueue retry. surfaceReconcileError maps each
+// type to its condition, event and requeue behavior in one place, and
+// reconcileErrorCategory buckets them for the reconcile errors metric. New
+// features should add a type and a mapping entry here instead of inlining their
+// own condition and event plumbing.
+
+const eventReasonPermissionsDenied = "RemediationPermissionsDenied"
+
+// The typed errors deliberately don't implement the pkg/errors causer interface:
+// they are the cause callers switch on, wrapping them with errors.Wrap for
+// context stays transparent either way.
+
+// templateNotFoundError reports a referenced remediation template which doesn't
+// exist, the NHC is disabled until it shows up
+type templateNotFoundError struct {
+	ref *v1.ObjectReference
+}
+
+func (e templateNotFoundError) Error() string {
+	return fmt.Sprintf("remediation template not found. Kind: %s, Namespace: %s, Name: %s",
+		e.ref.GroupVersionKind().Kind, e.ref.Namespace, e.ref.Name)
+}
+
+// leaseHeldError reports a node lease held by another holder, remediation of the
+// node is skipped until the lease frees up, see lease.AlreadyHeldError
+type leaseHeldError struct {
+	nodeName string
+}
+
+func (e leaseHeldError) Error() string {
+	return fmt.Sprintf("the lease of node %s is held by someone else", e.nodeName)
+}
+
+// minHealthyViolationError reports that remediating would breach spec.minHealthy,
+// or spec.minHealthyPerZone when zone is set
+type minHealthyViolationError struct {
+	healthyNodes int
+	minHealthy   int
+	zone         string
+}
+
+func (e minHealthyViolationError) Error() string {
+	if e.zone != "" {
+		return fmt.Sprintf("Skipped remediation in zone %q because the number of healthy nodes is %d and should equal or exceed %d",
+			e.zone, e.healthyNodes, e.minHealthy)
+	}
+	return fmt.Sprintf("Skipped remediation because the number of healthy nodes selected by the selector is %d and should equal or exceed %d",
+		e.healthyNodes, e.minHealthy)
+}
+
+// rbacDeniedError reports an API server denial of a remediation request, usually
+// a missing or mislabeled remediator role, see the rbac package
+type rbacDeniedError struct {
+	cause error
+}
+
+func (e rbacDeniedError) Error() string {
+	return fmt.Sprintf("the API server denied a remediation request, check the remediator's RBAC: %v", e.cause)
+}
+
+// surfaceReconcileError surfaces a typed reconcile error through its condition,
+// event and requeue. It returns whether the error was recognized, unknown errors
+// are left to the caller's generic handling. Callers pass a nil result when no
+// requeue is wanted, and decide themselves whether the error is also returned
+// for a workqueue retry.
+func (r *NodeHealthCheckReconciler) surfaceReconcileError(nhc *remediationv1alpha1.NodeHealthCheck, err error, result *ctrl.Result) bool {
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	switch typed := errors.Cause(err).(type) {
+	case templateNotFoundError:
+		if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled, remediationv1alpha1.ConditionReasonDisabledTemplateNotFound) {
+			rt := typed.ref
+			setStatusCondition(nhc, metav1.Condition{
+				Type:    remediationv1alpha1.ConditionTypeDisabled,
+				Status:  metav1.ConditionTrue,
+				Reason:  remediationv1alpha1.ConditionReasonDisabledTemplateNotFound,
+				Message: fmt.Sprintf("Remediation Template not found. Kind %s, Namespace: %s, Name %s", rt.GroupVersionKind().Kind, rt.Namespace, rt.Name),
+			})
+			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonDisabled, "Remediation Template not found. Kind: %s, Namespace: %s, Name %s", rt.GroupVersionKind().Kind, rt.Namespace, rt.Name)
+		}
+		// requeue for checking back if the template exists later
+		if result != nil {
+			updateResultNextReconcile(result, 15*time.Second)
+		}
+		return true
+
+	case templateLocationError:
+		// a misplaced template won't fix itself, disable NHC with a clear message
+		if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeDisabled, remediationv1alpha1.ConditionReasonDisabledInvalidConfig) {
+			log.Info("disabling NHC because of a misplaced remediation template")
+			setStatusCondition(nhc, metav1.Condition{
+				Type:    remediationv1alpha1.ConditionTypeDisabled,
+				Status:  metav1.ConditionTrue,
+				Reason:  remediationv1alpha1.ConditionReasonDisabledInvalidConfig,
+				Message: typed.Error(),
+			})
+			r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonDisabled, "Invalid configuration: %s", typed.Error())
+		}
+		return true
+
+	case minHealthyViolationError:
+		log.Info(typed.Error(), "healthyNodes", typed.healthyNodes, "minHealthy", typed.minHealthy)
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonRemediationSkipped, typed.Error())
+		return true
+
+	case leaseHeldError:
+		log.Info("node lease is held by someone else, skipping remediation for now", "Node name", typed.nodeName)
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonRemediationSkipped,
+			"Skipped remediation of node %s because its lease is held by someone else", typed.nodeName)
+		return true
+
+	case rbacDeniedError:
+		log.Info("the API server denied a remediation request", "denial", typed.cause.Error())
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonPermissionsDenied, typed.Error())
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+func TestReconcileErrorCategory(t *testing.T) {
+	ref := &v1.ObjectReference{Kind: "InfrastructureRemediationTemplate", Namespace: "default", Name: "template"}
+
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "a missing template is a template error",
+			err:      templateNotFoundError{ref: ref},
+			expected: metrics.ReconcileErrorCategoryTemplate,
+		},
+		{
+			name:     "a misplaced template is a template error",
+			err:      templateLocationError{msg: "misplaced"},
+			expected: metrics.ReconcileErrorCategoryTemplate,
+		},
+		{
+			name:     "a held lease is a lease error",
+			err:      leaseHeldError{nodeName: "worker-1"},
+			expected: metrics.ReconcileErrorCategoryLease,
+		},
+		{
+			name:     "an API server denial is an RBAC error",
+			err:      rbacDeniedError{cause: errors.New("forbidden")},
+			expected: metrics.ReconcileErrorCategoryRBAC,
+		},
+		{
+			name:     "wrapping doesn't hide the type",
+			err:      errors.Wrap(templateNotFoundError{ref: ref}, "failed to resolve templates"),
+			expected: metrics.ReconcileErrorCategoryTemplate,
+		},
+		{
+			name:     "an explicit category tag wins",
+			err:      categorized(errors.New("lease conflict"), metrics.ReconcileErrorCategoryLease),
+			expected: metrics.ReconcileErrorCategoryLease,
+		},
+		{
+			name:     "anything else is other",
+			err:      errors.New("boom"),
+			expected: metrics.ReconcileErrorCategoryOther,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if category := reconcileErrorCategory(c.err); category != c.expected {
+				t.Errorf("expected category %q, got %q", c.expected, category)
+			}
+		})
+	}
+}
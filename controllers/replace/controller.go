@@ -0,0 +1,154 @@
+package replace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/api/machine/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+const (
+	// machineAnnotation is set on nodes by the machine API, pointing to the node's machine
+	machineAnnotation = "machine.openshift.io/machine"
+
+	eventTypeNormal  = "Normal"
+	eventTypeWarning = "Warning"
+
+	eventReasonMachineDeleted  = "MachineDeleted"
+	eventReasonMachineNotFound = "MachineNotFound"
+)
+
+// NHC creates the remediation CRs and deletes them again on recovery, so unlike an
+// external remediator no aggregated role supplies these verbs
+// +kubebuilder:rbac:groups=remediation.medik8s.io,resources=replacemachineremediations,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=remediation.medik8s.io,resources=replacemachineremediations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=remediation.medik8s.io,resources=replacemachineremediationtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=machine.openshift.io,resources=machines,verbs=get;list;watch;delete
+
+// ReplaceMachineRemediationReconciler acts on the ReplaceMachineRemediation CRs NHC
+// creates: it deletes the unhealthy node's backing machine exactly once, letting the
+// machine set provision a replacement. It is the in-process counterpart of an external
+// remediation operator and is only registered with the enable-replace-machine-remediation
+// flag.
+type ReplaceMachineRemediationReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+}
+
+func (r *ReplaceMachineRemediationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("ReplaceMachineRemediation", req.NamespacedName)
+
+	rmr := &remediationv1alpha1.ReplaceMachineRemediation{}
+	if err := r.Get(ctx, req.NamespacedName, rmr); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed to get the ReplaceMachineRemediation")
+	}
+	if rmr.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+	if meta.FindStatusCondition(rmr.Status.Conditions, remediationv1alpha1.ConditionTypeMachineDeleted) != nil {
+		// the machine deletion is requested at most once, re-created CRs for the
+		// same attempt must not fence a second time, see the reason package
+		return ctrl.Result{}, nil
+	}
+
+	machine, err := r.findMachine(ctx, rmr.GetName())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if machine == nil {
+		log.Info("no machine backing the node was found, nothing to replace")
+		r.Recorder.Eventf(rmr, eventTypeWarning, eventReasonMachineNotFound,
+			"No machine backing node %s was found, the node can't be replaced", rmr.GetName())
+		meta.SetStatusCondition(&rmr.Status.Conditions, metav1.Condition{
+			Type:    remediationv1alpha1.ConditionTypeMachineDeleted,
+			Status:  metav1.ConditionFalse,
+			Reason:  remediationv1alpha1.ConditionReasonMachineNotFound,
+			Message: "no machine backing the node was found",
+		})
+		return ctrl.Result{}, r.Status().Update(ctx, rmr)
+	}
+
+	if machine.GetDeletionTimestamp() == nil {
+		if err := r.Delete(ctx, machine); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to delete machine %s/%s", machine.GetNamespace(), machine.GetName())
+		}
+	}
+	log.Info("requested the machine's deletion, the machine set provisions the replacement",
+		"Machine", fmt.Sprintf("%s/%s", machine.GetNamespace(), machine.GetName()))
+	r.Recorder.Eventf(rmr, eventTypeNormal, eventReasonMachineDeleted,
+		"Deleted machine %s/%s of node %s, its machine set provisions the replacement",
+		machine.GetNamespace(), machine.GetName(), rmr.GetName())
+
+	now := metav1.Now()
+	rmr.Status.Machine = fmt.Sprintf("%s/%s", machine.GetNamespace(), machine.GetName())
+	rmr.Status.MachineDeleted = &now
+	meta.SetStatusCondition(&rmr.Status.Conditions, metav1.Condition{
+		Type:   remediationv1alpha1.ConditionTypeMachineDeleted,
+		Status: metav1.ConditionTrue,
+		Reason: remediationv1alpha1.ConditionReasonMachineDeletionRequested,
+	})
+	return ctrl.Result{}, r.Status().Update(ctx, rmr)
+}
+
+// findMachine resolves the machine backing the given node, via the node's machine
+// annotation, or via the machines' node references when the node is already gone
+func (r *ReplaceMachineRemediationReconciler) findMachine(ctx context.Context, nodeName string) (*v1beta1.Machine, error) {
+	node := &v1.Node{}
+	err := r.Get(ctx, client.ObjectKey{Name: nodeName}, node)
+	if err == nil {
+		machineNsName, exists := node.GetAnnotations()[machineAnnotation]
+		if !exists {
+			return nil, nil
+		}
+		parts := strings.SplitN(machineNsName, "/", 2)
+		if len(parts) != 2 {
+			return nil, nil
+		}
+		machine := &v1beta1.Machine{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: parts[0], Name: parts[1]}, machine); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(err, "failed to get machine %s of node %s", machineNsName, nodeName)
+		}
+		return machine, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to get node %s", nodeName)
+	}
+
+	machines := &v1beta1.MachineList{}
+	if err := r.List(ctx, machines); err != nil {
+		return nil, errors.Wrap(err, "failed to list machines")
+	}
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if machine.Status.NodeRef != nil && machine.Status.NodeRef.Name == nodeName {
+			return machine, nil
+		}
+	}
+	return nil, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReplaceMachineRemediationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&remediationv1alpha1.ReplaceMachineRemediation{}).
+		Complete(r)
+}
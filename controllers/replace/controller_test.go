@@ -0,0 +1,136 @@
+package replace
+
+import (
+	"context"
+	"testing"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+func newTestReconciler(t *testing.T, objs ...client.Object) *ReplaceMachineRemediationReconciler {
+	t.Helper()
+	s := runtime.NewScheme()
+	if err := v1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add the core scheme: %v", err)
+	}
+	if err := machinev1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add the machine scheme: %v", err)
+	}
+	if err := remediationv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add the remediation scheme: %v", err)
+	}
+	return &ReplaceMachineRemediationReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		Log:      ctrl.Log.WithName("test machine replacer"),
+		Recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func newTestRMR(nodeName string) *remediationv1alpha1.ReplaceMachineRemediation {
+	return &remediationv1alpha1.ReplaceMachineRemediation{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName, Namespace: "test-ns"},
+	}
+}
+
+func reconcileRMR(t *testing.T, r *ReplaceMachineRemediationReconciler, nodeName string) *remediationv1alpha1.ReplaceMachineRemediation {
+	t.Helper()
+	request := reconcile.Request{NamespacedName: client.ObjectKey{Name: nodeName, Namespace: "test-ns"}}
+	if _, err := r.Reconcile(context.Background(), request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	rmr := &remediationv1alpha1.ReplaceMachineRemediation{}
+	if err := r.Get(context.Background(), request.NamespacedName, rmr); err != nil {
+		t.Fatalf("failed to get the ReplaceMachineRemediation: %v", err)
+	}
+	return rmr
+}
+
+func TestReconcileDeletesTheNodesMachine(t *testing.T) {
+	machine := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "machine-ns"}}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:        "node-1",
+		Annotations: map[string]string{machineAnnotation: "machine-ns/machine-1"},
+	}}
+	r := newTestReconciler(t, newTestRMR("node-1"), node, machine)
+
+	rmr := reconcileRMR(t, r, "node-1")
+
+	err := r.Get(context.Background(), client.ObjectKey{Name: "machine-1", Namespace: "machine-ns"}, &machinev1beta1.Machine{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the machine to be deleted, got %v", err)
+	}
+	if rmr.Status.Machine != "machine-ns/machine-1" {
+		t.Errorf("expected status.machine %q, got %q", "machine-ns/machine-1", rmr.Status.Machine)
+	}
+	if rmr.Status.MachineDeleted == nil {
+		t.Errorf("expected status.machineDeleted to be set")
+	}
+	if !meta.IsStatusConditionTrue(rmr.Status.Conditions, remediationv1alpha1.ConditionTypeMachineDeleted) {
+		t.Errorf("expected the MachineDeleted condition to be true")
+	}
+}
+
+func TestReconcileFindsTheMachineOfADeletedNode(t *testing.T) {
+	machine := &machinev1beta1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "machine-ns"},
+		Status:     machinev1beta1.MachineStatus{NodeRef: &v1.ObjectReference{Name: "node-1"}},
+	}
+	r := newTestReconciler(t, newTestRMR("node-1"), machine)
+
+	rmr := reconcileRMR(t, r, "node-1")
+
+	err := r.Get(context.Background(), client.ObjectKey{Name: "machine-1", Namespace: "machine-ns"}, &machinev1beta1.Machine{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the machine to be deleted, got %v", err)
+	}
+	if !meta.IsStatusConditionTrue(rmr.Status.Conditions, remediationv1alpha1.ConditionTypeMachineDeleted) {
+		t.Errorf("expected the MachineDeleted condition to be true")
+	}
+}
+
+func TestReconcileDeletesAtMostOnce(t *testing.T) {
+	machine := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "machine-ns"}}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:        "node-1",
+		Annotations: map[string]string{machineAnnotation: "machine-ns/machine-1"},
+	}}
+	r := newTestReconciler(t, newTestRMR("node-1"), node, machine)
+
+	reconcileRMR(t, r, "node-1")
+
+	// the machine set recreated the machine, a second reconcile must not delete it again
+	replacement := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "machine-ns"}}
+	if err := r.Create(context.Background(), replacement); err != nil {
+		t.Fatalf("failed to create the replacement machine: %v", err)
+	}
+	reconcileRMR(t, r, "node-1")
+
+	if err := r.Get(context.Background(), client.ObjectKey{Name: "machine-1", Namespace: "machine-ns"}, &machinev1beta1.Machine{}); err != nil {
+		t.Errorf("expected the replacement machine to survive, got %v", err)
+	}
+}
+
+func TestReconcileWithoutAMachine(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	r := newTestReconciler(t, newTestRMR("node-1"), node)
+
+	rmr := reconcileRMR(t, r, "node-1")
+
+	condition := meta.FindStatusCondition(rmr.Status.Conditions, remediationv1alpha1.ConditionTypeMachineDeleted)
+	if condition == nil || condition.Status != metav1.ConditionFalse ||
+		condition.Reason != remediationv1alpha1.ConditionReasonMachineNotFound {
+		t.Errorf("expected a false MachineDeleted condition with reason MachineNotFound, got %+v", condition)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	// defaults match the kubebuilder defaults of spec.changeRolloutPolicy
+	defaultRolloutGracePeriod    = 5 * time.Minute
+	defaultRolloutMaxConcurrency = 1
+
+	eventReasonRolloutStarted   = "ChangeRolloutStarted"
+	eventReasonRolloutCompleted = "ChangeRolloutCompleted"
+)
+
+// applyChangeRollout implements spec.changeRolloutPolicy: when the unhealthy criteria
+// change, the nodes matching them are only observed for the grace period, and afterwards
+// remediated with limited concurrency until no matching node is left. This prevents a
+// tightened criteria change from instantly fencing large parts of the cluster. It
+// returns the remediation candidates allowed in this reconcile.
+func (r *NodeHealthCheckReconciler) applyChangeRollout(nhc *remediationv1alpha1.NodeHealthCheck, unhealthyNodes []v1.Node, candidates []v1.Node, result *ctrl.Result) []v1.Node {
+	if nhc.Spec.ChangeRolloutPolicy == nil {
+		nhc.Status.ChangeRollout = nil
+		return candidates
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	hash := unhealthyCriteriaHash(nhc)
+	rollout := nhc.Status.ChangeRollout
+	if rollout == nil {
+		// first reconcile with the policy set, the current criteria are not a change
+		nhc.Status.ChangeRollout = &remediationv1alpha1.ChangeRolloutStatus{ObservedConditionsHash: hash, Completed: true}
+		return candidates
+	}
+	if rollout.ObservedConditionsHash != hash {
+		now := metav1.Time{Time: r.now()}
+		rollout = &remediationv1alpha1.ChangeRolloutStatus{ObservedConditionsHash: hash, ChangedAt: &now}
+		nhc.Status.ChangeRollout = rollout
+		log.Info("unhealthy criteria changed, phasing in remediation")
+		r.Recorder.Event(nhc, eventTypeNormal, eventReasonRolloutStarted,
+			"Unhealthy criteria changed, remediation is held back for the grace period")
+	}
+	if rollout.Completed {
+		return candidates
+	}
+
+	gracePeriod := defaultRolloutGracePeriod
+	if nhc.Spec.ChangeRolloutPolicy.GracePeriod != nil {
+		gracePeriod = nhc.Spec.ChangeRolloutPolicy.GracePeriod.Duration
+	}
+	if heldFor := r.now().Sub(rollout.ChangedAt.Time); heldFor < gracePeriod {
+		if len(candidates) > 0 {
+			log.Info("holding back remediation of nodes matching the changed unhealthy criteria",
+				"held back", len(candidates), "grace period ends in", gracePeriod-heldFor)
+		}
+		updateResultNextReconcile(result, gracePeriod-heldFor)
+		return nil
+	}
+
+	// after the grace period the rollout completes once no node matches the changed
+	// criteria anymore
+	if len(unhealthyNodes) == 0 {
+		rollout.Completed = true
+		log.Info("changed unhealthy criteria are rolled out, enabling full remediation")
+		r.Recorder.Event(nhc, eventTypeNormal, eventReasonRolloutCompleted,
+			"Changed unhealthy criteria are rolled out, full remediation concurrency is allowed again")
+		return candidates
+	}
+
+	maxConcurrency := defaultRolloutMaxConcurrency
+	if nhc.Spec.ChangeRolloutPolicy.MaxConcurrency != nil {
+		maxConcurrency = *nhc.Spec.ChangeRolloutPolicy.MaxConcurrency
+	}
+	if len(candidates) > maxConcurrency {
+		log.Info("limiting remediation concurrency while rolling out changed unhealthy criteria",
+			"allowed", maxConcurrency, "held back", len(candidates)-maxConcurrency)
+		candidates = candidates[:maxConcurrency]
+	}
+	return candidates
+}
@@ -0,0 +1,122 @@
+package safemode
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// crashThreshold is how many starts within crashWindow count as a crash loop
+	crashThreshold = 3
+	// crashWindow is how far back the recorded start times are considered
+	crashWindow = 10 * time.Minute
+)
+
+// Checker reports whether the operator runs in post-crash-loop safe mode, in
+// which it observes and reports but doesn't fence
+type Checker interface {
+	// Active returns whether safe mode is active, and a human readable reason
+	Active() (bool, string)
+}
+
+// Detector detects an operator crash loop from a marker file recording the
+// recent start times. A buggy rollout which crashes the operator is bad enough
+// on its own, fencing nodes with a half-initialized operator amplifies it into
+// an outage. After a crash loop the Detector keeps safe mode active until the
+// operator ran stable for the configured period, counted from this start.
+type Detector struct {
+	stablePeriod time.Duration
+	startedAt    time.Time
+	restarts     int
+	log          logr.Logger
+
+	now func() time.Time
+}
+
+var _ Checker = &Detector{}
+
+// NewDetector records this start in the marker file and returns a Detector which
+// knows whether the recent starts amount to a crash loop. File problems are
+// logged and treated as no crash loop, the detection must not take the operator
+// down itself.
+func NewDetector(markerFile string, stablePeriod time.Duration, log logr.Logger) *Detector {
+	detector := &Detector{
+		stablePeriod: stablePeriod,
+		startedAt:    time.Now(),
+		log:          log,
+		now:          time.Now,
+	}
+
+	starts := readStarts(markerFile, log)
+	recent := starts[:0]
+	for _, start := range starts {
+		if detector.startedAt.Sub(start) <= crashWindow {
+			recent = append(recent, start)
+		}
+	}
+	if len(recent) >= crashThreshold {
+		detector.restarts = len(recent)
+		log.Info("detected an operator crash loop, starting in safe mode",
+			"recent starts", len(recent), "window", crashWindow, "stable period", stablePeriod)
+	}
+
+	recent = append(recent, detector.startedAt)
+	writeStarts(markerFile, recent, log)
+	return detector
+}
+
+// Active implements Checker
+func (d *Detector) Active() (bool, string) {
+	if d.restarts == 0 {
+		return false, ""
+	}
+	stableFor := d.now().Sub(d.startedAt)
+	if stableFor >= d.stablePeriod {
+		return false, ""
+	}
+	return true, fmt.Sprintf("The operator restarted %d times within %v and runs in safe mode, "+
+		"fencing resumes after %v of stable operation, in %v",
+		d.restarts, crashWindow, d.stablePeriod, (d.stablePeriod - stableFor).Round(time.Second))
+}
+
+// readStarts parses the start times recorded in the marker file, one RFC3339
+// timestamp per line
+func readStarts(markerFile string, log logr.Logger) []time.Time {
+	content, err := os.ReadFile(markerFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Error(err, "failed to read the crash marker file", "file", markerFile)
+		}
+		return nil
+	}
+	var starts []time.Time
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, line)
+		if err != nil {
+			log.Error(err, "ignoring a malformed crash marker entry", "file", markerFile, "entry", line)
+			continue
+		}
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// writeStarts persists the start times to the marker file
+func writeStarts(markerFile string, starts []time.Time, log logr.Logger) {
+	lines := make([]string, 0, len(starts))
+	for _, start := range starts {
+		lines = append(lines, start.Format(time.RFC3339))
+	}
+	if err := os.WriteFile(markerFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		log.Error(err, "failed to write the crash marker file, "+
+			"a future crash loop won't be detected", "file", markerFile)
+	}
+}
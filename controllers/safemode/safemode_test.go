@@ -0,0 +1,77 @@
+package safemode
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestDetector(t *testing.T) {
+	log := ctrl.Log.WithName("test safe mode detector")
+	now := time.Now()
+
+	t.Run("a first start doesn't trigger safe mode", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "starts")
+		detector := NewDetector(marker, 10*time.Minute, log)
+		if active, _ := detector.Active(); active {
+			t.Error("expected safe mode to be inactive on a first start")
+		}
+	})
+
+	t.Run("recent restarts trigger safe mode until the stable period passed", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "starts")
+		writeMarker(t, marker, now.Add(-3*time.Minute), now.Add(-2*time.Minute), now.Add(-time.Minute))
+
+		detector := NewDetector(marker, 10*time.Minute, log)
+		active, reason := detector.Active()
+		if !active {
+			t.Fatal("expected safe mode to be active after a crash loop")
+		}
+		if !strings.Contains(reason, "safe mode") {
+			t.Errorf("expected the reason to mention safe mode, got %q", reason)
+		}
+
+		detector.now = func() time.Time { return now.Add(11 * time.Minute) }
+		if active, _ := detector.Active(); active {
+			t.Error("expected safe mode to end after the stable period")
+		}
+	})
+
+	t.Run("old starts outside the window don't count", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "starts")
+		writeMarker(t, marker, now.Add(-3*time.Hour), now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+		detector := NewDetector(marker, 10*time.Minute, log)
+		if active, _ := detector.Active(); active {
+			t.Error("expected safe mode to be inactive with only old starts recorded")
+		}
+	})
+
+	t.Run("the current start is recorded for the next detection", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "starts")
+		NewDetector(marker, 10*time.Minute, log)
+
+		content, err := os.ReadFile(marker)
+		if err != nil {
+			t.Fatalf("failed to read the marker file: %v", err)
+		}
+		if lines := strings.Fields(string(content)); len(lines) != 1 {
+			t.Errorf("expected one recorded start, got %v", lines)
+		}
+	})
+}
+
+func writeMarker(t *testing.T, marker string, starts ...time.Time) {
+	t.Helper()
+	var lines []string
+	for _, start := range starts {
+		lines = append(lines, start.Format(time.RFC3339))
+	}
+	if err := os.WriteFile(marker, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("failed to seed the marker file: %v", err)
+	}
+}
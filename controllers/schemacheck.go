@@ -0,0 +1,197 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	eventReasonSchemaMismatch = "RemediationSchemaMismatch"
+
+	// schemaProblemLimit caps how many offending fields a mismatch message names,
+	// so a badly outdated template doesn't blow up the event
+	schemaProblemLimit = 5
+)
+
+// checkRemediationSchema validates the rendered remediation CR against the OpenAPI
+// schema of the installed remediation CRD, before the CR is created. After a
+// remediator API upgrade the template's embedded spec may not fit the installed CRD
+// version anymore: unknown fields are silently pruned by the API server and type
+// mismatches fail creation with cryptic errors. The pre-flight check names the
+// offending fields in an event and the RemediationSchemaMismatch condition instead.
+// Remediation proceeds either way, a partially pruned remediation is still better
+// than none. Check errors (e.g. the CRD being unreadable) are only logged.
+func (r *NodeHealthCheckReconciler) checkRemediationSchema(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, cr *unstructured.Unstructured) {
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	crd, err := r.remediationCRD(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to read the remediation CRD for schema validation, skipping the check", "kind", cr.GetKind())
+		return
+	}
+	if crd == nil {
+		// no CRD found, creation will fail with a clear enough error
+		return
+	}
+
+	problems := validateRemediationSchema(cr, crd)
+	if len(problems) == 0 {
+		if utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeSchemaMismatch, remediationv1alpha1.ConditionReasonSchemaMismatch) {
+			setStatusCondition(nhc, metav1.Condition{
+				Type:    remediationv1alpha1.ConditionTypeSchemaMismatch,
+				Status:  metav1.ConditionFalse,
+				Reason:  remediationv1alpha1.ConditionReasonSchemaMatches,
+				Message: "The remediation CRs match the installed remediation CRD schema",
+			})
+		}
+		return
+	}
+
+	sort.Strings(problems)
+	if len(problems) > schemaProblemLimit {
+		problems = append(problems[:schemaProblemLimit], fmt.Sprintf("and %d more", len(problems)-schemaProblemLimit))
+	}
+	msg := fmt.Sprintf("The remediation CR of kind %s does not match the schema of the installed CRD %s, "+
+		"check whether the remediation template fits the installed remediator version: %s",
+		cr.GetKind(), crd.GetName(), strings.Join(problems, ", "))
+
+	if !utils.IsConditionTrue(nhc.Status.Conditions, remediationv1alpha1.ConditionTypeSchemaMismatch, remediationv1alpha1.ConditionReasonSchemaMismatch) {
+		log.Info("the rendered remediation CR does not match the installed CRD schema", "kind", cr.GetKind(), "problems", problems)
+		setStatusCondition(nhc, metav1.Condition{
+			Type:    remediationv1alpha1.ConditionTypeSchemaMismatch,
+			Status:  metav1.ConditionTrue,
+			Reason:  remediationv1alpha1.ConditionReasonSchemaMismatch,
+			Message: msg,
+		})
+		r.Recorder.Event(nhc, eventTypeWarning, eventReasonSchemaMismatch, msg)
+	}
+}
+
+// remediationCRD returns the CRD serving the given remediation CR's group and kind,
+// or nil when there is none
+func (r *NodeHealthCheckReconciler) remediationCRD(ctx context.Context, cr *unstructured.Unstructured) (*apiextensionsv1.CustomResourceDefinition, error) {
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.Client.List(ctx, crds); err != nil {
+		return nil, err
+	}
+	gvk := cr.GroupVersionKind()
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if crd.Spec.Group == gvk.Group && crd.Spec.Names.Kind == gvk.Kind {
+			return crd, nil
+		}
+	}
+	return nil, nil
+}
+
+// validateRemediationSchema returns the schema problems of the CR's spec, as
+// human-readable entries with dotted field paths. It covers the mismatch classes a
+// remediator API upgrade causes: the CR's version not being served anymore, fields
+// the schema doesn't know (which the API server silently prunes), missing required
+// fields and primitive type mismatches.
+func validateRemediationSchema(cr *unstructured.Unstructured, crd *apiextensionsv1.CustomResourceDefinition) []string {
+	version := cr.GroupVersionKind().Version
+	var schema *apiextensionsv1.JSONSchemaProps
+	served := false
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name != version {
+			continue
+		}
+		served = crd.Spec.Versions[i].Served
+		if crd.Spec.Versions[i].Schema != nil {
+			schema = crd.Spec.Versions[i].Schema.OpenAPIV3Schema
+		}
+		break
+	}
+	if !served {
+		return []string{fmt.Sprintf("version %s is not served by the installed CRD", version)}
+	}
+	if schema == nil {
+		return nil
+	}
+	specSchema, declared := schema.Properties["spec"]
+	if !declared {
+		return nil
+	}
+
+	var problems []string
+	validateAgainstSchema("spec", cr.Object["spec"], &specSchema, &problems)
+	return problems
+}
+
+func validateAgainstSchema(path string, value interface{}, schema *apiextensionsv1.JSONSchemaProps, problems *[]string) {
+	if value == nil || (schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields) {
+		return
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if schema.Type != "" && schema.Type != "object" {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got object", path, schema.Type))
+			return
+		}
+		for _, required := range schema.Required {
+			if _, present := typed[required]; !present {
+				*problems = append(*problems, fmt.Sprintf("%s.%s: required field is missing", path, required))
+			}
+		}
+		for key, child := range typed {
+			childPath := fmt.Sprintf("%s.%s", path, key)
+			if childSchema, known := schema.Properties[key]; known {
+				validateAgainstSchema(childPath, child, &childSchema, problems)
+			} else if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+				validateAgainstSchema(childPath, child, schema.AdditionalProperties.Schema, problems)
+			} else if len(schema.Properties) > 0 {
+				*problems = append(*problems, fmt.Sprintf("%s: unknown field, the API server will prune it", childPath))
+			}
+		}
+	case []interface{}:
+		if schema.Type != "" && schema.Type != "array" {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got array", path, schema.Type))
+			return
+		}
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return
+		}
+		for i, item := range typed {
+			validateAgainstSchema(fmt.Sprintf("%s[%d]", path, i), item, schema.Items.Schema, problems)
+		}
+	case string:
+		if schema.Type != "" && schema.Type != "string" {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got string", path, schema.Type))
+		}
+	case bool:
+		if schema.Type != "" && schema.Type != "boolean" {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got boolean", path, schema.Type))
+		}
+	case int64, float64:
+		if schema.Type != "" && schema.Type != "number" && schema.Type != "integer" {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got number", path, schema.Type))
+		}
+	}
+}
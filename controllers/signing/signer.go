@@ -0,0 +1,82 @@
+package signing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretKeyEntry is the entry of the signing secret holding the key material
+const secretKeyEntry = "key"
+
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+
+// Signer signs remediation decision records, so regulated environments can prove
+// a fencing action originated from this operator and was not tampered with
+type Signer interface {
+	// Sign returns the base64 encoded signature of the given payload
+	Sign(ctx context.Context, payload []byte) (string, error)
+}
+
+// NewHMACSigner returns a Signer using HMAC-SHA256 with the key material stored in
+// the given secret's "key" entry. The key is read lazily and cached, rotating it
+// needs an operator restart. The reader must be uncached: the granted RBAC is a
+// plain get, the lazily started informer of a cached secret read would never sync.
+func NewHMACSigner(c client.Reader, namespace string, secretName string, log logr.Logger) Signer {
+	return &hmacSigner{
+		client:     c,
+		namespace:  namespace,
+		secretName: secretName,
+		log:        log,
+	}
+}
+
+type hmacSigner struct {
+	client     client.Reader
+	namespace  string
+	secretName string
+	log        logr.Logger
+
+	mutex sync.Mutex
+	key   []byte
+}
+
+var _ Signer = &hmacSigner{}
+
+// Sign implements Signer
+func (s *hmacSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	key, err := s.getKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (s *hmacSigner) getKey(ctx context.Context) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.key != nil {
+		return s.key, nil
+	}
+	secret := &v1.Secret{}
+	if err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.secretName}, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the decision signing secret %s/%s", s.namespace, s.secretName)
+	}
+	key, exists := secret.Data[secretKeyEntry]
+	if !exists || len(key) == 0 {
+		return nil, errors.Errorf("the decision signing secret %s/%s has no %q entry", s.namespace, s.secretName, secretKeyEntry)
+	}
+	s.key = key
+	s.log.Info("loaded decision signing key", "secret", s.secretName)
+	return s.key, nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	eventReasonSpecChanged = "SpecChanged"
+
+	// specHistoryLimit caps status.specRevisions, older entries are dropped
+	specHistoryLimit = 10
+
+	// specDiffEntryLimit caps the field diff of a SpecChanged event, so bulk
+	// edits don't blow up the event message
+	specDiffEntryLimit = 10
+)
+
+// specAudit remembers the last reconciled spec per NHC, so updates can be diffed
+// field by field. It is in-memory only: after an operator restart a change is still
+// recorded by hash, just without the field diff.
+type specAudit struct {
+	mutex sync.Mutex
+	specs map[string]*remediationv1alpha1.NodeHealthCheckSpec
+}
+
+// auditSpecChange records spec changes for post-incident review: a SpecChanged event
+// with a compact field diff, and the new spec hash in status.specRevisions, so
+// fencing behavior changes can be tied to config changes afterwards.
+func (r *NodeHealthCheckReconciler) auditSpecChange(nhc *remediationv1alpha1.NodeHealthCheck) {
+	hash := specHash(&nhc.Spec)
+	if hash == "" {
+		return
+	}
+
+	r.specAudit.mutex.Lock()
+	if r.specAudit.specs == nil {
+		r.specAudit.specs = map[string]*remediationv1alpha1.NodeHealthCheckSpec{}
+	}
+	previous := r.specAudit.specs[nhc.GetName()]
+	r.specAudit.specs[nhc.GetName()] = nhc.Spec.DeepCopy()
+	r.specAudit.mutex.Unlock()
+
+	revisions := nhc.Status.SpecRevisions
+	if len(revisions) > 0 && revisions[len(revisions)-1].Hash == hash {
+		return
+	}
+
+	if len(revisions) > 0 {
+		// not the first reconcile of this NHC, the spec changed
+		if previous != nil {
+			r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonSpecChanged,
+				"Spec changed: %s", strings.Join(diffSpecs(previous, &nhc.Spec), ", "))
+		} else {
+			// the old spec was lost in an operator restart
+			r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonSpecChanged,
+				"Spec changed from revision %s to %s", revisions[len(revisions)-1].Hash, hash)
+		}
+		utils.GetLogWithNHC(r.Log, nhc).Info("observed a spec change", "revision", hash)
+	}
+
+	revisions = append(revisions, remediationv1alpha1.SpecRevision{Hash: hash, ChangedAt: metav1.Time{Time: r.now()}})
+	if len(revisions) > specHistoryLimit {
+		revisions = revisions[len(revisions)-specHistoryLimit:]
+	}
+	nhc.Status.SpecRevisions = revisions
+}
+
+// specHash identifies the spec content with a short hash
+func specHash(spec *remediationv1alpha1.NodeHealthCheckSpec) string {
+	marshalled, err := json.Marshal(spec)
+	if err != nil {
+		// not reachable with these types, but don't block remediation on it
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(marshalled))[:12]
+}
+
+// diffSpecs returns a compact human-readable field diff of two specs, as sorted
+// "field: old -> new" entries with dotted paths
+func diffSpecs(oldSpec, newSpec *remediationv1alpha1.NodeHealthCheckSpec) []string {
+	var entries []string
+	diffValues("", specAsMap(oldSpec), specAsMap(newSpec), &entries)
+	sort.Strings(entries)
+	if len(entries) > specDiffEntryLimit {
+		entries = append(entries[:specDiffEntryLimit], fmt.Sprintf("and %d more", len(entries)-specDiffEntryLimit))
+	}
+	return entries
+}
+
+func diffValues(path string, oldValue, newValue interface{}, entries *[]string) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := map[string]struct{}{}
+		for key := range oldMap {
+			keys[key] = struct{}{}
+		}
+		for key := range newMap {
+			keys[key] = struct{}{}
+		}
+		for key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			diffValues(childPath, oldMap[key], newMap[key], entries)
+		}
+		return
+	}
+	*entries = append(*entries, fmt.Sprintf("%s: %s -> %s", path, formatSpecValue(oldValue), formatSpecValue(newValue)))
+}
+
+func formatSpecValue(value interface{}) string {
+	if value == nil {
+		return "<unset>"
+	}
+	marshalled, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(marshalled)
+}
+
+func specAsMap(spec *remediationv1alpha1.NodeHealthCheckSpec) map[string]interface{} {
+	marshalled, err := json.Marshal(spec)
+	if err != nil {
+		return nil
+	}
+	asMap := map[string]interface{}{}
+	if err := json.Unmarshal(marshalled, &asMap); err != nil {
+		return nil
+	}
+	return asMap
+}
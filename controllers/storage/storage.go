@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattachments,verbs=get;list;watch
+
+// DetachChecker provides awareness of the cluster's volume attachment state, so
+// remediation isn't declared complete while RWO volumes are still being detached
+// from a node, see spec.waitForStorageDetach.
+type DetachChecker interface {
+	// PendingDetachments returns the names of the VolumeAttachments of the given
+	// node whose detach was requested but not confirmed by the storage driver yet
+	PendingDetachments(ctx context.Context, nodeName string) ([]string, error)
+}
+
+type detachChecker struct {
+	client client.Client
+}
+
+var _ DetachChecker = &detachChecker{}
+
+// NewDetachChecker returns a DetachChecker backed by the given client. Pass the
+// manager's client, so the lookups are served from a VolumeAttachment informer
+// instead of hitting the API server on every reconcile.
+func NewDetachChecker(c client.Client) DetachChecker {
+	return &detachChecker{client: c}
+}
+
+func (c *detachChecker) PendingDetachments(ctx context.Context, nodeName string) ([]string, error) {
+	list := &storagev1.VolumeAttachmentList{}
+	if err := c.client.List(ctx, list); err != nil {
+		return nil, errors.Wrap(err, "failed to list VolumeAttachments")
+	}
+	var pending []string
+	for i := range list.Items {
+		attachment := &list.Items[i]
+		if attachment.Spec.NodeName != nodeName {
+			continue
+		}
+		// a deletion timestamp means detach was requested, the attach-detach
+		// controller removes its finalizer once the storage driver confirmed it
+		if attachment.GetDeletionTimestamp() != nil {
+			pending = append(pending, attachment.GetName())
+		}
+	}
+	return pending, nil
+}
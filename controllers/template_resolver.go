@@ -0,0 +1,224 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// osLabel is the well-known node label holding the operating system, set by the kubelet
+const osLabel = "kubernetes.io/os"
+
+// templateResolver resolves which remediation template to use for a given node.
+// Nodes matching a nodeSelector of one of the spec.remediationTemplates entries use
+// that entry's template, then the spec.osRemediationTemplates entry matching the
+// node's operating system applies, all other nodes use spec.remediationTemplate.
+type templateResolver struct {
+	defaultTemplate  *unstructured.Unstructured
+	fallbackTemplate *unstructured.Unstructured
+	pools            []poolTemplate
+	osTemplates      map[string]*unstructured.Unstructured
+}
+
+type poolTemplate struct {
+	selector labels.Selector
+	template *unstructured.Unstructured
+}
+
+// templateLocation captures where a remediation template of some kind must live
+type templateLocation int
+
+const (
+	// templateLocationAnywhere puts no restriction on the template's namespace
+	templateLocationAnywhere templateLocation = iota
+	// templateLocationOperatorNamespace requires the template in this operator's
+	// deployment namespace
+	templateLocationOperatorNamespace
+)
+
+// templateLocationRules holds the per kind location requirements. Most remediators read
+// their templates from any namespace, but Machine Deletion Remediation only watches its
+// own operator namespace, which is shared with this operator in the default deployment.
+var templateLocationRules = map[string]templateLocation{
+	"MachineDeletionRemediationTemplate": templateLocationOperatorNamespace,
+}
+
+// templateLocationError reports a template referenced in a namespace its remediator
+// doesn't watch, it disables the NHC with a clear message instead of a dead end
+type templateLocationError struct {
+	msg string
+}
+
+func (e templateLocationError) Error() string {
+	return e.msg
+}
+
+// validateTemplateLocation checks the per kind location rules for the given template
+// reference, so mixed escalation chains (e.g. SNR -> MDR -> FAR) declared in one NHC
+// fail with actionable feedback when a template is misplaced
+func validateTemplateLocation(ref *v1.ObjectReference) error {
+	if !utils.IsWatchedNamespace(ref.Namespace) {
+		// the webhook rejects such references, old objects might still have them
+		return templateLocationError{
+			msg: fmt.Sprintf("%s %q is referenced in namespace %q, which is outside the operator's watch namespaces: %s",
+				ref.Kind, ref.Name, ref.Namespace, strings.Join(utils.WatchNamespaces(), ", ")),
+		}
+	}
+	location, exists := templateLocationRules[ref.Kind]
+	if !exists || location == templateLocationAnywhere {
+		return nil
+	}
+	operatorNs, err := utils.GetDeploymentNamespace()
+	if err != nil {
+		// without a known deployment namespace the rule can't be enforced
+		return nil
+	}
+	if ref.Namespace != operatorNs {
+		return templateLocationError{
+			msg: fmt.Sprintf("%s %q must live in the operator namespace %q, but is referenced in namespace %q",
+				ref.Kind, ref.Name, operatorNs, ref.Namespace),
+		}
+	}
+	return nil
+}
+
+// newTemplateResolver fetches all templates referenced by the NHC and returns a resolver
+// for them. A template which doesn't exist is reported as templateNotFoundError, a
+// misplaced one as templateLocationError, both carry the failing reference and are
+// surfaced centrally, see surfaceReconcileError.
+func (r *NodeHealthCheckReconciler) newTemplateResolver(nhc *remediationv1alpha1.NodeHealthCheck) (*templateResolver, error) {
+	if err := validateTemplateLocation(nhc.Spec.RemediationTemplate); err != nil {
+		return nil, err
+	}
+	defaultTemplate, err := r.fetchTemplate(nhc.Spec.RemediationTemplate)
+	if err != nil {
+		return nil, asTemplateError(nhc.Spec.RemediationTemplate, err)
+	}
+
+	resolver := &templateResolver{
+		defaultTemplate: defaultTemplate,
+	}
+
+	if ref := nhc.Spec.FallbackRemediationTemplate; ref != nil {
+		if err := validateTemplateLocation(ref); err != nil {
+			return nil, err
+		}
+		if resolver.fallbackTemplate, err = r.fetchTemplate(ref); err != nil {
+			return nil, asTemplateError(ref, err)
+		}
+	}
+
+	for i := range nhc.Spec.RemediationTemplates {
+		pool := &nhc.Spec.RemediationTemplates[i]
+		selector, err := metav1.LabelSelectorAsSelector(&pool.NodeSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed converting a selector from remediation template entry")
+		}
+		if err := validateTemplateLocation(pool.RemediationTemplate); err != nil {
+			return nil, err
+		}
+		template, err := r.fetchTemplate(pool.RemediationTemplate)
+		if err != nil {
+			return nil, asTemplateError(pool.RemediationTemplate, err)
+		}
+		resolver.pools = append(resolver.pools, poolTemplate{
+			selector: selector,
+			template: template,
+		})
+	}
+
+	for os, ref := range nhc.Spec.OSRemediationTemplates {
+		if err := validateTemplateLocation(ref); err != nil {
+			return nil, err
+		}
+		template, err := r.fetchTemplate(ref)
+		if err != nil {
+			return nil, asTemplateError(ref, err)
+		}
+		if resolver.osTemplates == nil {
+			resolver.osTemplates = map[string]*unstructured.Unstructured{}
+		}
+		resolver.osTemplates[os] = template
+	}
+	return resolver, nil
+}
+
+// asTemplateError converts a failed template fetch into the typed reconcile error
+// when the template doesn't exist, other fetch errors pass through unchanged
+func asTemplateError(ref *v1.ObjectReference, err error) error {
+	if apierrors.IsNotFound(errors.Cause(err)) {
+		return templateNotFoundError{ref: ref}
+	}
+	return err
+}
+
+// TemplateForNode returns the template of the first pool entry whose selector matches
+// the node, the template for the node's operating system, or the default template
+func (t *templateResolver) TemplateForNode(node *v1.Node) *unstructured.Unstructured {
+	for _, pool := range t.pools {
+		if pool.selector.Matches(labels.Set(node.GetLabels())) {
+			return pool.template
+		}
+	}
+	if template, exists := t.osTemplates[node.GetLabels()[osLabel]]; exists {
+		return template
+	}
+	return t.defaultTemplate
+}
+
+// Templates returns all distinct templates known to the resolver
+func (t *templateResolver) Templates() []*unstructured.Unstructured {
+	templates := []*unstructured.Unstructured{t.defaultTemplate}
+	appendUnknown := func(template *unstructured.Unstructured) {
+		for _, known := range templates {
+			if known.GroupVersionKind() == template.GroupVersionKind() &&
+				known.GetNamespace() == template.GetNamespace() &&
+				known.GetName() == template.GetName() {
+				return
+			}
+		}
+		templates = append(templates, template)
+	}
+	for _, pool := range t.pools {
+		appendUnknown(pool.template)
+	}
+	for _, template := range t.osTemplates {
+		appendUnknown(template)
+	}
+	if t.fallbackTemplate != nil {
+		appendUnknown(t.fallbackTemplate)
+	}
+	return templates
+}
+
+// FallbackTemplate returns the template configured for persistent creation
+// failures, or nil, see spec.fallbackRemediationTemplate
+func (t *templateResolver) FallbackTemplate() *unstructured.Unstructured {
+	return t.fallbackTemplate
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateCacheKey identifies a cached remediation template CR
+type templateCacheKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+// templateCache serves remediation template CRs from memory. Templates are read per
+// reconcile per node but change rarely, and unstructured objects bypass the manager's
+// cache, so with hundreds of simultaneous unhealthy nodes the live reads add up.
+// Entries are filled with a live read on the first miss and invalidated by an informer
+// on the template's kind; when the informer can't be registered the cache degrades to
+// live reads.
+type templateCache struct {
+	reader    client.Reader
+	informers runtimecache.Cache
+	log       logr.Logger
+	mutex     sync.Mutex
+	templates map[templateCacheKey]*unstructured.Unstructured
+	// watched holds the kinds an invalidation handler was registered for; informers
+	// of this controller-runtime version cannot be removed, so they are kept even
+	// when the last template of a kind goes away
+	watched map[schema.GroupVersionKind]struct{}
+}
+
+func newTemplateCache(reader client.Reader, informers runtimecache.Cache, log logr.Logger) *templateCache {
+	return &templateCache{
+		reader:    reader,
+		informers: informers,
+		log:       log,
+		templates: map[templateCacheKey]*unstructured.Unstructured{},
+		watched:   map[schema.GroupVersionKind]struct{}{},
+	}
+}
+
+// get returns the template CR for the given reference, from the cache when possible
+func (c *templateCache) get(ctx context.Context, ref *v1.ObjectReference) (*unstructured.Unstructured, error) {
+	gvk := ref.GroupVersionKind()
+	key := templateCacheKey{gvk: gvk, namespace: ref.Namespace, name: ref.Name}
+
+	c.mutex.Lock()
+	cached, exists := c.templates[key]
+	c.mutex.Unlock()
+	if exists {
+		// callers modify the spec they extract, hand out copies
+		return cached.DeepCopy(), nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := c.reader.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, obj); err != nil {
+		return nil, err
+	}
+
+	// only cache what can be invalidated, otherwise stay with live reads
+	if err := c.ensureInvalidation(ctx, gvk); err != nil {
+		c.log.Error(err, "not caching a template kind, failed to register its invalidation informer", "gvk", gvk)
+		return obj, nil
+	}
+	c.mutex.Lock()
+	c.templates[key] = obj.DeepCopy()
+	c.mutex.Unlock()
+	return obj, nil
+}
+
+// ensureInvalidation registers an informer handler for the given template kind which
+// drops the cached templates whenever one of them changes
+func (c *templateCache) ensureInvalidation(ctx context.Context, gvk schema.GroupVersionKind) error {
+	c.mutex.Lock()
+	_, exists := c.watched[gvk]
+	c.mutex.Unlock()
+	if exists {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	informer, err := c.informers.GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	// template changes are rare, dropping everything on any change beats
+	// bookkeeping which entry belongs to which event
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(interface{}, interface{}) { c.invalidate() },
+		DeleteFunc: func(interface{}) { c.invalidate() },
+	})
+
+	c.mutex.Lock()
+	c.watched[gvk] = struct{}{}
+	c.mutex.Unlock()
+	return nil
+}
+
+func (c *templateCache) invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.templates = map[templateCacheKey]*unstructured.Unstructured{}
+}
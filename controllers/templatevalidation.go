@@ -0,0 +1,260 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/rbac"
+)
+
+const (
+	// templateValidationPeriod is how often the referenced templates are revalidated,
+	// CRD and RBAC changes don't touch the NHC and wouldn't retrigger a watch
+	templateValidationPeriod = 10 * time.Minute
+
+	eventReasonTemplateInvalid = "RemediationTemplateInvalid"
+)
+
+// TemplateValidationReconciler continuously validates the remediation templates
+// the NodeHealthChecks reference — existence, allowed location, schema fit
+// against the installed remediation CRD and the operator's permissions — and
+// publishes the per template results in status.remediationTemplateStatuses.
+// It runs off the hot remediation path on its own schedule, so configuration
+// problems are found before the first node fails, not when fencing needs the
+// template.
+type TemplateValidationReconciler struct {
+	client.Client
+	Log                logr.Logger
+	Recorder           record.EventRecorder
+	PermissionsChecker rbac.PermissionsChecker
+}
+
+// Reconcile validates all templates the NodeHealthCheck references and updates
+// status.remediationTemplateStatuses
+func (r *TemplateValidationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	nhc := &remediationv1alpha1.NodeHealthCheck{}
+	if err := r.Get(ctx, req.NamespacedName, nhc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if nhc.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	var statuses []remediationv1alpha1.RemediationTemplateStatus
+	for _, ref := range referencedTemplates(nhc) {
+		status, err := r.validateTemplate(ctx, ref)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		left, right := &statuses[i].TemplateRef, &statuses[j].TemplateRef
+		if left.Kind != right.Kind {
+			return left.Kind < right.Kind
+		}
+		if left.Namespace != right.Namespace {
+			return left.Namespace < right.Namespace
+		}
+		return left.Name < right.Name
+	})
+
+	if err := r.publishTemplateStatuses(ctx, nhc, statuses); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: templateValidationPeriod}, nil
+}
+
+// validateTemplate runs the checks for one template reference and returns its
+// status entry. An error means the validity couldn't be determined, e.g. the API
+// server was unreachable, and the reconcile should be retried.
+func (r *TemplateValidationReconciler) validateTemplate(ctx context.Context, ref *v1.ObjectReference) (remediationv1alpha1.RemediationTemplateStatus, error) {
+	status := remediationv1alpha1.RemediationTemplateStatus{
+		TemplateRef: *ref,
+	}
+
+	if err := validateTemplateLocation(ref); err != nil {
+		status.Reason = remediationv1alpha1.TemplateReasonMisplaced
+		status.Message = err.Error()
+		return status, nil
+	}
+
+	template := new(unstructured.Unstructured)
+	template.SetGroupVersionKind(ref.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, template)
+	if meta.IsNoMatchError(err) {
+		status.Reason = remediationv1alpha1.TemplateReasonCRDNotInstalled
+		status.Message = fmt.Sprintf("no CRD serving %s is installed, check whether the remediator is deployed", ref.GroupVersionKind())
+		return status, nil
+	}
+	if apierrors.IsNotFound(err) {
+		status.Reason = remediationv1alpha1.TemplateReasonNotFound
+		status.Message = fmt.Sprintf("%s %q was not found in namespace %q", ref.Kind, ref.Name, ref.Namespace)
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+
+	problems, err := r.templateSchemaProblems(ctx, template)
+	if err != nil {
+		return status, err
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		if len(problems) > schemaProblemLimit {
+			problems = append(problems[:schemaProblemLimit], fmt.Sprintf("and %d more", len(problems)-schemaProblemLimit))
+		}
+		status.Reason = remediationv1alpha1.TemplateReasonSchemaMismatch
+		status.Message = fmt.Sprintf("the template's embedded spec does not fit the installed remediation CRD schema: %s", strings.Join(problems, ", "))
+		return status, nil
+	}
+
+	missing, err := r.PermissionsChecker.MissingPermissions(ctx, ref)
+	if err != nil {
+		return status, err
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		status.Reason = remediationv1alpha1.TemplateReasonPermissionsMissing
+		status.Message = fmt.Sprintf("the operator lacks permissions needed for remediation: %s", strings.Join(missing, ", "))
+		return status, nil
+	}
+
+	status.Valid = true
+	status.Reason = remediationv1alpha1.TemplateReasonValid
+	return status, nil
+}
+
+// templateSchemaProblems validates the template's embedded remediation spec
+// against the installed remediation CRD, like checkRemediationSchema does for the
+// rendered CR. Without the CRD there is no schema to check against and nothing is
+// reported, creation will fail with a clear enough error.
+func (r *TemplateValidationReconciler) templateSchemaProblems(ctx context.Context, template *unstructured.Unstructured) ([]string, error) {
+	templateSpec, found, err := unstructured.NestedMap(template.Object, "spec", "template")
+	if err != nil || !found {
+		return []string{"spec.template: required field is missing"}, nil
+	}
+	remediationKind, err := remediationv1alpha1.ParseRemediationKind(template.GetKind())
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+	probe := &unstructured.Unstructured{Object: templateSpec}
+	probe.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   template.GroupVersionKind().Group,
+		Version: template.GroupVersionKind().Version,
+		Kind:    remediationKind.String(),
+	})
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.List(ctx, crds); err != nil {
+		return nil, err
+	}
+	gvk := probe.GroupVersionKind()
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if crd.Spec.Group == gvk.Group && crd.Spec.Names.Kind == gvk.Kind {
+			return validateRemediationSchema(probe, crd), nil
+		}
+	}
+	return nil, nil
+}
+
+// publishTemplateStatuses writes the validation results to the NHC status. The
+// patch is suppressed while the results are unchanged, so the periodic
+// revalidation doesn't cause status churn, and newly invalid templates are
+// reported with a warning event.
+func (r *TemplateValidationReconciler) publishTemplateStatuses(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck, statuses []remediationv1alpha1.RemediationTemplateStatus) error {
+	previous := map[v1.ObjectReference]*remediationv1alpha1.RemediationTemplateStatus{}
+	for i := range nhc.Status.RemediationTemplateStatuses {
+		entry := &nhc.Status.RemediationTemplateStatuses[i]
+		previous[entry.TemplateRef] = entry
+	}
+
+	changed := len(statuses) != len(nhc.Status.RemediationTemplateStatuses)
+	now := metav1.Now()
+	for i := range statuses {
+		status := &statuses[i]
+		if prev, exists := previous[status.TemplateRef]; exists &&
+			prev.Valid == status.Valid && prev.Reason == status.Reason && prev.Message == status.Message {
+			status.LastTransitionTime = prev.LastTransitionTime
+			continue
+		}
+		status.LastTransitionTime = now
+		changed = true
+		if !status.Valid {
+			msg := fmt.Sprintf("The remediation template %s %q in namespace %q is not usable: %s",
+				status.TemplateRef.Kind, status.TemplateRef.Name, status.TemplateRef.Namespace, status.Message)
+			r.Log.Info("a referenced remediation template is not usable",
+				"NodeHealthCheck name", nhc.GetName(), "template kind", status.TemplateRef.Kind,
+				"template name", status.TemplateRef.Name, "reason", status.Reason)
+			r.Recorder.Event(nhc, eventTypeWarning, eventReasonTemplateInvalid, msg)
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	patch := client.MergeFrom(nhc.DeepCopy())
+	nhc.Status.RemediationTemplateStatuses = statuses
+	return r.Status().Patch(ctx, nhc, patch)
+}
+
+// referencedTemplates collects the remediation template references from all spec
+// fields which hold one
+func referencedTemplates(nhc *remediationv1alpha1.NodeHealthCheck) []*v1.ObjectReference {
+	refs := []*v1.ObjectReference{nhc.Spec.RemediationTemplate, nhc.Spec.FallbackRemediationTemplate}
+	for i := range nhc.Spec.RemediationTemplates {
+		refs = append(refs, nhc.Spec.RemediationTemplates[i].RemediationTemplate)
+	}
+	for _, ref := range nhc.Spec.OSRemediationTemplates {
+		refs = append(refs, ref)
+	}
+	var set []*v1.ObjectReference
+	for _, ref := range refs {
+		if ref != nil {
+			set = append(set, ref)
+		}
+	}
+	return set
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TemplateValidationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&remediationv1alpha1.NodeHealthCheck{}).
+		Complete(r)
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+const (
+	tuningConfigMapPrefix = "nhc-tuning-"
+
+	// tuningMinAttempts is how many ended attempts are needed before the outcome
+	// ratios mean anything, a single bad day must not produce suggestions
+	tuningMinAttempts = 5
+	// tuningSuggestionRatio is the outcome ratio above which a suggestion is made
+	tuningSuggestionRatio = 0.25
+)
+
+// recordOutcomeStats counts the result of one remediation attempt into
+// status.remediationStats and reports whether the stats changed. Each attempt is
+// counted once with its first result; only the TimedOut to Succeeded overwrite of
+// endRemediationOutcome re-counts, so the ratios stay truthful.
+func recordOutcomeStats(nhc *remediationv1alpha1.NodeHealthCheck, outcome remediationv1alpha1.RemediationOutcome,
+	result remediationv1alpha1.RemediationOutcomeResult, selfRecovered bool, now time.Time) bool {
+
+	timedOutTurnedHealthy := outcome.Result == remediationv1alpha1.RemediationOutcomeTimedOut &&
+		result == remediationv1alpha1.RemediationOutcomeSucceeded
+	if outcome.Result != "" && !timedOutTurnedHealthy {
+		// the attempt was counted already
+		return false
+	}
+
+	if nhc.Status.RemediationStats == nil {
+		nhc.Status.RemediationStats = &remediationv1alpha1.RemediationStats{}
+	}
+	stats := nhc.Status.RemediationStats
+	if timedOutTurnedHealthy {
+		stats.TimedOut--
+	} else {
+		stats.Attempts++
+	}
+	switch result {
+	case remediationv1alpha1.RemediationOutcomeSucceeded:
+		stats.Succeeded++
+	case remediationv1alpha1.RemediationOutcomeTimedOut:
+		stats.TimedOut++
+	case remediationv1alpha1.RemediationOutcomeEscalated:
+		stats.Escalated++
+	}
+	if selfRecovered {
+		stats.SelfRecovered++
+		duration := now.Sub(outcome.Started.Time)
+		if stats.LongestSelfRecovery == nil || duration > stats.LongestSelfRecovery.Duration {
+			stats.LongestSelfRecovery = &metav1.Duration{Duration: duration.Round(time.Second)}
+		}
+	}
+	return true
+}
+
+// tuningSuggestions derives right-sizing suggestions from the aggregated outcome
+// stats, e.g. a longer unhealthy condition duration when nodes keep recovering on
+// their own before fencing completes
+func tuningSuggestions(stats *remediationv1alpha1.RemediationStats) []string {
+	if stats == nil || stats.Attempts < tuningMinAttempts {
+		return nil
+	}
+	ratio := func(count int) float64 {
+		return float64(count) / float64(stats.Attempts)
+	}
+	percent := func(count int) int {
+		return int(ratio(count)*100 + 0.5)
+	}
+	var suggestions []string
+	if ratio(stats.SelfRecovered) >= tuningSuggestionRatio {
+		suggestion := fmt.Sprintf("%d%% of the remediations recovered on their own before fencing completed, consider increasing the duration of the unhealthy conditions", percent(stats.SelfRecovered))
+		if stats.LongestSelfRecovery != nil {
+			suggestion += fmt.Sprintf(" by at least %v, the longest observed self recovery", stats.LongestSelfRecovery.Duration)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if ratio(stats.TimedOut) >= tuningSuggestionRatio {
+		suggestions = append(suggestions, fmt.Sprintf("%d%% of the remediations timed out without the node turning healthy, consider a more forceful remediator or configuring spec.fallbackRemediationTemplate", percent(stats.TimedOut)))
+	}
+	if ratio(stats.Escalated) >= tuningSuggestionRatio {
+		suggestions = append(suggestions, fmt.Sprintf("%d%% of the remediations were escalated away from their first remediator, consider starting with the remediator the escalations end up with", percent(stats.Escalated)))
+	}
+	return suggestions
+}
+
+// updateTuningReport writes the aggregated outcome stats and the suggestions
+// derived from them into a ConfigMap named "nhc-tuning-<NHC name>" in the operator
+// namespace. It is best effort: failures are logged but must not block remediation.
+func (r *NodeHealthCheckReconciler) updateTuningReport(ctx context.Context, nhc *remediationv1alpha1.NodeHealthCheck) {
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	namespace, err := utils.GetDeploymentNamespace()
+	if err != nil {
+		log.Error(err, "failed to get the deployment namespace for the tuning report")
+		return
+	}
+
+	marshalled, err := json.MarshalIndent(nhc.Status.RemediationStats, "", "  ")
+	if err != nil {
+		log.Error(err, "failed to marshal the remediation stats for the tuning report")
+		return
+	}
+	data := map[string]string{
+		"statistics": string(marshalled),
+	}
+	if suggestions := tuningSuggestions(nhc.Status.RemediationStats); len(suggestions) > 0 {
+		data["suggestions"] = strings.Join(suggestions, "\n")
+	} else {
+		data["suggestions"] = "no tuning suggestions, the remediation outcomes look fine"
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tuningConfigMapPrefix + nhc.GetName(),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of": "node-healthcheck-controller",
+			},
+		},
+		Data: data,
+	}
+	if err := r.Client.Create(ctx, cm); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			log.Error(err, "failed to create the tuning report ConfigMap")
+			return
+		}
+		existing := &v1.ConfigMap{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(cm), existing); err != nil {
+			log.Error(err, "failed to get the existing tuning report ConfigMap")
+			return
+		}
+		existing.Data = data
+		if err := r.Client.Update(ctx, existing); err != nil {
+			log.Error(err, "failed to update the tuning report ConfigMap")
+			return
+		}
+	}
+	log.V(5).Info("updated the tuning report", "ConfigMap name", cm.GetName(), "ns", namespace)
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+func TestRecordOutcomeStats(t *testing.T) {
+	now := time.Now()
+	open := v1alpha1.RemediationOutcome{Started: metav1.Time{Time: now.Add(-5 * time.Minute)}}
+
+	t.Run("first result counts once", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		if !recordOutcomeStats(nhc, open, v1alpha1.RemediationOutcomeSucceeded, false, now) {
+			t.Fatal("expected the stats to change")
+		}
+		stats := nhc.Status.RemediationStats
+		if stats.Attempts != 1 || stats.Succeeded != 1 {
+			t.Errorf("expected one succeeded attempt, got %+v", stats)
+		}
+		ended := open
+		ended.Result = v1alpha1.RemediationOutcomeSucceeded
+		if recordOutcomeStats(nhc, ended, v1alpha1.RemediationOutcomeSucceeded, false, now) {
+			t.Error("expected the repeated result to be ignored")
+		}
+		if stats.Attempts != 1 {
+			t.Errorf("expected the attempt to stay counted once, got %+v", stats)
+		}
+	})
+
+	t.Run("timed out turning healthy is re-counted", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		recordOutcomeStats(nhc, open, v1alpha1.RemediationOutcomeTimedOut, false, now)
+		timedOut := open
+		timedOut.Result = v1alpha1.RemediationOutcomeTimedOut
+		if !recordOutcomeStats(nhc, timedOut, v1alpha1.RemediationOutcomeSucceeded, false, now) {
+			t.Fatal("expected the stats to change")
+		}
+		stats := nhc.Status.RemediationStats
+		if stats.Attempts != 1 || stats.TimedOut != 0 || stats.Succeeded != 1 {
+			t.Errorf("expected the timed out attempt to move to succeeded, got %+v", stats)
+		}
+	})
+
+	t.Run("self recovery tracks the longest duration", func(t *testing.T) {
+		nhc := &v1alpha1.NodeHealthCheck{}
+		recordOutcomeStats(nhc, open, v1alpha1.RemediationOutcomeSucceeded, true, now)
+		shorter := v1alpha1.RemediationOutcome{Started: metav1.Time{Time: now.Add(-time.Minute)}}
+		recordOutcomeStats(nhc, shorter, v1alpha1.RemediationOutcomeSucceeded, true, now)
+		stats := nhc.Status.RemediationStats
+		if stats.SelfRecovered != 2 {
+			t.Errorf("expected two self recoveries, got %+v", stats)
+		}
+		if stats.LongestSelfRecovery == nil || stats.LongestSelfRecovery.Duration != 5*time.Minute {
+			t.Errorf("expected the longest self recovery to be 5m, got %v", stats.LongestSelfRecovery)
+		}
+	})
+}
+
+func TestTuningSuggestions(t *testing.T) {
+	cases := []struct {
+		name     string
+		stats    *v1alpha1.RemediationStats
+		expected []string
+	}{
+		{
+			name: "too few attempts produce no suggestions",
+			stats: &v1alpha1.RemediationStats{
+				Attempts: tuningMinAttempts - 1, Succeeded: 1, SelfRecovered: tuningMinAttempts - 1,
+			},
+		},
+		{
+			name: "healthy outcomes produce no suggestions",
+			stats: &v1alpha1.RemediationStats{
+				Attempts: 10, Succeeded: 9, TimedOut: 1,
+			},
+		},
+		{
+			name: "many self recoveries suggest a longer condition duration",
+			stats: &v1alpha1.RemediationStats{
+				Attempts: 10, Succeeded: 10, SelfRecovered: 4,
+				LongestSelfRecovery: &metav1.Duration{Duration: 3 * time.Minute},
+			},
+			expected: []string{"40% of the remediations recovered on their own", "by at least 3m0s"},
+		},
+		{
+			name: "many timeouts suggest a more forceful remediator",
+			stats: &v1alpha1.RemediationStats{
+				Attempts: 10, Succeeded: 6, TimedOut: 4,
+			},
+			expected: []string{"40% of the remediations timed out", "spec.fallbackRemediationTemplate"},
+		},
+		{
+			name: "many escalations suggest skipping the first remediator",
+			stats: &v1alpha1.RemediationStats{
+				Attempts: 10, Succeeded: 7, Escalated: 3,
+			},
+			expected: []string{"30% of the remediations were escalated"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			suggestions := strings.Join(tuningSuggestions(c.stats), "\n")
+			if len(c.expected) == 0 && suggestions != "" {
+				t.Errorf("expected no suggestions, got %q", suggestions)
+			}
+			for _, expected := range c.expected {
+				if !strings.Contains(suggestions, expected) {
+					t.Errorf("expected the suggestions to contain %q, got %q", expected, suggestions)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+// MinConditionDebounce is the smallest effective unhealthy condition duration.
+// Configured durations below it, including the explicitly acknowledged zero for
+// immediate remediation, are raised to it: with durations shorter than the watch
+// and requeue granularity the verdict would depend on reconcile timing, and a
+// single brief condition flip must not fence a node.
+const MinConditionDebounce = 5 * time.Second
+
+// effectiveConditionDuration applies the minimum debounce to the configured duration
+func effectiveConditionDuration(c v1alpha1.UnhealthyCondition) time.Duration {
+	if c.Duration.Duration < MinConditionDebounce {
+		return MinConditionDebounce
+	}
+	return c.Duration.Duration
+}
+
+// IsNodeHealthy checks the node conditions against the unhealthy condition tests. For nodes
+// which match an unhealthy status but whose duration didn't elapse yet, it also returns
+// the time until the earliest condition duration expires, so callers can check back
+// exactly then instead of relying on a coarse resync interval. Callers pass the current
+// time from their clock, so an injected fake clock governs the duration decision too.
+func IsNodeHealthy(conditionTests []v1alpha1.UnhealthyCondition, nodeConditions []v1.NodeCondition, now time.Time) (bool, *time.Duration) {
+	nodeConditionByType := make(map[v1.NodeConditionType]v1.NodeCondition)
+	for _, nc := range nodeConditions {
+		nodeConditionByType[nc.Type] = nc
+	}
+
+	var nextUnhealthy *time.Duration
+	for _, c := range conditionTests {
+		n, exists := nodeConditionByType[c.Type]
+		if !exists || n.Status != c.Status {
+			continue
+		}
+		deadline := n.LastTransitionTime.Add(effectiveConditionDuration(c))
+		if now.After(deadline) {
+			return false, nil
+		}
+		if until := deadline.Sub(now); nextUnhealthy == nil || until < *nextUnhealthy {
+			nextUnhealthy = &until
+		}
+	}
+	return true, nextUnhealthy
+}
+
+// MatchedUnhealthyConditions returns the unhealthy condition tests the node currently
+// matches with their duration elapsed, i.e. the ones making it unhealthy. Like
+// IsNodeHealthy it judges the durations against the caller's current time.
+func MatchedUnhealthyConditions(conditionTests []v1alpha1.UnhealthyCondition, nodeConditions []v1.NodeCondition, now time.Time) []v1alpha1.UnhealthyCondition {
+	nodeConditionByType := make(map[v1.NodeConditionType]v1.NodeCondition)
+	for _, nc := range nodeConditions {
+		nodeConditionByType[nc.Type] = nc
+	}
+
+	var matched []v1alpha1.UnhealthyCondition
+	for _, c := range conditionTests {
+		n, exists := nodeConditionByType[c.Type]
+		if !exists || n.Status != c.Status {
+			continue
+		}
+		if now.After(n.LastTransitionTime.Add(effectiveConditionDuration(c))) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
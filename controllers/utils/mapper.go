@@ -16,6 +16,20 @@ import (
 	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
 )
 
+// NHCByOwnerMapperFunc returns the mapper function enqueuing the NodeHealthCheck
+// owning the object, used for watching remediation CRs
+func NHCByOwnerMapperFunc() handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		var r []reconcile.Request
+		for _, owner := range o.GetOwnerReferences() {
+			if owner.Kind == "NodeHealthCheck" && owner.APIVersion == remediationv1alpha1.GroupVersion.String() {
+				r = append(r, reconcile.Request{NamespacedName: types.NamespacedName{Name: owner.Name}})
+			}
+		}
+		return r
+	}
+}
+
 // NHCByNodeMapperFunc return the Node-to-NHC mapper function
 func NHCByNodeMapperFunc(c client.Client, logger logr.Logger) handler.MapFunc {
 	// This closure is meant to fetch all NHC to fill the reconcile queue.
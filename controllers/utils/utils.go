@@ -27,14 +27,59 @@ func GetDeploymentNamespace() (string, error) {
 	return ns, nil
 }
 
+// watchNamespaces holds the namespaces the operator is restricted to, empty means
+// the whole cluster, see SetWatchNamespaces
+var watchNamespaces []string
+
+// SetWatchNamespaces records the namespaces the operator is restricted to, set from
+// the watch-namespaces flag before the manager starts
+func SetWatchNamespaces(namespaces []string) {
+	watchNamespaces = namespaces
+}
+
+// WatchNamespaces returns the namespaces the operator is restricted to, or an empty
+// slice when it watches the whole cluster
+func WatchNamespaces() []string {
+	return watchNamespaces
+}
+
+// IsWatchedNamespace reports whether namespaced resources in the given namespace are
+// visible to the operator
+func IsWatchedNamespace(namespace string) bool {
+	if len(watchNamespaces) == 0 {
+		return true
+	}
+	for _, watched := range watchNamespaces {
+		if watched == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// GetOperatorNodeName returns the name of the node this operator's pod runs on,
+// or an empty string when the downward API env variable OPERATOR_NODE_NAME isn't
+// set, e.g. on older deployments.
+func GetOperatorNodeName() string {
+	return os.Getenv("OPERATOR_NODE_NAME")
+}
+
 // IsOnOpenshift returns true if the cluster has the openshift config group
 func IsOnOpenshift(config *rest.Config) (bool, error) {
+	kind := schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ClusterVersion"}
+	return IsAPIAvailable(config, kind)
+}
+
+// IsAPIAvailable returns true if the cluster serves the group and version of the given kind
+func IsAPIAvailable(config *rest.Config, kind schema.GroupVersionKind) (bool, error) {
 	dc, err := discovery.NewDiscoveryClientForConfig(config)
 	if err != nil {
 		return false, err
 	}
 	apiGroups, err := dc.ServerGroups()
-	kind := schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ClusterVersion"}
+	if err != nil {
+		return false, err
+	}
 	for _, apiGroup := range apiGroups.Groups {
 		for _, supportedVersion := range apiGroup.Versions {
 			if supportedVersion.GroupVersion == kind.GroupVersion().String() {
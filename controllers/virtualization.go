@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstancemigrations,verbs=get;list;watch;create
+
+const (
+	eventReasonLiveMigrationStarted  = "LiveMigrationStarted"
+	eventReasonLiveMigrationTimedOut = "LiveMigrationTimedOut"
+
+	// defaultLiveMigrationTimeout bounds the fencing delay when
+	// spec.virtualization doesn't configure one
+	defaultLiveMigrationTimeout = 10 * time.Minute
+
+	// liveMigrationPollInterval is how often a node is rechecked for remaining
+	// virtual machine instances while fencing waits for the live-migrations
+	liveMigrationPollInterval = 15 * time.Second
+)
+
+// vmiListGVK and vmiMigrationGVK identify the KubeVirt objects this operator
+// coordinates with, their Go types are not linked in
+var (
+	vmiListGVK      = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachineInstanceList"}
+	vmiMigrationGVK = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachineInstanceMigration"}
+)
+
+// applyLiveMigration implements spec.virtualization.waitForLiveMigration: before the
+// remediation CR of a node hosting KubeVirt virtual machine instances is created, a
+// live-migration is requested for each instance, and fencing is delayed until no
+// instance runs on the node anymore or the timeout passed. The actual migrations are
+// carried out by the KubeVirt migration controller. It returns whether fencing may
+// proceed, and a requeue delay while it may not.
+func (r *NodeHealthCheckReconciler) applyLiveMigration(ctx context.Context, node *v1.Node, nhc *remediationv1alpha1.NodeHealthCheck) (*time.Duration, bool) {
+	options := nhc.Spec.Virtualization
+	if options == nil || !options.WaitForLiveMigration {
+		return nil, true
+	}
+
+	log := utils.GetLogWithNHC(r.Log, nhc)
+
+	vmis, err := r.instancesOnNode(ctx, node)
+	if err != nil {
+		if !meta.IsNoMatchError(err) {
+			// waiting is best effort, a node whose instances can't be listed must
+			// still be fenced
+			log.Error(err, "failed to list the node's virtual machine instances", "Node name", node.GetName())
+		}
+		// with no match KubeVirt isn't installed, there is nothing to migrate
+		return nil, true
+	}
+	if len(vmis) == 0 {
+		delete(nhc.Status.PendingLiveMigrations, node.GetName())
+		return nil, true
+	}
+
+	started, exists := nhc.Status.PendingLiveMigrations[node.GetName()]
+	if !exists {
+		started = metav1.Time{Time: r.now()}
+		if nhc.Status.PendingLiveMigrations == nil {
+			nhc.Status.PendingLiveMigrations = map[string]metav1.Time{}
+		}
+		nhc.Status.PendingLiveMigrations[node.GetName()] = started
+		log.Info("waiting for live-migrations before fencing", "Node name", node.GetName(), "instances", len(vmis))
+		r.Recorder.Eventf(nhc, eventTypeNormal, eventReasonLiveMigrationStarted,
+			"Waiting for the live-migration of %d virtual machine instances of node %s before fencing", len(vmis), node.GetName())
+	}
+
+	timeout := defaultLiveMigrationTimeout
+	if options.LiveMigrationTimeout != nil {
+		timeout = options.LiveMigrationTimeout.Duration
+	}
+	if r.now().Sub(started.Time) >= timeout {
+		log.Info("live-migrations didn't finish in time, fencing anyway", "Node name", node.GetName(), "instances", len(vmis))
+		r.Recorder.Eventf(nhc, eventTypeWarning, eventReasonLiveMigrationTimedOut,
+			"%d virtual machine instances of node %s didn't live-migrate away within %s, fencing anyway", len(vmis), node.GetName(), timeout)
+		delete(nhc.Status.PendingLiveMigrations, node.GetName())
+		return nil, true
+	}
+
+	// requested on every reconcile, instances scheduled onto the node after the
+	// first request are picked up too
+	for i := range vmis {
+		if err := r.requestLiveMigration(ctx, &vmis[i]); err != nil {
+			log.Error(err, "failed to request a live-migration", "Node name", node.GetName(), "VMI name", vmis[i].GetName())
+		}
+	}
+
+	requeue := liveMigrationPollInterval
+	if remaining := timeout - r.now().Sub(started.Time); remaining < requeue {
+		requeue = remaining
+	}
+	return &requeue, false
+}
+
+// instancesOnNode returns the virtual machine instances currently running on the node
+func (r *NodeHealthCheckReconciler) instancesOnNode(ctx context.Context, node *v1.Node) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(vmiListGVK)
+	if err := r.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	var onNode []unstructured.Unstructured
+	for _, vmi := range list.Items {
+		nodeName, _, _ := unstructured.NestedString(vmi.Object, "status", "nodeName")
+		if nodeName == node.GetName() {
+			onNode = append(onNode, vmi)
+		}
+	}
+	return onNode, nil
+}
+
+// requestLiveMigration asks the KubeVirt migration controller to move the given
+// instance, with a deterministic migration name so repeated reconciles don't pile
+// up requests
+func (r *NodeHealthCheckReconciler) requestLiveMigration(ctx context.Context, vmi *unstructured.Unstructured) error {
+	migration := &unstructured.Unstructured{}
+	migration.SetGroupVersionKind(vmiMigrationGVK)
+	migration.SetNamespace(vmi.GetNamespace())
+	migration.SetName(fmt.Sprintf("nhc-%s", vmi.GetName()))
+	if err := unstructured.SetNestedField(migration.Object, vmi.GetName(), "spec", "vmiName"); err != nil {
+		return err
+	}
+	err := r.Client.Create(ctx, migration)
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
+)
+
+// watchDiscoveryInterval is how often referenced remediation kinds are checked for
+// newly installed CRDs
+const watchDiscoveryInterval = 30 * time.Second
+
+// watchManager registers watches for the remediation CR kinds referenced by any NHC
+// at runtime. Remediation CRDs may be installed after this operator started, so these
+// watches cannot be declared statically in SetupWithManager, and requiring an operator
+// restart for every new remediator would be unfriendly.
+type watchManager struct {
+	controller controller.Controller
+	client     client.Client
+	mapper     meta.RESTMapper
+	log        logr.Logger
+	// watched holds the kinds a watch was registered for. Watches are never registered
+	// twice: the shared cache of this controller-runtime version cannot remove
+	// informers, so a kind which vanishes and comes back keeps its original watch.
+	watched map[schema.GroupVersionKind]struct{}
+}
+
+func newWatchManager(c controller.Controller, cl client.Client, mapper meta.RESTMapper, log logr.Logger) *watchManager {
+	return &watchManager{
+		controller: c,
+		client:     cl,
+		mapper:     mapper,
+		log:        log,
+		watched:    map[schema.GroupVersionKind]struct{}{},
+	}
+}
+
+// Start implements manager.Runnable
+func (w *watchManager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(watchDiscoveryInterval)
+	defer ticker.Stop()
+	for {
+		w.discover(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// discover registers watches for all referenced remediation kinds whose CRD exists
+func (w *watchManager) discover(ctx context.Context) {
+	for gvk := range w.referencedRemediationKinds(ctx) {
+		if _, exists := w.watched[gvk]; exists {
+			continue
+		}
+		// only watch kinds whose CRD is installed by now, the deferred RESTMapper
+		// rediscovers new CRDs on misses
+		if _, err := w.mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if !meta.IsNoMatchError(err) {
+				w.log.Error(err, "failed to check for the remediation kind's CRD", "gvk", gvk)
+			}
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		if err := w.controller.Watch(&source.Kind{Type: obj}, handler.EnqueueRequestsFromMapFunc(utils.NHCByOwnerMapperFunc())); err != nil {
+			w.log.Error(err, "failed to register a watch for the remediation kind", "gvk", gvk)
+			continue
+		}
+		w.watched[gvk] = struct{}{}
+		w.log.Info("registered a watch for a remediation kind", "gvk", gvk)
+	}
+}
+
+// referencedRemediationKinds collects the remediation CR kinds referenced by any NHC,
+// derived from the template references as in generateRemediationCR
+func (w *watchManager) referencedRemediationKinds(ctx context.Context) map[schema.GroupVersionKind]struct{} {
+	kinds := map[schema.GroupVersionKind]struct{}{}
+	nhcList := &remediationv1alpha1.NodeHealthCheckList{}
+	if err := w.client.List(ctx, nhcList); err != nil {
+		w.log.Error(err, "failed to list NHCs for remediation kind discovery")
+		return kinds
+	}
+	addRef := func(ref *v1.ObjectReference) {
+		if ref == nil {
+			return
+		}
+		gvk, err := remediationv1alpha1.RemediationGVK(ref)
+		if err != nil {
+			// the webhook rejects such templates, old objects might still have them
+			w.log.Error(err, "not watching a template not following the kind convention")
+			return
+		}
+		kinds[gvk] = struct{}{}
+	}
+	for i := range nhcList.Items {
+		nhc := &nhcList.Items[i]
+		addRef(nhc.Spec.RemediationTemplate)
+		addRef(nhc.Spec.FallbackRemediationTemplate)
+		for j := range nhc.Spec.RemediationTemplates {
+			addRef(nhc.Spec.RemediationTemplates[j].RemediationTemplate)
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/node-healthcheck-operator/e2e/utils"
+)
+
+// This test path disrupts a control plane node, which is far more invasive than the
+// worker scenarios. It is skipped unless CONTROL_PLANE_E2E=true is set, keep this
+// aligned with CI config!
+var _ = Describe("e2e - control plane remediation", func() {
+
+	var masters *v1.NodeList
+
+	BeforeEach(func() {
+		if os.Getenv("CONTROL_PLANE_E2E") != "true" {
+			Skip("skipping control plane e2e, set CONTROL_PLANE_E2E=true to enable")
+		}
+
+		masters = &v1.NodeList{}
+		selector := labels.NewSelector()
+		req, _ := labels.NewRequirement("node-role.kubernetes.io/master", selection.Exists, []string{})
+		selector = selector.Add(*req)
+		Expect(client.List(context.Background(), masters, &ctrl.ListOptions{LabelSelector: selector})).To(Succeed())
+		Expect(len(masters.Items)).To(BeNumerically(">=", 3), "control plane remediation needs at least 3 control plane nodes")
+
+		// don't start with a degraded etcd, the test relies on quorum being intact
+		ready, err := utils.CountReadyEtcdMembers(clientSet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ready).To(Equal(len(masters.Items)), "etcd is degraded already, aborting")
+	})
+
+	It("remediates a single control plane node while keeping etcd quorum", func() {
+		spec := CurrentGinkgoTestDescription().FullTestText
+		// don't touch the first master, the api-blocker pod of the worker tests might run there
+		masterName := masters.Items[len(masters.Items)-1].GetName()
+		testStart := time.Now()
+		Expect(makeNodeUnready(masterName)).To(Succeed())
+
+		Eventually(
+			fetchRemediationResourceByName(masterName), remediationStartedTimeout, 10*time.Second).
+			Should(Succeed())
+		utils.RecordTiming(spec, "detection", testStart)
+
+		// the quorum guard must prevent remediation of further control plane nodes
+		// while this one is down, and etcd must only lose the member under remediation
+		Consistently(func(g Gomega) {
+			remediated := 0
+			for _, master := range masters.Items {
+				if err := fetchRemediationResourceByName(master.GetName())(); err == nil {
+					remediated++
+				}
+			}
+			g.Expect(remediated).To(BeNumerically("<=", 1), "more than one control plane node is being remediated")
+
+			ready, err := utils.CountReadyEtcdMembers(clientSet)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ready).To(BeNumerically(">=", len(masters.Items)-1), "etcd lost more members than the node under remediation")
+		}, 5*time.Minute, 30*time.Second).Should(Succeed())
+
+		// the node reboots and rejoins the cluster
+		rebootStart := time.Now()
+		Eventually(func() (time.Time, error) {
+			bootTime, err := utils.GetBootTime(clientSet, masterName, log)
+			if bootTime != nil && err == nil {
+				return *bootTime, nil
+			}
+			return time.Time{}, err
+		}, nodeRebootedTimeout, 30*time.Second).Should(BeTemporally(">", testStart))
+		utils.RecordTiming(spec, "reboot", rebootStart)
+		utils.RecordTiming(spec, "remediation", testStart)
+
+		Eventually(func() error {
+			node := &v1.Node{}
+			if err := client.Get(context.Background(), ctrl.ObjectKey{Name: masterName}, node); err != nil {
+				return err
+			}
+			for _, cond := range node.Status.Conditions {
+				if cond.Type == v1.NodeReady && cond.Status == v1.ConditionTrue {
+					return nil
+				}
+			}
+			return fmt.Errorf("node %s is not ready yet", masterName)
+		}, nodeRebootedTimeout, 30*time.Second).Should(Succeed())
+
+		// with all members back, the quorum guard allows disruptions again
+		Eventually(func() (bool, error) {
+			return utils.IsEtcdDisruptionAllowed(clientSet)
+		}, 5*time.Minute, 30*time.Second).Should(BeTrue(), "etcd quorum guard should allow disruptions again")
+	})
+})
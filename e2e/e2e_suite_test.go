@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/reporters"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap/zapcore"
 
@@ -21,10 +22,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/e2e/utils"
 )
 
 func TestE2e(t *testing.T) {
 	RegisterFailHandler(Fail)
+	// with an artifact directory configured, CI gets a JUnit file next to the
+	// timing report written in AfterSuite
+	if junit := utils.ArtifactPath("junit_e2e.xml"); junit != "" {
+		RunSpecsWithDefaultAndCustomReporters(t, "E2e Suite", []Reporter{reporters.NewJUnitReporter(junit)})
+		return
+	}
 	RunSpecs(t, "E2e Suite")
 }
 
@@ -91,6 +99,10 @@ var _ = BeforeSuite(func() {
 	debug()
 }, 10)
 
+var _ = AfterSuite(func() {
+	utils.WriteTimingReport(log)
+})
+
 func debug() {
 	version, _ := clientSet.ServerVersion()
 	fmt.Fprint(GinkgoWriter, version)
@@ -179,9 +179,12 @@ var _ = Describe("e2e", func() {
 		})
 
 		It("Remediates a host", func() {
+			spec := CurrentGinkgoTestDescription().FullTestText
 			Eventually(
 				fetchRemediationResourceByName(nodeUnderTest.Name), remediationStartedTimeout, 10*time.Second).
 				Should(Succeed())
+			utils.RecordTiming(spec, "detection", testStart)
+			rebootStart := time.Now()
 			Eventually(func() (time.Time, error) {
 				bootTime, err := utils.GetBootTime(clientSet, nodeUnderTest.Name, log)
 				if bootTime != nil && err == nil {
@@ -193,6 +196,8 @@ var _ = Describe("e2e", func() {
 			}, nodeRebootedTimeout, 30*time.Second).Should(
 				BeTemporally(">", testStart),
 			)
+			utils.RecordTiming(spec, "reboot", rebootStart)
+			utils.RecordTiming(spec, "remediation", testStart)
 		})
 	})
 })
@@ -229,7 +234,7 @@ func getTemplateNS() (string, error) {
 	return "", fmt.Errorf("failed to find the default remediation template")
 }
 
-//makeNodeUnready puts a node in an unready condition by disrupting the network
+// makeNodeUnready puts a node in an unready condition by disrupting the network
 // for the duration passed
 func makeNodeUnready(nodeName string) error {
 	// run a privileged pod that blocks the api port
@@ -257,23 +262,27 @@ func makeNodeUnready(nodeName string) error {
 					},
 				},
 				Name:  "main",
-				Image: "registry.access.redhat.com/ubi8/ubi-minimal",
+				Image: utils.UtilsImage(),
 				Command: []string{
 					"/bin/bash",
 					"-c",
+					// uses the host's iptables via chroot, so nothing needs to be
+					// installed at runtime, which also works in disconnected clusters
 					`#!/bin/bash -ex
-microdnf install iptables
 port=$(awk -F[\:] '/server\:/ {print $NF}' /etc/kubernetes/kubeconfig 2>/dev/null || awk -F[\:] '/server\:/ {print $NF}' /etc/kubernetes/kubelet.conf)
 sleep ${DELAYDURATION}
-iptables -A OUTPUT -p tcp --dport ${port} -j REJECT
+chroot /host iptables -A OUTPUT -p tcp --dport ${port} -j REJECT
 sleep ${SLEEPDURATION}
-iptables -D OUTPUT -p tcp --dport ${port} -j REJECT
+chroot /host iptables -D OUTPUT -p tcp --dport ${port} -j REJECT
 sleep infinity
 `,
 				},
 				VolumeMounts: []v1.VolumeMount{{
 					Name:      "etckube",
 					MountPath: "/etc/kubernetes",
+				}, {
+					Name:      "host",
+					MountPath: "/host",
 				}},
 				SecurityContext: &v1.SecurityContext{
 					Privileged:               pointer.BoolPtr(true),
@@ -288,6 +297,14 @@ sleep infinity
 						Type: &directory,
 					},
 				},
+			}, {
+				Name: "host",
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: "/",
+						Type: &directory,
+					},
+				},
 			}},
 		},
 	}
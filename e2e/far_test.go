@@ -0,0 +1,135 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+// This test path runs against a cluster with Fence Agents Remediation (FAR) deployed,
+// with a mock fence agent backed by a virtualized BMC (sushy-tools). It is skipped
+// unless FAR_E2E=true is set, keep this aligned with CI config!
+const (
+	farTemplateName  = "nhc-e2e-far-template"
+	farBMCEndpointEV = "FAR_BMC_ENDPOINT"
+)
+
+var (
+	farTemplateGVR = schema.GroupVersionResource{
+		Group:    "fence-agents-remediation.medik8s.io",
+		Version:  "v1alpha1",
+		Resource: "fenceagentsremediationtemplates",
+	}
+	farGVR = schema.GroupVersionResource{
+		Group:    "fence-agents-remediation.medik8s.io",
+		Version:  "v1alpha1",
+		Resource: "fenceagentsremediations",
+	}
+)
+
+var _ = Describe("e2e - fence agents remediation", func() {
+
+	var nhc *v1alpha1.NodeHealthCheck
+	var oldTemplateRef *v1.ObjectReference
+	var farNodeName string
+
+	BeforeEach(func() {
+		if os.Getenv("FAR_E2E") != "true" {
+			Skip("skipping FAR e2e, set FAR_E2E=true to enable")
+		}
+
+		// the template is created in the test namespace, the controller must resolve
+		// it there and must be allowed to create FAR CRs via the aggregated role
+		template := &unstructured.Unstructured{}
+		template.SetGroupVersionKind(farTemplateGVR.GroupVersion().WithKind("FenceAgentsRemediationTemplate"))
+		template.SetName(farTemplateName)
+		template.SetNamespace(testNamespace)
+		Expect(unstructured.SetNestedField(template.Object, "fence_redfish", "spec", "template", "spec", "agent")).To(Succeed())
+		Expect(unstructured.SetNestedMap(template.Object, map[string]interface{}{
+			"--ip":           os.Getenv(farBMCEndpointEV),
+			"--username":     "admin",
+			"--password":     "password",
+			"--ssl-insecure": "true",
+		}, "spec", "template", "spec", "sharedparameters")).To(Succeed())
+		_, err := dynamicClient.Resource(farTemplateGVR).Namespace(testNamespace).
+			Create(context.Background(), template, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// switch the NHC from SNR to the FAR template
+		nhcList := &v1alpha1.NodeHealthCheckList{}
+		Expect(client.List(context.Background(), nhcList)).To(Succeed())
+		Expect(nhcList.Items).To(HaveLen(1), "less or more than 1 NHC found")
+		nhc = &nhcList.Items[0]
+		oldTemplateRef = nhc.Spec.RemediationTemplate
+		nhc.Spec.RemediationTemplate = &v1.ObjectReference{
+			APIVersion: farTemplateGVR.GroupVersion().String(),
+			Kind:       "FenceAgentsRemediationTemplate",
+			Namespace:  testNamespace,
+			Name:       farTemplateName,
+		}
+		Expect(client.Update(context.Background(), nhc)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if nhc != nil {
+			Expect(client.Get(context.Background(), ctrl.ObjectKeyFromObject(nhc), nhc)).To(Succeed())
+			nhc.Spec.RemediationTemplate = oldTemplateRef
+			Expect(client.Update(context.Background(), nhc)).To(Succeed())
+		}
+	})
+
+	It("remediates an unhealthy node with the fence agent", func() {
+		// find a worker node and make it unready
+		workers := &v1.NodeList{}
+		selector := labels.NewSelector()
+		req, _ := labels.NewRequirement("node-role.kubernetes.io/worker", selection.Exists, []string{})
+		selector = selector.Add(*req)
+		Expect(client.List(context.Background(), workers, &ctrl.ListOptions{LabelSelector: selector})).To(Succeed())
+		Expect(len(workers.Items)).To(BeNumerically(">=", 2))
+		// don't touch the node used by the SNR test path
+		farNodeName = workers.Items[len(workers.Items)-1].GetName()
+		Expect(makeNodeUnready(farNodeName)).To(Succeed())
+
+		// the FAR CR must be created in the template's namespace
+		Eventually(func() error {
+			far, err := dynamicClient.Resource(farGVR).Namespace(testNamespace).
+				Get(context.Background(), farNodeName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			log.Info("found FAR resource", "name", far.GetName())
+			return nil
+		}, remediationStartedTimeout, 10*time.Second).Should(Succeed())
+
+		// the mock fence agent reboots the node via the virtual BMC
+		Eventually(func() error {
+			node := &v1.Node{}
+			if err := client.Get(context.Background(), ctrl.ObjectKey{Name: farNodeName}, node); err != nil {
+				return err
+			}
+			for _, cond := range node.Status.Conditions {
+				if cond.Type == v1.NodeReady && cond.Status == v1.ConditionTrue {
+					return nil
+				}
+			}
+			return fmt.Errorf("node %s is not ready yet", farNodeName)
+		}, nodeRebootedTimeout, 30*time.Second).Should(Succeed())
+	})
+})
@@ -0,0 +1,133 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+// This test path restarts the operator pod while a remediation is in flight and
+// asserts the new pod resumes from the persisted state: the remediation stays in
+// place, the node lease keeps being renewed, and the timing recorded in the NHC
+// status survives the restart. It is disruptive for the other test paths, so it is
+// skipped unless RESTART_E2E=true is set, keep this aligned with CI config!
+const operatorPodLabel = "control-plane"
+
+var _ = Describe("e2e - operator restart mid-remediation", func() {
+
+	var restartNodeName string
+
+	BeforeEach(func() {
+		if os.Getenv("RESTART_E2E") != "true" {
+			Skip("skipping operator restart e2e, set RESTART_E2E=true to enable")
+		}
+
+		// find a worker node and make it unready
+		workers := &v1.NodeList{}
+		selector := labels.NewSelector()
+		req, _ := labels.NewRequirement("node-role.kubernetes.io/worker", selection.Exists, []string{})
+		selector = selector.Add(*req)
+		Expect(client.List(context.Background(), workers, &ctrl.ListOptions{LabelSelector: selector})).To(Succeed())
+		Expect(len(workers.Items)).To(BeNumerically(">=", 2))
+		restartNodeName = workers.Items[0].GetName()
+		Expect(makeNodeUnready(restartNodeName)).To(Succeed())
+	})
+
+	It("resumes the in-flight remediation after the operator pod restarted", func() {
+		// wait until remediation started
+		Eventually(
+			fetchRemediationResourceByName(restartNodeName), remediationStartedTimeout, 10*time.Second).
+			Should(Succeed())
+
+		operatorPod := getOperatorPod()
+		operatorNamespace := operatorPod.GetNamespace()
+
+		// the state the new pod must resume from: the remediation start time in the
+		// NHC status and the node lease
+		nhc := getSingleNHC()
+		startedBefore, tracked := nhc.Status.InFlightRemediations[restartNodeName]
+		Expect(tracked).To(BeTrue(), "the in-flight remediation should be tracked in the NHC status")
+		leaseBefore := getNodeLease(operatorNamespace, restartNodeName)
+		Expect(leaseBefore.Spec.RenewTime).NotTo(BeNil())
+
+		// restart the operator
+		log.Info("deleting the operator pod", "name", operatorPod.GetName(), "namespace", operatorNamespace)
+		Expect(client.Delete(context.Background(), operatorPod)).To(Succeed())
+		Eventually(func() error {
+			pod := getOperatorPod()
+			if pod.GetName() == operatorPod.GetName() {
+				return fmt.Errorf("the old operator pod %s is still around", pod.GetName())
+			}
+			if pod.Status.Phase != v1.PodRunning {
+				return fmt.Errorf("the new operator pod %s is in phase %s", pod.GetName(), pod.Status.Phase)
+			}
+			return nil
+		}, 5*time.Minute, 10*time.Second).Should(Succeed(), "a new operator pod should be running")
+
+		// the new pod must keep renewing the node lease
+		Eventually(func() (bool, error) {
+			lease := getNodeLease(operatorNamespace, restartNodeName)
+			if lease.Spec.RenewTime == nil {
+				return false, fmt.Errorf("the node lease lost its renew time")
+			}
+			return lease.Spec.RenewTime.After(leaseBefore.Spec.RenewTime.Time), nil
+		}, 5*time.Minute, 10*time.Second).Should(BeTrue(), "the new operator pod should resume renewing the node lease")
+
+		// and the persisted remediation timing must have survived, a reset start time
+		// would skew the escalation and lease overdue deadlines
+		nhc = getSingleNHC()
+		startedAfter, tracked := nhc.Status.InFlightRemediations[restartNodeName]
+		Expect(tracked).To(BeTrue(), "the in-flight remediation should still be tracked in the NHC status")
+		Expect(startedAfter.Time).To(BeTemporally("==", startedBefore.Time),
+			"the remediation start time must survive the operator restart")
+
+		// the remediation CR itself is still in place
+		Expect(fetchRemediationResourceByName(restartNodeName)()).To(Succeed())
+	})
+})
+
+// getOperatorPod returns the NHC operator's pod, found via the controller-manager
+// label of the operator deployment
+func getOperatorPod() *v1.Pod {
+	pods := &v1.PodList{}
+	selector := labels.NewSelector()
+	req, _ := labels.NewRequirement(operatorPodLabel, selection.Equals, []string{"controller-manager"})
+	selector = selector.Add(*req)
+	ExpectWithOffset(1, client.List(context.Background(), pods, &ctrl.ListOptions{LabelSelector: selector})).To(Succeed())
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.GetDeletionTimestamp() == nil {
+			return pod
+		}
+	}
+	Fail("no running operator pod found")
+	return nil
+}
+
+// getSingleNHC returns the one NHC expected in the test cluster
+func getSingleNHC() *v1alpha1.NodeHealthCheck {
+	nhcList := &v1alpha1.NodeHealthCheckList{}
+	ExpectWithOffset(1, client.List(context.Background(), nhcList)).To(Succeed())
+	ExpectWithOffset(1, nhcList.Items).To(HaveLen(1), "less or more than 1 NHC found")
+	return &nhcList.Items[0]
+}
+
+// getNodeLease returns the node lease the operator holds for the given node
+func getNodeLease(namespace, nodeName string) *coordv1.Lease {
+	lease := &coordv1.Lease{}
+	ExpectWithOffset(1, client.Get(context.Background(),
+		ctrl.ObjectKey{Namespace: namespace, Name: fmt.Sprintf("node-%s", nodeName)}, lease)).To(Succeed())
+	return lease
+}
@@ -0,0 +1,196 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The soak suite repeatedly disrupts random workers over hours, looking for remediation
+// leaks and operator degradation which the short scenarios can't catch. It runs for
+// SOAK_DURATION (default 2h) and is skipped unless SOAK_E2E=true is set, keep this
+// aligned with CI config!
+var _ = Describe("e2e - soak", func() {
+
+	var (
+		soakDuration time.Duration
+		reportPath   string
+		workers      *v1.NodeList
+	)
+
+	BeforeEach(func() {
+		if os.Getenv("SOAK_E2E") != "true" {
+			Skip("skipping soak e2e, set SOAK_E2E=true to enable")
+		}
+
+		soakDuration = 2 * time.Hour
+		if configured := os.Getenv("SOAK_DURATION"); configured != "" {
+			parsed, err := time.ParseDuration(configured)
+			Expect(err).NotTo(HaveOccurred(), "SOAK_DURATION must be a Go duration like \"4h\"")
+			soakDuration = parsed
+		}
+		reportPath = os.Getenv("SOAK_REPORT")
+		if reportPath == "" {
+			reportPath = "soak-report.txt"
+		}
+
+		workers = &v1.NodeList{}
+		selector := labels.NewSelector()
+		req, _ := labels.NewRequirement("node-role.kubernetes.io/worker", selection.Exists, []string{})
+		selector = selector.Add(*req)
+		Expect(client.List(context.Background(), workers, &ctrl.ListOptions{LabelSelector: selector})).To(Succeed())
+		Expect(len(workers.Items)).To(BeNumerically(">=", 2))
+	})
+
+	It("repeatedly remediates random workers without leaking resources", func() {
+		report := &strings.Builder{}
+		fmt.Fprintf(report, "soak started at %s, duration %s, workers %d\n",
+			time.Now().Format(time.RFC3339), soakDuration, len(workers.Items))
+
+		memoryBaseline := operatorMemoryBytes()
+		fmt.Fprintf(report, "operator memory baseline: %.0f bytes\n", memoryBaseline)
+
+		deadline := time.Now().Add(soakDuration)
+		cycle := 0
+		for time.Now().Before(deadline) {
+			cycle++
+			nodeName := workers.Items[rand.Intn(len(workers.Items))].GetName()
+			log.Info("starting soak cycle", "cycle", cycle, "Node name", nodeName)
+
+			cycleStart := time.Now()
+			Expect(makeNodeUnready(nodeName)).To(Succeed())
+
+			// detection latency must stay bounded over all cycles
+			Eventually(
+				fetchRemediationResourceByName(nodeName), remediationStartedTimeout, 10*time.Second).
+				Should(Succeed())
+			detectionLatency := time.Since(cycleStart)
+
+			// the node recovers and the remediation CR is cleaned up again
+			Eventually(func() error {
+				return checkNodeReady(nodeName)
+			}, nodeRebootedTimeout, 30*time.Second).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(fetchRemediationResourceByName(nodeName)())
+			}, remediationStartedTimeout, 10*time.Second).Should(BeTrue())
+			removeAPIBlockingPod()
+
+			fmt.Fprintf(report, "cycle %d: node %s, detection latency %s, recovered after %s\n",
+				cycle, nodeName, detectionLatency.Round(time.Second), time.Since(cycleStart).Round(time.Second))
+		}
+
+		// nothing may be left behind after the last cycle completed
+		ns, err := getTemplateNS()
+		Expect(err).NotTo(HaveOccurred())
+
+		remediations, err := dynamicClient.Resource(remediationGVR).Namespace(ns).List(context.Background(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remediations.Items).To(BeEmpty(), "remediation CRs leaked")
+
+		leases, err := clientSet.CoordinationV1().Leases(ns).List(context.Background(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		for _, lease := range leases.Items {
+			Expect(strings.HasPrefix(lease.GetName(), "node-")).To(BeFalse(),
+				fmt.Sprintf("node lease %s leaked", lease.GetName()))
+		}
+
+		_, err = clientSet.CoreV1().Pods(testNamespace).Get(context.Background(), blockingPodName, metav1.GetOptions{})
+		Expect(errors.IsNotFound(err)).To(BeTrue(), "the api blocker pod leaked")
+
+		// the operator must not grow unboundedly or get OOM killed over the soak
+		if memoryBaseline > 0 {
+			memoryFinal := operatorMemoryBytes()
+			fmt.Fprintf(report, "operator memory final: %.0f bytes\n", memoryFinal)
+			Expect(memoryFinal).To(BeNumerically("<", 2*memoryBaseline), "operator memory usage doubled over the soak")
+		}
+		Expect(operatorRestartCount()).To(BeZero(), "the operator restarted during the soak")
+
+		fmt.Fprintf(report, "soak finished at %s after %d cycles\n", time.Now().Format(time.RFC3339), cycle)
+		Expect(os.WriteFile(reportPath, []byte(report.String()), 0644)).To(Succeed())
+		log.Info("wrote soak report", "path", reportPath)
+	})
+})
+
+// checkNodeReady returns nil when the node's Ready condition is true
+func checkNodeReady(nodeName string) error {
+	node := &v1.Node{}
+	if err := client.Get(context.Background(), ctrl.ObjectKey{Name: nodeName}, node); err != nil {
+		return err
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+			return nil
+		}
+	}
+	return fmt.Errorf("node %s is not ready", nodeName)
+}
+
+// operatorPod returns the operator's pod, assuming it shares the namespace with the
+// default remediation template
+func operatorPod() (*v1.Pod, error) {
+	ns, err := getTemplateNS()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientSet.CoreV1().Pods(ns).List(context.Background(), metav1.ListOptions{LabelSelector: "control-plane=controller-manager"})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no operator pod found in namespace %s", ns)
+	}
+	return &pods.Items[0], nil
+}
+
+// operatorMemoryBytes reads the operator's resident memory from its metrics endpoint,
+// it returns 0 when the metric isn't reachable
+func operatorMemoryBytes() float64 {
+	pod, err := operatorPod()
+	if err != nil {
+		log.Error(err, "failed to find the operator pod for the memory check")
+		return 0
+	}
+	raw, err := clientSet.CoreV1().Pods(pod.GetNamespace()).
+		ProxyGet("http", pod.GetName(), "8080", "metrics", nil).
+		DoRaw(context.Background())
+	if err != nil {
+		log.Error(err, "failed to scrape the operator metrics endpoint")
+		return 0
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(line, "process_resident_memory_bytes") {
+			fields := strings.Fields(line)
+			if value, err := strconv.ParseFloat(fields[len(fields)-1], 64); err == nil {
+				return value
+			}
+		}
+	}
+	return 0
+}
+
+// operatorRestartCount sums the container restarts of the operator pod
+func operatorRestartCount() int32 {
+	pod, err := operatorPod()
+	if err != nil {
+		return 0
+	}
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
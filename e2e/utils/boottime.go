@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -21,38 +23,105 @@ import (
 
 // This code is for big parts from https://github.com/openshift-kni/performance-addon-operators/tree/master/functests/utils
 
-// GetBootTime gets the boot time of the given node by running a pod on it executing uptime command
+// boot time pods are cached and reused across specs, creating and scheduling a
+// new pod for every boot time query is slow
+var (
+	bootTimePodsLock sync.Mutex
+	bootTimePods     = map[string]*corev1.Pod{}
+)
+
+// GetBootTime gets the boot time of the given node by running a pod on it executing uptime
+// command, or a PowerShell query on Windows nodes
 func GetBootTime(c *kubernetes.Clientset, nodeName string, log logr.Logger) (*time.Time, error) {
 
-	// create a pod and wait that it's running
-	pod := getBootTimePod(nodeName)
-	pod, err := c.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+	node, err := c.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
+	isWindows := node.GetLabels()["kubernetes.io/os"] == "windows"
 
-	err = waitForCondition(c, pod, corev1.PodReady, corev1.ConditionTrue, time.Minute)
+	pod, err := getOrCreateBootTimePod(c, nodeName, isWindows, log)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Info("boot time pod is running, going to execute uptime command")
+	if isWindows {
+		// Windows nodes have no uptime command, ask WMI via PowerShell instead
+		bootTimeCommand := []string{"powershell", "-NonInteractive", "-Command",
+			"(Get-CimInstance Win32_OperatingSystem).LastBootUpTime.ToString('yyyy-MM-dd HH:mm:ss')"}
+		bytes, err := waitForPodOutput(c, pod, bootTimeCommand)
+		if err != nil {
+			return nil, err
+		}
+		bootTime, err := time.Parse("2006-01-02 15:04:05", strings.TrimSpace(string(bytes)))
+		if err != nil {
+			return nil, err
+		}
+		return &bootTime, nil
+	}
 
-	// get boot time
-	// ubi does noy have uptime command, so we need to install it...
-	bootTimeCommand := []string{"sh", "-c", "microdnf install procps -y >/dev/null 2>&1 && uptime -s"}
+	// the uptime command needs procps, and installing packages at runtime is slow and
+	// fails in disconnected clusters, so derive the boot time from /proc/uptime instead
+	bootTimeCommand := []string{"sh", "-c", "echo $(( $(date +%s) - $(cut -d. -f1 /proc/uptime) ))"}
 	bytes, err := waitForPodOutput(c, pod, bootTimeCommand)
 	if err != nil {
 		return nil, err
 	}
-	bootTime, err := time.Parse("2006-01-02 15:04:05", strings.TrimSpace(string(bytes)))
+	bootTimeEpoch, err := strconv.ParseInt(strings.TrimSpace(string(bytes)), 10, 64)
 	if err != nil {
 		return nil, err
 	}
-
+	bootTime := time.Unix(bootTimeEpoch, 0)
 	return &bootTime, nil
 }
 
+// getOrCreateBootTimePod returns a running boot time pod for the given node, reusing
+// a cached one when it is still around, or creating a new one otherwise
+func getOrCreateBootTimePod(c *kubernetes.Clientset, nodeName string, isWindows bool, log logr.Logger) (*corev1.Pod, error) {
+	bootTimePodsLock.Lock()
+	defer bootTimePodsLock.Unlock()
+
+	if cached, exists := bootTimePods[nodeName]; exists {
+		pod, err := c.CoreV1().Pods(cached.Namespace).Get(context.Background(), cached.Name, metav1.GetOptions{})
+		if err == nil && pod.GetDeletionTimestamp() == nil && isPodReady(pod) {
+			log.Info("reusing cached boot time pod", "pod", pod.Name, "node", nodeName)
+			return pod, nil
+		}
+		// the pod is gone or not usable anymore, e.g. after the node rebooted
+		delete(bootTimePods, nodeName)
+	}
+
+	pod := getBootTimePod(nodeName)
+	if isWindows {
+		pod = getWindowsBootTimePod(nodeName)
+	}
+
+	// create the pod and wait that it's running
+	pod, err := c.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = waitForCondition(c, pod, corev1.PodReady, corev1.ConditionTrue, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("boot time pod is running, going to execute boot time command")
+
+	bootTimePods[nodeName] = pod
+	return pod, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 func waitForPodOutput(c *kubernetes.Clientset, pod *corev1.Pod, command []string) ([]byte, error) {
 	var out []byte
 	if err := wait.PollImmediate(15*time.Second, time.Minute, func() (done bool, err error) {
@@ -133,6 +202,37 @@ func waitForCondition(c *kubernetes.Clientset, pod *corev1.Pod, conditionType co
 	})
 }
 
+// getWindowsBootTimePod returns a pod for Windows nodes, with PowerShell available for
+// the boot time query. Windows nodes are tainted, so the pod needs a toleration.
+func getWindowsBootTimePod(nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "boot-time-",
+			Labels: map[string]string{
+				"test": "",
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name:    "test",
+					Image:   WindowsUtilsImage(),
+					Command: []string{"powershell", "-Command", "Start-Sleep 3600"},
+				},
+			},
+			Tolerations: []corev1.Toleration{
+				{
+					Key:      "os",
+					Value:    "Windows",
+					Effect:   corev1.TaintEffectNoSchedule,
+					Operator: corev1.TolerationOpEqual,
+				},
+			},
+		},
+	}
+}
+
 func getBootTimePod(nodeName string) *corev1.Pod {
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -146,8 +246,8 @@ func getBootTimePod(nodeName string) *corev1.Pod {
 			Containers: []corev1.Container{
 				{
 					Name:    "test",
-					Image:   "registry.access.redhat.com/ubi8/ubi-minimal",
-					Command: []string{"sleep", "2m"},
+					Image:   UtilsImage(),
+					Command: []string{"sleep", "1h"},
 				},
 			},
 		},
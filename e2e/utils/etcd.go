@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	etcdNamespace          = "openshift-etcd"
+	etcdPodLabelSelector   = "app=etcd"
+	etcdQuorumGuardPDBName = "etcd-quorum-guard"
+)
+
+// CountReadyEtcdMembers returns how many etcd pods are ready, which equals the
+// number of healthy etcd members on OpenShift
+func CountReadyEtcdMembers(clientSet *kubernetes.Clientset) (int, error) {
+	pods, err := clientSet.CoreV1().Pods(etcdNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: etcdPodLabelSelector,
+	})
+	if err != nil {
+		return 0, err
+	}
+	ready := 0
+	for _, pod := range pods.Items {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == v1.PodReady && cond.Status == v1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return ready, nil
+}
+
+// IsEtcdDisruptionAllowed checks whether the etcd quorum guard PDB would allow
+// disrupting another etcd member
+func IsEtcdDisruptionAllowed(clientSet *kubernetes.Clientset) (bool, error) {
+	pdb, err := clientSet.PolicyV1().PodDisruptionBudgets(etcdNamespace).Get(context.Background(), etcdQuorumGuardPDBName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return pdb.Status.DisruptionsAllowed > 0, nil
+}
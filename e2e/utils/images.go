@@ -0,0 +1,37 @@
+package utils
+
+import "os"
+
+// The images of all e2e helper pods, with env-driven overrides so the suite
+// runs in disconnected environments where images come from a mirror registry.
+// The list of images to mirror is in e2e/images.yaml.
+
+const (
+	// UtilsImageEnvVar overrides the image of the Linux helper pods, e.g. the
+	// boot time pods and the api blocker pod. Any image with bash and the usual
+	// coreutils works, no extra packages are required.
+	UtilsImageEnvVar = "TEST_UTILS_IMAGE"
+
+	// WindowsUtilsImageEnvVar overrides the image of the Windows boot time
+	// pods, the image must provide PowerShell
+	WindowsUtilsImageEnvVar = "TEST_WINDOWS_UTILS_IMAGE"
+
+	defaultUtilsImage        = "registry.access.redhat.com/ubi8/ubi-minimal"
+	defaultWindowsUtilsImage = "mcr.microsoft.com/windows/servercore:ltsc2022"
+)
+
+// UtilsImage returns the image for the Linux helper pods
+func UtilsImage() string {
+	if image := os.Getenv(UtilsImageEnvVar); image != "" {
+		return image
+	}
+	return defaultUtilsImage
+}
+
+// WindowsUtilsImage returns the image for the Windows helper pods
+func WindowsUtilsImage() string {
+	if image := os.Getenv(WindowsUtilsImageEnvVar); image != "" {
+		return image
+	}
+	return defaultWindowsUtilsImage
+}
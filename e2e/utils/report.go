@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// artifactDirEnvVar points to the directory CI collects artifacts from; when it is
+// not set, no artifacts are written and the reporting helpers are no-ops
+const artifactDirEnvVar = "ARTIFACT_DIR"
+
+// timingReportFileName is the JSON artifact holding the per-spec timings, meant for
+// trending fencing performance over releases next to the JUnit results
+const timingReportFileName = "e2e_timings.json"
+
+// SpecTiming is one measured phase of a spec, e.g. how long detection of the
+// unhealthy node took
+type SpecTiming struct {
+	Spec            string    `json:"spec"`
+	Phase           string    `json:"phase"`
+	CompletedAt     time.Time `json:"completedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+}
+
+var (
+	specTimingsLock sync.Mutex
+	specTimings     []SpecTiming
+)
+
+// RecordTiming records that the given phase of the given spec, started at start,
+// completed now. The timings end up in the timing report artifact.
+func RecordTiming(spec, phase string, start time.Time) {
+	specTimingsLock.Lock()
+	defer specTimingsLock.Unlock()
+	specTimings = append(specTimings, SpecTiming{
+		Spec:            spec,
+		Phase:           phase,
+		CompletedAt:     time.Now().UTC(),
+		DurationSeconds: time.Since(start).Seconds(),
+	})
+}
+
+// ArtifactPath returns the path of the named artifact inside the CI artifact
+// directory, or an empty string when no artifact directory is configured
+func ArtifactPath(name string) string {
+	dir := os.Getenv(artifactDirEnvVar)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
+
+// WriteTimingReport writes the recorded spec timings as a JSON artifact. Reporting is
+// best effort, a failure to write the artifact must not fail the suite.
+func WriteTimingReport(log logr.Logger) {
+	path := ArtifactPath(timingReportFileName)
+	if path == "" {
+		return
+	}
+	specTimingsLock.Lock()
+	defer specTimingsLock.Unlock()
+	out, err := json.MarshalIndent(specTimings, "", "  ")
+	if err != nil {
+		log.Error(err, "failed to marshal the timing report")
+		return
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		log.Error(err, "failed to write the timing report", "path", path)
+		return
+	}
+	log.Info("wrote the timing report", "path", path, "timings", len(specTimings))
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Writes the Grafana dashboard JSON generated from the metrics package
+// to the path given as first argument.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/medik8s/node-healthcheck-operator/metrics"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: generate-dashboard <output file>")
+		os.Exit(1)
+	}
+	data, err := metrics.GenerateDashboard()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate dashboard: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(os.Args[1], append(data, '\n'), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write dashboard: %v\n", err)
+		os.Exit(1)
+	}
+}
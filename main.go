@@ -17,33 +17,53 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/medik8s/node-healthcheck-operator/controllers/bootstrap"
 
 	"github.com/medik8s/node-healthcheck-operator/controllers"
 	"github.com/medik8s/node-healthcheck-operator/controllers/cluster"
+	"github.com/medik8s/node-healthcheck-operator/controllers/evaluate"
+	"github.com/medik8s/node-healthcheck-operator/controllers/events"
+	"github.com/medik8s/node-healthcheck-operator/controllers/hub"
+	"github.com/medik8s/node-healthcheck-operator/controllers/lease"
 	"github.com/medik8s/node-healthcheck-operator/controllers/mhc"
+	"github.com/medik8s/node-healthcheck-operator/controllers/migration"
+	"github.com/medik8s/node-healthcheck-operator/controllers/notify"
+	"github.com/medik8s/node-healthcheck-operator/controllers/pooldisable"
+	"github.com/medik8s/node-healthcheck-operator/controllers/rbac"
+	"github.com/medik8s/node-healthcheck-operator/controllers/replace"
+	"github.com/medik8s/node-healthcheck-operator/controllers/safemode"
+	"github.com/medik8s/node-healthcheck-operator/controllers/signing"
+	"github.com/medik8s/node-healthcheck-operator/controllers/storage"
+	"github.com/medik8s/node-healthcheck-operator/controllers/utils"
 	"go.uber.org/zap/zapcore"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecluster "sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 
 	remediationv1alpha1 "github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+	"github.com/medik8s/node-healthcheck-operator/capabilities"
 	"github.com/medik8s/node-healthcheck-operator/metrics"
 	"github.com/medik8s/node-healthcheck-operator/version"
 	// +kubebuilder:scaffold:imports
@@ -62,15 +82,96 @@ func init() {
 
 	utilruntime.Must(machinev1beta1.Install(scheme))
 
+	// remediator capability discovery reads remediation template CRDs
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
+	var metricsTLSAddr string
+	var metricsCertDir string
+	var metricsClusterID string
 	var enableLeaderElection bool
 	var probeAddr string
+	var decisionSigningSecret string
+	var evaluationAddr string
+	var evaluationTokenSecret string
+	var hostedClusterKubeconfigSecret string
+	var slackWebhookSecret string
+	var teamsWebhookSecret string
+	var notificationCABundle string
+	var apiServerQPS float64
+	var apiServerBurst int
+	var preUpgradeCheck bool
+	var hubMode bool
+	var safeModeMarkerFile string
+	var safeModeStablePeriod time.Duration
+	var enableReplaceMachineRemediation bool
+	var watchNamespaces string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&metricsTLSAddr, "metrics-tls-bind-address", "", "The address the TLS metric endpoint binds to. Empty string disables the TLS endpoint.")
+	flag.StringVar(&metricsCertDir, "metrics-cert-dir", "/etc/nhc/metrics-certs",
+		"Directory with the tls.crt and tls.key for serving metrics with TLS. "+
+			"A self-signed certificate is used when the files don't exist.")
+	flag.StringVar(&metricsClusterID, "metrics-cluster-id", "",
+		"Value of a cluster_id label attached to all nodehealthcheck metrics, so fleet monitoring "+
+			"federating many clusters can tell their series apart without relabeling rules. The special "+
+			"value \"auto\" reads the ID from the OpenShift ClusterVersion. Empty string disables the label.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&decisionSigningSecret, "decision-signing-secret", "",
+		"Name of a secret in the deployment namespace with a \"key\" entry, used for signing "+
+			"remediation decisions with HMAC-SHA256. Empty string disables decision signing.")
+	flag.StringVar(&evaluationAddr, "evaluation-bind-address", "",
+		"The address the health evaluation endpoint binds to, served with TLS using the metrics certificates. "+
+			"It lets external automation query NHC's health verdict for a node. Empty string disables the endpoint.")
+	flag.StringVar(&evaluationTokenSecret, "evaluation-token-secret", "",
+		"Name of a secret in the deployment namespace with a \"token\" entry, required as bearer token "+
+			"by the health evaluation endpoint.")
+	flag.StringVar(&slackWebhookSecret, "slack-webhook-secret", "",
+		"Name of a secret in the deployment namespace with a \"webhook-url\" entry for a Slack incoming webhook. "+
+			"When set, remediation start, finish and escalation are posted there. Empty string disables Slack notifications.")
+	flag.StringVar(&teamsWebhookSecret, "teams-webhook-secret", "",
+		"Name of a secret in the deployment namespace with a \"webhook-url\" entry for an MS Teams incoming webhook. "+
+			"When set, remediation start, finish and escalation are posted there. Empty string disables Teams notifications.")
+	flag.StringVar(&notificationCABundle, "notification-ca-bundle", notify.TrustedCABundleFile,
+		"Path of a PEM CA bundle trusted for outbound notifications on top of the system pool, e.g. the "+
+			"cluster-wide trusted CA bundle injected into the trusted-ca ConfigMap. The file is re-read when "+
+			"it changes, and a missing file is ignored. The cluster-wide proxy is honored via the proxy "+
+			"environment variables.")
+	flag.StringVar(&hostedClusterKubeconfigSecret, "hosted-cluster-kubeconfig-secret", "",
+		"Name of a secret in the deployment namespace with a \"kubeconfig\" entry for the hosted cluster. "+
+			"Set this when running in a Hypershift hosted control plane namespace, where Nodes live in the "+
+			"hosted cluster while Machines live in the management cluster. Empty string disables the dual-client mode.")
+	flag.Float64Var(&apiServerQPS, "api-server-qps", 20,
+		"Maximum sustained queries per second to the API server. "+
+			"Lower this on heavily loaded clusters to reduce pressure on the API server.")
+	flag.IntVar(&apiServerBurst, "api-server-burst", 30,
+		"Maximum burst of queries to the API server before throttling kicks in.")
+	flag.BoolVar(&hubMode, "hub-mode", false,
+		"Distribute NodeHealthChecks annotated with \"hub.remediation.medik8s.io/distribute: true\" to all "+
+			"available Open Cluster Management ManagedClusters, and aggregate the spokes' status on the hub. "+
+			"Requires OCM to be installed, local health checking keeps working either way.")
+	flag.BoolVar(&enableReplaceMachineRemediation, "enable-replace-machine-remediation", false,
+		"Act on ReplaceMachineRemediation CRs by deleting the unhealthy node's backing machine, "+
+			"letting its machine set provision a replacement. For machine managed clusters which don't "+
+			"want to install a separate remediation operator. Requires the Machine API.")
+	flag.BoolVar(&preUpgradeCheck, "pre-upgrade-check", false,
+		"Check that an operator upgrade can't orphan in-flight remediations and exit. "+
+			"Meant to run from a pre-upgrade hook of the deployment mechanism.")
+	flag.StringVar(&safeModeMarkerFile, "safe-mode-marker-file", "/tmp/nhc-start-marker",
+		"File recording the operator's recent start times, used to detect a crash loop. After a crash "+
+			"loop the operator starts in safe mode: it observes and reports but doesn't fence until it "+
+			"ran stable for -safe-mode-stable-period, protecting clusters from a buggy rollout being "+
+			"amplified by fencing. Empty string disables the detection.")
+	flag.DurationVar(&safeModeStablePeriod, "safe-mode-stable-period", 10*time.Minute,
+		"How long the operator has to run without crashing again before safe mode ends and fencing resumes.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma-separated list of namespaces where remediation templates and remediation CRs are watched and created. "+
+			"Restricted multi-team clusters can scope the operator to these namespaces instead of granting it "+
+			"cluster-wide read access to namespaced resources. The deployment namespace is always included. "+
+			"Empty string watches all namespaces.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", true,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -86,7 +187,25 @@ func main() {
 
 	printVersion()
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = float32(apiServerQPS)
+	restConfig.Burst = apiServerBurst
+
+	if preUpgradeCheck {
+		checkClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create a client for the pre-upgrade check")
+			os.Exit(1)
+		}
+		if err := migration.PreUpgradeCheck(context.Background(), checkClient); err != nil {
+			setupLog.Error(err, "pre-upgrade check failed")
+			os.Exit(1)
+		}
+		setupLog.Info("pre-upgrade check passed")
+		return
+	}
+
+	managerOptions := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
@@ -94,18 +213,44 @@ func main() {
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "e1f13584.medik8s.io",
 		SyncPeriod:             &syncPeriod,
-	})
+	}
+
+	if watchNamespaces != "" {
+		namespaces := parseWatchNamespaces(watchNamespaces)
+		// restrict the cache to the configured namespaces, cluster scoped resources
+		// like Nodes and NodeHealthChecks are served by a global cache either way
+		managerOptions.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+		// the controller and the webhook reject template references outside of them
+		utils.SetWatchNamespaces(namespaces)
+		remediationv1alpha1.SetWatchNamespaces(namespaces)
+		setupLog.Info("watching a restricted set of namespaces", "namespaces", namespaces)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, managerOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if metricsTLSAddr != "" {
+		if err = mgr.Add(metrics.NewTLSServer(metricsTLSAddr, metricsCertDir, ctrl.Log.WithName("metrics"))); err != nil {
+			setupLog.Error(err, "failed to add TLS metrics server to the manager")
+			os.Exit(1)
+		}
+	}
+
 	upgradeChecker, err := cluster.NewClusterUpgradeStatusChecker(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable initialize cluster upgrade checker")
 		os.Exit(1)
 	}
 
+	platformHealthChecker, err := cluster.NewPlatformHealthChecker(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable initialize platform health checker")
+		os.Exit(1)
+	}
+
 	mhcChecker, err := mhc.NewMHCChecker(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable initialize MHC checker")
@@ -116,18 +261,163 @@ func main() {
 		os.Exit(1)
 	}
 
+	// migrate stored NHC CRs and remediation CR ownership after upgrades which
+	// changed the CRD schema
+	migrator := migration.NewMigrator(mgr.GetClient(), ctrl.Log.WithName("storageVersionMigrator"))
+	if err = mgr.Add(migrator); err != nil {
+		setupLog.Error(err, "failed to add the storage version migrator to the manager")
+		os.Exit(1)
+	}
+
+	if hubMode {
+		if err = mgr.Add(hub.NewDistributor(mgr.GetClient(), ctrl.Log.WithName("hubDistributor"))); err != nil {
+			setupLog.Error(err, "failed to add the hub distributor to the manager")
+			os.Exit(1)
+		}
+	}
+
+	// surface missing or stale remediation permissions before fencing needs them
+	rbacVerifier := rbac.NewVerifier(mgr, ctrl.Log.WithName("rbacVerifier"))
+	if err = mgr.Add(rbacVerifier); err != nil {
+		setupLog.Error(err, "failed to add the RBAC verifier to the manager")
+		os.Exit(1)
+	}
+
+	poolDisableResolver, err := pooldisable.NewResolver(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable initialize machine pool disable resolver")
+		os.Exit(1)
+	}
+
+	// detect whether this start is part of a crash loop, a buggy rollout amplified
+	// by fencing turns into an outage, so safe mode holds fencing back until the
+	// operator proved stable again
+	var safeModeChecker safemode.Checker
+	if safeModeMarkerFile != "" {
+		safeModeChecker = safemode.NewDetector(safeModeMarkerFile, safeModeStablePeriod, ctrl.Log.WithName("safeMode"))
+	}
+
+	eventsAggregator := events.NewAggregator(mgr)
+	if err = mgr.Add(eventsAggregator); err != nil {
+		setupLog.Error(err, "failed to add events aggregator to the manager")
+		os.Exit(1)
+	}
+
+	ns, err := utils.GetDeploymentNamespace()
+	if err != nil {
+		setupLog.Error(err, "unable to get the deployment namespace")
+		os.Exit(1)
+	}
+	apiServerClock := lease.NewAPIServerClock(mgr.GetClient(), ns, ctrl.Log.WithName("apiServerClock"))
+	leaseManager := lease.NewManager(
+		mgr.GetClient(),
+		ns,
+		apiServerClock,
+		ctrl.Log.WithName("leaseManager"),
+	)
+	// the API server based clock also measures the local clock's skew, the
+	// reconciler surfaces a too large one as a ClockSkewed condition
+	clockSkewChecker, _ := apiServerClock.(lease.SkewChecker)
+
+	// on Hypershift Nodes live in the hosted cluster, give the reconciler and the
+	// lease sweeper a second client and cache for them
+	var hostedCluster runtimecluster.Cluster
+	if hostedClusterKubeconfigSecret != "" {
+		hostedCluster, err = cluster.NewHostedCluster(restConfig, ns, hostedClusterKubeconfigSecret, scheme)
+		if err != nil {
+			setupLog.Error(err, "unable to initialize the hosted cluster client")
+			os.Exit(1)
+		}
+		if err = mgr.Add(hostedCluster); err != nil {
+			setupLog.Error(err, "failed to add the hosted cluster to the manager")
+			os.Exit(1)
+		}
+	}
+
+	// clean up the leases left behind by deleted nodes; the nodes are read from
+	// the cluster they live in, like the reconciler reads them
+	sweeperNodeReader := client.Reader(mgr.GetClient())
+	if hostedCluster != nil {
+		sweeperNodeReader = hostedCluster.GetClient()
+	}
+	if err = mgr.Add(lease.NewSweeper(mgr.GetClient(), sweeperNodeReader, ns, ctrl.Log.WithName("leaseSweeper"))); err != nil {
+		setupLog.Error(err, "failed to add the lease sweeper to the manager")
+		os.Exit(1)
+	}
+
+	var decisionSigner signing.Signer
+	if decisionSigningSecret != "" {
+		// read the signing secret uncached, the granted RBAC doesn't allow the
+		// informer a cached secret read would start
+		decisionSigner = signing.NewHMACSigner(mgr.GetAPIReader(), ns, decisionSigningSecret, ctrl.Log.WithName("decisionSigner"))
+	}
+
+	if evaluationAddr != "" {
+		if evaluationTokenSecret == "" {
+			setupLog.Error(nil, "the evaluation endpoint requires -evaluation-token-secret, refusing to serve it unauthenticated")
+			os.Exit(1)
+		}
+		evaluationServer := metrics.NewTLSServer(evaluationAddr, metricsCertDir, ctrl.Log.WithName("evaluation"))
+		// the token secret is read uncached, the granted RBAC doesn't allow the
+		// informer a cached secret read would start
+		evaluationServer.Handler = evaluate.NewHandler(mgr.GetClient(), mgr.GetAPIReader(), ns, evaluationTokenSecret, ctrl.Log.WithName("evaluation"))
+		if err = mgr.Add(evaluationServer); err != nil {
+			setupLog.Error(err, "failed to add the evaluation server to the manager")
+			os.Exit(1)
+		}
+	}
+
+	var notificationProviders []notify.Provider
+	// all providers share one transport, so they agree on the cluster-wide proxy
+	// and the trusted CA bundle; the webhook secrets are read uncached, the
+	// granted RBAC doesn't allow the informer a cached secret read would start
+	notificationTransport := notify.NewTransport(notificationCABundle)
+	if slackWebhookSecret != "" {
+		notificationProviders = append(notificationProviders, notify.NewSlackProvider(mgr.GetAPIReader(), ns, slackWebhookSecret, notificationTransport))
+	}
+	if teamsWebhookSecret != "" {
+		notificationProviders = append(notificationProviders, notify.NewTeamsProvider(mgr.GetAPIReader(), ns, teamsWebhookSecret, notificationTransport))
+	}
+	var notifier notify.Notifier
+	if len(notificationProviders) > 0 {
+		notifier = notify.NewNotifier(ctrl.Log.WithName("notifier"), notificationProviders...)
+	}
+
 	if err := (&controllers.NodeHealthCheckReconciler{
 		Client:                      mgr.GetClient(),
 		Log:                         ctrl.Log.WithName("controllers").WithName("NodeHealthCheck"),
 		Scheme:                      mgr.GetScheme(),
 		Recorder:                    mgr.GetEventRecorderFor("NodeHealthCheck"),
 		ClusterUpgradeStatusChecker: upgradeChecker,
+		PlatformHealthChecker:       platformHealthChecker,
 		MHCChecker:                  mhcChecker,
+		LeaseManager:                leaseManager,
+		EventsAggregator:            eventsAggregator,
+		DecisionSigner:              decisionSigner,
+		Notifier:                    notifier,
+		HostedCluster:               hostedCluster,
+		StorageDetachChecker:        storage.NewDetachChecker(mgr.GetClient()),
+		Capabilities:                capabilities.NewReader(mgr.GetClient()),
+		PoolDisableResolver:         poolDisableResolver,
+		SafeModeChecker:             safeModeChecker,
+		ClockSkewChecker:            clockSkewChecker,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NodeHealthCheck")
 		os.Exit(1)
 	}
 
+	// validate the referenced remediation templates off the hot remediation path,
+	// so configuration problems surface before the first node fails
+	if err := (&controllers.TemplateValidationReconciler{
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("TemplateValidation"),
+		Recorder:           mgr.GetEventRecorderFor("TemplateValidation"),
+		PermissionsChecker: rbacVerifier,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TemplateValidation")
+		os.Exit(1)
+	}
+
 	if err := (&controllers.MachineHealthCheckReconciler{
 		Client:                      mgr.GetClient(),
 		Log:                         ctrl.Log.WithName("controllers").WithName("MachineHealthCheck"),
@@ -140,10 +430,38 @@ func main() {
 		os.Exit(1)
 	}
 
+	if enableReplaceMachineRemediation {
+		openshift, err := utils.IsOnOpenshift(mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "failed to check whether the Machine API is available")
+			os.Exit(1)
+		}
+		if !openshift {
+			setupLog.Error(nil, "the built-in machine replacement requires the Machine API, refusing to start without it")
+			os.Exit(1)
+		}
+		if err := (&replace.ReplaceMachineRemediationReconciler{
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("controllers").WithName("ReplaceMachineRemediation"),
+			Recorder: mgr.GetEventRecorderFor("ReplaceMachineRemediation"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ReplaceMachineRemediation")
+			os.Exit(1)
+		}
+	}
+
+	// the default deployment has no serving certs for the webhook, so it is opt-in
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&remediationv1alpha1.NodeHealthCheck{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NodeHealthCheck")
+			os.Exit(1)
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	// Do some initialization, it potentially exits!
-	if err = bootstrap.Initialize(mgr, setupLog); err != nil {
+	if err = bootstrap.Initialize(mgr, leaseManager, setupLog); err != nil {
 		setupLog.Error(err, "unable to init")
 		os.Exit(1)
 	}
@@ -152,12 +470,27 @@ func main() {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
+	if err := mgr.AddHealthzCheck("mhc-checker", mhcChecker.HealthCheck); err != nil {
+		setupLog.Error(err, "unable to set up the MHC checker health check")
+		os.Exit(1)
+	}
 	if err := mgr.AddReadyzCheck("check", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
 
 	// Register the MHC specific metrics
+	if metricsClusterID != "" {
+		clusterID := metricsClusterID
+		if clusterID == "auto" {
+			var err error
+			if clusterID, err = cluster.GetClusterID(context.Background(), restConfig); err != nil {
+				setupLog.Error(err, "unable to determine the cluster ID for the metrics label")
+				os.Exit(1)
+			}
+		}
+		metrics.SetStaticLabels(map[string]string{"cluster_id": clusterID})
+	}
 	metrics.InitializeNodeHealthCheckMetrics()
 
 	setupLog.Info("starting manager")
@@ -167,6 +500,28 @@ func main() {
 	}
 }
 
+// parseWatchNamespaces splits the watch-namespaces flag value and makes sure the
+// deployment namespace is included, the operator reads its own ConfigMaps, Secrets
+// and templates from there
+func parseWatchNamespaces(flagValue string) []string {
+	seen := map[string]bool{}
+	var namespaces []string
+	add := func(namespace string) {
+		if namespace == "" || seen[namespace] {
+			return
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+	for _, namespace := range strings.Split(flagValue, ",") {
+		add(strings.TrimSpace(namespace))
+	}
+	if deploymentNamespace, err := utils.GetDeploymentNamespace(); err == nil {
+		add(deploymentNamespace)
+	}
+	return namespaces
+}
+
 func printVersion() {
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Machine API Operator authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The Grafana dashboard is generated from the metric name constants in nodehealthcheck.go,
+// so dashboard queries can't get out of sync with the exported metrics.
+// Run `make generate-dashboard` after changing metrics or panels here.
+
+type dashboard struct {
+	Title         string  `json:"title"`
+	UID           string  `json:"uid"`
+	SchemaVersion int     `json:"schemaVersion"`
+	Editable      bool    `json:"editable"`
+	Panels        []panel `json:"panels"`
+}
+
+type panel struct {
+	ID      int      `json:"id"`
+	Title   string   `json:"title"`
+	Type    string   `json:"type"`
+	GridPos gridPos  `json:"gridPos"`
+	Targets []target `json:"targets"`
+}
+
+type gridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type target struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+// GenerateDashboard returns the Grafana dashboard JSON for the NHC metrics
+func GenerateDashboard() ([]byte, error) {
+	d := dashboard{
+		Title:         "Node Health Check",
+		UID:           "node-healthcheck",
+		SchemaVersion: 30,
+		Editable:      true,
+		Panels: []panel{
+			{
+				ID:      1,
+				Title:   "Nodes currently being remediated",
+				Type:    "timeseries",
+				GridPos: gridPos{H: 8, W: 12, X: 0, Y: 0},
+				Targets: []target{{
+					Expr:         fmt.Sprintf("sum(%s) by (name)", ongoingRemediationsMetric),
+					LegendFormat: "{{name}}",
+				}},
+			},
+			{
+				ID:      2,
+				Title:   "Remediation success rate per remediator",
+				Type:    "timeseries",
+				GridPos: gridPos{H: 8, W: 12, X: 12, Y: 0},
+				Targets: []target{{
+					Expr: fmt.Sprintf("sum(rate(%[1]s{result=%[2]q}[1h])) by (remediator) / sum(rate(%[1]s{result=%[3]q}[1h])) by (remediator)",
+						remediationsTotalMetric, RemediationResultSucceeded, RemediationResultStarted),
+					LegendFormat: "{{remediator}}",
+				}},
+			},
+			{
+				ID:      3,
+				Title:   "Mean time to repair (unhealthy -> healthy)",
+				Type:    "timeseries",
+				GridPos: gridPos{H: 8, W: 12, X: 0, Y: 8},
+				Targets: []target{{
+					Expr: fmt.Sprintf("histogram_quantile(0.9, sum(rate(%s_bucket[1h])) by (remediator, le))",
+						unhealthyDurationMetric),
+					LegendFormat: "{{remediator}} p90",
+				}},
+			},
+			{
+				ID:      4,
+				Title:   "Escalation frequency",
+				Type:    "timeseries",
+				GridPos: gridPos{H: 8, W: 12, X: 12, Y: 8},
+				Targets: []target{{
+					Expr:         fmt.Sprintf("sum(rate(%s[1h])) by (remediator)", escalationsTotalMetric),
+					LegendFormat: "{{remediator}}",
+				}},
+			},
+			{
+				ID:      5,
+				Title:   "Old remediation CRs",
+				Type:    "timeseries",
+				GridPos: gridPos{H: 8, W: 12, X: 0, Y: 16},
+				Targets: []target{{
+					Expr:         fmt.Sprintf("sum(%s) by (name)", oldRemediationCRMetric),
+					LegendFormat: "{{name}}",
+				}},
+			},
+			{
+				ID:      6,
+				Title:   "Time to detect (condition transition -> remediation CR)",
+				Type:    "timeseries",
+				GridPos: gridPos{H: 8, W: 12, X: 12, Y: 16},
+				Targets: []target{{
+					Expr: fmt.Sprintf("histogram_quantile(0.9, sum(rate(%s_bucket[1h])) by (name, le))",
+						detectionLatencyMetric),
+					LegendFormat: "{{name}} p90",
+				}},
+			},
+		},
+	}
+	return json.MarshalIndent(d, "", "  ")
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Machine API Operator authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	heldNodeLeasesMetric           = "nodehealthcheck_held_node_leases"
+	leaseDenialsMetric             = "nodehealthcheck_lease_denials_total"
+	leaseRequestFailuresMetric     = "nodehealthcheck_lease_request_failures_total"
+	leaseOverdueInvalidationMetric = "nodehealthcheck_lease_overdue_invalidations_total"
+	leaseDenialBackoffMetric       = "nodehealthcheck_lease_denial_backoff_seconds"
+	sweptLeasesMetric              = "nodehealthcheck_swept_leases_total"
+)
+
+var (
+	// NodeHealthCheckHeldNodeLeases reports the number of node leases currently held while remediating.
+	NodeHealthCheckHeldNodeLeases = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: heldNodeLeasesMetric,
+			Help: "Number of node leases currently held by NodeHealthCheck",
+		},
+	)
+
+	// NodeHealthCheckLeaseDenials counts how often obtaining a node lease was denied because
+	// another holder owns it. This makes lease contention with remediators measurable.
+	NodeHealthCheckLeaseDenials = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: leaseDenialsMetric,
+			Help: "Number of node lease requests denied because the lease is held by someone else",
+		}, []string{"holder"},
+	)
+
+	// NodeHealthCheckLeaseRequestFailures counts failed API requests while managing node leases,
+	// by the operation which failed.
+	NodeHealthCheckLeaseRequestFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: leaseRequestFailuresMetric,
+			Help: "Number of failed node lease API requests, by operation",
+		}, []string{"operation"},
+	)
+
+	// NodeHealthCheckLeaseOverdueInvalidations counts node leases which were invalidated
+	// because they were held for too long without the node getting healthy.
+	NodeHealthCheckLeaseOverdueInvalidations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: leaseOverdueInvalidationMetric,
+			Help: "Number of node leases invalidated because they were held for too long",
+		},
+	)
+
+	// NodeHealthCheckLeaseDenialBackoff reports the current requeue backoff per node
+	// whose lease is persistently held by another holder.
+	NodeHealthCheckLeaseDenialBackoff = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: leaseDenialBackoffMetric,
+			Help: "Current requeue backoff for nodes whose lease is held by someone else",
+		}, []string{"node"},
+	)
+
+	// NodeHealthCheckSweptLeases counts held node leases which were deleted because
+	// their node no longer exists.
+	NodeHealthCheckSweptLeases = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: sweptLeasesMetric,
+			Help: "Number of held node leases swept because their node was deleted",
+		},
+	)
+)
+
+// ObserveNodeLeaseAcquired updates the held leases gauge when a node lease was newly
+// acquired or taken over
+func ObserveNodeLeaseAcquired() {
+	NodeHealthCheckHeldNodeLeases.Inc()
+}
+
+// ObserveNodeLeaseReleased updates the held leases gauge when a held node lease was deleted
+func ObserveNodeLeaseReleased() {
+	NodeHealthCheckHeldNodeLeases.Dec()
+}
+
+// ObserveNodeLeaseDenied counts a node lease request denied by the given holder
+func ObserveNodeLeaseDenied(holder string) {
+	NodeHealthCheckLeaseDenials.With(prometheus.Labels{
+		"holder": holder,
+	}).Inc()
+}
+
+// ObserveNodeLeaseRequestFailed counts a failed node lease API request for the given operation
+func ObserveNodeLeaseRequestFailed(operation string) {
+	NodeHealthCheckLeaseRequestFailures.With(prometheus.Labels{
+		"operation": operation,
+	}).Inc()
+}
+
+// ObserveNodeLeaseOverdue counts an overdue node lease invalidation
+func ObserveNodeLeaseOverdue() {
+	NodeHealthCheckLeaseOverdueInvalidations.Inc()
+}
+
+// ObserveNodeLeaseSwept counts a held node lease swept because its node was deleted
+func ObserveNodeLeaseSwept() {
+	NodeHealthCheckSweptLeases.Inc()
+}
+
+// SetNodeLeaseDenialBackoff updates the current denial backoff of the given node
+func SetNodeLeaseDenialBackoff(nodeName string, backoff time.Duration) {
+	NodeHealthCheckLeaseDenialBackoff.With(prometheus.Labels{
+		"node": nodeName,
+	}).Set(backoff.Seconds())
+}
+
+// ClearNodeLeaseDenialBackoff removes the denial backoff series of the given node
+// once its lease isn't held by someone else anymore
+func ClearNodeLeaseDenialBackoff(nodeName string) {
+	NodeHealthCheckLeaseDenialBackoff.Delete(prometheus.Labels{
+		"node": nodeName,
+	})
+}
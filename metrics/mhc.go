@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const lastMHCEvaluationMetric = "nodehealthcheck_last_mhc_evaluation_timestamp_seconds"
+
+// NodeHealthCheckLastMHCEvaluation reports when the MHC checker last evaluated the
+// MachineHealthChecks successfully. Alert on this falling behind, a stale evaluation
+// means NHC may stay disabled or enabled incorrectly.
+var NodeHealthCheckLastMHCEvaluation = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: lastMHCEvaluationMetric,
+		Help: "Unix timestamp of the last successful MachineHealthCheck evaluation",
+	},
+)
+
+// SetLastMHCEvaluationTime updates the timestamp of the last successful MHC evaluation
+func SetLastMHCEvaluationTime(t time.Time) {
+	NodeHealthCheckLastMHCEvaluation.Set(float64(t.Unix()))
+}
@@ -1,39 +1,238 @@
 /*
-   Copyright 2020 The Machine API Operator authors
+Copyright 2020 The Machine API Operator authors
 
-   Licensed under the Apache License, Version 2.0 (the "License");
-   you may not use this file except in compliance with the License.
-   You may obtain a copy of the License at
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
 
-       http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
-   Unless required by applicable law or agreed to in writing, software
-   distributed under the License is distributed on an "AS IS" BASIS,
-   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-   See the License for the specific language governing permissions and
-   limitations under the License.
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
 */
 package metrics
 
 import (
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+const (
+	// metric names are shared with the generated Grafana dashboard, see dashboard.go
+	oldRemediationCRMetric        = "nodehealthcheck_old_remediation_cr"
+	remediationsTotalMetric       = "nodehealthcheck_remediations_total"
+	unhealthyDurationMetric       = "nodehealthcheck_unhealthy_node_duration_seconds"
+	escalationsTotalMetric        = "nodehealthcheck_escalations_total"
+	fallbacksTotalMetric          = "nodehealthcheck_remediation_fallbacks_total"
+	ongoingRemediationsMetric     = "nodehealthcheck_ongoing_remediations"
+	suppressedStatusUpdatesMetric = "nodehealthcheck_suppressed_status_updates_total"
+	reconcileDurationMetric       = "nodehealthcheck_reconcile_duration_seconds"
+	remediationCreationMetric     = "nodehealthcheck_remediation_creation_duration_seconds"
+	reconcileErrorsMetric         = "nodehealthcheck_reconcile_errors_total"
+	globalPauseMetric             = "nodehealthcheck_global_pause"
+	flappingNodeMetric            = "nodehealthcheck_flapping_node"
+	// the detection latency metric predates the nodehealthcheck_ naming scheme,
+	// dashboards and SLOs already rely on this name
+	detectionLatencyMetric = "nhc_detection_latency_seconds"
+	// the capacity signal is a stable contract for external capacity automation,
+	// see docs/capacity-signal.md before touching its name or semantics
+	nodesBeingRemediatedMetric = "nodehealthcheck_nodes_being_remediated"
+)
+
+// categories for the reconcile errors metric, so problems are attributable to the
+// failing subsystem
+const (
+	ReconcileErrorCategoryLease    = "lease"
+	ReconcileErrorCategoryTemplate = "template"
+	ReconcileErrorCategoryRBAC     = "rbac"
+	ReconcileErrorCategoryOther    = "other"
+)
+
 var (
 	// NodeHealthCheckOldRemediationCR is a Prometheus metric, which reports the number of old Remediation CRs.
 	// It is an indication for remediation that is pending for a long while, which might indicate a problem with the external remediation mechanism.
 	NodeHealthCheckOldRemediationCR = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "nodehealthcheck_old_remediation_cr",
+			Name: oldRemediationCRMetric,
 			Help: "Number of old remediation CRs detected by NodeHealthChecks",
 		}, []string{"name", "namespace"},
 	)
+
+	// NodeHealthCheckRemediationsTotal counts remediations by remediator kind and result.
+	// Together with the "started" result it allows computing a per-remediator success rate.
+	NodeHealthCheckRemediationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: remediationsTotalMetric,
+			Help: "Number of remediations started and completed by NodeHealthChecks, by remediator kind and result",
+		}, []string{"remediator", "result"},
+	)
+
+	// NodeHealthCheckUnhealthyNodeDuration tracks the time nodes spent in remediation until they
+	// turned healthy again. This is the cluster's mean-time-to-repair source.
+	NodeHealthCheckUnhealthyNodeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    unhealthyDurationMetric,
+			Help:    "Time from remediation start until the node turned healthy again",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+		}, []string{"remediator"},
+	)
+
+	// NodeHealthCheckEscalationsTotal counts how often remediation needed to be escalated
+	// to another remediator.
+	NodeHealthCheckEscalationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: escalationsTotalMetric,
+			Help: "Number of escalations to another remediator triggered by NodeHealthChecks",
+		}, []string{"remediator"},
+	)
+
+	// NodeHealthCheckFallbacksTotal counts how often remediation fell back to the
+	// designated fallback template because creating a remediation CR persistently failed.
+	NodeHealthCheckFallbacksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fallbacksTotalMetric,
+			Help: "Number of remediations which fell back to the fallback template after persistent CR creation failures",
+		}, []string{"remediator"},
+	)
+
+	// NodeHealthCheckOngoingRemediations reports the number of nodes currently being remediated per NHC.
+	NodeHealthCheckOngoingRemediations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: ongoingRemediationsMetric,
+			Help: "Number of nodes currently being remediated per NodeHealthCheck",
+		}, []string{"name"},
+	)
+
+	// NodeHealthCheckSuppressedStatusUpdates counts status updates which were suppressed
+	// because they only contained timestamp churn below the resolution.
+	NodeHealthCheckSuppressedStatusUpdates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: suppressedStatusUpdatesMetric,
+			Help: "Number of NodeHealthCheck status updates suppressed by semantic diffing",
+		}, []string{"name"},
+	)
+
+	// NodeHealthCheckReconcileDuration tracks the time one reconcile of an NHC takes,
+	// per NHC, beyond the unlabeled default controller-runtime metrics.
+	NodeHealthCheckReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    reconcileDurationMetric,
+			Help:    "Time one reconcile of a NodeHealthCheck took",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+		}, []string{"name"},
+	)
+
+	// NodeHealthCheckRemediationCreation tracks how long creating a remediation CR took,
+	// high values delay fencing.
+	NodeHealthCheckRemediationCreation = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    remediationCreationMetric,
+			Help:    "Time creating a remediation CR took",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+		}, []string{"name"},
+	)
+
+	// NodeHealthCheckReconcileErrors counts reconcile errors per NHC, bucketed by the
+	// failing subsystem.
+	NodeHealthCheckReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: reconcileErrorsMetric,
+			Help: "Number of NodeHealthCheck reconcile errors, by error category",
+		}, []string{"name", "category"},
+	)
+
+	// NodeHealthCheckDetectionLatency tracks the time from a node condition flipping
+	// to unhealthy until the remediation CR was created. This is the time-to-detect,
+	// it includes the condition duration configured in the unhealthy criteria.
+	NodeHealthCheckDetectionLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    detectionLatencyMetric,
+			Help:    "Time from the node condition transition until the remediation CR was created",
+			Buckets: prometheus.ExponentialBuckets(0.5, 2, 14),
+		}, []string{"name"},
+	)
+
+	// NodeHealthCheckFlappingNode reports whether a node is currently considered
+	// flapping by an NHC's flapping detection, see spec.flappingDetection.
+	NodeHealthCheckFlappingNode = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: flappingNodeMetric,
+			Help: "Whether a node's Ready condition flips often enough to be considered flapping (1) or not (0)",
+		}, []string{"name", "node"},
+	)
+
+	// NodeHealthCheckGlobalPause reports whether the cluster wide global remediation
+	// pause is active. Alert on this staying at 1 after an incident is over.
+	NodeHealthCheckGlobalPause = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: globalPauseMetric,
+			Help: "Whether the global remediation pause is active (1) or not (0)",
+		},
+	)
+
+	// NodeHealthCheckNodesBeingRemediated reports the cluster wide number of nodes
+	// currently fenced or being remediated, summed over all NodeHealthChecks. It is
+	// the capacity signal for autoscalers adding replacement capacity during
+	// remediation storms, its contract is documented in docs/capacity-signal.md.
+	NodeHealthCheckNodesBeingRemediated = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: nodesBeingRemediatedMetric,
+			Help: "Number of nodes currently fenced or being remediated, over all NodeHealthChecks",
+		},
+	)
 )
 
+const (
+	// RemediationResultStarted is the result label value for started remediations
+	RemediationResultStarted = "started"
+	// RemediationResultSucceeded is the result label value for remediations whose node turned healthy again
+	RemediationResultSucceeded = "succeeded"
+)
+
+// staticLabels are attached to every metric registered by
+// InitializeNodeHealthCheckMetrics, see SetStaticLabels
+var staticLabels prometheus.Labels
+
+// SetStaticLabels records labels attached to all exported metrics, e.g. a cluster_id
+// which lets fleet monitoring federating many clusters tell their series apart without
+// relabeling rules. Must be called before InitializeNodeHealthCheckMetrics.
+func SetStaticLabels(labels map[string]string) {
+	staticLabels = labels
+}
+
 func InitializeNodeHealthCheckMetrics() {
-	metrics.Registry.MustRegister(
+	registerer := prometheus.Registerer(metrics.Registry)
+	if len(staticLabels) > 0 {
+		registerer = prometheus.WrapRegistererWith(staticLabels, metrics.Registry)
+	}
+	registerer.MustRegister(
 		NodeHealthCheckOldRemediationCR,
+		NodeHealthCheckRemediationsTotal,
+		NodeHealthCheckUnhealthyNodeDuration,
+		NodeHealthCheckEscalationsTotal,
+		NodeHealthCheckFallbacksTotal,
+		NodeHealthCheckOngoingRemediations,
+		NodeHealthCheckSuppressedStatusUpdates,
+		NodeHealthCheckReconcileDuration,
+		NodeHealthCheckRemediationCreation,
+		NodeHealthCheckReconcileErrors,
+		NodeHealthCheckDetectionLatency,
+		NodeHealthCheckGlobalPause,
+		NodeHealthCheckNodesBeingRemediated,
+		NodeHealthCheckFlappingNode,
+		NodeHealthCheckHeldNodeLeases,
+		NodeHealthCheckLeaseDenials,
+		NodeHealthCheckLeaseRequestFailures,
+		NodeHealthCheckLeaseOverdueInvalidations,
+		NodeHealthCheckLeaseDenialBackoff,
+		NodeHealthCheckSweptLeases,
+		NodeHealthCheckLastMHCEvaluation,
 	)
 }
 
@@ -43,3 +242,153 @@ func ObserveNodeHealthCheckOldRemediationCR(name string, namespace string) {
 		"namespace": namespace,
 	}).Inc()
 }
+
+// ObserveNodeHealthCheckRemediationCreated counts a started remediation for the given remediator kind
+func ObserveNodeHealthCheckRemediationCreated(remediator string) {
+	NodeHealthCheckRemediationsTotal.With(prometheus.Labels{
+		"remediator": remediator,
+		"result":     RemediationResultStarted,
+	}).Inc()
+}
+
+// ObserveNodeHealthCheckRemediationSucceeded counts a successful remediation and records
+// how long the node was being remediated
+func ObserveNodeHealthCheckRemediationSucceeded(remediator string, unhealthyDuration time.Duration) {
+	NodeHealthCheckRemediationsTotal.With(prometheus.Labels{
+		"remediator": remediator,
+		"result":     RemediationResultSucceeded,
+	}).Inc()
+	NodeHealthCheckUnhealthyNodeDuration.With(prometheus.Labels{
+		"remediator": remediator,
+	}).Observe(unhealthyDuration.Seconds())
+}
+
+// ObserveNodeHealthCheckEscalated counts an escalation away from the given remediator kind
+func ObserveNodeHealthCheckEscalated(remediator string) {
+	NodeHealthCheckEscalationsTotal.With(prometheus.Labels{
+		"remediator": remediator,
+	}).Inc()
+}
+
+// ObserveNodeHealthCheckRemediationFallback counts a fallback away from the given
+// remediator kind after persistent CR creation failures
+func ObserveNodeHealthCheckRemediationFallback(remediator string) {
+	NodeHealthCheckFallbacksTotal.With(prometheus.Labels{
+		"remediator": remediator,
+	}).Inc()
+}
+
+// ObserveNodeHealthCheckSuppressedStatusUpdate counts a status update which was suppressed
+// by semantic diffing for the given NodeHealthCheck
+func ObserveNodeHealthCheckSuppressedStatusUpdate(name string) {
+	NodeHealthCheckSuppressedStatusUpdates.With(prometheus.Labels{
+		"name": name,
+	}).Inc()
+}
+
+// ObserveNodeHealthCheckReconcileDuration records the duration of one reconcile of the
+// given NodeHealthCheck
+func ObserveNodeHealthCheckReconcileDuration(name string, duration time.Duration) {
+	NodeHealthCheckReconcileDuration.With(prometheus.Labels{
+		"name": name,
+	}).Observe(duration.Seconds())
+}
+
+// ObserveNodeHealthCheckRemediationCreation records how long creating a remediation CR
+// took for the given NodeHealthCheck
+func ObserveNodeHealthCheckRemediationCreation(name string, duration time.Duration) {
+	NodeHealthCheckRemediationCreation.With(prometheus.Labels{
+		"name": name,
+	}).Observe(duration.Seconds())
+}
+
+// ObserveNodeHealthCheckReconcileError counts a reconcile error of the given
+// NodeHealthCheck in the given category, one of the ReconcileErrorCategory* constants
+func ObserveNodeHealthCheckReconcileError(name string, category string) {
+	NodeHealthCheckReconcileErrors.With(prometheus.Labels{
+		"name":     name,
+		"category": category,
+	}).Inc()
+}
+
+// ObserveNodeHealthCheckDetectionLatency records the time from the node condition
+// transition until the remediation CR was created for the given NodeHealthCheck
+func ObserveNodeHealthCheckDetectionLatency(name string, latency time.Duration) {
+	NodeHealthCheckDetectionLatency.With(prometheus.Labels{
+		"name": name,
+	}).Observe(latency.Seconds())
+}
+
+// SetGlobalPause updates whether the global remediation pause is active
+func SetGlobalPause(active bool) {
+	if active {
+		NodeHealthCheckGlobalPause.Set(1)
+	} else {
+		NodeHealthCheckGlobalPause.Set(0)
+	}
+}
+
+// SetNodeHealthCheckNodeFlapping updates whether the given node is considered flapping
+// by the given NodeHealthCheck
+func SetNodeHealthCheckNodeFlapping(name string, node string, flapping bool) {
+	value := float64(0)
+	if flapping {
+		value = 1
+	}
+	NodeHealthCheckFlappingNode.With(prometheus.Labels{
+		"name": name,
+		"node": node,
+	}).Set(value)
+}
+
+// ClearNodeHealthCheckNodeFlapping drops the flapping metric of a node which isn't
+// tracked by the given NodeHealthCheck anymore
+func ClearNodeHealthCheckNodeFlapping(name string, node string) {
+	NodeHealthCheckFlappingNode.Delete(prometheus.Labels{
+		"name": name,
+		"node": node,
+	})
+}
+
+// SetNodeHealthCheckOngoingRemediations updates the number of nodes currently being remediated
+// by the given NodeHealthCheck
+func SetNodeHealthCheckOngoingRemediations(name string, count int) {
+	NodeHealthCheckOngoingRemediations.With(prometheus.Labels{
+		"name": name,
+	}).Set(float64(count))
+	setNodesBeingRemediated(name, count)
+}
+
+// ClearNodeHealthCheckOngoingRemediations drops the contribution of a deleted
+// NodeHealthCheck from the remediation gauges
+func ClearNodeHealthCheckOngoingRemediations(name string) {
+	NodeHealthCheckOngoingRemediations.Delete(prometheus.Labels{
+		"name": name,
+	})
+	nodesBeingRemediatedMutex.Lock()
+	defer nodesBeingRemediatedMutex.Unlock()
+	delete(nodesBeingRemediated, name)
+	updateNodesBeingRemediated()
+}
+
+// the per NHC contributions to the cluster wide capacity signal, the unlabeled
+// gauge spares capacity automation a sum() over the per NHC series
+var (
+	nodesBeingRemediatedMutex sync.Mutex
+	nodesBeingRemediated      = map[string]int{}
+)
+
+func setNodesBeingRemediated(name string, count int) {
+	nodesBeingRemediatedMutex.Lock()
+	defer nodesBeingRemediatedMutex.Unlock()
+	nodesBeingRemediated[name] = count
+	updateNodesBeingRemediated()
+}
+
+func updateNodesBeingRemediated() {
+	total := 0
+	for _, count := range nodesBeingRemediated {
+		total += count
+	}
+	NodeHealthCheckNodesBeingRemediated.Set(float64(total))
+}
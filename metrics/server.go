@@ -0,0 +1,148 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// TLSServer serves the metrics registry over HTTPS. On OpenShift the certificate
+// is expected to be issued by the service-ca operator, which also rotates it;
+// the certificate is re-read on every TLS handshake so rotated certs are picked
+// up without a restart. When no certificate files exist (e.g. on vanilla k8s
+// without cert-manager) a self-signed certificate is generated as fallback.
+type TLSServer struct {
+	Address string
+	CertDir string
+	Log     logr.Logger
+
+	// Handler overrides the default metrics handler, letting other components
+	// reuse the TLS serving and certificate rotation setup for their endpoints
+	Handler http.Handler
+
+	selfSigned *tls.Certificate
+}
+
+var _ manager.Runnable = &TLSServer{}
+
+// NewTLSServer returns a TLSServer serving the controller-runtime metrics registry
+// on the given address with certificates from certDir
+func NewTLSServer(address string, certDir string, log logr.Logger) *TLSServer {
+	return &TLSServer{
+		Address: address,
+		CertDir: certDir,
+		Log:     log,
+	}
+}
+
+// Start implements manager.Runnable
+func (s *TLSServer) Start(ctx context.Context) error {
+	handler := s.Handler
+	if handler == nil {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+		handler = mux
+	}
+
+	server := &http.Server{
+		Addr:    s.Address,
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: s.getCertificate,
+		},
+	}
+
+	errChan := make(chan error)
+	go func() {
+		// cert and key are provided by TLSConfig.GetCertificate
+		errChan <- server.ListenAndServeTLS("", "")
+	}()
+
+	s.Log.Info("serving with TLS", "address", s.Address, "certDir", s.CertDir)
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	case err := <-errChan:
+		return err
+	}
+}
+
+// getCertificate loads the serving cert on each handshake, which transparently
+// handles certificate rotation. It falls back to a generated self-signed cert
+// when no cert files are found.
+func (s *TLSServer) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certFile := filepath.Join(s.CertDir, "tls.crt")
+	keyFile := filepath.Join(s.CertDir, "tls.key")
+	if _, err := os.Stat(certFile); err == nil {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			s.Log.Error(err, "failed to load serving cert, falling back to self-signed cert")
+			return s.getSelfSignedCertificate()
+		}
+		return &cert, nil
+	}
+	return s.getSelfSignedCertificate()
+}
+
+func (s *TLSServer) getSelfSignedCertificate() (*tls.Certificate, error) {
+	if s.selfSigned != nil {
+		return s.selfSigned, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "node-healthcheck-operator-metrics"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	s.selfSigned = &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+	s.Log.Info("no serving cert found, using a generated self-signed certificate")
+	return s.selfSigned, nil
+}
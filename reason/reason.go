@@ -0,0 +1,56 @@
+// Package reason defines the annotation contract by which the Node Healthcheck
+// Operator tells remediators why it triggered a remediation. NHC stamps these
+// annotations on every remediation CR it creates, so remediators like the Fence
+// Agents Remediation operator can choose softer or harder actions depending on what
+// went wrong, e.g. a soft reboot for a NotReady kubelet versus power fencing for an
+// unreachable node.
+//
+// All annotations are informational: a missing annotation means the information is
+// unknown, remediators must never fail over absent or unparsable values.
+package reason
+
+// annotationPrefix groups the reason annotations
+const annotationPrefix = "reason.remediation.medik8s.io/"
+
+const (
+	// TriggerAnnotation names the kinds of unhealthy criteria which triggered the
+	// remediation, as a comma separated list of the Trigger* values below.
+	TriggerAnnotation = annotationPrefix + "trigger"
+
+	// UnhealthyConditionsAnnotation lists the node conditions which matched the
+	// NHC's unhealthy criteria, as comma separated Type=Status:Duration entries,
+	// e.g. "Ready=Unknown:5m0s". The duration is the configured threshold the
+	// condition exceeded, not how long it has been failing. Only set when the
+	// trigger includes Condition.
+	UnhealthyConditionsAnnotation = annotationPrefix + "unhealthy-conditions"
+
+	// EscalationStepAnnotation counts the remediation attempts of the current
+	// unhealthiness: "1" for the first remediator, growing by one every time
+	// remediation escalates to another remediator.
+	EscalationStepAnnotation = annotationPrefix + "escalation-step"
+
+	// AttemptIDAnnotation uniquely identifies one remediation attempt, as an
+	// idempotency token. NHC generates the ID when remediation starts, persists it,
+	// and stamps the same ID again when it re-creates a CR for the same attempt,
+	// e.g. after an operator restart. A remediator which already acted on an ID
+	// can thereby detect the duplicate and must not fence a second time. A new
+	// unhealthiness of the same node gets a new ID.
+	AttemptIDAnnotation = annotationPrefix + "attempt-id"
+)
+
+// Values of the TriggerAnnotation
+const (
+	// TriggerCondition means a node condition matched an unhealthy criterion for
+	// longer than its configured duration
+	TriggerCondition = "Condition"
+
+	// TriggerEvent means node events matched spec.unhealthyEvents
+	TriggerEvent = "Event"
+
+	// TriggerCEL means a spec.unhealthyCEL expression evaluated to true
+	TriggerCEL = "CEL"
+
+	// TriggerResourceDegraded means a node resource observation degraded beyond
+	// the configured thresholds
+	TriggerResourceDegraded = "ResourceDegraded"
+)
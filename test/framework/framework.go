@@ -0,0 +1,208 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package framework provides reusable test helpers for the NodeHealthCheck semantics:
+// scheme registration, a fake remediation CRD, and factories for Nodes, NHCs,
+// remediation templates and remediation CRs. Remediator projects integrating with
+// NHC can use it for their tests instead of copying the setup code.
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+
+	"github.com/medik8s/node-healthcheck-operator/api/v1alpha1"
+)
+
+// InitScheme registers all types needed for testing NHC semantics on the given scheme
+func InitScheme(s *runtime.Scheme) error {
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		return err
+	}
+	return v1alpha1.AddToScheme(s)
+}
+
+// NewRemediationCR returns a remediation CR for the given node, as created from the
+// template returned by NewRemediationTemplate, owned by the NHC returned by NewNodeHealthCheck
+func NewRemediationCR(nodeName string) unstructured.Unstructured {
+	cr := unstructured.Unstructured{}
+	cr.SetName(nodeName)
+	cr.SetNamespace("default")
+	cr.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   RemediationCRD().Spec.Group,
+		Version: RemediationCRD().Spec.Versions[0].Name,
+		Kind:    RemediationCRD().Spec.Names.Kind,
+	})
+	cr.SetOwnerReferences([]metav1.OwnerReference{
+		{
+			APIVersion: "remediation.medik8s.io/v1alpha1",
+			Kind:       "NodeHealthCheck",
+			Name:       "test",
+		},
+	})
+	return cr
+}
+
+// NewRemediationTemplate returns a remediation template matching the fake remediation CRD
+func NewRemediationTemplate() runtime.Object {
+	r := map[string]interface{}{
+		"kind":       "InfrastructureRemediation",
+		"apiVersion": "test.medik8s.io/v1alpha1",
+		"metadata":   map[string]interface{}{},
+		"spec": map[string]interface{}{
+			"size": "foo",
+		},
+	}
+	template := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": r,
+			},
+		},
+	}
+	template.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "test.medik8s.io",
+		Version: "v1alpha1",
+		Kind:    "InfrastructureRemediationTemplate",
+	})
+	template.SetGenerateName("remediation-template-name-")
+	template.SetNamespace("default")
+	template.SetName("template")
+	return template.DeepCopyObject()
+}
+
+// NewNodeHealthCheck returns an NHC named "test" selecting all nodes and using the
+// template returned by NewRemediationTemplate
+func NewNodeHealthCheck() *v1alpha1.NodeHealthCheck {
+	unhealthy := intstr.FromString("51%")
+	return &v1alpha1.NodeHealthCheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NodeHealthCheck",
+			APIVersion: "remediation.medik8s.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+		Spec: v1alpha1.NodeHealthCheckSpec{
+			Selector:   metav1.LabelSelector{},
+			MinHealthy: &unhealthy,
+			UnhealthyConditions: []v1alpha1.UnhealthyCondition{
+				{
+					Type:     v1.NodeReady,
+					Status:   v1.ConditionFalse,
+					Duration: metav1.Duration{Duration: time.Second * 300},
+				},
+			},
+			RemediationTemplate: &v1.ObjectReference{
+				Kind:       "InfrastructureRemediationTemplate",
+				APIVersion: "test.medik8s.io/v1alpha1",
+				Namespace:  "default",
+				Name:       "template",
+			},
+		},
+	}
+}
+
+// NewNodes returns the given number of unhealthy and healthy nodes, named
+// unhealthy-node-N and healthy-node-N
+func NewNodes(unhealthy int, healthy int) []runtime.Object {
+	o := make([]runtime.Object, 0, healthy+unhealthy)
+	for i := unhealthy; i > 0; i-- {
+		node := NewNode(fmt.Sprintf("unhealthy-node-%d", i), v1.NodeReady, v1.ConditionFalse, time.Minute*10)
+		o = append(o, node)
+	}
+	for i := healthy; i > 0; i-- {
+		o = append(o, NewNode(fmt.Sprintf("healthy-node-%d", i), v1.NodeReady, v1.ConditionTrue, time.Minute*10))
+	}
+	return o
+}
+
+// NewNode returns a node with the given condition, which transitioned the given duration ago
+func NewNode(name string, t v1.NodeConditionType, s v1.ConditionStatus, d time.Duration) runtime.Object {
+	return runtime.Object(
+		&v1.Node{
+			TypeMeta:   metav1.TypeMeta{Kind: "Node"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{
+					{
+						Type:               t,
+						Status:             s,
+						LastTransitionTime: metav1.Time{Time: time.Now().Add(-d)},
+					},
+				},
+			},
+		})
+}
+
+// RemediationCRD returns a fake remediation CRD whose CRs have free form spec and status,
+// for installing into test clusters
+func RemediationCRD() *apiextensions.CustomResourceDefinition {
+	return remediationCRD.DeepCopy()
+}
+
+var remediationCRD = &apiextensions.CustomResourceDefinition{
+	TypeMeta: metav1.TypeMeta{
+		APIVersion: apiextensions.SchemeGroupVersion.String(),
+		Kind:       "CustomResourceDefinition",
+	},
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "infrastructureremediations.medik8s.io",
+	},
+	Spec: apiextensions.CustomResourceDefinitionSpec{
+		Group: "test.medik8s.io",
+		Scope: apiextensions.NamespaceScoped,
+		Names: apiextensions.CustomResourceDefinitionNames{
+			Kind:   "InfrastructureRemediation",
+			Plural: "infrastructureremediations",
+		},
+		Versions: []apiextensions.CustomResourceDefinitionVersion{
+			{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+				Subresources: &apiextensions.CustomResourceSubresources{
+					Status: &apiextensions.CustomResourceSubresourceStatus{},
+				},
+				Schema: &apiextensions.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensions.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextensions.JSONSchemaProps{
+							"spec": {
+								Type:                   "object",
+								XPreserveUnknownFields: pointer.BoolPtr(true),
+							},
+							"status": {
+								Type:                   "object",
+								XPreserveUnknownFields: pointer.BoolPtr(true),
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}